@@ -28,6 +28,8 @@ var (
 	Default = register(nil)
 
 	SubtaskErr = register(&Type{meta: "subtask"})
+	// Partial tells that a subtask finished with part of its work skipped, the task ends as TASK_PARTIAL
+	Partial = register(&Type{meta: "partial"})
 	//400+
 	BadInput     = register(&Type{httpCode: http.StatusBadRequest, meta: "bad-input"})
 	Unauthorized = register(&Type{httpCode: http.StatusUnauthorized, meta: "unauthorized"})
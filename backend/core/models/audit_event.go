@@ -0,0 +1,60 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+)
+
+const (
+	AUDIT_ACTION_CREATE  = "create"
+	AUDIT_ACTION_UPDATE  = "update"
+	AUDIT_ACTION_DELETE  = "delete"
+	AUDIT_ACTION_TRIGGER = "trigger"
+
+	AUDIT_TARGET_CONNECTION   = "connection"
+	AUDIT_TARGET_SCOPE_CONFIG = "scope-config"
+	AUDIT_TARGET_BLUEPRINT    = "blueprint"
+	AUDIT_TARGET_PROJECT      = "project"
+	AUDIT_TARGET_API_KEY      = "api-key"
+	AUDIT_TARGET_PIPELINE     = "pipeline"
+	AUDIT_TARGET_ROLE_BINDING = "role-binding"
+)
+
+// AuditEvent records who changed which configuration and how, rows are never updated or deleted
+type AuditEvent struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+	// Actor is the user name from the auth proxy, or the creator of the api key
+	Actor      string `gorm:"type:varchar(255);index" json:"actor"`
+	ActorEmail string `gorm:"type:varchar(255)" json:"actorEmail"`
+	// ApiKeyName is set when the request was authenticated by an api key
+	ApiKeyName   string `gorm:"type:varchar(255)" json:"apiKeyName"`
+	SourceIp     string `gorm:"type:varchar(100)" json:"sourceIp"`
+	Action       string `gorm:"type:varchar(50);index" json:"action"`
+	TargetType   string `gorm:"type:varchar(50);index" json:"targetType"`
+	TargetPlugin string `gorm:"type:varchar(100)" json:"targetPlugin"`
+	TargetId     string `gorm:"type:varchar(255);index" json:"targetId"`
+	TargetName   string `gorm:"type:varchar(255)" json:"targetName"`
+	// Diff is a json object of the changed fields with sensitive values redacted: {"field": {"before": x, "after": y}}
+	Diff string `gorm:"type:text" json:"diff"`
+}
+
+func (AuditEvent) TableName() string {
+	return "_devlake_audit_events"
+}
@@ -0,0 +1,47 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CollectorCheckpoint stores the pagination progress of a collector periodically, so a crashed collection
+// could be resumed from the last checkpoint by the next run with the same configuration
+type CollectorCheckpoint struct {
+	RawDataTable  string `gorm:"primaryKey;type:varchar(255)" json:"rawDataTable"`
+	RawDataParams string `gorm:"primaryKey;type:varchar(255);index" json:"rawDataParams"`
+	// CollectorKey is the sha256 of the collector identity, i.e. the UrlTemplate of ApiCollector
+	CollectorKey string `gorm:"primaryKey;type:varchar(64)" json:"collectorKey"`
+	// Fingerprint is the sha256 of the collection configuration, a checkpoint with a different fingerprint is discarded
+	Fingerprint string `gorm:"type:varchar(64)" json:"fingerprint"`
+	// Page and Skip of the next page to be fetched
+	Page int `json:"page"`
+	Skip int `json:"skip"`
+	// Cursor is the json encoded cursor of the next page, i.e. CustomData of ApiCollector or EndCursor of GraphqlCollector
+	Cursor json.RawMessage `gorm:"type:json" json:"cursor"`
+	// LastRawId is the id of the last raw row saved before the checkpoint, rows after it would be deleted when resuming
+	LastRawId uint64    `json:"lastRawId"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (CollectorCheckpoint) TableName() string {
+	return "_devlake_collector_checkpoints"
+}
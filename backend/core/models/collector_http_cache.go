@@ -0,0 +1,42 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+)
+
+// CollectorHttpCache stores the validators (ETag/Last-Modified) of the latest response of a request sent
+// by ApiCollector, so the request could be sent conditionally by the next run
+type CollectorHttpCache struct {
+	RawDataTable  string `gorm:"primaryKey;type:varchar(255)" json:"rawDataTable"`
+	RawDataParams string `gorm:"primaryKey;type:varchar(255);index" json:"rawDataParams"`
+	// UrlHash is the sha256 of the request url (including query string)
+	UrlHash      string `gorm:"primaryKey;type:varchar(64)" json:"urlHash"`
+	Url          string `json:"url"`
+	ETag         string `gorm:"column:etag;type:varchar(255)" json:"etag"`
+	LastModified string `gorm:"type:varchar(255)" json:"lastModified"`
+	// ReusedAt is the last time the server responded 304 Not Modified and the raw rows got reused
+	ReusedAt  *time.Time `json:"reusedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+func (CollectorHttpCache) TableName() string {
+	return "_devlake_collector_http_caches"
+}
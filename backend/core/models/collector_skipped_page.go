@@ -0,0 +1,44 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CollectorSkippedPage records a page that ApiCollector failed to fetch and skipped, so it could be
+// re-collected by the next run instead of failing the whole subtask
+type CollectorSkippedPage struct {
+	ID            uint64          `gorm:"primaryKey" json:"id"`
+	RawDataTable  string          `gorm:"type:varchar(255);index" json:"rawDataTable"`
+	RawDataParams string          `gorm:"type:varchar(255);index" json:"rawDataParams"`
+	UrlTemplate   string          `json:"urlTemplate"`
+	Method        string          `gorm:"type:varchar(20)" json:"method"`
+	Url           string          `json:"url"`
+	Query         string          `json:"query"`
+	Header        json.RawMessage `gorm:"type:json" json:"header"`
+	Body          json.RawMessage `gorm:"type:json" json:"body"`
+	Input         json.RawMessage `gorm:"type:json" json:"input"`
+	Error         string          `json:"error"`
+	CreatedAt     time.Time       `json:"createdAt"`
+}
+
+func (CollectorSkippedPage) TableName() string {
+	return "_devlake_collector_skipped_pages"
+}
@@ -28,8 +28,12 @@ const (
 )
 
 type User struct {
-	Name  string
-	Email string
+	Name   string
+	Email  string
+	Groups []string
+	// ApiKeyName is set when the user is authenticated by an api key
+	ApiKeyName string
+	SourceIp   string
 }
 
 type Model struct {
@@ -0,0 +1,82 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package codequality
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// CqRepoCoverage is the test coverage of a repo at a commit, as reported by the CI
+type CqRepoCoverage struct {
+	common.NoPKModel
+	RepoId          string `gorm:"primaryKey;type:varchar(255)"`
+	CommitSha       string `gorm:"primaryKey;type:varchar(40)"`
+	Format          string `gorm:"type:varchar(20);comment:Format of the report | cobertura | lcov | gocover"`
+	FileCount       int
+	LinesToCover    int
+	CoveredLines    int
+	UncoveredLines  int
+	LineCoverage    float64 `gorm:"comment:Percentage of covered lines"`
+	BranchesToCover int
+	CoveredBranches int
+	BranchCoverage  *float64 `gorm:"comment:Percentage of covered branches, null if the report has no branch data"`
+	ReportedDate    time.Time
+}
+
+func (CqRepoCoverage) TableName() string {
+	return "cq_repo_coverages"
+}
+
+// CqFileCoverage is the test coverage of a file at a commit, the line ranges allow computing the coverage of changed lines
+type CqFileCoverage struct {
+	common.NoPKModel
+	RepoId              string `gorm:"primaryKey;type:varchar(255)"`
+	CommitSha           string `gorm:"primaryKey;type:varchar(40)"`
+	FilePath            string `gorm:"primaryKey;type:varchar(400)"`
+	LinesToCover        int
+	CoveredLines        int
+	UncoveredLines      int
+	BranchesToCover     int
+	CoveredBranches     int
+	CoveredLineRanges   string `gorm:"type:text;comment:Comma separated line ranges, e.g. 1-5,8"`
+	UncoveredLineRanges string `gorm:"type:text;comment:Comma separated line ranges, e.g. 6-7,9"`
+}
+
+func (CqFileCoverage) TableName() string {
+	return "cq_file_coverages"
+}
+
+// CqPullRequestCoverage is the test coverage of the lines a pull request adds, as of the report of its head commit
+type CqPullRequestCoverage struct {
+	common.NoPKModel
+	PullRequestId  string `gorm:"primaryKey;type:varchar(255)"`
+	RepoId         string `gorm:"type:varchar(255)"`
+	CommitSha      string `gorm:"type:varchar(40);comment:Head commit the report was uploaded for"`
+	ChangedLines   int    `gorm:"comment:Lines added by the pull request which are still in its head commit"`
+	LinesToCover   int    `gorm:"comment:Changed lines instrumented by the report"`
+	CoveredLines   int
+	UncoveredLines int
+	LineCoverage   float64 `gorm:"comment:Percentage of covered changed lines"`
+	ReportedDate   time.Time
+}
+
+func (CqPullRequestCoverage) TableName() string {
+	return "cq_pull_request_coverages"
+}
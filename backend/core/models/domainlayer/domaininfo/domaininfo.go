@@ -24,6 +24,7 @@ import (
 	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
 	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
 	"github.com/apache/incubator-devlake/core/models/domainlayer/qa"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/sbom"
 	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
 )
 
@@ -56,6 +57,9 @@ func GetDomainTablesInfo() []dal.Tabler {
 		&codequality.CqIssue{},
 		&codequality.CqIssueImpact{},
 		&codequality.CqProject{},
+		&codequality.CqRepoCoverage{},
+		&codequality.CqFileCoverage{},
+		&codequality.CqPullRequestCoverage{},
 		// crossdomain
 		&crossdomain.Account{},
 		&crossdomain.BoardRepo{},
@@ -67,6 +71,8 @@ func GetDomainTablesInfo() []dal.Tabler {
 		&crossdomain.PullRequestIssue{},
 		&crossdomain.RefsIssuesDiffs{},
 		&crossdomain.Team{},
+		&crossdomain.TeamAttribution{},
+		&crossdomain.TeamClosure{},
 		&crossdomain.TeamUser{},
 		&crossdomain.User{},
 		&crossdomain.UserAccount{},
@@ -100,5 +106,12 @@ func GetDomainTablesInfo() []dal.Tabler {
 		&qa.QaApi{},
 		&qa.QaTestCase{},
 		&qa.QaTestCaseExecution{},
+		&qa.QaTestCaseFlakiness{},
+		// sbom
+		&sbom.SbomComponent{},
+		&sbom.SbomComponentVersion{},
+		&sbom.SbomSnapshot{},
+		&sbom.SbomSnapshotComponent{},
+		&sbom.SbomComponentVulnerability{},
 	}
 }
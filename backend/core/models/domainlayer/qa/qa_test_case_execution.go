@@ -26,13 +26,16 @@ import (
 // QaTestCaseExecution represents a QA test case execution in the domain layer
 type QaTestCaseExecution struct {
 	domainlayer.DomainEntityExtended
-	QaProjectId  string    `gorm:"type:varchar(255);index;comment:Project ID"`
-	QaTestCaseId string    `gorm:"type:varchar(255);index;comment:Test case ID"`
-	CreateTime   time.Time `gorm:"comment:Test (plan) creation time"`
-	StartTime    time.Time `gorm:"comment:Test start time"`
-	FinishTime   time.Time `gorm:"comment:Test finish time"`
-	CreatorId    string    `gorm:"type:varchar(255);comment:Executor ID"`
-	Status       string    `gorm:"type:varchar(255);comment:Test execution status | PENDING | IN_PROGRESS | SUCCESS | FAILED"` // enum, using string
+	QaProjectId    string    `gorm:"type:varchar(255);index;comment:Project ID"`
+	QaTestCaseId   string    `gorm:"type:varchar(255);index;comment:Test case ID"`
+	CreateTime     time.Time `gorm:"comment:Test (plan) creation time"`
+	StartTime      time.Time `gorm:"comment:Test start time"`
+	FinishTime     time.Time `gorm:"comment:Test finish time"`
+	CreatorId      string    `gorm:"type:varchar(255);comment:Executor ID"`
+	Status         string    `gorm:"type:varchar(255);comment:Test execution status | PENDING | IN_PROGRESS | SUCCESS | FAILED"` // enum, using string
+	DurationMs     *int64    `gorm:"comment:Execution duration in milliseconds"`
+	FailureMessage string    `gorm:"type:text;comment:Failure or error message of a failed execution"`
+	CicdPipelineId string    `gorm:"type:varchar(255);index;comment:ID of the cicd_pipeline that ran the test"`
 }
 
 func (QaTestCaseExecution) TableName() string {
@@ -0,0 +1,45 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package qa
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// QaTestCaseFlakiness summarizes the pass/fail flips of a test case on the same commit or pipeline within a project
+type QaTestCaseFlakiness struct {
+	common.NoPKModel
+	ProjectName     string     `gorm:"primaryKey;type:varchar(100)"`
+	QaTestCaseId    string     `gorm:"primaryKey;type:varchar(500)"`
+	QaProjectId     string     `gorm:"type:varchar(255);index"`
+	TotalRuns       int        `gorm:"comment:Number of finished executions"`
+	FailedRuns      int        `gorm:"comment:Number of failed executions"`
+	FlakyGroups     int        `gorm:"comment:Number of commits or pipelines on which the test both passed and failed"`
+	Flips           int        `gorm:"comment:Number of status changes between consecutive executions on the same commit or pipeline"`
+	FlakinessScore  float64    `gorm:"comment:Flips divided by the consecutive execution pairs on the same commit or pipeline, from 0 to 1"`
+	RerunDurationMs int64      `gorm:"comment:Time spent re-running the test on commits or pipelines where it was flaky"`
+	FirstFlakyDate  *time.Time `gorm:"comment:Time of the first flip"`
+	LastFlakyDate   *time.Time `gorm:"comment:Time of the last flip"`
+	IsQuarantined   bool       `gorm:"comment:Whether the test is flaky enough to be quarantined"`
+}
+
+func (QaTestCaseFlakiness) TableName() string {
+	return "qa_test_case_flakiness"
+}
@@ -0,0 +1,50 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom
+
+import (
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+)
+
+// SbomComponent is a software package regardless of its version, e.g. pkg:maven/org.apache.logging.log4j/log4j-core
+type SbomComponent struct {
+	domainlayer.DomainEntity
+	Type      string `gorm:"type:varchar(100);index;comment:Package type of the purl, e.g. maven, npm, golang"`
+	Namespace string `gorm:"type:varchar(255);comment:Group, scope or vendor of the package"`
+	Name      string `gorm:"type:varchar(255);index"`
+	Purl      string `gorm:"type:varchar(500);comment:Package url without version"`
+}
+
+func (SbomComponent) TableName() string {
+	return "sbom_components"
+}
+
+// SbomComponentVersion is a released version of a component
+type SbomComponentVersion struct {
+	domainlayer.DomainEntity
+	ComponentId string `gorm:"type:varchar(255);index"`
+	Version     string `gorm:"type:varchar(255);index"`
+	Purl        string `gorm:"type:varchar(1000);comment:Package url with version"`
+	Licenses    string `gorm:"type:varchar(1000);comment:Comma separated license ids or expressions"`
+	Supplier    string `gorm:"type:varchar(255)"`
+	Sha256      string `gorm:"type:varchar(64)"`
+}
+
+func (SbomComponentVersion) TableName() string {
+	return "sbom_component_versions"
+}
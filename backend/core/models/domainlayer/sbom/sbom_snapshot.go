@@ -0,0 +1,79 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+)
+
+const (
+	TARGET_REPO            = "repos"
+	TARGET_CICD_DEPLOYMENT = "cicd_deployments"
+)
+
+// SbomSnapshot is a software bill of materials of a repo at a commit, or of the artifacts shipped by a deployment
+type SbomSnapshot struct {
+	domainlayer.DomainEntity
+	TargetType     string `gorm:"type:varchar(50);index:idx_sbom_snapshots_target;comment:repos | cicd_deployments"`
+	TargetId       string `gorm:"type:varchar(255);index:idx_sbom_snapshots_target;comment:ID of the repo or the cicd_deployment"`
+	CommitSha      string `gorm:"type:varchar(40);index"`
+	Format         string `gorm:"type:varchar(20);comment:cyclonedx | spdx"`
+	SpecVersion    string `gorm:"type:varchar(20)"`
+	DocumentId     string `gorm:"type:varchar(500);comment:Serial number of a CycloneDX bom or namespace of a SPDX document"`
+	Tool           string `gorm:"type:varchar(255);comment:Tool which generated the bom"`
+	ComponentCount int
+	GeneratedDate  *time.Time
+}
+
+func (SbomSnapshot) TableName() string {
+	return "sbom_snapshots"
+}
+
+// SbomSnapshotComponent links a snapshot to the component versions it contains
+type SbomSnapshotComponent struct {
+	common.NoPKModel
+	SnapshotId         string `gorm:"primaryKey;type:varchar(255)"`
+	ComponentVersionId string `gorm:"primaryKey;type:varchar(255);index"`
+	IsDirect           bool   `gorm:"comment:Whether the root of the bom depends on the component directly"`
+	Scope              string `gorm:"type:varchar(50);comment:required | optional | excluded"`
+}
+
+func (SbomSnapshotComponent) TableName() string {
+	return "sbom_snapshot_components"
+}
+
+// SbomComponentVulnerability links a component version to a known vulnerability, e.g. CVE-2021-44228
+type SbomComponentVulnerability struct {
+	common.NoPKModel
+	ComponentVersionId string   `gorm:"primaryKey;type:varchar(255)"`
+	VulnerabilityId    string   `gorm:"primaryKey;type:varchar(100);index"`
+	Source             string   `gorm:"type:varchar(100);comment:Database of the vulnerability, e.g. NVD, GitHub, OSV"`
+	Url                string   `gorm:"type:varchar(1000)"`
+	Severity           string   `gorm:"type:varchar(20);comment:CRITICAL | HIGH | MEDIUM | LOW | INFO | UNKNOWN"`
+	Score              *float64 `gorm:"comment:CVSS score"`
+	Cwes               string   `gorm:"type:varchar(255);comment:Comma separated CWE ids"`
+	Description        string   `gorm:"type:text"`
+	AnalysisState      string   `gorm:"type:varchar(50);comment:Impact analysis state reported by the bom, e.g. exploitable, not_affected"`
+}
+
+func (SbomComponentVulnerability) TableName() string {
+	return "sbom_component_vulnerabilities"
+}
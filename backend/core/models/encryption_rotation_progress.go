@@ -0,0 +1,37 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import "time"
+
+// EncryptionRotationProgress records how far an ENCRYPTION_SECRET rotation went for a table, so an interrupted
+// rotation could be resumed deterministically with the same new secret
+type EncryptionRotationProgress struct {
+	EncryptedTable string `gorm:"primaryKey;type:varchar(255)" json:"encryptedTable"`
+	// SecretDigest is the sha256 of the new secret, resuming with a different secret is rejected
+	SecretDigest string `gorm:"type:varchar(64)" json:"secretDigest"`
+	// Rows is the number of rows, ordered by the primary key, that were re-encrypted with the new secret
+	Rows      int       `json:"rows"`
+	Done      bool      `json:"done"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (EncryptionRotationProgress) TableName() string {
+	return "_devlake_encryption_rotation_progresses"
+}
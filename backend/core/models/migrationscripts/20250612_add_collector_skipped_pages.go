@@ -0,0 +1,63 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+var _ plugin.MigrationScript = (*addCollectorSkippedPages)(nil)
+
+type collectorSkippedPage20250612 struct {
+	ID            uint64 `gorm:"primaryKey"`
+	RawDataTable  string `gorm:"type:varchar(255);index"`
+	RawDataParams string `gorm:"type:varchar(255);index"`
+	UrlTemplate   string
+	Method        string `gorm:"type:varchar(20)"`
+	Url           string
+	Query         string
+	Header        json.RawMessage `gorm:"type:json"`
+	Body          json.RawMessage `gorm:"type:json"`
+	Input         json.RawMessage `gorm:"type:json"`
+	Error         string
+	CreatedAt     time.Time
+}
+
+func (collectorSkippedPage20250612) TableName() string {
+	return "_devlake_collector_skipped_pages"
+}
+
+type addCollectorSkippedPages struct{}
+
+func (script *addCollectorSkippedPages) Up(basicRes context.BasicRes) errors.Error {
+	db := basicRes.GetDal()
+	return db.AutoMigrate(&collectorSkippedPage20250612{})
+}
+
+func (*addCollectorSkippedPages) Version() uint64 {
+	return 20250612093000
+}
+
+func (*addCollectorSkippedPages) Name() string {
+	return "add _devlake_collector_skipped_pages"
+}
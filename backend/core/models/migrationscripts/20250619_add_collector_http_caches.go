@@ -0,0 +1,59 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+var _ plugin.MigrationScript = (*addCollectorHttpCaches)(nil)
+
+type collectorHttpCache20250619 struct {
+	RawDataTable  string `gorm:"primaryKey;type:varchar(255)"`
+	RawDataParams string `gorm:"primaryKey;type:varchar(255);index"`
+	UrlHash       string `gorm:"primaryKey;type:varchar(64)"`
+	Url           string
+	ETag          string `gorm:"column:etag;type:varchar(255)"`
+	LastModified  string `gorm:"type:varchar(255)"`
+	ReusedAt      *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (collectorHttpCache20250619) TableName() string {
+	return "_devlake_collector_http_caches"
+}
+
+type addCollectorHttpCaches struct{}
+
+func (script *addCollectorHttpCaches) Up(basicRes context.BasicRes) errors.Error {
+	db := basicRes.GetDal()
+	return db.AutoMigrate(&collectorHttpCache20250619{})
+}
+
+func (*addCollectorHttpCaches) Version() uint64 {
+	return 20250619140000
+}
+
+func (*addCollectorHttpCaches) Name() string {
+	return "add _devlake_collector_http_caches"
+}
@@ -0,0 +1,55 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+var _ plugin.MigrationScript = (*addRoleBindings)(nil)
+
+type roleBinding20250703 struct {
+	archived.Model
+	archived.Creator
+	SubjectType string `gorm:"type:varchar(20)"`
+	Subject     string `gorm:"type:varchar(255);index"`
+	Role        string `gorm:"type:varchar(50)"`
+	ProjectName string `gorm:"type:varchar(255);index"`
+}
+
+func (roleBinding20250703) TableName() string {
+	return "_devlake_role_bindings"
+}
+
+type addRoleBindings struct{}
+
+func (script *addRoleBindings) Up(basicRes context.BasicRes) errors.Error {
+	db := basicRes.GetDal()
+	return db.AutoMigrate(&roleBinding20250703{})
+}
+
+func (*addRoleBindings) Version() uint64 {
+	return 20250703100000
+}
+
+func (*addRoleBindings) Name() string {
+	return "add _devlake_role_bindings"
+}
@@ -0,0 +1,62 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+var _ plugin.MigrationScript = (*addAuditEvents)(nil)
+
+type auditEvent20250710 struct {
+	ID           uint64    `gorm:"primaryKey"`
+	CreatedAt    time.Time `gorm:"index"`
+	Actor        string    `gorm:"type:varchar(255);index"`
+	ActorEmail   string    `gorm:"type:varchar(255)"`
+	ApiKeyName   string    `gorm:"type:varchar(255)"`
+	SourceIp     string    `gorm:"type:varchar(100)"`
+	Action       string    `gorm:"type:varchar(50);index"`
+	TargetType   string    `gorm:"type:varchar(50);index"`
+	TargetPlugin string    `gorm:"type:varchar(100)"`
+	TargetId     string    `gorm:"type:varchar(255);index"`
+	TargetName   string    `gorm:"type:varchar(255)"`
+	Diff         string    `gorm:"type:text"`
+}
+
+func (auditEvent20250710) TableName() string {
+	return "_devlake_audit_events"
+}
+
+type addAuditEvents struct{}
+
+func (script *addAuditEvents) Up(basicRes context.BasicRes) errors.Error {
+	db := basicRes.GetDal()
+	return db.AutoMigrate(&auditEvent20250710{})
+}
+
+func (*addAuditEvents) Version() uint64 {
+	return 20250710100000
+}
+
+func (*addAuditEvents) Name() string {
+	return "add _devlake_audit_events"
+}
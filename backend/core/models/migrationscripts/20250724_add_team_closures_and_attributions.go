@@ -0,0 +1,65 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addTeamClosuresAndAttributions)(nil)
+
+type teamClosure20250724 struct {
+	AncestorId   string `gorm:"primaryKey;type:varchar(255)"`
+	DescendantId string `gorm:"primaryKey;type:varchar(255)"`
+	Depth        int
+	archived.NoPKModel
+}
+
+func (teamClosure20250724) TableName() string {
+	return "team_closures"
+}
+
+type teamAttribution20250724 struct {
+	TeamId     string `gorm:"primaryKey;type:varchar(255)"`
+	EntityType string `gorm:"primaryKey;type:varchar(100)"`
+	EntityId   string `gorm:"primaryKey;type:varchar(255)"`
+	UserId     string `gorm:"type:varchar(255)"`
+	archived.NoPKModel
+}
+
+func (teamAttribution20250724) TableName() string {
+	return "team_attributions"
+}
+
+type addTeamClosuresAndAttributions struct{}
+
+func (script *addTeamClosuresAndAttributions) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, &teamClosure20250724{}, &teamAttribution20250724{})
+}
+
+func (*addTeamClosuresAndAttributions) Version() uint64 {
+	return 20250724100000
+}
+
+func (*addTeamClosuresAndAttributions) Name() string {
+	return "add team_closures and team_attributions"
+}
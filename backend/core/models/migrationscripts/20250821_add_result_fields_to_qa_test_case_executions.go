@@ -0,0 +1,54 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+var _ plugin.MigrationScript = (*addResultFieldsToQaTestCaseExecutions)(nil)
+
+type qaTestCaseExecution20250821 struct {
+	DurationMs     *int64
+	FailureMessage string `gorm:"type:text"`
+	CicdPipelineId string `gorm:"type:varchar(255);index"`
+}
+
+func (qaTestCaseExecution20250821) TableName() string {
+	return "qa_test_case_executions"
+}
+
+type addResultFieldsToQaTestCaseExecutions struct{}
+
+func (*addResultFieldsToQaTestCaseExecutions) Up(basicRes context.BasicRes) errors.Error {
+	db := basicRes.GetDal()
+	if err := db.AutoMigrate(&qaTestCaseExecution20250821{}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (*addResultFieldsToQaTestCaseExecutions) Version() uint64 {
+	return 20250821100000
+}
+
+func (*addResultFieldsToQaTestCaseExecutions) Name() string {
+	return "add duration_ms, failure_message and cicd_pipeline_id to qa_test_case_executions"
+}
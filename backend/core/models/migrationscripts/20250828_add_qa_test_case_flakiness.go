@@ -0,0 +1,64 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addQaTestCaseFlakiness)(nil)
+
+type qaTestCaseFlakiness20250828 struct {
+	archived.NoPKModel
+	ProjectName     string `gorm:"primaryKey;type:varchar(100)"`
+	QaTestCaseId    string `gorm:"primaryKey;type:varchar(500)"`
+	QaProjectId     string `gorm:"type:varchar(255);index"`
+	TotalRuns       int
+	FailedRuns      int
+	FlakyGroups     int
+	Flips           int
+	FlakinessScore  float64
+	RerunDurationMs int64
+	FirstFlakyDate  *time.Time
+	LastFlakyDate   *time.Time
+	IsQuarantined   bool
+}
+
+func (qaTestCaseFlakiness20250828) TableName() string {
+	return "qa_test_case_flakiness"
+}
+
+type addQaTestCaseFlakiness struct{}
+
+func (*addQaTestCaseFlakiness) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, &qaTestCaseFlakiness20250828{})
+}
+
+func (*addQaTestCaseFlakiness) Version() uint64 {
+	return 20250828100000
+}
+
+func (*addQaTestCaseFlakiness) Name() string {
+	return "add qa_test_case_flakiness"
+}
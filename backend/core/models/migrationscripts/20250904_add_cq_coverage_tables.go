@@ -0,0 +1,99 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addCqCoverageTables)(nil)
+
+type cqRepoCoverage20250904 struct {
+	archived.NoPKModel
+	RepoId          string `gorm:"primaryKey;type:varchar(255)"`
+	CommitSha       string `gorm:"primaryKey;type:varchar(40)"`
+	Format          string `gorm:"type:varchar(20)"`
+	FileCount       int
+	LinesToCover    int
+	CoveredLines    int
+	UncoveredLines  int
+	LineCoverage    float64
+	BranchesToCover int
+	CoveredBranches int
+	BranchCoverage  *float64
+	ReportedDate    time.Time
+}
+
+func (cqRepoCoverage20250904) TableName() string {
+	return "cq_repo_coverages"
+}
+
+type cqFileCoverage20250904 struct {
+	archived.NoPKModel
+	RepoId              string `gorm:"primaryKey;type:varchar(255)"`
+	CommitSha           string `gorm:"primaryKey;type:varchar(40)"`
+	FilePath            string `gorm:"primaryKey;type:varchar(400)"`
+	LinesToCover        int
+	CoveredLines        int
+	UncoveredLines      int
+	BranchesToCover     int
+	CoveredBranches     int
+	CoveredLineRanges   string `gorm:"type:text"`
+	UncoveredLineRanges string `gorm:"type:text"`
+}
+
+func (cqFileCoverage20250904) TableName() string {
+	return "cq_file_coverages"
+}
+
+type cqPullRequestCoverage20250904 struct {
+	archived.NoPKModel
+	PullRequestId  string `gorm:"primaryKey;type:varchar(255)"`
+	RepoId         string `gorm:"type:varchar(255)"`
+	CommitSha      string `gorm:"type:varchar(40)"`
+	ChangedLines   int
+	LinesToCover   int
+	CoveredLines   int
+	UncoveredLines int
+	LineCoverage   float64
+	ReportedDate   time.Time
+}
+
+func (cqPullRequestCoverage20250904) TableName() string {
+	return "cq_pull_request_coverages"
+}
+
+type addCqCoverageTables struct{}
+
+func (*addCqCoverageTables) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, &cqRepoCoverage20250904{}, &cqFileCoverage20250904{}, &cqPullRequestCoverage20250904{})
+}
+
+func (*addCqCoverageTables) Version() uint64 {
+	return 20250904100000
+}
+
+func (*addCqCoverageTables) Name() string {
+	return "add cq_repo_coverages, cq_file_coverages and cq_pull_request_coverages"
+}
@@ -0,0 +1,123 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addSbomTables)(nil)
+
+type sbomComponent20250911 struct {
+	archived.DomainEntity
+	Type      string `gorm:"type:varchar(100);index"`
+	Namespace string `gorm:"type:varchar(255)"`
+	Name      string `gorm:"type:varchar(255);index"`
+	Purl      string `gorm:"type:varchar(500)"`
+}
+
+func (sbomComponent20250911) TableName() string {
+	return "sbom_components"
+}
+
+type sbomComponentVersion20250911 struct {
+	archived.DomainEntity
+	ComponentId string `gorm:"type:varchar(255);index"`
+	Version     string `gorm:"type:varchar(255);index"`
+	Purl        string `gorm:"type:varchar(1000)"`
+	Licenses    string `gorm:"type:varchar(1000)"`
+	Supplier    string `gorm:"type:varchar(255)"`
+	Sha256      string `gorm:"type:varchar(64)"`
+}
+
+func (sbomComponentVersion20250911) TableName() string {
+	return "sbom_component_versions"
+}
+
+type sbomSnapshot20250911 struct {
+	archived.DomainEntity
+	TargetType     string `gorm:"type:varchar(50);index:idx_sbom_snapshots_target"`
+	TargetId       string `gorm:"type:varchar(255);index:idx_sbom_snapshots_target"`
+	CommitSha      string `gorm:"type:varchar(40);index"`
+	Format         string `gorm:"type:varchar(20)"`
+	SpecVersion    string `gorm:"type:varchar(20)"`
+	DocumentId     string `gorm:"type:varchar(500)"`
+	Tool           string `gorm:"type:varchar(255)"`
+	ComponentCount int
+	GeneratedDate  *time.Time
+}
+
+func (sbomSnapshot20250911) TableName() string {
+	return "sbom_snapshots"
+}
+
+type sbomSnapshotComponent20250911 struct {
+	archived.NoPKModel
+	SnapshotId         string `gorm:"primaryKey;type:varchar(255)"`
+	ComponentVersionId string `gorm:"primaryKey;type:varchar(255);index"`
+	IsDirect           bool
+	Scope              string `gorm:"type:varchar(50)"`
+}
+
+func (sbomSnapshotComponent20250911) TableName() string {
+	return "sbom_snapshot_components"
+}
+
+type sbomComponentVulnerability20250911 struct {
+	archived.NoPKModel
+	ComponentVersionId string `gorm:"primaryKey;type:varchar(255)"`
+	VulnerabilityId    string `gorm:"primaryKey;type:varchar(100);index"`
+	Source             string `gorm:"type:varchar(100)"`
+	Url                string `gorm:"type:varchar(1000)"`
+	Severity           string `gorm:"type:varchar(20)"`
+	Score              *float64
+	Cwes               string `gorm:"type:varchar(255)"`
+	Description        string `gorm:"type:text"`
+	AnalysisState      string `gorm:"type:varchar(50)"`
+}
+
+func (sbomComponentVulnerability20250911) TableName() string {
+	return "sbom_component_vulnerabilities"
+}
+
+type addSbomTables struct{}
+
+func (*addSbomTables) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(
+		basicRes,
+		&sbomComponent20250911{},
+		&sbomComponentVersion20250911{},
+		&sbomSnapshot20250911{},
+		&sbomSnapshotComponent20250911{},
+		&sbomComponentVulnerability20250911{},
+	)
+}
+
+func (*addSbomTables) Version() uint64 {
+	return 20250911100000
+}
+
+func (*addSbomTables) Name() string {
+	return "add sbom domain tables"
+}
@@ -0,0 +1,56 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+var _ plugin.MigrationScript = (*addEncryptionRotationProgresses)(nil)
+
+type encryptionRotationProgress20251016 struct {
+	EncryptedTable string `gorm:"primaryKey;type:varchar(255)"`
+	SecretDigest   string `gorm:"type:varchar(64)"`
+	Rows           int
+	Done           bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (encryptionRotationProgress20251016) TableName() string {
+	return "_devlake_encryption_rotation_progresses"
+}
+
+type addEncryptionRotationProgresses struct{}
+
+func (*addEncryptionRotationProgresses) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, &encryptionRotationProgress20251016{})
+}
+
+func (*addEncryptionRotationProgresses) Version() uint64 {
+	return 20251016100000
+}
+
+func (*addEncryptionRotationProgresses) Name() string {
+	return "add _devlake_encryption_rotation_progresses"
+}
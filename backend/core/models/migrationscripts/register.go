@@ -138,5 +138,16 @@ func All() []plugin.MigrationScript {
 		new(createQaTables),
 		new(increaseCqIssueComponentLength),
 		new(extendFieldSizeForCq),
+		new(addCollectorSkippedPages),
+		new(addCollectorHttpCaches),
+		new(addCollectorCheckpoints),
+		new(addRoleBindings),
+		new(addAuditEvents),
+		new(addTeamClosuresAndAttributions),
+		new(addResultFieldsToQaTestCaseExecutions),
+		new(addQaTestCaseFlakiness),
+		new(addCqCoverageTables),
+		new(addSbomTables),
+		new(addEncryptionRotationProgresses),
 	}
 }
@@ -0,0 +1,49 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+const (
+	// ROLE_ADMIN can do anything, it is not bound to any project
+	ROLE_ADMIN = "admin"
+	// ROLE_PROJECT_MAINTAINER can manage the project, its blueprint, pipelines and connections
+	ROLE_PROJECT_MAINTAINER = "project-maintainer"
+	// ROLE_VIEWER can read the project, its blueprint, pipelines and connections
+	ROLE_VIEWER = "viewer"
+
+	ROLE_SUBJECT_USER  = "user"
+	ROLE_SUBJECT_GROUP = "group"
+)
+
+// RoleBinding grants a role to a user or a group, on a project for project-maintainer and viewer
+type RoleBinding struct {
+	common.Model
+	common.Creator
+	SubjectType string `json:"subjectType" gorm:"type:varchar(20)" validate:"required,oneof=user group"`
+	// Subject is the user name or email for user bindings, or the group name for group bindings
+	Subject     string `json:"subject" gorm:"type:varchar(255);index" validate:"required"`
+	Role        string `json:"role" gorm:"type:varchar(50)" validate:"required,oneof=admin project-maintainer viewer"`
+	ProjectName string `json:"projectName" gorm:"type:varchar(255);index"`
+}
+
+func (RoleBinding) TableName() string {
+	return "_devlake_role_bindings"
+}
@@ -27,10 +27,11 @@ import (
 
 // ApiResourceInput Contains api request information
 type ApiResourceInput struct {
-	Params  map[string]string      // path variables
-	Query   url.Values             // query string
-	Body    map[string]interface{} // json body
-	Request *http.Request
+	Params   map[string]string      // path variables
+	Query    url.Values             // query string
+	Body     map[string]interface{} // json body
+	Request  *http.Request          // the original request, its Body can be re-read in full
+	ClientIp string                 // address of the client, only taken from proxy headers sent by TRUSTED_PROXIES
 
 	User *common.User
 }
@@ -24,6 +24,7 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
+	"strings"
 
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/utils"
@@ -31,6 +32,15 @@ import (
 
 const EncodeKeyEnvStr = "ENCRYPTION_SECRET"
 
+// SecretRefPrefix is the prefix of values referencing a secret managed by an external secrets provider,
+// i.e. `secret://devlake/github#token`
+const SecretRefPrefix = "secret://"
+
+// IsSecretRef tells if the value is a reference to an external secret rather than the secret itself
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretRefPrefix)
+}
+
 // TODO: maybe move encryption/decryption into helper?
 // AES + Base64 encryption using ENCRYPTION_SECRET in .env as key
 func Encrypt(encryptionSecret, plainText string) (string, errors.Error) {
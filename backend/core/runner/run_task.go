@@ -77,7 +77,19 @@ func RunTask(
 		}
 		finishedAt := time.Now()
 		spentSeconds := finishedAt.Unix() - beganAt.Unix()
-		if err != nil {
+		if err != nil && err.As(errors.Partial) != nil {
+			// the task went through but some subtasks skipped part of their work
+			dbe := db.UpdateColumns(task, []dal.DalSet{
+				{ColumnName: "status", Value: models.TASK_PARTIAL},
+				{ColumnName: "message", Value: err.Error()},
+				{ColumnName: "finished_at", Value: finishedAt},
+				{ColumnName: "spent_seconds", Value: spentSeconds},
+			})
+			if dbe != nil {
+				logger.Error(dbe, "failed to finalize task status into db (task partially succeeded)")
+			}
+			err = nil
+		} else if err != nil {
 			lakeErr := errors.AsLakeErrorType(err)
 			subTaskName := "unknown"
 			if lakeErr = lakeErr.As(errors.SubtaskErr); lakeErr != nil {
@@ -293,6 +305,7 @@ func RunPluginSubTasks(
 	// execute subtasks in order
 	taskCtx.SetProgress(0, steps)
 	subtaskNumber := 0
+	var partialMessages []string
 	for _, subtaskMeta := range subtaskMetas {
 		subtaskCtx, err := taskCtx.SubTaskContext(subtaskMeta.Name)
 		if err != nil {
@@ -329,6 +342,16 @@ func RunPluginSubTasks(
 			start := time.Now()
 			err = runSubtask(basicRes, subtaskCtx, task.ID, subtaskNumber, subtaskMeta.EntryPoint)
 			logger.Info("subtask %s finished in %d ms", subtaskMeta.Name, time.Since(start).Milliseconds())
+			if err != nil && err.As(errors.Partial) != nil {
+				// keep going, the task ends as TASK_PARTIAL
+				logger.Warn(err, "subtask %s finished partially", subtaskMeta.Name)
+				where := dal.Where("task_id = ? and name = ?", task.ID, subtaskCtx.GetName())
+				if dbe := basicRes.GetDal().UpdateColumn(subtask, "message", err.Error(), where); dbe != nil {
+					basicRes.GetLogger().Error(dbe, "error writing subtask %v status to DB", subtaskCtx.GetName())
+				}
+				partialMessages = append(partialMessages, fmt.Sprintf("subtask %s: %s", subtaskMeta.Name, err.Error()))
+				err = nil
+			}
 			if err != nil {
 				err = errors.SubtaskErr.Wrap(err, fmt.Sprintf("subtask %s ended unexpectedly", subtaskMeta.Name), errors.WithData(&subtaskMeta))
 				logger.Error(err, "")
@@ -345,6 +368,9 @@ func RunPluginSubTasks(
 		taskCtx.IncProgress(1)
 	}
 
+	if len(partialMessages) > 0 {
+		return errors.Partial.New(strings.Join(partialMessages, "; "))
+	}
 	return nil
 }
 
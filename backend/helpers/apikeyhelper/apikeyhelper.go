@@ -220,7 +220,12 @@ func (c *ApiKeyHelper) generateApiKey() (apiKey string, hashedApiKey string, err
 }
 
 func (c *ApiKeyHelper) DigestToken(token string) (string, errors.Error) {
-	h := hmac.New(sha256.New, []byte(c.encryptionSecret))
+	// the secret might have been rotated since the helper was created
+	encryptionSecret := strings.TrimSpace(c.cfg.GetString(EncodeKeyEnvStr))
+	if encryptionSecret == "" {
+		encryptionSecret = c.encryptionSecret
+	}
+	h := hmac.New(sha256.New, []byte(encryptionSecret))
 	if _, err := h.Write([]byte(token)); err != nil {
 		c.logger.Error(err, "hmac write api key")
 		return "", errors.Default.Wrap(err, "hmac write token")
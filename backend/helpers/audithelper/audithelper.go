@@ -0,0 +1,224 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audithelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/log"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+const redactedValue = "******"
+
+// sensitiveKeyPattern matches field names holding credentials, in both camelCase and snake_case
+var sensitiveKeyPattern = regexp.MustCompile(`(?i)(password|token|secret|private_?key|app_?key|api_?key|credential)`)
+
+// ignoredDiffKeys are changed by every update and only add noise to the diff
+var ignoredDiffKeys = map[string]bool{
+	"updatedAt": true,
+}
+
+// AuditHelper appends audit events of configuration changes to `_devlake_audit_events`
+type AuditHelper struct {
+	db     dal.Dal
+	logger log.Logger
+}
+
+func NewAuditHelper(basicRes context.BasicRes) *AuditHelper {
+	return &AuditHelper{
+		db:     basicRes.GetDal(),
+		logger: basicRes.GetLogger().Nested("audit"),
+	}
+}
+
+// Record appends the event of the change made by the user, before is nil for creation and after is nil for deletion.
+// The change has been made already, so failures are logged instead of returned.
+func (h *AuditHelper) Record(user *common.User, event *models.AuditEvent, before, after interface{}) {
+	if user != nil {
+		event.Actor = user.Name
+		event.ActorEmail = user.Email
+		event.ApiKeyName = user.ApiKeyName
+		event.SourceIp = user.SourceIp
+	}
+	diff, err := Diff(before, after)
+	if err != nil {
+		h.logger.Error(err, "failed to diff %s %s", event.TargetType, event.TargetId)
+	}
+	event.ID = 0
+	event.CreatedAt = time.Now()
+	event.Diff = diff
+	err = h.db.Create(event)
+	if err != nil {
+		h.logger.Error(err, "failed to record audit event: %s %s %s", event.Action, event.TargetType, event.TargetId)
+	}
+}
+
+// Diff returns a json object of the fields changed from before to after: {"field": {"before": x, "after": y}}.
+// Values of credential fields and fields encrypted by the encdec serializer are redacted.
+func Diff(before, after interface{}) (string, errors.Error) {
+	sensitiveKeys := make(map[string]bool)
+	collectEncDecKeys(reflect.TypeOf(before), sensitiveKeys)
+	collectEncDecKeys(reflect.TypeOf(after), sensitiveKeys)
+	beforeMap, err := toMap(before)
+	if err != nil {
+		return "", err
+	}
+	afterMap, err := toMap(after)
+	if err != nil {
+		return "", err
+	}
+	keys := make(map[string]bool)
+	for key := range beforeMap {
+		keys[key] = true
+	}
+	for key := range afterMap {
+		keys[key] = true
+	}
+	changes := make(map[string]map[string]interface{})
+	for key := range keys {
+		if ignoredDiffKeys[key] {
+			continue
+		}
+		beforeValue, hasBefore := beforeMap[key]
+		afterValue, hasAfter := afterMap[key]
+		if hasBefore && hasAfter && reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+		sensitive := sensitiveKeys[key] || sensitiveKeyPattern.MatchString(key)
+		change := make(map[string]interface{})
+		if hasBefore {
+			change["before"] = redact(beforeValue, sensitive)
+		}
+		if hasAfter {
+			change["after"] = redact(afterValue, sensitive)
+		}
+		changes[key] = change
+	}
+	diff, e := json.Marshal(changes)
+	if e != nil {
+		return "", errors.Default.Wrap(e, "failed to marshal the diff")
+	}
+	return string(diff), nil
+}
+
+// Snapshot returns a deep copy of v, taken before v gets modified in place to diff with it later
+func Snapshot(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	t := rv.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	snapshot := reflect.New(t)
+	if json.Unmarshal(data, snapshot.Interface()) != nil {
+		return nil
+	}
+	return snapshot.Interface()
+}
+
+func toMap(v interface{}) (map[string]interface{}, errors.Error) {
+	if v == nil {
+		return nil, nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to marshal %T", v))
+	}
+	m := make(map[string]interface{})
+	if json.Unmarshal(data, &m) != nil {
+		// not a json object
+		var value interface{}
+		_ = json.Unmarshal(data, &value)
+		m = map[string]interface{}{"value": value}
+	}
+	return m, nil
+}
+
+// collectEncDecKeys collects json names of the fields encrypted in database
+func collectEncDecKeys(t reflect.Type, keys map[string]bool) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			collectEncDecKeys(field.Type, keys)
+			continue
+		}
+		if strings.Contains(field.Tag.Get("gorm"), "serializer:encdec") {
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" {
+				name = field.Name
+			}
+			keys[name] = true
+		}
+	}
+}
+
+func redact(v interface{}, sensitive bool) interface{} {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			redacted[key] = redact(item, sensitive || sensitiveKeyPattern.MatchString(key))
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(value))
+		for i, item := range value {
+			redacted[i] = redact(item, sensitive)
+		}
+		return redacted
+	case string:
+		// keep empty values so the diff tells whether the credential was set
+		if sensitive && value != "" {
+			return redactedValue
+		}
+		return value
+	default:
+		if sensitive {
+			return redactedValue
+		}
+		return value
+	}
+}
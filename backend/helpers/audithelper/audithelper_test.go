@@ -0,0 +1,95 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audithelper
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testConnection struct {
+	ID       uint64 `json:"id"`
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint"`
+	Token    string `json:"token" gorm:"serializer:encdec"`
+	Cert     string `json:"cert" gorm:"serializer:encdec"`
+	Proxy    struct {
+		Url      string `json:"url"`
+		Password string `json:"password"`
+	} `json:"proxy"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+func decodeDiff(t *testing.T, before, after interface{}) map[string]map[string]interface{} {
+	diff, err := Diff(before, after)
+	assert.Nil(t, err)
+	changes := make(map[string]map[string]interface{})
+	assert.Nil(t, json.Unmarshal([]byte(diff), &changes))
+	return changes
+}
+
+func TestDiffUpdate(t *testing.T) {
+	before := &testConnection{ID: 1, Name: "a", Endpoint: "https://a", Token: "t1", Cert: "c", UpdatedAt: "1"}
+	before.Proxy.Url = "http://proxy"
+	after := *before
+	after.Name = "b"
+	after.Token = "t2"
+	after.Proxy.Password = "p"
+	after.UpdatedAt = "2"
+
+	changes := decodeDiff(t, before, &after)
+	assert.Equal(t, map[string]map[string]interface{}{
+		"name":  {"before": "a", "after": "b"},
+		"token": {"before": redactedValue, "after": redactedValue},
+		"proxy": {
+			"before": map[string]interface{}{"url": "http://proxy", "password": ""},
+			"after":  map[string]interface{}{"url": "http://proxy", "password": redactedValue},
+		},
+	}, changes)
+}
+
+func TestDiffCreateAndDelete(t *testing.T) {
+	conn := &testConnection{ID: 1, Name: "a", Token: "t1"}
+	created := decodeDiff(t, nil, conn)
+	assert.Equal(t, map[string]interface{}{"after": "a"}, created["name"])
+	assert.Equal(t, map[string]interface{}{"after": redactedValue}, created["token"])
+	assert.Equal(t, map[string]interface{}{"after": ""}, created["cert"])
+
+	deleted := decodeDiff(t, conn, (*testConnection)(nil))
+	assert.Equal(t, map[string]interface{}{"before": "a"}, deleted["name"])
+	assert.Equal(t, map[string]interface{}{"before": redactedValue}, deleted["token"])
+}
+
+func TestDiffMaps(t *testing.T) {
+	changes := decodeDiff(t,
+		map[string]interface{}{"enable": true, "apiKey": "xxx"},
+		map[string]interface{}{"enable": false, "apiKey": "yyy"},
+	)
+	assert.Equal(t, map[string]interface{}{"before": true, "after": false}, changes["enable"])
+	assert.Equal(t, map[string]interface{}{"before": redactedValue, "after": redactedValue}, changes["apiKey"])
+}
+
+func TestSnapshot(t *testing.T) {
+	conn := &testConnection{ID: 1, Name: "a"}
+	snapshot := Snapshot(conn)
+	conn.Name = "b"
+	assert.Equal(t, &testConnection{ID: 1, Name: "a"}, snapshot)
+	assert.Nil(t, Snapshot((*testConnection)(nil)))
+}
@@ -0,0 +1,409 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidchelper
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/config"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// keysRefreshInterval limits how often the JWKS gets refetched for tokens signed by unknown keys
+const keysRefreshInterval = time.Minute
+
+// OidcProviderOptions holds the settings of OidcProvider
+type OidcProviderOptions struct {
+	IssuerUrl    string   // i.e. https://accounts.example.com, the discovery document is loaded from `/.well-known/openid-configuration`
+	ClientId     string   // client of the authorization code login, always accepted as the audience
+	ClientSecret string   // optional for public clients
+	RedirectUrl  string   // i.e. https://devlake.example.com/api/auth/callback
+	Scopes       []string // default to openid, profile, email and groups
+	Audiences    []string // extra audiences accepted in bearer tokens of machine clients
+	// UsernameClaim is the claim for the user name, default to preferred_username and falls back to email then sub
+	UsernameClaim string
+	// GroupsClaim is the claim for the groups, which are bound to roles by role bindings, default to groups
+	GroupsClaim string
+	Timeout     time.Duration // default to 10s
+}
+
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// OidcProvider logs users in with the authorization code flow and verifies tokens issued by an OpenID Connect provider
+type OidcProvider struct {
+	opts          OidcProviderOptions
+	client        *http.Client
+	mu            sync.Mutex
+	metadata      *providerMetadata
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+	now           func() time.Time
+}
+
+// NewOidcProviderFromConfig creates the OidcProvider configured by OIDC_* variables, nil is returned when OIDC_ENABLED is off
+func NewOidcProviderFromConfig(cfg config.ConfigReader) (*OidcProvider, errors.Error) {
+	if !cfg.GetBool("OIDC_ENABLED") {
+		return nil, nil
+	}
+	return NewOidcProvider(&OidcProviderOptions{
+		IssuerUrl:     cfg.GetString("OIDC_ISSUER_URL"),
+		ClientId:      cfg.GetString("OIDC_CLIENT_ID"),
+		ClientSecret:  cfg.GetString("OIDC_CLIENT_SECRET"),
+		RedirectUrl:   cfg.GetString("OIDC_REDIRECT_URL"),
+		Scopes:        splitList(cfg.GetString("OIDC_SCOPES")),
+		Audiences:     splitList(cfg.GetString("OIDC_AUDIENCES")),
+		UsernameClaim: cfg.GetString("OIDC_USERNAME_CLAIM"),
+		GroupsClaim:   cfg.GetString("OIDC_GROUPS_CLAIM"),
+		Timeout:       cfg.GetDuration("OIDC_TIMEOUT"),
+	})
+}
+
+// NewOidcProvider creates a new OidcProvider, the discovery document is loaded on first use
+func NewOidcProvider(opts *OidcProviderOptions) (*OidcProvider, errors.Error) {
+	if opts.IssuerUrl == "" || opts.ClientId == "" {
+		return nil, errors.BadInput.New("OIDC_ISSUER_URL and OIDC_CLIENT_ID are required by OIDC login")
+	}
+	o := *opts
+	o.IssuerUrl = strings.TrimRight(o.IssuerUrl, "/")
+	if len(o.Scopes) == 0 {
+		o.Scopes = []string{"openid", "profile", "email", "groups"}
+	}
+	if o.UsernameClaim == "" {
+		o.UsernameClaim = "preferred_username"
+	}
+	if o.GroupsClaim == "" {
+		o.GroupsClaim = "groups"
+	}
+	if o.Timeout == 0 {
+		o.Timeout = 10 * time.Second
+	}
+	return &OidcProvider{
+		opts:   o,
+		client: &http.Client{Timeout: o.Timeout},
+		keys:   make(map[string]interface{}),
+		now:    time.Now,
+	}, nil
+}
+
+// AuthCodeURL returns the url of the provider to redirect the user to, the code verifier is sent as a S256 PKCE challenge
+func (p *OidcProvider) AuthCodeURL(state, nonce, codeVerifier string) (string, errors.Error) {
+	oauth2Config, err := p.oauth2Config()
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	return oauth2Config.AuthCodeURL(
+		state,
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	), nil
+}
+
+// Exchange redeems the authorization code and returns the user in the verified id token
+func (p *OidcProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*common.User, errors.Error) {
+	oauth2Config, err := p.oauth2Config()
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+	token, e := oauth2Config.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", codeVerifier))
+	if e != nil {
+		return nil, errors.Unauthorized.Wrap(e, "failed to exchange the authorization code")
+	}
+	rawIdToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIdToken == "" {
+		return nil, errors.Unauthorized.New("id_token is missing in the token response")
+	}
+	claims, err := p.verify(rawIdToken, []string{p.opts.ClientId})
+	if err != nil {
+		return nil, err
+	}
+	if claimNonce, _ := claims["nonce"].(string); claimNonce != nonce {
+		return nil, errors.Unauthorized.New("nonce of the id token doesn't match")
+	}
+	return p.userFromClaims(claims)
+}
+
+// VerifyBearerToken returns the user in the bearer token of a machine client
+func (p *OidcProvider) VerifyBearerToken(rawToken string) (*common.User, errors.Error) {
+	claims, err := p.verify(rawToken, append([]string{p.opts.ClientId}, p.opts.Audiences...))
+	if err != nil {
+		return nil, err
+	}
+	return p.userFromClaims(claims)
+}
+
+func (p *OidcProvider) verify(rawToken string, audiences []string) (jwt.MapClaims, errors.Error) {
+	metadata, err := p.getMetadata()
+	if err != nil {
+		return nil, err
+	}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithLeeway(time.Minute),
+		jwt.WithTimeFunc(p.now),
+	)
+	claims := jwt.MapClaims{}
+	_, e := parser.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(kid)
+	})
+	if e != nil {
+		return nil, errors.Unauthorized.Wrap(e, "invalid token")
+	}
+	if _, e := claims.GetExpirationTime(); e != nil || claims["exp"] == nil {
+		return nil, errors.Unauthorized.New("token without expiration time is not accepted")
+	}
+	tokenAudiences, e := claims.GetAudience()
+	if e != nil {
+		return nil, errors.Unauthorized.Wrap(e, "invalid audience")
+	}
+	for _, aud := range tokenAudiences {
+		for _, accepted := range audiences {
+			if aud == accepted {
+				return claims, nil
+			}
+		}
+	}
+	return nil, errors.Unauthorized.New(fmt.Sprintf("audience %v is not accepted", tokenAudiences))
+}
+
+func (p *OidcProvider) userFromClaims(claims jwt.MapClaims) (*common.User, errors.Error) {
+	user := &common.User{}
+	user.Email, _ = claims["email"].(string)
+	for _, claim := range []string{p.opts.UsernameClaim, "email", "sub"} {
+		if name, ok := claims[claim].(string); ok && name != "" {
+			user.Name = name
+			break
+		}
+	}
+	if user.Name == "" {
+		return nil, errors.Unauthorized.New("user name is missing in the token")
+	}
+	switch groups := claims[p.opts.GroupsClaim].(type) {
+	case string:
+		user.Groups = splitList(groups)
+	case []interface{}:
+		for _, group := range groups {
+			if g, ok := group.(string); ok && g != "" {
+				user.Groups = append(user.Groups, g)
+			}
+		}
+	}
+	return user, nil
+}
+
+func (p *OidcProvider) oauth2Config() (*oauth2.Config, errors.Error) {
+	metadata, err := p.getMetadata()
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Config{
+		ClientID:     p.opts.ClientId,
+		ClientSecret: p.opts.ClientSecret,
+		RedirectURL:  p.opts.RedirectUrl,
+		Scopes:       p.opts.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  metadata.AuthorizationEndpoint,
+			TokenURL: metadata.TokenEndpoint,
+		},
+	}, nil
+}
+
+// getMetadata loads the discovery document, failures are not cached so the provider could be down for a while
+func (p *OidcProvider) getMetadata() (*providerMetadata, errors.Error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+	metadata := &providerMetadata{}
+	err := p.getJson(p.opts.IssuerUrl+"/.well-known/openid-configuration", metadata)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimRight(metadata.Issuer, "/") != p.opts.IssuerUrl {
+		return nil, errors.Default.New(fmt.Sprintf("issuer %s of the discovery document doesn't match OIDC_ISSUER_URL", metadata.Issuer))
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JwksUri == "" {
+		return nil, errors.Default.New("endpoints are missing in the discovery document")
+	}
+	p.metadata = metadata
+	return metadata, nil
+}
+
+// getKey returns the public key of the kid, the JWKS is refetched when the provider rotates its keys
+func (p *OidcProvider) getKey(kid string) (interface{}, error) {
+	metadata, err := p.getMetadata()
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if !p.keysFetchedAt.IsZero() && p.now().Sub(p.keysFetchedAt) < keysRefreshInterval {
+		return nil, errors.Unauthorized.New(fmt.Sprintf("unknown signing key %s", kid))
+	}
+	jwks := &struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	err = p.getJson(metadata.JwksUri, jwks)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]interface{})
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := parseJsonWebKey(&jwk)
+		if err != nil {
+			// keys of unsupported types are skipped
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	p.keys = keys
+	p.keysFetchedAt = p.now()
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, errors.Unauthorized.New(fmt.Sprintf("unknown signing key %s", kid))
+}
+
+// lookupKey finds the key by kid, tokens without kid are accepted only when the provider has a single key
+func (p *OidcProvider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *OidcProvider) getJson(url string, v interface{}) errors.Error {
+	res, e := p.client.Get(url)
+	if e != nil {
+		return errors.Default.Wrap(e, fmt.Sprintf("failed to request %s", url))
+	}
+	defer res.Body.Close()
+	body, e := io.ReadAll(res.Body)
+	if e != nil {
+		return errors.Default.Wrap(e, fmt.Sprintf("failed to read response of %s", url))
+	}
+	if res.StatusCode != http.StatusOK {
+		return errors.Default.New(fmt.Sprintf("unexpected status %d from %s", res.StatusCode, url))
+	}
+	if e := json.Unmarshal(body, v); e != nil {
+		return errors.Default.Wrap(e, fmt.Sprintf("failed to decode response of %s", url))
+	}
+	return nil
+}
+
+func parseJsonWebKey(jwk *jsonWebKey) (interface{}, errors.Error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Default.New(fmt.Sprintf("unsupported curve %s", jwk.Crv))
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, errors.Default.New(fmt.Sprintf("unsupported key type %s", jwk.Kty))
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, errors.Error) {
+	b, e := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if e != nil {
+		return nil, errors.Default.Wrap(e, "invalid base64url value in the JWKS")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
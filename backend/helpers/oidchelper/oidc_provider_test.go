@@ -0,0 +1,265 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidchelper
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAuthorization struct {
+	nonce     string
+	challenge string
+}
+
+// mockOidcProvider is a minimal OpenID Connect provider which approves every authorization request
+type mockOidcProvider struct {
+	*httptest.Server
+	t        *testing.T
+	mu       sync.Mutex
+	keys     map[string]*rsa.PrivateKey
+	codes    map[string]*mockAuthorization
+	claims   jwt.MapClaims
+	jwksHits int
+}
+
+func newMockOidcProvider(t *testing.T) *mockOidcProvider {
+	m := &mockOidcProvider{
+		t:     t,
+		keys:  make(map[string]*rsa.PrivateKey),
+		codes: make(map[string]*mockAuthorization),
+		claims: jwt.MapClaims{
+			"sub":                "u1",
+			"preferred_username": "alice",
+			"email":              "alice@example.com",
+			"groups":             []string{"dev", "ops"},
+		},
+	}
+	m.addKey("k1")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"jwks_uri":               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.jwksHits++
+		keys := make([]map[string]string, 0)
+		for kid, key := range m.keys {
+			keys = append(keys, map[string]string{
+				"kty": "RSA",
+				"kid": kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "code", q.Get("response_type"))
+		assert.Equal(t, "S256", q.Get("code_challenge_method"))
+		code, _ := RandomString()
+		m.mu.Lock()
+		m.codes[code] = &mockAuthorization{nonce: q.Get("nonce"), challenge: q.Get("code_challenge")}
+		m.mu.Unlock()
+		redirect, _ := url.Parse(q.Get("redirect_uri"))
+		redirect.RawQuery = url.Values{"code": {code}, "state": {q.Get("state")}}.Encode()
+		http.Redirect(w, r, redirect.String(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, r.ParseForm())
+		m.mu.Lock()
+		authorization := m.codes[r.PostForm.Get("code")]
+		delete(m.codes, r.PostForm.Get("code"))
+		m.mu.Unlock()
+		challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if authorization == nil || authorization.challenge != base64.RawURLEncoding.EncodeToString(challenge[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		claims := jwt.MapClaims{"aud": "devlake", "nonce": authorization.nonce}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     m.issue("k1", claims, time.Hour),
+		})
+	})
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+func (m *mockOidcProvider) addKey(kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(m.t, err)
+	m.mu.Lock()
+	m.keys[kid] = key
+	m.mu.Unlock()
+}
+
+func (m *mockOidcProvider) issue(kid string, claims jwt.MapClaims, ttl time.Duration) string {
+	allClaims := jwt.MapClaims{
+		"iss": m.URL,
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(ttl).Unix(),
+	}
+	for k, v := range m.claims {
+		allClaims[k] = v
+	}
+	for k, v := range claims {
+		allClaims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, allClaims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(m.keys[kid])
+	assert.Nil(m.t, err)
+	return signed
+}
+
+func newTestProvider(t *testing.T, mock *mockOidcProvider) *OidcProvider {
+	provider, err := NewOidcProvider(&OidcProviderOptions{
+		IssuerUrl:    mock.URL,
+		ClientId:     "devlake",
+		ClientSecret: "secret",
+		RedirectUrl:  "http://devlake.local/auth/callback",
+		Audiences:    []string{"devlake-api"},
+	})
+	assert.Nil(t, err)
+	return provider
+}
+
+func TestAuthorizationCodeLogin(t *testing.T) {
+	mock := newMockOidcProvider(t)
+	provider := newTestProvider(t, mock)
+
+	state, _ := RandomString()
+	nonce, _ := RandomString()
+	verifier, _ := RandomString()
+	authUrl, err := provider.AuthCodeURL(state, nonce, verifier)
+	assert.Nil(t, err)
+
+	// let the mock provider approve the login and redirect back
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, e := client.Get(authUrl)
+	assert.Nil(t, e)
+	assert.Equal(t, http.StatusFound, res.StatusCode)
+	callback, e := url.Parse(res.Header.Get("Location"))
+	assert.Nil(t, e)
+	assert.Equal(t, state, callback.Query().Get("state"))
+	code := callback.Query().Get("code")
+
+	// wrong code verifier
+	_, err = provider.Exchange(context.Background(), code, "wrong", nonce)
+	assert.NotNil(t, err)
+
+	res, _ = client.Get(authUrl)
+	callback, _ = url.Parse(res.Header.Get("Location"))
+	code = callback.Query().Get("code")
+	user, err := provider.Exchange(context.Background(), code, verifier, nonce)
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", user.Name)
+	assert.Equal(t, "alice@example.com", user.Email)
+	assert.Equal(t, []string{"dev", "ops"}, user.Groups)
+
+	// replayed id token of another login
+	res, _ = client.Get(authUrl)
+	callback, _ = url.Parse(res.Header.Get("Location"))
+	_, err = provider.Exchange(context.Background(), callback.Query().Get("code"), verifier, "another nonce")
+	assert.NotNil(t, err)
+}
+
+func TestVerifyBearerToken(t *testing.T) {
+	mock := newMockOidcProvider(t)
+	provider := newTestProvider(t, mock)
+
+	user, err := provider.VerifyBearerToken(mock.issue("k1", jwt.MapClaims{"aud": "devlake-api"}, time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", user.Name)
+
+	user, err = provider.VerifyBearerToken(mock.issue("k1", jwt.MapClaims{"aud": []string{"other", "devlake"}, "preferred_username": nil, "email": nil}, time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, "u1", user.Name)
+
+	_, err = provider.VerifyBearerToken(mock.issue("k1", jwt.MapClaims{"aud": "other"}, time.Hour))
+	assert.NotNil(t, err)
+	_, err = provider.VerifyBearerToken(mock.issue("k1", jwt.MapClaims{"aud": "devlake", "iss": "https://evil.example.com"}, time.Hour))
+	assert.NotNil(t, err)
+	_, err = provider.VerifyBearerToken(mock.issue("k1", jwt.MapClaims{"aud": "devlake"}, -time.Hour))
+	assert.NotNil(t, err)
+
+	// tampered payload
+	token := strings.Split(mock.issue("k1", jwt.MapClaims{"aud": "devlake"}, time.Hour), ".")
+	forged := strings.Split(mock.issue("k1", jwt.MapClaims{"aud": "devlake", "preferred_username": "admin"}, time.Hour), ".")
+	_, err = provider.VerifyBearerToken(token[0] + "." + forged[1] + "." + token[2])
+	assert.NotNil(t, err)
+
+	// symmetric algorithms are never accepted
+	hs := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iss": mock.URL, "aud": "devlake", "sub": "x", "exp": time.Now().Add(time.Hour).Unix()})
+	hsToken, _ := hs.SignedString([]byte("secret"))
+	_, err = provider.VerifyBearerToken(hsToken)
+	assert.NotNil(t, err)
+}
+
+func TestSigningKeyRotation(t *testing.T) {
+	mock := newMockOidcProvider(t)
+	provider := newTestProvider(t, mock)
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+
+	_, err := provider.VerifyBearerToken(mock.issue("k1", jwt.MapClaims{"aud": "devlake"}, time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, mock.jwksHits)
+
+	// unknown keys don't hit the provider again within the refresh interval
+	mock.addKey("k2")
+	_, err = provider.VerifyBearerToken(mock.issue("k2", jwt.MapClaims{"aud": "devlake"}, time.Hour))
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, mock.jwksHits)
+
+	now = now.Add(2 * keysRefreshInterval)
+	_, err = provider.VerifyBearerToken(mock.issue("k2", jwt.MapClaims{"aud": "devlake"}, time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, mock.jwksHits)
+}
@@ -0,0 +1,166 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidchelper
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	SessionCookieName = "devlake_session"
+	StateCookieName   = "devlake_oidc_state"
+	// StateTtl is how long the user has to finish the login at the provider
+	StateTtl = 10 * time.Minute
+
+	sessionAudience = "devlake-session"
+	stateAudience   = "devlake-oidc-state"
+)
+
+// LoginState is kept in a short-lived cookie between the login redirect and the callback
+type LoginState struct {
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"codeVerifier"`
+	Redirect     string `json:"redirect"`
+}
+
+type sessionClaims struct {
+	jwt.RegisteredClaims
+	Email  string   `json:"email,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+type stateClaims struct {
+	jwt.RegisteredClaims
+	LoginState
+}
+
+// SessionManager signs the session and login state cookies, no server side storage is needed so
+// sessions work across multiple server instances
+type SessionManager struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewSessionManager creates a new SessionManager, the signing key is derived from the secret
+func NewSessionManager(secret string, ttl time.Duration) *SessionManager {
+	key := sha256.Sum256([]byte("devlake-session/" + secret))
+	if ttl == 0 {
+		ttl = 12 * time.Hour
+	}
+	return &SessionManager{
+		key: key[:],
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+// Ttl returns how long a session lasts
+func (m *SessionManager) Ttl() time.Duration {
+	return m.ttl
+}
+
+// EncodeSession returns the signed session of the user
+func (m *SessionManager) EncodeSession(user *common.User) (string, errors.Error) {
+	now := m.now()
+	return m.sign(&sessionClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.Name,
+			Audience:  jwt.ClaimStrings{sessionAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.ttl)),
+		},
+		Email:  user.Email,
+		Groups: user.Groups,
+	})
+}
+
+// DecodeSession returns the user of a valid session
+func (m *SessionManager) DecodeSession(session string) (*common.User, errors.Error) {
+	claims := &sessionClaims{}
+	if err := m.parse(session, claims, sessionAudience); err != nil {
+		return nil, err
+	}
+	return &common.User{
+		Name:   claims.Subject,
+		Email:  claims.Email,
+		Groups: claims.Groups,
+	}, nil
+}
+
+// EncodeState returns the signed login state
+func (m *SessionManager) EncodeState(state *LoginState) (string, errors.Error) {
+	now := m.now()
+	return m.sign(&stateClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{stateAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(StateTtl)),
+		},
+		LoginState: *state,
+	})
+}
+
+// DecodeState returns the login state if it is valid
+func (m *SessionManager) DecodeState(state string) (*LoginState, errors.Error) {
+	claims := &stateClaims{}
+	if err := m.parse(state, claims, stateAudience); err != nil {
+		return nil, err
+	}
+	return &claims.LoginState, nil
+}
+
+func (m *SessionManager) sign(claims jwt.Claims) (string, errors.Error) {
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.key)
+	if err != nil {
+		return "", errors.Default.Wrap(err, "failed to sign")
+	}
+	return signed, nil
+}
+
+func (m *SessionManager) parse(signed string, claims jwt.Claims, audience string) errors.Error {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(audience),
+		jwt.WithTimeFunc(m.now),
+	)
+	_, err := parser.ParseWithClaims(signed, claims, func(token *jwt.Token) (interface{}, error) {
+		return m.key, nil
+	})
+	if err != nil {
+		return errors.Unauthorized.Wrap(err, "invalid or expired session")
+	}
+	return nil
+}
+
+// RandomString returns a url safe random string for the state, nonce and PKCE code verifier
+func RandomString() (string, errors.Error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Default.Wrap(err, "failed to generate random string")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
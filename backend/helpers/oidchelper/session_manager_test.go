@@ -0,0 +1,57 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oidchelper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession(t *testing.T) {
+	m := NewSessionManager("secret", time.Hour)
+	user := &common.User{Name: "alice", Email: "alice@example.com", Groups: []string{"dev"}}
+	session, err := m.EncodeSession(user)
+	assert.Nil(t, err)
+
+	decoded, err := m.DecodeSession(session)
+	assert.Nil(t, err)
+	assert.Equal(t, user, decoded)
+
+	// signed by another secret
+	_, err = NewSessionManager("another", time.Hour).DecodeSession(session)
+	assert.NotNil(t, err)
+
+	// login state is not a session
+	state, err := m.EncodeState(&LoginState{State: "s", Nonce: "n", CodeVerifier: "v", Redirect: "/"})
+	assert.Nil(t, err)
+	_, err = m.DecodeSession(state)
+	assert.NotNil(t, err)
+	loginState, err := m.DecodeState(state)
+	assert.Nil(t, err)
+	assert.Equal(t, &LoginState{State: "s", Nonce: "n", CodeVerifier: "v", Redirect: "/"}, loginState)
+	_, err = m.DecodeState(session)
+	assert.NotNil(t, err)
+
+	// expired
+	m.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = m.DecodeSession(session)
+	assert.NotNil(t, err)
+}
@@ -41,12 +41,18 @@ var HttpMinStatusRetryCode = http.StatusBadRequest
 type ApiAsyncClient struct {
 	*ApiClient
 	*WorkerScheduler
-	maxRetry     int
-	numOfWorkers int
-	logger       log.Logger
+	maxRetry       int
+	numOfWorkers   int
+	logger         log.Logger
+	circuitBreaker *CircuitBreaker
 }
 
+// ApiAsyncFailureCallback would be called when an asynchronous request failed eventually, either retry exceeded
+// or rejected by the circuit breaker. The failure would be ignored if nil was returned
+type ApiAsyncFailureCallback func(err errors.Error) errors.Error
+
 const defaultTimeout = 120 * time.Second
+const defaultCircuitBreakerCooldown = time.Minute
 
 // CreateAsyncApiClient creates a new ApiAsyncClient
 func CreateAsyncApiClient(
@@ -85,6 +91,19 @@ func CreateAsyncApiClient(
 	rateLimiter.GlobalRateLimitPerHour = globalRateLimitPerHour
 	rateLimiter.MaxRetry = retry
 
+	// the circuit of an endpoint opens after the specified number of consecutive failures, 0 to disable
+	circuitBreakerThreshold, err := utils.StrToIntOr(taskCtx.GetConfig("API_CIRCUIT_BREAKER_THRESHOLD"), 10)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "failed to parse API_CIRCUIT_BREAKER_THRESHOLD")
+	}
+	circuitBreakerCooldown := defaultCircuitBreakerCooldown
+	if cooldownConf := taskCtx.GetConfig("API_CIRCUIT_BREAKER_COOLDOWN"); cooldownConf != "" {
+		circuitBreakerCooldown, err = errors.Convert01(time.ParseDuration(cooldownConf))
+		if err != nil {
+			return nil, errors.BadInput.Wrap(err, "failed to parse API_CIRCUIT_BREAKER_COOLDOWN")
+		}
+	}
+
 	// ok, calculate api rate limit based on response (normally from headers)
 	requests, duration, err := rateLimiter.Calculate(apiClient)
 	if err != nil {
@@ -129,6 +148,7 @@ func CreateAsyncApiClient(
 		retry,
 		numOfWorkers,
 		logger,
+		NewCircuitBreaker(circuitBreakerThreshold, circuitBreakerCooldown),
 	}, nil
 }
 
@@ -144,6 +164,16 @@ func (apiClient *ApiAsyncClient) SetMaxRetry(
 	apiClient.maxRetry = maxRetry
 }
 
+// GetCircuitBreaker returns the circuit breaker of the client, nil if it was disabled
+func (apiClient *ApiAsyncClient) GetCircuitBreaker() *CircuitBreaker {
+	return apiClient.circuitBreaker
+}
+
+// SetCircuitBreaker replaces the circuit breaker of the client, nil to disable
+func (apiClient *ApiAsyncClient) SetCircuitBreaker(circuitBreaker *CircuitBreaker) {
+	apiClient.circuitBreaker = circuitBreaker
+}
+
 // DoAsync would carry out an asynchronous request
 func (apiClient *ApiAsyncClient) DoAsync(
 	method string,
@@ -154,15 +184,57 @@ func (apiClient *ApiAsyncClient) DoAsync(
 	handler plugin.ApiAsyncCallback,
 	retry int,
 ) {
+	apiClient.doAsync(method, path, query, body, header, handler, nil, retry)
+}
+
+// DoAsyncWithFailureCallback works like DoAsync, except that onFailure would be called when the request failed
+// eventually, so the caller may decide whether to fail the whole scheduler or skip the request
+func (apiClient *ApiAsyncClient) DoAsyncWithFailureCallback(
+	method string,
+	path string,
+	query url.Values,
+	body interface{},
+	header http.Header,
+	handler plugin.ApiAsyncCallback,
+	onFailure ApiAsyncFailureCallback,
+) {
+	apiClient.doAsync(method, path, query, body, header, handler, onFailure, 0)
+}
+
+func (apiClient *ApiAsyncClient) doAsync(
+	method string,
+	path string,
+	query url.Values,
+	body interface{},
+	header http.Header,
+	handler plugin.ApiAsyncCallback,
+	onFailure ApiAsyncFailureCallback,
+	retry int,
+) {
+	circuitKey := CircuitBreakerKey(method, path)
+	fail := func(err errors.Error) errors.Error {
+		apiClient.logger.Error(err, "")
+		if onFailure != nil && !errors.Is(err, context.Canceled) {
+			return onFailure(err)
+		}
+		return err
+	}
 	var request func() errors.Error
 	request = func() errors.Error {
 		var err error
 		var res *http.Response
 		var respBody []byte
 
+		// fail fast when the endpoint is known to be unavailable
+		if !apiClient.circuitBreaker.Allow(circuitKey) {
+			return fail(errors.Default.Wrap(ErrCircuitOpen, fmt.Sprintf("request to %s was rejected, too many consecutive failures", path)))
+		}
+
 		apiClient.logger.Debug("endpoint: %s  method: %s  header: %s  body: %s query: %s", path, method, header, body, query)
 		res, err = apiClient.Do(method, path, query, body, header)
 		if err == ErrIgnoreAndContinue {
+			// the endpoint did respond, release the circuit in case the request was a half-open probe
+			apiClient.circuitBreaker.RecordSuccess(circuitKey)
 			// make sure defer func got be executed
 			err = nil //nolint
 			return nil
@@ -191,10 +263,21 @@ func (apiClient *ApiAsyncClient) DoAsync(
 			err = errors.HttpStatus(res.StatusCode).New(errMessage)
 		}
 
+		// only server side errors count, the endpoint is considered available otherwise (4xx included), so that
+		// a half-open probe always closes or reopens the circuit
+		if (err != nil && res == nil && !errors.Is(err, context.Canceled)) || (res != nil && res.StatusCode >= http.StatusInternalServerError) {
+			if apiClient.circuitBreaker.RecordFailure(circuitKey) {
+				apiClient.logger.Warn(err, "circuit breaker opened for %s", circuitKey)
+			}
+		} else {
+			apiClient.circuitBreaker.RecordSuccess(circuitKey)
+		}
+
 		//  if it needs retry, check and retry
 		if needRetry {
 			// check whether we still have retry times and not error from handler and canceled error
-			if retry < apiClient.maxRetry && err != context.Canceled {
+			// and stop retrying once the circuit opened
+			if retry < apiClient.maxRetry && err != context.Canceled && !apiClient.circuitBreaker.IsOpen(circuitKey) {
 				apiClient.logger.Warn(err, "retry #%d calling %s", retry, path)
 				retry++
 				apiClient.NextTick(func() errors.Error {
@@ -206,9 +289,7 @@ func (apiClient *ApiAsyncClient) DoAsync(
 		}
 
 		if err != nil {
-			err = errors.Default.Wrap(err, fmt.Sprintf("Retry exceeded %d times calling %s. The last error was: %s", retry, path, errMessage))
-			apiClient.logger.Error(err, "")
-			return errors.Convert(err)
+			return fail(errors.Default.Wrap(err, fmt.Sprintf("Retry exceeded %d times calling %s. The last error was: %s", retry, path, errMessage)))
 		}
 
 		// it is important to let handler have a chance to handle error, or it can hang indefinitely
@@ -260,3 +341,11 @@ type RateLimitedApiClient interface {
 }
 
 var _ RateLimitedApiClient = (*ApiAsyncClient)(nil)
+
+// FailureCallbackApiClient is implemented by the RateLimitedApiClient able to report failed requests to the caller,
+// which is required by ApiCollectorArgs.SkipFailedPages
+type FailureCallbackApiClient interface {
+	DoAsyncWithFailureCallback(method string, path string, query url.Values, body interface{}, header http.Header, handler plugin.ApiAsyncCallback, onFailure ApiAsyncFailureCallback)
+}
+
+var _ FailureCallbackApiClient = (*ApiAsyncClient)(nil)
@@ -0,0 +1,151 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+// ErrCircuitOpen is returned when a request was rejected because the circuit breaker of the endpoint is open
+var ErrCircuitOpen = errors.Default.New("circuit breaker is open")
+
+var circuitBreakerKeySegmentPattern = regexp.MustCompile(`\d`)
+
+// CircuitBreakerKey returns the key of the endpoint for the given request, query string is dropped and
+// path segments that contain digits (ids, numbers, shas) are collapsed so requests targeting the same kind
+// of resource would share the same circuit
+func CircuitBreakerKey(method string, path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if circuitBreakerKeySegmentPattern.MatchString(segment) {
+			segments[i] = "{}"
+		}
+	}
+	if method == "" {
+		method = http.MethodGet
+	}
+	return method + " " + strings.Join(segments, "/")
+}
+
+type circuitState struct {
+	failures int
+	openedAt *time.Time
+	probing  bool
+}
+
+// CircuitBreaker keeps track of consecutive failures per endpoint, the circuit of an endpoint opens once the
+// number of consecutive failures reaches the threshold, and all requests to the endpoint would be rejected
+// until the cooldown elapsed. After that, a single probe request is allowed to go through, the circuit
+// closes if it succeeded or opens again otherwise.
+// A nil CircuitBreaker allows everything.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	mu        sync.Mutex
+	circuits  map[string]*circuitState
+	now       func() time.Time
+}
+
+// NewCircuitBreaker creates a CircuitBreaker, nil would be returned if threshold is not positive which
+// means the circuit breaker is disabled
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		circuits:  make(map[string]*circuitState),
+		now:       time.Now,
+	}
+}
+
+func (cb *CircuitBreaker) getCircuit(key string) *circuitState {
+	circuit, ok := cb.circuits[key]
+	if !ok {
+		circuit = &circuitState{}
+		cb.circuits[key] = circuit
+	}
+	return circuit
+}
+
+// Allow tells if a request to the endpoint could be sent
+func (cb *CircuitBreaker) Allow(key string) bool {
+	if cb == nil {
+		return true
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	circuit := cb.getCircuit(key)
+	if circuit.openedAt == nil {
+		return true
+	}
+	if circuit.probing || cb.now().Sub(*circuit.openedAt) < cb.cooldown {
+		return false
+	}
+	// half-open: let one request through to probe the endpoint
+	circuit.probing = true
+	return true
+}
+
+// IsOpen tells if the circuit of the endpoint is open, a half-open circuit is considered open as well
+func (cb *CircuitBreaker) IsOpen(key string) bool {
+	if cb == nil {
+		return false
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.getCircuit(key).openedAt != nil
+}
+
+// RecordSuccess closes the circuit of the endpoint and resets its failure counter
+func (cb *CircuitBreaker) RecordSuccess(key string) {
+	if cb == nil {
+		return
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	delete(cb.circuits, key)
+}
+
+// RecordFailure increases the failure counter of the endpoint and returns true if the circuit got opened
+func (cb *CircuitBreaker) RecordFailure(key string) bool {
+	if cb == nil {
+		return false
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	circuit := cb.getCircuit(key)
+	circuit.failures++
+	if circuit.probing || (circuit.openedAt == nil && circuit.failures >= cb.threshold) {
+		now := cb.now()
+		circuit.openedAt = &now
+		circuit.probing = false
+		return true
+	}
+	return false
+}
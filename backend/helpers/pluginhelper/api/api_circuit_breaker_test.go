@@ -0,0 +1,136 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/impls/logruslog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakerKey(t *testing.T) {
+	assert.Equal(t, "GET repos/apache/devlake/pulls/{}/commits", CircuitBreakerKey("", "repos/apache/devlake/pulls/123/commits?page=2"))
+	assert.Equal(t, "POST rest/api/{}/search", CircuitBreakerKey(http.MethodPost, "rest/api/2/search"))
+	assert.Equal(t,
+		CircuitBreakerKey(http.MethodGet, "rest/api/2/issue/10001/changelog"),
+		CircuitBreakerKey(http.MethodGet, "rest/api/2/issue/10002/changelog"),
+	)
+}
+
+func TestCircuitBreakerOpenAndRecover(t *testing.T) {
+	now := time.Date(2025, 6, 12, 0, 0, 0, 0, time.UTC)
+	cb := NewCircuitBreaker(3, time.Minute)
+	cb.now = func() time.Time { return now }
+	key := CircuitBreakerKey(http.MethodGet, "issues")
+
+	// consecutive failures below the threshold keep the circuit closed
+	assert.False(t, cb.RecordFailure(key))
+	assert.False(t, cb.RecordFailure(key))
+	assert.True(t, cb.Allow(key))
+	assert.True(t, cb.RecordFailure(key))
+	assert.True(t, cb.IsOpen(key))
+	assert.False(t, cb.Allow(key))
+
+	// other endpoints are not affected
+	assert.True(t, cb.Allow(CircuitBreakerKey(http.MethodGet, "pulls")))
+
+	// only one probe is allowed after the cooldown, and a failed probe opens the circuit again
+	now = now.Add(time.Minute)
+	assert.True(t, cb.Allow(key))
+	assert.False(t, cb.Allow(key))
+	assert.True(t, cb.RecordFailure(key))
+	assert.False(t, cb.Allow(key))
+
+	// a successful probe closes the circuit
+	now = now.Add(time.Minute)
+	assert.True(t, cb.Allow(key))
+	cb.RecordSuccess(key)
+	assert.False(t, cb.IsOpen(key))
+	assert.True(t, cb.Allow(key))
+	assert.True(t, cb.Allow(key))
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	cb := NewCircuitBreaker(2, time.Minute)
+	key := CircuitBreakerKey(http.MethodGet, "issues")
+	assert.False(t, cb.RecordFailure(key))
+	cb.RecordSuccess(key)
+	assert.False(t, cb.RecordFailure(key))
+	assert.True(t, cb.Allow(key))
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	cb := NewCircuitBreaker(0, time.Minute)
+	assert.Nil(t, cb)
+	for i := 0; i < 10; i++ {
+		assert.False(t, cb.RecordFailure("GET issues"))
+	}
+	assert.True(t, cb.Allow("GET issues"))
+	assert.False(t, cb.IsOpen("GET issues"))
+}
+
+func TestApiAsyncClientHalfOpenProbe(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	apiClient := &ApiClient{}
+	apiClient.Setup(server.URL, nil, time.Second)
+	apiClient.SetContext(context.Background())
+	scheduler, err := NewWorkerScheduler(context.Background(), 1, time.Millisecond, logruslog.Global)
+	assert.Nil(t, err)
+	defer scheduler.Release()
+	cb := NewCircuitBreaker(1, 0)
+	asyncClient := &ApiAsyncClient{ApiClient: apiClient, WorkerScheduler: scheduler, logger: logruslog.Global, circuitBreaker: cb}
+	key := CircuitBreakerKey(http.MethodGet, "issues")
+
+	probe := func(afterResponse plugin.ApiClientAfterResponse) {
+		assert.True(t, cb.RecordFailure(key))
+		apiClient.SetAfterFunction(afterResponse)
+		asyncClient.DoAsyncWithFailureCallback(http.MethodGet, "issues", nil, nil, nil,
+			func(res *http.Response) errors.Error { return nil },
+			func(err errors.Error) errors.Error { return nil },
+		)
+		assert.Nil(t, asyncClient.WaitAsync())
+	}
+
+	// a client error means the endpoint is available
+	status = http.StatusNotFound
+	probe(nil)
+	assert.False(t, cb.IsOpen(key))
+
+	// so does a response ignored by the after function
+	status = http.StatusOK
+	probe(func(res *http.Response) errors.Error { return ErrIgnoreAndContinue })
+	assert.False(t, cb.IsOpen(key))
+
+	// a server error reopens the circuit
+	status = http.StatusInternalServerError
+	probe(nil)
+	assert.True(t, cb.IsOpen(key))
+	assert.True(t, cb.Allow(key))
+}
@@ -41,6 +41,7 @@ import (
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/log"
 	"github.com/apache/incubator-devlake/core/utils"
+	"github.com/apache/incubator-devlake/helpers/secrethelper"
 )
 
 // ErrIgnoreAndContinue is a error which should be ignored
@@ -70,14 +71,33 @@ type ApiClient struct {
 }
 
 // NewApiClientFromConnection creates ApiClient based on given connection.
+// The `secret://` references of the connection are resolved only when br is the context of a task, whose connection
+// is loaded from the database, connections of api requests could be built from the request body.
 func NewApiClientFromConnection(
 	ctx gocontext.Context,
 	br context.BasicRes,
 	connection plugin.ApiConnection,
+) (*ApiClient, errors.Error) {
+	_, isTask := br.(plugin.ExecContext)
+	return newApiClientFromConnection(ctx, br, connection, isTask)
+}
+
+func newApiClientFromConnection(
+	ctx gocontext.Context,
+	br context.BasicRes,
+	connection plugin.ApiConnection,
+	resolveSecrets bool,
 ) (*ApiClient, errors.Error) {
 	if reflect.ValueOf(connection).Kind() != reflect.Ptr {
 		panic(fmt.Errorf("connection is not a pointer"))
 	}
+	var err errors.Error
+	if resolveSecrets {
+		connection, err = resolveConnectionSecrets(br, connection)
+		if err != nil {
+			return nil, err
+		}
+	}
 	apiClient, err := NewApiClient(ctx, connection.GetEndpoint(), nil, 0, connection.GetProxy(), br)
 	if err != nil {
 		return nil, err
@@ -101,6 +121,26 @@ func NewApiClientFromConnection(
 	return apiClient, nil
 }
 
+// resolveConnectionSecrets returns a copy of the connection with `secret://` references replaced by the secrets,
+// the connection itself is left untouched so references wouldn't be overwritten by the secrets when it gets saved
+func resolveConnectionSecrets(br context.BasicRes, connection plugin.ApiConnection) (plugin.ApiConnection, errors.Error) {
+	if !secrethelper.HasSecretRefs(connection) {
+		return connection, nil
+	}
+	resolver, err := secrethelper.GetSecretResolver(br.GetConfigReader())
+	if err != nil {
+		return nil, err
+	}
+	copied := reflect.New(reflect.TypeOf(connection).Elem())
+	copied.Elem().Set(reflect.ValueOf(connection).Elem())
+	resolved := copied.Interface().(plugin.ApiConnection)
+	err = resolver.ResolveRefs(resolved)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "failed to resolve secrets of the connection")
+	}
+	return resolved, nil
+}
+
 // NewApiClient creates a new synchronize ApiClient
 func NewApiClient(
 	ctx gocontext.Context,
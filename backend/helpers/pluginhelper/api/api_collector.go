@@ -25,9 +25,11 @@ import (
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"text/template"
 	"time"
 
+	"github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/core/plugin"
 
 	"github.com/apache/incubator-devlake/core/dal"
@@ -91,13 +93,33 @@ type ApiCollectorArgs struct {
 	AfterResponse  plugin.ApiClientAfterResponse
 	RequestBody    func(reqData *RequestData) map[string]interface{}
 	Method         string
+	// SkipFailedPages tells ApiCollector to record pages failed to be fetched (retry exceeded or rejected by the
+	// circuit breaker) instead of failing the subtask, recorded pages would be re-collected by the next incremental run
+	// and the subtask ends with an errors.Partial error, so the task ends as TASK_PARTIAL. The ApiClient must implement
+	// FailureCallbackApiClient. Note that pages depending on the failed one (i.e. GetNextPageCustomData) would not be
+	// collected in this run
+	SkipFailedPages bool
+	// ConditionalRequests tells ApiCollector to store the ETag/Last-Modified of responses and send If-None-Match/
+	// If-Modified-Since with the next run, raw rows collected previously would be reused if the server responded
+	// 304 Not Modified. It works with GET requests only and can't be used along with GetTotalPages or
+	// GetNextPageCustomData for paginated apis since the pagination depends on the response body
+	ConditionalRequests bool
+	// CheckpointInterval tells ApiCollector to persist the pagination progress every specified number of pages, so
+	// the next run with the same configuration could resume from the last checkpoint if the collection crashed.
+	// It works with GetNextPageCustomData without Input only, CustomData is persisted in JSON and would be restored
+	// as a decoded JSON value, i.e. string for string cursors
+	CheckpointInterval int
 }
 
 // ApiCollector FIXME ...
 type ApiCollector struct {
 	*RawDataSubTask
-	args        *ApiCollectorArgs
-	urlTemplate *template.Template
+	args         *ApiCollectorArgs
+	urlTemplate  *template.Template
+	skippedPages int32
+	httpCaches   map[string]*models.CollectorHttpCache
+	checkpointer *collectorCheckpointer
+	lastRawId    uint64
 }
 
 // NewApiCollector allocates a new ApiCollector with the given args.
@@ -123,6 +145,15 @@ func NewApiCollector(args ApiCollectorArgs) (*ApiCollector, errors.Error) {
 	if args.ResponseParser == nil {
 		return nil, errors.Default.New("ResponseParser is required")
 	}
+	if args.ConditionalRequests && args.PageSize > 0 && (args.GetTotalPages != nil || args.GetNextPageCustomData != nil) {
+		return nil, errors.Default.New("ConditionalRequests can't be used along with GetTotalPages or GetNextPageCustomData")
+	}
+	if args.CheckpointInterval > 0 && (args.Input != nil || args.PageSize <= 0 || args.GetNextPageCustomData == nil) {
+		return nil, errors.Default.New("CheckpointInterval works with GetNextPageCustomData without Input only")
+	}
+	if _, ok := args.ApiClient.(FailureCallbackApiClient); args.SkipFailedPages && !ok {
+		return nil, errors.Default.New("SkipFailedPages requires an ApiClient implementing FailureCallbackApiClient")
+	}
 	apiCollector := &ApiCollector{
 		RawDataSubTask: rawDataSubTask,
 		args:           &args,
@@ -167,13 +198,49 @@ func (collector *ApiCollector) Execute() errors.Error {
 	if syncPolicy != nil && syncPolicy.FullSync {
 		isIncremental = false
 	}
-	// flush data if not incremental collection
-	if !isIncremental {
+	// raw rows of unmodified responses would be reused unless user asked for a full sync
+	isConditional := collector.args.ConditionalRequests && collector.args.Method != http.MethodPost
+	if syncPolicy != nil && syncPolicy.FullSync {
+		isConditional = false
+	}
+	startedAt := time.Now()
+	if collector.args.ConditionalRequests {
+		err = collector.loadHttpCaches(isConditional)
+		if err != nil {
+			return errors.Default.Wrap(err, "error loading http caches")
+		}
+	}
+	collector.checkpointer, err = newCollectorCheckpointer(
+		collector.args.Ctx,
+		collector.table,
+		collector.params,
+		collector.args.UrlTemplate,
+		collector.args.CheckpointInterval,
+		isIncremental,
+	)
+	if err != nil {
+		return err
+	}
+	if checkpoint := collector.checkpointer.Resumable(); checkpoint != nil {
+		// resume from the checkpoint, rows saved after it would be collected again
+		logger.Info("resuming collection from page %d", checkpoint.Page)
+		err = collector.checkpointer.PurgeUncheckpointed()
+		if err != nil {
+			return errors.Default.Wrap(err, "error deleting data collected after the checkpoint")
+		}
+	} else if !isIncremental && !isConditional {
+		// flush data if not incremental collection, stale rows would be purged after collection for conditional requests
 		err = db.Delete(&RawData{}, dal.From(collector.table), dal.Where("params = ?", collector.params))
 		if err != nil {
 			return errors.Default.Wrap(err, "error deleting data from collector")
 		}
 	}
+	// pages skipped by the previous run would be collected again by a full collection, or re-collected otherwise,
+	// they are removed only after being collected successfully
+	skippedPages, err := collector.loadSkippedPages()
+	if err != nil {
+		return errors.Default.Wrap(err, "error loading skipped pages")
+	}
 
 	// if MinTickInterval was specified
 	if collector.args.MinTickInterval != nil {
@@ -194,6 +261,15 @@ func (collector *ApiCollector) Execute() errors.Error {
 	}
 
 	collector.args.Ctx.SetProgress(0, -1)
+	if len(skippedPages) > 0 && isIncremental {
+		logger.Info("re-collecting %d pages skipped by the previous run", len(skippedPages))
+		for _, page := range skippedPages {
+			err = collector.refetchAsync(page)
+			if err != nil {
+				return errors.Default.Wrap(err, fmt.Sprintf("error re-collecting skipped page %d", page.ID))
+			}
+		}
+	}
 	if collector.args.Input != nil {
 		iterator := collector.args.Input
 		defer iterator.Close()
@@ -216,11 +292,14 @@ func (collector *ApiCollector) Execute() errors.Error {
 			if err != nil {
 				break
 			}
-			collector.exec(input)
+			err = collector.exec(input)
+			if err != nil {
+				break
+			}
 		}
 	} else {
 		// or we just did it once
-		collector.exec(nil)
+		err = collector.exec(nil)
 	}
 
 	if err != nil {
@@ -231,17 +310,93 @@ func (collector *ApiCollector) Execute() errors.Error {
 	if err != nil {
 		logger.Error(err, "end api collection error")
 		err = errors.Default.Wrap(err, "Error waiting for async Collector execution")
+	} else if atomic.LoadInt32(&collector.skippedPages) > 0 {
+		logger.Warn(nil, "end api collection with %d pages skipped", atomic.LoadInt32(&collector.skippedPages))
 	} else {
 		logger.Info("end api collection without error")
 	}
+	if err == nil && !isIncremental && isConditional {
+		err = collector.purgeStaleRawData(startedAt)
+	}
+	if err == nil && !isIncremental {
+		err = collector.deleteSkippedPages(skippedPages...)
+	}
+	if err == nil {
+		err = collector.checkpointer.Done()
+	}
+	if skipped := atomic.LoadInt32(&collector.skippedPages); err == nil && skipped > 0 {
+		err = errors.Partial.New(fmt.Sprintf("%d pages of %s were skipped, they would be re-collected by the next run", skipped, collector.table))
+	}
 
 	return err
 }
 
-func (collector *ApiCollector) exec(input interface{}) {
+// loadSkippedPages loads the pages skipped by the previous run of this collector
+func (collector *ApiCollector) loadSkippedPages() ([]*models.CollectorSkippedPage, errors.Error) {
+	var pages []*models.CollectorSkippedPage
+	err := collector.args.Ctx.GetDal().All(
+		&pages,
+		dal.From(&models.CollectorSkippedPage{}),
+		dal.Where(
+			"raw_data_table = ? AND raw_data_params = ? AND url_template = ?",
+			collector.table, collector.params, collector.args.UrlTemplate,
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// deleteSkippedPages removes the given skipped pages once they were collected
+func (collector *ApiCollector) deleteSkippedPages(pages ...*models.CollectorSkippedPage) errors.Error {
+	if len(pages) == 0 {
+		return nil
+	}
+	ids := make([]uint64, len(pages))
+	for i, page := range pages {
+		ids[i] = page.ID
+	}
+	err := collector.args.Ctx.GetDal().Delete(&models.CollectorSkippedPage{}, dal.Where("id IN ?", ids))
+	if err != nil {
+		return errors.Default.Wrap(err, "error deleting skipped pages")
+	}
+	return nil
+}
+
+// refetchAsync fetches a page skipped by the previous run, pages depending on it would not be collected. The
+// page is removed once it was collected, or kept for the next run if it failed again
+func (collector *ApiCollector) refetchAsync(page *models.CollectorSkippedPage) errors.Error {
+	apiQuery, err := errors.Convert01(url.ParseQuery(page.Query))
+	if err != nil {
+		return errors.Default.Wrap(err, "error decoding the query of the skipped page")
+	}
+	var apiHeader http.Header
+	if len(page.Header) > 0 {
+		err = errors.Convert(json.Unmarshal(page.Header, &apiHeader))
+		if err != nil {
+			return errors.Default.Wrap(err, "error decoding the header of the skipped page")
+		}
+	}
+	var reqBody map[string]interface{}
+	if len(page.Body) > 0 {
+		err = errors.Convert(json.Unmarshal(page.Body, &reqBody))
+		if err != nil {
+			return errors.Default.Wrap(err, "error decoding the body of the skipped page")
+		}
+	}
+	var body interface{}
+	if reqBody != nil {
+		body = reqBody
+	}
+	collector.doFetchAsync(page.Method, page.Url, apiQuery, body, apiHeader, page.Input, nil, page)
+	return nil
+}
+
+func (collector *ApiCollector) exec(input interface{}) errors.Error {
 	inputJson, err := json.Marshal(input)
 	if err != nil {
-		panic(err)
+		return errors.Default.Wrap(err, "error encoding the input")
 	}
 	reqData := new(RequestData)
 	reqData.Input = input
@@ -250,6 +405,14 @@ func (collector *ApiCollector) exec(input interface{}) {
 		Page: 1,
 		Size: collector.args.PageSize,
 	}
+	if checkpoint := collector.checkpointer.Resumable(); checkpoint != nil {
+		reqData.Pager.Page = checkpoint.Page
+		reqData.Pager.Skip = checkpoint.Skip
+		err = json.Unmarshal(checkpoint.Cursor, &reqData.CustomData)
+		if err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("error decoding the cursor of the checkpoint at page %d", checkpoint.Page))
+		}
+	}
 	// fetch the detail
 	if collector.args.PageSize <= 0 {
 		collector.fetchAsync(reqData, nil)
@@ -263,6 +426,7 @@ func (collector *ApiCollector) exec(input interface{}) {
 	} else {
 		collector.fetchPagesUndetermined(reqData, false)
 	}
+	return nil
 }
 
 // fetchPagesSequentially fetches data of all pages in order to build RequestData by prev response
@@ -284,6 +448,15 @@ func (collector *ApiCollector) fetchPagesSequentially(reqData *RequestData) {
 			reqData.CustomData = customData
 			reqData.Pager.Skip += collector.args.PageSize
 			reqData.Pager.Page += 1
+			err = collector.checkpointer.Tick(
+				reqData.Pager.Page,
+				reqData.Pager.Skip,
+				reqData.CustomData,
+				atomic.LoadUint64(&collector.lastRawId),
+			)
+			if err != nil {
+				return errors.Default.Wrap(err, "error saving checkpoint")
+			}
 			collector.args.ApiClient.NextTick(collect)
 			return nil
 		})
@@ -381,16 +554,17 @@ func (collector *ApiCollector) fetchPagesUndetermined(reqData *RequestData, skip
 	}
 }
 
-func (collector *ApiCollector) generateUrl(pager *Pager, input interface{}) (string, errors.Error) {
+func (collector *ApiCollector) generateUrl(reqData *RequestData) (string, errors.Error) {
 	params := collector.args.Params
 	if collector.args.Options != nil {
 		params = collector.args.Options.GetParams()
 	}
 	var buf bytes.Buffer
 	err := collector.urlTemplate.Execute(&buf, &RequestData{
-		Pager:  pager,
-		Params: params,
-		Input:  input,
+		Pager:      reqData.Pager,
+		Params:     params,
+		Input:      reqData.Input,
+		CustomData: reqData.CustomData,
 	})
 	if err != nil {
 		return "", errors.Convert(err)
@@ -416,7 +590,7 @@ func (collector *ApiCollector) fetchAsync(reqData *RequestData, handler func(int
 			Skip: 0,
 		}
 	}
-	apiUrl, err := collector.generateUrl(reqData.Pager, reqData.Input)
+	apiUrl, err := collector.generateUrl(reqData)
 	if err != nil {
 		panic(err)
 	}
@@ -442,11 +616,38 @@ func (collector *ApiCollector) fetchAsync(reqData *RequestData, handler func(int
 			panic(err)
 		}
 	}
+	method := collector.args.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	collector.doFetchAsync(method, apiUrl, apiQuery, reqBody, apiHeader, reqData.InputJSON, handler, nil)
+}
+
+// doFetchAsync sends the request and saves the response into the raw table, handler would be called for
+// triggering the next fetch. skippedPage is the page being re-collected, nil for regular fetches
+func (collector *ApiCollector) doFetchAsync(
+	method string,
+	apiUrl string,
+	apiQuery url.Values,
+	reqBody interface{},
+	apiHeader http.Header,
+	inputJSON []byte,
+	handler func(int, []byte, *http.Response) errors.Error,
+	skippedPage *models.CollectorSkippedPage,
+) {
 	logger := collector.args.Ctx.GetLogger()
 	logger.Debug("fetchAsync <<< enqueueing for %s %v", apiUrl, apiQuery)
+	var httpCacheKey string
+	if collector.httpCaches != nil && method == http.MethodGet {
+		httpCacheKey = collector.getHttpCacheKey(apiUrl, apiQuery)
+		apiHeader = collector.setConditionalHeaders(httpCacheKey, apiHeader)
+	}
 	responseHandler := func(res *http.Response) errors.Error {
 		defer logger.Debug("fetchAsync >>> done for %s %v", apiUrl, apiQuery)
 		logger := collector.args.Ctx.GetLogger()
+		if httpCacheKey != "" && res.StatusCode == http.StatusNotModified {
+			return collector.reuseRawData(httpCacheKey, res, handler)
+		}
 		// read body to buffer
 		body, err := io.ReadAll(res.Body)
 		if err != nil {
@@ -458,7 +659,7 @@ func (collector *ApiCollector) fetchAsync(reqData *RequestData, handler func(int
 		items, err := collector.args.ResponseParser(res)
 		if err != nil {
 			if errors.Is(err, ErrFinishCollect) {
-				logger.Info("a fetch stop by parser, reqInput: #%s", reqData.Params)
+				logger.Info("a fetch stop by parser, reqInput: #%s", string(inputJSON))
 				handler = nil
 			} else {
 				return errors.Default.Wrap(err, fmt.Sprintf("error parsing response from %s", apiUrl))
@@ -478,14 +679,21 @@ func (collector *ApiCollector) fetchAsync(reqData *RequestData, handler func(int
 				Params: collector.params,
 				Data:   msg,
 				Url:    urlString,
-				Input:  reqData.InputJSON,
+				Input:  inputJSON,
 			}
 		}
 		err = db.Create(rows, dal.From(collector.table))
 		if err != nil {
 			return errors.Default.Wrap(err, fmt.Sprintf("error inserting raw rows into %s", collector.table))
 		}
+		atomic.StoreUint64(&collector.lastRawId, rows[count-1].ID)
 		logger.Debug("fetchAsync === total %d rows were saved into database", count)
+		if httpCacheKey != "" {
+			err = collector.saveHttpCache(httpCacheKey, res)
+			if err != nil {
+				return errors.Default.Wrap(err, fmt.Sprintf("error saving http cache for %s", urlString))
+			}
+		}
 		// increase progress only when it was not nested
 		collector.args.Ctx.IncProgress(1)
 		if handler != nil {
@@ -495,10 +703,69 @@ func (collector *ApiCollector) fetchAsync(reqData *RequestData, handler func(int
 		}
 		return nil
 	}
-	if collector.args.Method == http.MethodPost {
+	if skippedPage != nil {
+		collect := responseHandler
+		responseHandler = func(res *http.Response) errors.Error {
+			err := collect(res)
+			if err != nil {
+				return err
+			}
+			return collector.deleteSkippedPages(skippedPage)
+		}
+	}
+	if collector.args.SkipFailedPages {
+		onFailure := func(err errors.Error) errors.Error {
+			return collector.recordSkippedPage(method, apiUrl, apiQuery, reqBody, apiHeader, inputJSON, skippedPage, err)
+		}
+		// checked by NewApiCollector
+		apiClient := collector.args.ApiClient.(FailureCallbackApiClient)
+		apiClient.DoAsyncWithFailureCallback(method, apiUrl, apiQuery, reqBody, apiHeader, responseHandler, onFailure)
+	} else if method == http.MethodPost {
 		collector.args.ApiClient.DoPostAsync(apiUrl, apiQuery, reqBody, apiHeader, responseHandler)
 	} else {
 		collector.args.ApiClient.DoGetAsync(apiUrl, apiQuery, apiHeader, responseHandler)
 	}
 	logger.Debug("fetchAsync === enqueued for %s %v", apiUrl, apiQuery)
 }
+
+func (collector *ApiCollector) recordSkippedPage(
+	method string,
+	apiUrl string,
+	apiQuery url.Values,
+	reqBody interface{},
+	apiHeader http.Header,
+	inputJSON []byte,
+	skippedPage *models.CollectorSkippedPage,
+	failure errors.Error,
+) errors.Error {
+	var err errors.Error
+	if skippedPage != nil {
+		// the page failed again, keep the existing record with the latest error
+		skippedPage.Error = failure.Error()
+		err = collector.args.Ctx.GetDal().Update(skippedPage)
+	} else {
+		page := &models.CollectorSkippedPage{
+			RawDataTable:  collector.table,
+			RawDataParams: collector.params,
+			UrlTemplate:   collector.args.UrlTemplate,
+			Method:        method,
+			Url:           apiUrl,
+			Query:         apiQuery.Encode(),
+			Input:         inputJSON,
+			Error:         failure.Error(),
+		}
+		if apiHeader != nil {
+			page.Header = errors.Must1(json.Marshal(apiHeader))
+		}
+		if reqBody != nil {
+			page.Body = errors.Must1(json.Marshal(reqBody))
+		}
+		err = collector.args.Ctx.GetDal().Create(page)
+	}
+	if err != nil {
+		return errors.Default.Wrap(err, fmt.Sprintf("error recording skipped page %s", apiUrl))
+	}
+	atomic.AddInt32(&collector.skippedPages, 1)
+	collector.args.Ctx.GetLogger().Warn(failure, "page %s %v skipped, it would be re-collected by the next run", apiUrl, apiQuery)
+	return nil
+}
@@ -0,0 +1,147 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+)
+
+// loadHttpCaches loads validators stored by the previous run, they would be dropped if the conditional requests
+// were not to be sent, e.g. user asked for a full sync
+func (collector *ApiCollector) loadHttpCaches(isConditional bool) errors.Error {
+	db := collector.args.Ctx.GetDal()
+	where := dal.Where("raw_data_table = ? AND raw_data_params = ?", collector.table, collector.params)
+	if !isConditional {
+		collector.httpCaches = nil
+		return db.Delete(&models.CollectorHttpCache{}, where)
+	}
+	var caches []*models.CollectorHttpCache
+	err := db.All(&caches, dal.From(&models.CollectorHttpCache{}), where)
+	if err != nil {
+		return err
+	}
+	collector.httpCaches = make(map[string]*models.CollectorHttpCache, len(caches))
+	for _, cache := range caches {
+		collector.httpCaches[cache.UrlHash] = cache
+	}
+	return nil
+}
+
+func (collector *ApiCollector) getHttpCacheKey(apiUrl string, apiQuery url.Values) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s?%s", apiUrl, apiQuery.Encode())))
+	return hex.EncodeToString(sum[:])
+}
+
+// setConditionalHeaders returns a copy of the header with validators of the previous response
+func (collector *ApiCollector) setConditionalHeaders(httpCacheKey string, apiHeader http.Header) http.Header {
+	cache := collector.httpCaches[httpCacheKey]
+	if cache == nil || (cache.ETag == "" && cache.LastModified == "") {
+		return apiHeader
+	}
+	if apiHeader == nil {
+		apiHeader = http.Header{}
+	} else {
+		apiHeader = apiHeader.Clone()
+	}
+	if cache.ETag != "" {
+		apiHeader.Set("If-None-Match", cache.ETag)
+	}
+	if cache.LastModified != "" {
+		apiHeader.Set("If-Modified-Since", cache.LastModified)
+	}
+	return apiHeader
+}
+
+// saveHttpCache stores validators of the response for the next run
+func (collector *ApiCollector) saveHttpCache(httpCacheKey string, res *http.Response) errors.Error {
+	etag := res.Header.Get("ETag")
+	lastModified := res.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return nil
+	}
+	return collector.args.Ctx.GetDal().CreateOrUpdate(&models.CollectorHttpCache{
+		RawDataTable:  collector.table,
+		RawDataParams: collector.params,
+		UrlHash:       httpCacheKey,
+		Url:           res.Request.URL.String(),
+		ETag:          etag,
+		LastModified:  lastModified,
+	})
+}
+
+// reuseRawData marks raw rows collected previously as reused for a 304 Not Modified response without re-writing
+// them, and calls the handler with the number of reused rows to continue the pagination
+func (collector *ApiCollector) reuseRawData(
+	httpCacheKey string,
+	res *http.Response,
+	handler func(int, []byte, *http.Response) errors.Error,
+) errors.Error {
+	db := collector.args.Ctx.GetDal()
+	cache := collector.httpCaches[httpCacheKey]
+	if cache == nil {
+		return errors.Default.New(fmt.Sprintf("unexpected 304 Not Modified response from %s", res.Request.URL.String()))
+	}
+	now := time.Now()
+	err := db.UpdateColumn(
+		&models.CollectorHttpCache{},
+		"reused_at", now,
+		dal.Where("raw_data_table = ? AND raw_data_params = ? AND url_hash = ?", cache.RawDataTable, cache.RawDataParams, cache.UrlHash),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, fmt.Sprintf("error updating http cache for %s", cache.Url))
+	}
+	collector.args.Ctx.GetLogger().Debug("fetchAsync === %s not modified, reusing raw rows", cache.Url)
+	collector.args.Ctx.IncProgress(1)
+	if handler == nil {
+		return nil
+	}
+	count, err := db.Count(dal.From(collector.table), dal.Where("params = ? AND url = ?", collector.params, cache.Url))
+	if err != nil {
+		return errors.Default.Wrap(err, fmt.Sprintf("error counting raw rows of %s", cache.Url))
+	}
+	return handler(int(count), nil, res)
+}
+
+// purgeStaleRawData deletes raw rows collected by previous runs except those reused by the current run
+func (collector *ApiCollector) purgeStaleRawData(startedAt time.Time) errors.Error {
+	db := collector.args.Ctx.GetDal()
+	err := db.Delete(
+		&RawData{},
+		dal.From(collector.table),
+		dal.Where(
+			fmt.Sprintf(
+				"params = ? AND created_at < ? AND url NOT IN (SELECT url FROM %s WHERE raw_data_table = ? AND raw_data_params = ? AND reused_at >= ?)",
+				models.CollectorHttpCache{}.TableName(),
+			),
+			collector.params, startedAt, collector.table, collector.params, startedAt,
+		),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "error purging stale raw data")
+	}
+	return nil
+}
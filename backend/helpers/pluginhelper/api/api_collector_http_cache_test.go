@@ -0,0 +1,58 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiCollectorHttpCacheKey(t *testing.T) {
+	collector := &ApiCollector{}
+	key := collector.getHttpCacheKey("api/2/status", url.Values{"page": []string{"1"}})
+	assert.Len(t, key, 64)
+	assert.Equal(t, key, collector.getHttpCacheKey("api/2/status", url.Values{"page": []string{"1"}}))
+	assert.NotEqual(t, key, collector.getHttpCacheKey("api/2/status", url.Values{"page": []string{"2"}}))
+}
+
+func TestApiCollectorSetConditionalHeaders(t *testing.T) {
+	collector := &ApiCollector{
+		httpCaches: map[string]*models.CollectorHttpCache{
+			"etag":     {ETag: `W/"abc"`},
+			"modified": {LastModified: "Wed, 21 Oct 2015 07:28:00 GMT"},
+			"empty":    {},
+		},
+	}
+
+	original := http.Header{"Accept": []string{"application/json"}}
+	header := collector.setConditionalHeaders("etag", original)
+	assert.Equal(t, `W/"abc"`, header.Get("If-None-Match"))
+	assert.Equal(t, "application/json", header.Get("Accept"))
+	assert.Empty(t, original.Get("If-None-Match"))
+
+	header = collector.setConditionalHeaders("modified", nil)
+	assert.Equal(t, "Wed, 21 Oct 2015 07:28:00 GMT", header.Get("If-Modified-Since"))
+	assert.Empty(t, header.Get("If-None-Match"))
+
+	assert.Nil(t, collector.setConditionalHeaders("empty", nil))
+	assert.Nil(t, collector.setConditionalHeaders("unknown", nil))
+}
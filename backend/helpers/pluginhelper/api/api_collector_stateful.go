@@ -91,16 +91,25 @@ func (m *StatefulApiCollector) InitGraphQLCollector(args GraphqlCollectorArgs) e
 	return nil
 }
 
-// Execute all nested collectors and save the state if all collectors succeed
+// Execute all nested collectors and save the state if all collectors succeed, collectors which skipped
+// pages count as succeeded since the skipped pages would be re-collected by the next run
 func (m *StatefulApiCollector) Execute() errors.Error {
+	var partial errors.Error
 	for _, subtask := range m.nestedCollectors {
 		err := subtask.Execute()
 		if err != nil {
-			return err
+			if err.As(errors.Partial) == nil {
+				return err
+			}
+			partial = err
 		}
 	}
 
-	return m.CollectorStateManager.Close()
+	err := m.CollectorStateManager.Close()
+	if err != nil {
+		return err
+	}
+	return partial
 }
 
 // NewStatefulApiCollectorForFinalizableEntity aims to add timeFilter/diffSync support for
@@ -217,9 +226,10 @@ func NewStatefulApiCollectorForFinalizableEntity(args FinalizableApiCollectorArg
 			}
 			return items, err
 		},
-		AfterResponse: args.CollectNewRecordsByList.AfterResponse,
-		RequestBody:   args.CollectNewRecordsByList.RequestBody,
-		Method:        args.CollectNewRecordsByList.Method,
+		AfterResponse:   args.CollectNewRecordsByList.AfterResponse,
+		RequestBody:     args.CollectNewRecordsByList.RequestBody,
+		Method:          args.CollectNewRecordsByList.Method,
+		SkipFailedPages: args.CollectNewRecordsByList.SkipFailedPages,
 		// pagination
 		PageSize:              args.CollectNewRecordsByList.PageSize,
 		Concurrency:           args.CollectNewRecordsByList.Concurrency,
@@ -264,6 +274,7 @@ func NewStatefulApiCollectorForFinalizableEntity(args FinalizableApiCollectorArg
 		AfterResponse:   args.CollectUnfinishedDetails.AfterResponse,
 		RequestBody:     args.CollectUnfinishedDetails.RequestBody,
 		Method:          args.CollectUnfinishedDetails.Method,
+		SkipFailedPages: args.CollectUnfinishedDetails.SkipFailedPages,
 	})
 	return manager, err
 }
@@ -286,6 +297,7 @@ type FinalizableApiCollectorCommonArgs struct {
 	MinTickInterval *time.Duration                                                                  // optional, minimum interval between two requests, some endpoints might have a more conservative rate limit than others within the same instance, you can mitigate this by setting a higher MinTickInterval to override the connection level rate limit.
 	AfterResponse   plugin.ApiClientAfterResponse                                                   // optional, hook to run after each response, would be called before the ResponseParser
 	ResponseParser  func(res *http.Response) ([]json.RawMessage, errors.Error)                      // required, parse the response body and return a list of entities
+	SkipFailedPages bool                                                                            // optional, record pages failed to be fetched and re-collect them in the next run instead of failing the subtask
 }
 
 // FinalizableApiCollectorListArgs is the arguments for the list collector
@@ -32,6 +32,9 @@ type ApiExtractorArgs struct {
 	Params    interface{}
 	Extract   func(row *RawData) ([]interface{}, errors.Error)
 	BatchSize int
+	// Workers is the number of goroutines calling `Extract` concurrently, rows are extracted one by one when it is
+	// less than 2. Results are still saved in the order of the raw rows, but `Extract` MUST be goroutine-safe.
+	Workers int
 }
 
 // ApiExtractor helps you extract Raw Data from api responses to Tool Layer Data
@@ -98,47 +101,67 @@ func (extractor *ApiExtractor) Execute() errors.Error {
 
 	// progress
 	extractor.args.Ctx.SetProgress(0, -1)
-	ctx := extractor.args.Ctx.GetContext()
 	// iterate all rows
-	for cursor.Next() {
-		select {
-		case <-ctx.Done():
-			return errors.Convert(ctx.Err())
-		default:
-		}
-		row := &RawData{}
-		err = db.Fetch(cursor, row)
-		if err != nil {
-			return errors.Default.Wrap(err, "error fetching row")
-		}
-
-		results, err := extractor.args.Extract(row)
-		if err != nil {
-			return errors.Default.Wrap(err, "error calling plugin Extract implementation")
-		}
-		for _, result := range results {
-			// get the batch operator for the specific type
-			batch, err := divider.ForType(reflect.TypeOf(result))
+	err = processRows(
+		extractor.args.Ctx.GetContext(),
+		extractor.args.Workers,
+		func() (*RawData, bool, errors.Error) {
+			if !cursor.Next() {
+				return nil, false, nil
+			}
+			row := &RawData{}
+			err := db.Fetch(cursor, row)
 			if err != nil {
-				return errors.Default.Wrap(err, "error getting batch from result")
+				return nil, false, errors.Default.Wrap(err, "error fetching row")
 			}
-			// set raw data origin field
-			setRawDataOrigin(result, common.RawDataOrigin{
-				RawDataTable:  extractor.table,
-				RawDataId:     row.ID,
-				RawDataParams: row.Params,
-			})
-			// records get saved into db when slots were max outed
-			err = batch.Add(result)
+			return row, true, nil
+		},
+		func(row *RawData) ([]any, errors.Error) {
+			results, err := extractor.args.Extract(row)
 			if err != nil {
-				return errors.Default.Wrap(err, "error adding result to batch")
+				return nil, errors.Default.Wrap(err, "error calling plugin Extract implementation")
 			}
-		}
-		extractor.args.Ctx.IncProgress(1)
+			for _, result := range results {
+				// set raw data origin field
+				setRawDataOrigin(result, common.RawDataOrigin{
+					RawDataTable:  extractor.table,
+					RawDataId:     row.ID,
+					RawDataParams: row.Params,
+				})
+			}
+			return results, nil
+		},
+		func(results []any) errors.Error {
+			err := saveToDivider(divider, results)
+			if err != nil {
+				return err
+			}
+			extractor.args.Ctx.IncProgress(1)
+			return nil
+		},
+	)
+	if err != nil {
+		return err
 	}
 
 	// save the last batches
 	return divider.Close()
 }
 
+// saveToDivider adds the results to their batches, records get saved into db when slots were max outed
+func saveToDivider(divider *BatchSaveDivider, results []any) errors.Error {
+	for _, result := range results {
+		// get the batch operator for the specific type
+		batch, err := divider.ForType(reflect.TypeOf(result))
+		if err != nil {
+			return errors.Default.Wrap(err, "error getting batch from result")
+		}
+		err = batch.Add(result)
+		if err != nil {
+			return errors.Default.Wrap(err, "error adding result to batch")
+		}
+	}
+	return nil
+}
+
 var _ plugin.SubTask = (*ApiExtractor)(nil)
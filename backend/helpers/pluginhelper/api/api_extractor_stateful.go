@@ -19,7 +19,6 @@ package api
 
 import (
 	"encoding/json"
-	"reflect"
 
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
@@ -32,6 +31,10 @@ type StatefulApiExtractorArgs[InputType any] struct {
 	*SubtaskCommonArgs
 	BeforeExtract func(issue *InputType, stateManager *SubtaskStateManager) errors.Error
 	Extract       func(body *InputType, row *RawData) ([]any, errors.Error)
+	// Workers is the number of goroutines loading and extracting rows concurrently, rows are extracted one by one
+	// when it is less than 2. Results are still saved in the order of the raw rows, but `BeforeExtract` and `Extract`
+	// MUST be goroutine-safe.
+	Workers int
 }
 
 // StatefulApiExtractor is a struct that manages the stateful API extraction process.
@@ -138,60 +141,65 @@ func (extractor *StatefulApiExtractor[InputType]) Execute() errors.Error {
 
 	// progress
 	extractor.SetProgress(0, -1)
-	ctx := extractor.GetContext()
 
 	// process each record individually by ID
-	for _, id := range ids {
-		select {
-		case <-ctx.Done():
-			return errors.Convert(ctx.Err())
-		default:
-		}
-
-		// load full record by ID
-		row := &RawData{}
-		err := db.First(row, dal.From(table), dal.Where("id = ?", id))
-		if err != nil {
-			return errors.Default.Wrap(err, "error loading full row by ID")
-		}
-
-		body := new(InputType)
-		err = errors.Convert(json.Unmarshal(row.Data, body))
-		if err != nil {
-			return err
-		}
+	i := 0
+	err = processRows(
+		extractor.GetContext(),
+		extractor.Workers,
+		func() (uint64, bool, errors.Error) {
+			if i >= len(ids) {
+				return 0, false, nil
+			}
+			i++
+			return ids[i-1], true, nil
+		},
+		func(id uint64) ([]any, errors.Error) {
+			// load full record by ID
+			row := &RawData{}
+			err := db.First(row, dal.From(table), dal.Where("id = ?", id))
+			if err != nil {
+				return nil, errors.Default.Wrap(err, "error loading full row by ID")
+			}
 
-		if extractor.BeforeExtract != nil {
-			err = extractor.BeforeExtract(body, extractor.SubtaskStateManager)
+			body := new(InputType)
+			err = errors.Convert(json.Unmarshal(row.Data, body))
 			if err != nil {
-				return err
+				return nil, err
 			}
-		}
 
-		results, err := extractor.Extract(body, row)
-		if err != nil {
-			return errors.Default.Wrap(err, "error calling plugin Extract implementation")
-		}
+			if extractor.BeforeExtract != nil {
+				err = extractor.BeforeExtract(body, extractor.SubtaskStateManager)
+				if err != nil {
+					return nil, err
+				}
+			}
 
-		for _, result := range results {
-			// get the batch operator for the specific type
-			batch, err := divider.ForType(reflect.TypeOf(result))
+			results, err := extractor.Extract(body, row)
 			if err != nil {
-				return errors.Default.Wrap(err, "error getting batch from result")
+				return nil, errors.Default.Wrap(err, "error calling plugin Extract implementation")
+			}
+			for _, result := range results {
+				// set raw data origin field
+				setRawDataOrigin(result, common.RawDataOrigin{
+					RawDataTable:  table,
+					RawDataParams: params,
+					RawDataId:     row.ID,
+				})
 			}
-			// set raw data origin field
-			setRawDataOrigin(result, common.RawDataOrigin{
-				RawDataTable:  table,
-				RawDataParams: params,
-				RawDataId:     row.ID,
-			})
-			// records get saved into db when slots were max outed
-			err = batch.Add(result)
+			return results, nil
+		},
+		func(results []any) errors.Error {
+			err := saveToDivider(divider, results)
 			if err != nil {
-				return errors.Default.Wrap(err, "error adding result to batch")
+				return err
 			}
-		}
-		extractor.IncProgress(1)
+			extractor.IncProgress(1)
+			return nil
+		},
+	)
+	if err != nil {
+		return err
 	}
 
 	// save the last batches
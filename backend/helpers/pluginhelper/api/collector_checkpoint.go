@@ -0,0 +1,163 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/utils"
+)
+
+// collectorCheckpointer persists the pagination progress of a sequential collection every `interval` pages,
+// a nil collectorCheckpointer does nothing
+type collectorCheckpointer struct {
+	db         dal.Dal
+	interval   int
+	pages      int
+	mu         sync.Mutex
+	checkpoint *models.CollectorCheckpoint
+	resumable  bool
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// newCollectorCheckpointer loads the checkpoint left by the previous run, it would be discarded if the collection
+// configuration has changed since then
+func newCollectorCheckpointer(
+	ctx plugin.SubTaskContext,
+	table string,
+	params string,
+	collectorKey string,
+	interval int,
+	incremental bool,
+) (*collectorCheckpointer, errors.Error) {
+	if interval <= 0 {
+		return nil, nil
+	}
+	fingerprint := map[string]interface{}{"incremental": incremental}
+	if syncPolicy := ctx.TaskContext().SyncPolicy(); syncPolicy != nil {
+		fingerprint["fullSync"] = syncPolicy.FullSync
+		fingerprint["timeAfter"] = syncPolicy.TimeAfter
+	}
+	checkpoint := &models.CollectorCheckpoint{
+		RawDataTable:  table,
+		RawDataParams: params,
+		CollectorKey:  sha256Hex(collectorKey),
+		Fingerprint:   sha256Hex(utils.ToJsonString(fingerprint)),
+	}
+	c := &collectorCheckpointer{
+		db:         ctx.GetDal(),
+		interval:   interval,
+		checkpoint: checkpoint,
+	}
+	previous := &models.CollectorCheckpoint{}
+	err := c.db.First(previous, c.where())
+	if err == nil && previous.Fingerprint == checkpoint.Fingerprint {
+		c.checkpoint = previous
+		c.resumable = true
+		return c, nil
+	}
+	if err != nil && !c.db.IsErrorNotFound(err) {
+		return nil, errors.Default.Wrap(err, "failed to load the collector checkpoint")
+	}
+	if err == nil {
+		ctx.GetLogger().Info("collection configuration changed, discarding the checkpoint of the previous run")
+		err = c.db.Delete(&models.CollectorCheckpoint{}, c.where())
+		if err != nil {
+			return nil, err
+		}
+	}
+	// rows collected by previous runs must be kept when resuming even if no row was saved before the first checkpoint
+	last := &RawData{}
+	err = c.db.First(last, dal.Select("id"), dal.From(table), dal.Where("params = ?", params), dal.Orderby("id DESC"))
+	if err != nil && !c.db.IsErrorNotFound(err) {
+		return nil, errors.Default.Wrap(err, "failed to load the last raw row")
+	}
+	checkpoint.LastRawId = last.ID
+	return c, nil
+}
+
+func (c *collectorCheckpointer) where() dal.Clause {
+	return dal.Where(
+		"raw_data_table = ? AND raw_data_params = ? AND collector_key = ?",
+		c.checkpoint.RawDataTable, c.checkpoint.RawDataParams, c.checkpoint.CollectorKey,
+	)
+}
+
+// Resumable returns the checkpoint to resume from, nil if the collection should start from the beginning
+func (c *collectorCheckpointer) Resumable() *models.CollectorCheckpoint {
+	if c == nil || !c.resumable {
+		return nil
+	}
+	return c.checkpoint
+}
+
+// PurgeUncheckpointed deletes raw rows saved after the checkpoint, they would be collected again
+func (c *collectorCheckpointer) PurgeUncheckpointed() errors.Error {
+	checkpoint := c.Resumable()
+	if checkpoint == nil {
+		return nil
+	}
+	return c.db.Delete(
+		&RawData{},
+		dal.From(checkpoint.RawDataTable),
+		dal.Where("params = ? AND id > ?", checkpoint.RawDataParams, checkpoint.LastRawId),
+	)
+}
+
+// Tick counts a page fetched and saves the progress every `interval` pages, page/skip/cursor are of the next page
+func (c *collectorCheckpointer) Tick(page int, skip int, cursor interface{}, lastRawId uint64) errors.Error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pages++
+	if c.pages%c.interval != 0 {
+		return nil
+	}
+	cursorJson, err := json.Marshal(cursor)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to encode cursor of the checkpoint")
+	}
+	c.checkpoint.Page = page
+	c.checkpoint.Skip = skip
+	c.checkpoint.Cursor = cursorJson
+	if lastRawId > c.checkpoint.LastRawId {
+		c.checkpoint.LastRawId = lastRawId
+	}
+	return c.db.CreateOrUpdate(c.checkpoint)
+}
+
+// Done removes the checkpoint once the collection finished successfully
+func (c *collectorCheckpointer) Done() errors.Error {
+	if c == nil {
+		return nil
+	}
+	return c.db.Delete(&models.CollectorCheckpoint{}, c.where())
+}
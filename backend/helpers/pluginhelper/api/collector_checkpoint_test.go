@@ -0,0 +1,60 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models"
+	mockdal "github.com/apache/incubator-devlake/mocks/core/dal"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCollectorCheckpointerTick(t *testing.T) {
+	mockDal := new(mockdal.Dal)
+	// checkpoint should be saved every 2 pages
+	mockDal.On("CreateOrUpdate", mock.Anything, mock.Anything).Return(nil).Twice()
+
+	checkpointer := &collectorCheckpointer{
+		db:         mockDal,
+		interval:   2,
+		checkpoint: &models.CollectorCheckpoint{LastRawId: 10},
+	}
+	for page := 2; page <= 5; page++ {
+		assert.Nil(t, checkpointer.Tick(page, (page-1)*100, fmt.Sprintf("cursor%d", page), uint64(page*10)))
+	}
+	mockDal.AssertExpectations(t)
+	assert.Equal(t, 5, checkpointer.checkpoint.Page)
+	assert.Equal(t, 400, checkpointer.checkpoint.Skip)
+	assert.Equal(t, json.RawMessage(`"cursor5"`), checkpointer.checkpoint.Cursor)
+	assert.Equal(t, uint64(50), checkpointer.checkpoint.LastRawId)
+	// only a checkpoint loaded from the previous run is resumable
+	assert.Nil(t, checkpointer.Resumable())
+}
+
+func TestCollectorCheckpointerDisabled(t *testing.T) {
+	var checkpointer *collectorCheckpointer
+	assert.Nil(t, checkpointer.Resumable())
+	assert.Nil(t, checkpointer.PurgeUncheckpointed())
+	assert.Nil(t, checkpointer.Tick(2, 100, "cursor", 1))
+	assert.Nil(t, checkpointer.Done())
+}
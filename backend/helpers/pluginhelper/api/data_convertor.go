@@ -36,6 +36,7 @@ type DataConvertHandler func(row interface{}) ([]interface{}, errors.Error)
 //				RawDataSubTaskArgs: args about raw data task
 //				Convert: 			main function including conversion logic
 //				BatchSize: 			batch size
+//				Workers: 			number of goroutines running Convert concurrently
 type DataConverterArgs struct {
 	RawDataSubTaskArgs
 	// Domain layer entity ID prefix, i.e. `jira:JiraIssue:1`, `github:GithubIssue`
@@ -43,6 +44,9 @@ type DataConverterArgs struct {
 	Input        dal.Rows
 	Convert      DataConvertHandler
 	BatchSize    int
+	// Workers is the number of goroutines calling `Convert` concurrently, rows are converted one by one when it is
+	// less than 2. Results are still saved in the order of the input rows, but `Convert` MUST be goroutine-safe.
+	Workers int
 }
 
 // DataConverter helps you convert Data from Tool Layer Tables to Domain Layer Tables
@@ -88,43 +92,46 @@ func (converter *DataConverter) Execute() errors.Error {
 
 	cursor := converter.args.Input
 	defer cursor.Close()
-	ctx := converter.args.Ctx.GetContext()
 	// iterate all rows
-	for cursor.Next() {
-		select {
-		case <-ctx.Done():
-			return errors.Convert(ctx.Err())
-		default:
-		}
-		inputRow := reflect.New(converter.args.InputRowType).Interface()
-		err := db.Fetch(cursor, inputRow)
-		if err != nil {
-			return errors.Default.Wrap(err, "error fetching rows")
-		}
-
-		results, err := converter.args.Convert(inputRow)
-		if err != nil {
-			return errors.Default.Wrap(err, "error calling Converter plugin implementation")
-		}
-
-		for _, result := range results {
-			// get the batch operator for the specific type
-			batch, err := divider.ForType(reflect.TypeOf(result))
+	err := processRows(
+		converter.args.Ctx.GetContext(),
+		converter.args.Workers,
+		func() (interface{}, bool, errors.Error) {
+			if !cursor.Next() {
+				return nil, false, nil
+			}
+			inputRow := reflect.New(converter.args.InputRowType).Interface()
+			err := db.Fetch(cursor, inputRow)
 			if err != nil {
-				return errors.Default.Wrap(err, "error getting batch from result")
+				return nil, false, errors.Default.Wrap(err, "error fetching rows")
 			}
-			// set raw data origin field
-			origin := reflect.ValueOf(result).Elem().FieldByName(RAW_DATA_ORIGIN)
-			if origin.IsValid() {
-				origin.Set(reflect.ValueOf(inputRow).Elem().FieldByName(RAW_DATA_ORIGIN))
+			return inputRow, true, nil
+		},
+		func(inputRow interface{}) ([]any, errors.Error) {
+			results, err := converter.args.Convert(inputRow)
+			if err != nil {
+				return nil, errors.Default.Wrap(err, "error calling Converter plugin implementation")
+			}
+			for _, result := range results {
+				// set raw data origin field
+				origin := reflect.ValueOf(result).Elem().FieldByName(RAW_DATA_ORIGIN)
+				if origin.IsValid() {
+					origin.Set(reflect.ValueOf(inputRow).Elem().FieldByName(RAW_DATA_ORIGIN))
+				}
 			}
-			// records get saved into db when slots were max outed
-			err = batch.Add(result)
+			return results, nil
+		},
+		func(results []any) errors.Error {
+			err := saveToDivider(divider, results)
 			if err != nil {
-				return errors.Default.Wrap(err, "error adding result to batch")
+				return err
 			}
-		}
-		converter.args.Ctx.IncProgress(1)
+			converter.args.Ctx.IncProgress(1)
+			return nil
+		},
+	)
+	if err != nil {
+		return err
 	}
 
 	// save the last batches
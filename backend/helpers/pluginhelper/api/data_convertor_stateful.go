@@ -31,6 +31,10 @@ type StatefulDataConverterArgs[InputType any] struct {
 	BeforeConvert func(issue *InputType, stateManager *SubtaskStateManager) errors.Error
 	Convert       func(row *InputType) ([]any, errors.Error)
 	BatchSize     int
+	// Workers is the number of goroutines calling `BeforeConvert` and `Convert` concurrently, rows are converted
+	// one by one when it is less than 2. Results are still saved in the order of the input rows, but both handlers
+	// MUST be goroutine-safe.
+	Workers int
 }
 
 // StatefulDataConverter is a struct that manages the stateful data conversion process.
@@ -141,50 +145,53 @@ func (converter *StatefulDataConverter[InputType]) Execute() errors.Error {
 		return err
 	}
 	defer cursor.Close()
-	ctx := converter.GetContext()
 	// iterate all rows
-	for cursor.Next() {
-		select {
-		case <-ctx.Done():
-			return errors.Convert(ctx.Err())
-		default:
-		}
-		inputRow := new(InputType)
-		err := db.Fetch(cursor, inputRow)
-		if err != nil {
-			return errors.Default.Wrap(err, "error fetching rows")
-		}
-
-		if converter.BeforeConvert != nil {
-			err = converter.BeforeConvert(inputRow, converter.SubtaskStateManager)
+	err = processRows(
+		converter.GetContext(),
+		converter.Workers,
+		func() (*InputType, bool, errors.Error) {
+			if !cursor.Next() {
+				return nil, false, nil
+			}
+			inputRow := new(InputType)
+			err := db.Fetch(cursor, inputRow)
 			if err != nil {
-				return err
+				return nil, false, errors.Default.Wrap(err, "error fetching rows")
+			}
+			return inputRow, true, nil
+		},
+		func(inputRow *InputType) ([]any, errors.Error) {
+			if converter.BeforeConvert != nil {
+				err := converter.BeforeConvert(inputRow, converter.SubtaskStateManager)
+				if err != nil {
+					return nil, err
+				}
 			}
-		}
-
-		results, err := converter.Convert(inputRow)
-		if err != nil {
-			return errors.Default.Wrap(err, "error calling Converter plugin implementation")
-		}
 
-		for _, result := range results {
-			// get the batch operator for the specific type
-			batch, err := divider.ForType(reflect.TypeOf(result))
+			results, err := converter.Convert(inputRow)
 			if err != nil {
-				return errors.Default.Wrap(err, "error getting batch from result")
+				return nil, errors.Default.Wrap(err, "error calling Converter plugin implementation")
 			}
-			// set raw data origin field
-			origin := reflect.ValueOf(result).Elem().FieldByName(RAW_DATA_ORIGIN)
-			if origin.IsValid() {
-				origin.Set(reflect.ValueOf(inputRow).Elem().FieldByName(RAW_DATA_ORIGIN))
+			for _, result := range results {
+				// set raw data origin field
+				origin := reflect.ValueOf(result).Elem().FieldByName(RAW_DATA_ORIGIN)
+				if origin.IsValid() {
+					origin.Set(reflect.ValueOf(inputRow).Elem().FieldByName(RAW_DATA_ORIGIN))
+				}
 			}
-			// records get saved into db when slots were max outed
-			err = batch.Add(result)
+			return results, nil
+		},
+		func(results []any) errors.Error {
+			err := saveToDivider(divider, results)
 			if err != nil {
-				return errors.Default.Wrap(err, "error adding result to batch")
+				return err
 			}
-		}
-		converter.IncProgress(1)
+			converter.IncProgress(1)
+			return nil
+		},
+	)
+	if err != nil {
+		return err
 	}
 
 	// save the last batches
@@ -24,7 +24,9 @@ import (
 
 	"github.com/apache/incubator-devlake/core/context"
 	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/audithelper"
 	"github.com/apache/incubator-devlake/helpers/srvhelper"
 )
 
@@ -32,6 +34,7 @@ import (
 type DsConnectionApiHelper[C plugin.ToolLayerConnection, S plugin.ToolLayerScope, SC plugin.ToolLayerScopeConfig] struct {
 	*ModelApiHelper[C]
 	*srvhelper.ConnectionSrvHelper[C, S, SC]
+	auditHelper *audithelper.AuditHelper
 }
 
 func NewDsConnectionApiHelper[
@@ -45,9 +48,35 @@ func NewDsConnectionApiHelper[
 	return &DsConnectionApiHelper[C, S, SC]{
 		ModelApiHelper:      NewModelApiHelper[C](basicRes, connSrvHelper.ModelSrvHelper, []string{"connectionId"}, sterilizer),
 		ConnectionSrvHelper: connSrvHelper,
+		auditHelper:         audithelper.NewAuditHelper(basicRes),
 	}
 }
 
+func (connApi *DsConnectionApiHelper[C, S, SC]) Post(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	out, err := connApi.ModelApiHelper.Post(input)
+	if err != nil {
+		return out, err
+	}
+	conn := out.Body.(*C)
+	recordPluginAuditEvent(connApi.auditHelper, input, models.AUDIT_ACTION_CREATE, models.AUDIT_TARGET_CONNECTION, (*conn).ConnectionId(), conn, nil, conn)
+	return out, nil
+}
+
+func (connApi *DsConnectionApiHelper[C, S, SC]) Patch(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	before, err := connApi.FindByPk(input)
+	if err != nil {
+		return nil, err
+	}
+	before = connApi.Sanitize(before)
+	out, err := connApi.ModelApiHelper.Patch(input)
+	if err != nil {
+		return out, err
+	}
+	conn := out.Body.(*C)
+	recordPluginAuditEvent(connApi.auditHelper, input, models.AUDIT_ACTION_UPDATE, models.AUDIT_TARGET_CONNECTION, (*conn).ConnectionId(), conn, before, conn)
+	return out, nil
+}
+
 func (connApi *DsConnectionApiHelper[C, S, SC]) GetMergedConnection(input *plugin.ApiResourceInput) (*C, errors.Error) {
 	connection, err := connApi.FindByPk(input)
 	if err != nil {
@@ -76,11 +105,27 @@ func (connApi *DsConnectionApiHelper[C, S, SC]) Delete(input *plugin.ApiResource
 		}, Status: err.GetType().GetHttpCode()}, err
 	}
 	conn = connApi.Sanitize(conn)
+	recordPluginAuditEvent(connApi.auditHelper, input, models.AUDIT_ACTION_DELETE, models.AUDIT_TARGET_CONNECTION, (*conn).ConnectionId(), conn, conn, nil)
 	return &plugin.ApiResourceOutput{
 		Body: conn,
 	}, nil
 }
 
+// recordPluginAuditEvent appends the audit event of the change on the plugin model made through the plugin api
+func recordPluginAuditEvent(auditHelper *audithelper.AuditHelper, input *plugin.ApiResourceInput, action, targetType string, targetId uint64, model any, before, after any) {
+	targetName := ""
+	if hasField(model, "Name") {
+		targetName = reflectField(model, "Name").String()
+	}
+	auditHelper.Record(input.User, &models.AuditEvent{
+		Action:       action,
+		TargetType:   targetType,
+		TargetPlugin: input.GetPlugin(),
+		TargetId:     strconv.FormatUint(targetId, 10),
+		TargetName:   targetName,
+	}, before, after)
+}
+
 func extractConnectionId(input *plugin.ApiResourceInput) (uint64, errors.Error) {
 	connectionId, ok := input.Params["connectionId"]
 	if !ok {
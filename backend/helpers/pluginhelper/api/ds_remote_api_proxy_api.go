@@ -77,7 +77,8 @@ func (rap *DsRemoteApiProxyHelper[C]) getApiClient(connection *C) (*ApiClient, e
 		}
 	}
 	// create new client if cache missed
-	client, err := NewApiClientFromConnection(gocontext.TODO(), rap.basicRes, c.(plugin.ApiConnection))
+	// the connection was loaded from the database as is, so its secret references can be resolved
+	client, err := newApiClientFromConnection(gocontext.TODO(), rap.basicRes, c.(plugin.ApiConnection), true)
 	if err != nil {
 		return nil, err
 	}
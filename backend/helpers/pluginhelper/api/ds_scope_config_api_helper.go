@@ -20,7 +20,9 @@ package api
 import (
 	"github.com/apache/incubator-devlake/core/context"
 	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/audithelper"
 	"github.com/apache/incubator-devlake/helpers/srvhelper"
 	"github.com/apache/incubator-devlake/server/api/shared"
 )
@@ -29,6 +31,7 @@ import (
 type DsScopeConfigApiHelper[C plugin.ToolLayerConnection, S plugin.ToolLayerScope, SC plugin.ToolLayerScopeConfig] struct {
 	*ModelApiHelper[SC]
 	*srvhelper.ScopeConfigSrvHelper[C, S, SC]
+	auditHelper *audithelper.AuditHelper
 }
 
 func NewDsScopeConfigApiHelper[
@@ -43,6 +46,7 @@ func NewDsScopeConfigApiHelper[
 	return &DsScopeConfigApiHelper[C, S, SC]{
 		ModelApiHelper:       NewModelApiHelper[SC](basicRes, dalHelper.ModelSrvHelper, []string{"scopeConfigId"}, sterilizer),
 		ScopeConfigSrvHelper: dalHelper,
+		auditHelper:          audithelper.NewAuditHelper(basicRes),
 	}
 }
 
@@ -76,7 +80,13 @@ func (connApi *DsScopeConfigApiHelper[C, S, SC]) Post(input *plugin.ApiResourceI
 		return nil, err
 	}
 	input.Body["connectionId"] = connectionId
-	return connApi.ModelApiHelper.Post(input)
+	out, err = connApi.ModelApiHelper.Post(input)
+	if err != nil {
+		return out, err
+	}
+	scopeConfig := out.Body.(*SC)
+	recordPluginAuditEvent(connApi.auditHelper, input, models.AUDIT_ACTION_CREATE, models.AUDIT_TARGET_SCOPE_CONFIG, (*scopeConfig).ScopeConfigId(), scopeConfig, nil, scopeConfig)
+	return out, nil
 }
 
 func (connApi *DsScopeConfigApiHelper[C, S, SC]) Patch(input *plugin.ApiResourceInput) (out *plugin.ApiResourceOutput, err errors.Error) {
@@ -86,7 +96,18 @@ func (connApi *DsScopeConfigApiHelper[C, S, SC]) Patch(input *plugin.ApiResource
 		return nil, err
 	}
 	input.Body["connectionId"] = connectionId
-	return connApi.ModelApiHelper.Patch(input)
+	before, err := connApi.FindByPk(input)
+	if err != nil {
+		return nil, err
+	}
+	before = connApi.Sanitize(before)
+	out, err = connApi.ModelApiHelper.Patch(input)
+	if err != nil {
+		return out, err
+	}
+	scopeConfig := out.Body.(*SC)
+	recordPluginAuditEvent(connApi.auditHelper, input, models.AUDIT_ACTION_UPDATE, models.AUDIT_TARGET_SCOPE_CONFIG, (*scopeConfig).ScopeConfigId(), scopeConfig, before, scopeConfig)
+	return out, nil
 }
 
 func (connApi *DsScopeConfigApiHelper[C, S, SC]) Delete(input *plugin.ApiResourceInput) (out *plugin.ApiResourceOutput, err errors.Error) {
@@ -103,6 +124,7 @@ func (connApi *DsScopeConfigApiHelper[C, S, SC]) Delete(input *plugin.ApiResourc
 			Data:    refs,
 		}, Status: err.GetType().GetHttpCode()}, err
 	}
+	recordPluginAuditEvent(connApi.auditHelper, input, models.AUDIT_ACTION_DELETE, models.AUDIT_TARGET_SCOPE_CONFIG, (*scopeConfig).ScopeConfigId(), scopeConfig, scopeConfig, nil)
 	return &plugin.ApiResourceOutput{
 		Body: scopeConfig,
 	}, nil
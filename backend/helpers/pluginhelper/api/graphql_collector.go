@@ -80,6 +80,10 @@ type GraphqlCollectorArgs struct {
 	// one of ResponseParser and ResponseParserEvenWhenDataErrors is required to parse response
 	ResponseParser    func(queryWrapper interface{}) ([]json.RawMessage, errors.Error)
 	IgnoreQueryErrors bool
+	// CheckpointInterval tells GraphqlCollector to persist the cursor every specified number of pages, so the next
+	// run with the same configuration could resume from the last checkpoint if the collection crashed.
+	// It works with GetPageInfo without Input only
+	CheckpointInterval int
 }
 
 // GraphqlCollector help you collect data from Graphql services
@@ -88,6 +92,8 @@ type GraphqlCollector struct {
 	args         *GraphqlCollectorArgs
 	workerErrors []error
 	batchSave    *BatchSave
+	checkpointer *collectorCheckpointer
+	lastRawId    uint64
 }
 
 // ErrFinishCollect is an error which will finish this collector
@@ -114,6 +120,9 @@ func NewGraphqlCollector(args GraphqlCollectorArgs) (*GraphqlCollector, errors.E
 	if args.InputStep == 0 {
 		args.InputStep = 1
 	}
+	if args.CheckpointInterval > 0 && (args.Input != nil || args.GetPageInfo == nil) {
+		return nil, errors.Default.New("CheckpointInterval works with GetPageInfo without Input only")
+	}
 	apiCollector := &GraphqlCollector{
 		RawDataSubTask: rawDataSubTask,
 		args:           &args,
@@ -138,8 +147,26 @@ func (collector *GraphqlCollector) Execute() errors.Error {
 	if err != nil {
 		return errors.Default.Wrap(err, "error running auto-migrate")
 	}
-	// flush data if not incremental collection
-	if !collector.args.Incremental {
+	collector.checkpointer, err = newCollectorCheckpointer(
+		collector.args.Ctx,
+		collector.table,
+		collector.params,
+		"graphql",
+		collector.args.CheckpointInterval,
+		collector.args.Incremental,
+	)
+	if err != nil {
+		return err
+	}
+	if checkpoint := collector.checkpointer.Resumable(); checkpoint != nil {
+		// resume from the checkpoint, rows saved after it would be collected again
+		logger.Info("resuming graphql collection from page %d", checkpoint.Page)
+		err = collector.checkpointer.PurgeUncheckpointed()
+		if err != nil {
+			return errors.Default.Wrap(err, "error deleting data collected after the checkpoint")
+		}
+	} else if !collector.args.Incremental {
+		// flush data if not incremental collection
 		err = db.Delete(&RawData{}, dal.From(collector.table), dal.Where("params = ?", collector.params))
 		if err != nil {
 			return errors.Default.Wrap(err, "error deleting data from collector")
@@ -195,7 +222,10 @@ func (collector *GraphqlCollector) Execute() errors.Error {
 	}
 
 	err = collector.batchSave.Close()
-	return err
+	if err != nil {
+		return err
+	}
+	return collector.checkpointer.Done()
 }
 
 func (collector *GraphqlCollector) exec(input interface{}) {
@@ -210,6 +240,13 @@ func (collector *GraphqlCollector) exec(input interface{}) {
 		SkipCursor: nil,
 		Size:       collector.args.PageSize,
 	}
+	if checkpoint := collector.checkpointer.Resumable(); checkpoint != nil {
+		err = json.Unmarshal(checkpoint.Cursor, &reqData.Pager.SkipCursor)
+		if err != nil {
+			collector.checkError(errors.Default.Wrap(err, `cursor of the checkpoint can not be unmarshal from json`))
+			return
+		}
+	}
 	if collector.args.GetPageInfo != nil {
 		collector.fetchOneByOne(reqData)
 	} else {
@@ -219,6 +256,10 @@ func (collector *GraphqlCollector) exec(input interface{}) {
 
 // fetchOneByOne fetches data of all pages for APIs that return paging information
 func (collector *GraphqlCollector) fetchOneByOne(reqData *GraphqlRequestData) {
+	page := 1
+	if checkpoint := collector.checkpointer.Resumable(); checkpoint != nil {
+		page = checkpoint.Page
+	}
 	// fetch first page
 	var fetchNextPage func(query interface{}) errors.Error
 	fetchNextPage = func(query interface{}) errors.Error {
@@ -230,6 +271,11 @@ func (collector *GraphqlCollector) fetchOneByOne(reqData *GraphqlRequestData) {
 			return errors.Default.New("fetchPagesDetermined got pageInfo is nil")
 		}
 		if pageInfo.HasNextPage {
+			page++
+			err = collector.checkpointer.Tick(page, 0, pageInfo.EndCursor, collector.lastRawId)
+			if err != nil {
+				return errors.Default.Wrap(err, "error saving checkpoint")
+			}
 			collector.args.GraphqlClient.NextTick(func() errors.Error {
 				reqDataTemp := &GraphqlRequestData{
 					Pager: &CursorPager{
@@ -305,6 +351,9 @@ func (collector *GraphqlCollector) fetchAsync(reqData *GraphqlRequestData, handl
 			collector.checkError(errors.Default.Wrap(err, `not created row table in graphql collector`))
 			return
 		}
+		if collector.checkpointer != nil {
+			collector.lastRawId = row.ID
+		}
 	}
 	if err != nil {
 		if errors.Is(err, ErrFinishCollect) {
@@ -0,0 +1,141 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"sync"
+
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+// rowProcessJob holds one input row and its processing results while it travels through the worker pool
+type rowProcessJob[InputType any] struct {
+	input   InputType
+	results []any
+	err     errors.Error
+	done    chan struct{}
+}
+
+// processRows pulls rows from `next` until it reports there is nothing left, transforms each of them with `process`
+// and hands the results to `save`.
+// With workers <= 1 everything runs on the calling goroutine, row by row.
+// With workers > 1 `process` runs concurrently on a pool of goroutines while `next` and `save` stay single-threaded,
+// results are saved in exactly the same order as the rows were pulled, so records sharing a primary key are always
+// written in input order, and only about 2*workers rows are held in memory at any time.
+// `process` MUST be goroutine-safe when workers > 1.
+func processRows[InputType any](
+	ctx context.Context,
+	workers int,
+	next func() (InputType, bool, errors.Error),
+	process func(InputType) ([]any, errors.Error),
+	save func([]any) errors.Error,
+) errors.Error {
+	if workers <= 1 {
+		for {
+			select {
+			case <-ctx.Done():
+				return errors.Convert(ctx.Err())
+			default:
+			}
+			input, ok, err := next()
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+			results, err := process(input)
+			if err != nil {
+				return err
+			}
+			err = save(results)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	poolCtx, cancel := context.WithCancel(ctx)
+	wg := &sync.WaitGroup{}
+	// make sure no goroutine is touching the cursor or the handlers once we return
+	defer wg.Wait()
+	defer cancel()
+
+	// jobs are queued in the input order, the capacity limits how many rows can be in-flight
+	queue := make(chan *rowProcessJob[InputType], workers)
+	jobs := make(chan *rowProcessJob[InputType])
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.results, job.err = process(job.input)
+				close(job.done)
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		defer close(queue)
+		for {
+			input, ok, err := next()
+			if err != nil {
+				job := &rowProcessJob[InputType]{err: err, done: make(chan struct{})}
+				close(job.done)
+				select {
+				case queue <- job:
+				case <-poolCtx.Done():
+				}
+				return
+			}
+			if !ok {
+				return
+			}
+			job := &rowProcessJob[InputType]{input: input, done: make(chan struct{})}
+			select {
+			case queue <- job:
+			case <-poolCtx.Done():
+				return
+			}
+			select {
+			case jobs <- job:
+			case <-poolCtx.Done():
+				return
+			}
+		}
+	}()
+
+	for job := range queue {
+		select {
+		case <-job.done:
+		case <-poolCtx.Done():
+			return errors.Convert(poolCtx.Err())
+		}
+		if job.err != nil {
+			return job.err
+		}
+		err := save(job.results)
+		if err != nil {
+			return err
+		}
+	}
+	return errors.Convert(ctx.Err())
+}
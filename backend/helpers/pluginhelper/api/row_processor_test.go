@@ -0,0 +1,157 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func rowSource(n int) func() (int, bool, errors.Error) {
+	i := 0
+	return func() (int, bool, errors.Error) {
+		if i >= n {
+			return 0, false, nil
+		}
+		i++
+		return i, true, nil
+	}
+}
+
+func TestProcessRowsKeepsOrder(t *testing.T) {
+	for _, workers := range []int{0, 1, 8} {
+		var saved []any
+		err := processRows(
+			context.Background(),
+			workers,
+			rowSource(100),
+			func(i int) ([]any, errors.Error) {
+				// later rows finish earlier
+				time.Sleep(time.Duration(100-i) * 10 * time.Microsecond)
+				return []any{i, -i}, nil
+			},
+			func(results []any) errors.Error {
+				saved = append(saved, results...)
+				return nil
+			},
+		)
+		assert.Nil(t, err)
+		assert.Len(t, saved, 200)
+		for i := 0; i < 100; i++ {
+			assert.Equal(t, i+1, saved[2*i])
+			assert.Equal(t, -i-1, saved[2*i+1])
+		}
+	}
+}
+
+func TestProcessRowsBoundsInflightRows(t *testing.T) {
+	var pulled, saved int32
+	var maxInflight int32
+	err := processRows(
+		context.Background(),
+		4,
+		func() (int, bool, errors.Error) {
+			n := atomic.AddInt32(&pulled, 1)
+			if inflight := n - atomic.LoadInt32(&saved); inflight > atomic.LoadInt32(&maxInflight) {
+				atomic.StoreInt32(&maxInflight, inflight)
+			}
+			return int(n), n <= 1000, nil
+		},
+		func(i int) ([]any, errors.Error) {
+			return []any{i}, nil
+		},
+		func(results []any) errors.Error {
+			// a slow writer must not let the reader run away
+			time.Sleep(10 * time.Microsecond)
+			atomic.AddInt32(&saved, 1)
+			return nil
+		},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1000), saved)
+	assert.LessOrEqual(t, maxInflight, int32(2*4+2))
+}
+
+func TestProcessRowsStopsOnError(t *testing.T) {
+	for _, workers := range []int{1, 8} {
+		var saved int32
+		err := processRows(
+			context.Background(),
+			workers,
+			rowSource(100),
+			func(i int) ([]any, errors.Error) {
+				if i == 50 {
+					return nil, errors.Default.New("bad row")
+				}
+				return []any{i}, nil
+			},
+			func(results []any) errors.Error {
+				atomic.AddInt32(&saved, 1)
+				return nil
+			},
+		)
+		assert.NotNil(t, err)
+		assert.Equal(t, "bad row", err.Error())
+		// rows before the broken one are saved, none after
+		assert.Equal(t, int32(49), saved)
+	}
+
+	err := processRows(
+		context.Background(),
+		8,
+		func() (int, bool, errors.Error) {
+			return 0, false, errors.Default.New("bad cursor")
+		},
+		func(i int) ([]any, errors.Error) {
+			return []any{i}, nil
+		},
+		func(results []any) errors.Error {
+			return nil
+		},
+	)
+	assert.NotNil(t, err)
+	assert.Equal(t, "bad cursor", err.Error())
+}
+
+func TestProcessRowsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var saved int32
+	err := processRows(
+		ctx,
+		8,
+		func() (int, bool, errors.Error) {
+			return 1, true, nil
+		},
+		func(i int) ([]any, errors.Error) {
+			return []any{i}, nil
+		},
+		func(results []any) errors.Error {
+			if atomic.AddInt32(&saved, 1) == 10 {
+				cancel()
+			}
+			return nil
+		},
+	)
+	assert.NotNil(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
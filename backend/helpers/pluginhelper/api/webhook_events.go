@@ -0,0 +1,196 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/utils"
+)
+
+// VerifyHmacSha256Signature tells if signature is the hex encoded HMAC-SHA256 of payload keyed with secret,
+// the `sha256=` prefix GitHub puts in front of it is accepted
+func VerifyHmacSha256Signature(secret string, payload []byte, signature string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(signature), "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// VerifySecretToken tells if token equals the shared secret, GitLab sends the secret as is in `X-Gitlab-Token`
+func VerifySecretToken(secret string, token string) bool {
+	return secret != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(token)) == 1
+}
+
+// SignWithNonce signs payload the way DevLake signs its outbound pipeline notifications, the hex encoded sha256
+// of the payload, the secret and the nonce concatenated
+func SignWithNonce(payload []byte, secret string, nonce string) string {
+	sum := sha256.Sum256(append(append(append([]byte{}, payload...), secret...), nonce...))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyNonceSignature tells if signature was produced by SignWithNonce with the same secret and nonce
+func VerifyNonceSignature(payload []byte, secret string, nonce string, signature string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	expected := SignWithNonce(payload, secret, nonce)
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(strings.TrimSpace(signature)))) == 1
+}
+
+// SaveWebhookRawData stores a payload received from a webhook into the `_raw_<table>` table the collectors write to,
+// so the extractors handle it exactly like a collected record
+func SaveWebhookRawData(db dal.Dal, table string, params any, url string, data []byte) errors.Error {
+	rawTable := fmt.Sprintf("_raw_%s", table)
+	rawTableAutoMigrateLock.Lock()
+	err := db.AutoMigrate(&RawData{}, dal.From(rawTable))
+	rawTableAutoMigrateLock.Unlock()
+	if err != nil {
+		return err
+	}
+	return db.Create(&RawData{
+		Params:    plugin.MarshalScopeParams(params),
+		Data:      data,
+		Url:       url,
+		Input:     json.RawMessage("null"),
+		CreatedAt: time.Now(),
+	}, dal.From(rawTable))
+}
+
+// WebhookRefresher queues a pipeline extracting and converting the raw data of a scope shortly after webhook events
+// were stored into it, so the tool and domain layers are refreshed by the pipeline runner rather than within the api
+// request. The events of a scope received until the pipeline is queued are folded into it.
+type WebhookRefresher struct {
+	basicRes    context.BasicRes
+	pluginNames []string
+	delay       time.Duration
+	mu          sync.Mutex
+	pending     map[string]*models.PipelineTask
+}
+
+// NewWebhookRefresher creates a WebhookRefresher queueing pipelines after the delay, pluginNames are the plugins whose
+// tasks write the same tool layer tables, no pipeline is queued while one of their tasks is pending
+func NewWebhookRefresher(basicRes context.BasicRes, delay time.Duration, pluginNames ...string) *WebhookRefresher {
+	return &WebhookRefresher{
+		basicRes:    basicRes,
+		pluginNames: pluginNames,
+		delay:       delay,
+		pending:     make(map[string]*models.PipelineTask),
+	}
+}
+
+// Schedule queues a pipeline running the subtasks of task for the scope identified by key after the delay, collectors
+// are skipped. The subtasks of the calls made for the same key in the meantime are added to the same task.
+func (r *WebhookRefresher) Schedule(key string, task *models.PipelineTask) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if pending, ok := r.pending[key]; ok {
+		for _, subtask := range task.Subtasks {
+			if !utils.StringsContains(pending.Subtasks, subtask) {
+				pending.Subtasks = append(pending.Subtasks, subtask)
+			}
+		}
+		return
+	}
+	r.pending[key] = &models.PipelineTask{
+		Plugin:   task.Plugin,
+		Subtasks: append([]string(nil), task.Subtasks...),
+		Options:  task.Options,
+	}
+	time.AfterFunc(r.delay, func() {
+		r.run(key)
+	})
+}
+
+func (r *WebhookRefresher) run(key string) {
+	r.mu.Lock()
+	task := r.pending[key]
+	delete(r.pending, key)
+	r.mu.Unlock()
+	busy, err := r.queue(key, task)
+	if err != nil {
+		r.basicRes.GetLogger().Error(err, "failed to queue the pipeline of %s %s webhook events", task.Plugin, key)
+		return
+	}
+	if busy {
+		// try again once the pending tasks are done, the raw data they extract may predate the events
+		r.Schedule(key, task)
+	}
+}
+
+// queue creates the pipeline unless a task of the plugins is pending, it would race with it on the same tool layer rows
+func (r *WebhookRefresher) queue(key string, task *models.PipelineTask) (busy bool, err errors.Error) {
+	db := r.basicRes.GetDal()
+	count, err := db.Count(
+		dal.From(&models.Task{}),
+		dal.Where("plugin IN ? AND status IN ?", r.pluginNames, models.PendingTaskStatus),
+	)
+	if err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	pipeline := &models.Pipeline{
+		Name:       fmt.Sprintf("%s webhook events %s", task.Plugin, key),
+		Status:     models.TASK_CREATED,
+		Plan:       models.PipelinePlan{{task}},
+		TotalTasks: 1,
+		SyncPolicy: models.SyncPolicy{TriggerSyncPolicy: models.TriggerSyncPolicy{SkipCollectors: true}},
+	}
+	tx := db.Begin()
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+	if err = tx.Create(pipeline); err != nil {
+		return false, err
+	}
+	err = tx.Create(&models.Task{
+		Plugin:      task.Plugin,
+		Subtasks:    task.Subtasks,
+		Options:     task.Options,
+		Status:      models.TASK_CREATED,
+		PipelineId:  pipeline.ID,
+		PipelineRow: 1,
+		PipelineCol: 1,
+	})
+	return false, err
+}
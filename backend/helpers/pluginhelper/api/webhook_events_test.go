@@ -0,0 +1,68 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyHmacSha256Signature(t *testing.T) {
+	payload := []byte(`{"action":"opened"}`)
+	// printf '{"action":"opened"}' | openssl dgst -sha256 -hmac secret
+	signature := "sha256=d42142b53efbc7cf5cd20b6e074eb33707e0de3b368f698e6d6f6c824ffb8d37"
+	assert.True(t, VerifyHmacSha256Signature("secret", payload, signature))
+	assert.True(t, VerifyHmacSha256Signature("secret", payload, signature[len("sha256="):]))
+	assert.False(t, VerifyHmacSha256Signature("secret", []byte(`{"action":"closed"}`), signature))
+	assert.False(t, VerifyHmacSha256Signature("", payload, signature))
+	assert.False(t, VerifyHmacSha256Signature("secret", payload, ""))
+	assert.False(t, VerifyHmacSha256Signature("secret", payload, "sha256=not-hex"))
+	assert.False(t, VerifyHmacSha256Signature("other", payload, signature))
+}
+
+func TestVerifySecretToken(t *testing.T) {
+	assert.True(t, VerifySecretToken("secret", "secret"))
+	assert.False(t, VerifySecretToken("secret", "Secret"))
+	assert.False(t, VerifySecretToken("", ""))
+}
+
+func TestSignWithNonce(t *testing.T) {
+	payload := []byte(`{"id":1}`)
+	// printf '{"id":1}s3cret1700000000-abc' | sha256sum
+	expected := "8dbfd53b5ef148c227b1f9cd9ba8c0cce093679dc14cffd09e87eb6ee6a9b1d2"
+	assert.Equal(t, expected, SignWithNonce(payload, "s3cret", "1700000000-abc"))
+	assert.True(t, VerifyNonceSignature(payload, "s3cret", "1700000000-abc", expected))
+	assert.False(t, VerifyNonceSignature(payload, "s3cret", "1700000000-abd", expected))
+	assert.False(t, VerifyNonceSignature(payload, "", "1700000000-abc", expected))
+}
+
+func TestWebhookRefresherSchedule(t *testing.T) {
+	refresher := NewWebhookRefresher(nil, time.Hour, "github", "github_graphql")
+	subtasks := []string{"Extract Pull Requests", "Convert Pull Requests"}
+	refresher.Schedule("1:apache/devlake", &models.PipelineTask{Plugin: "github_graphql", Subtasks: subtasks})
+	refresher.Schedule("1:apache/devlake", &models.PipelineTask{Plugin: "github_graphql", Subtasks: []string{"Extract Workflow Runs", "Convert Pull Requests"}})
+	refresher.Schedule("2:apache/devlake", &models.PipelineTask{Plugin: "github_graphql", Subtasks: []string{"Extract Workflow Runs"}})
+
+	// the events of a scope are folded into one task
+	assert.Len(t, refresher.pending, 2)
+	assert.Equal(t, []string{"Extract Pull Requests", "Convert Pull Requests", "Extract Workflow Runs"}, refresher.pending["1:apache/devlake"].Subtasks)
+	assert.Equal(t, []string{"Extract Pull Requests", "Convert Pull Requests"}, subtasks)
+}
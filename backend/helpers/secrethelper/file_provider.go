@@ -0,0 +1,111 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrethelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+var _ SecretProvider = (*FileSecretProvider)(nil)
+
+// FileSecretProvider reads secrets mounted as files under the root directory, i.e. kubernetes secrets or docker secrets.
+// A path pointing to a directory is treated as a secret whose keys are the file names in it, while a path pointing
+// to a file is treated as a secret whose keys are the properties of the JSON object in it, or the single key `value`
+// holding the whole content when it is not a JSON object.
+type FileSecretProvider struct {
+	root string
+}
+
+// NewFileSecretProvider creates a new FileSecretProvider
+func NewFileSecretProvider(root string) (*FileSecretProvider, errors.Error) {
+	if root == "" {
+		return nil, errors.BadInput.New("SECRET_FILE_ROOT is required by the file secret provider")
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, errors.Convert(err)
+	}
+	return &FileSecretProvider{root: root}, nil
+}
+
+// GetSecret implements SecretProvider
+func (p *FileSecretProvider) GetSecret(path string) (map[string]string, errors.Error) {
+	fullPath := filepath.Join(p.root, filepath.FromSlash(path))
+	if fullPath != p.root && !strings.HasPrefix(fullPath, p.root+string(filepath.Separator)) {
+		return nil, errors.BadInput.New(fmt.Sprintf("secret path %s is out of the root", path))
+	}
+	info, err := os.Stat(fullPath)
+	if os.IsNotExist(err) {
+		return nil, errors.NotFound.New(fmt.Sprintf("secret %s not found", path))
+	}
+	if err != nil {
+		return nil, errors.Convert(err)
+	}
+	if !info.IsDir() {
+		return readSecretFile(fullPath)
+	}
+	entries, err := os.ReadDir(fullPath)
+	if err != nil {
+		return nil, errors.Convert(err)
+	}
+	values := make(map[string]string)
+	for _, entry := range entries {
+		// kubernetes mounts secrets through hidden symlinks like `..data`
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(fullPath, entry.Name()))
+		if err != nil {
+			return nil, errors.Convert(err)
+		}
+		values[entry.Name()] = strings.TrimRight(string(content), "\r\n")
+	}
+	return values, nil
+}
+
+func readSecretFile(path string) (map[string]string, errors.Error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Convert(err)
+	}
+	var object map[string]json.RawMessage
+	if json.Unmarshal(content, &object) != nil {
+		return map[string]string{"value": strings.TrimRight(string(content), "\r\n")}, nil
+	}
+	return flattenSecretValues(object), nil
+}
+
+// flattenSecretValues converts JSON values to strings, JSON strings are unquoted while others are kept as they are
+func flattenSecretValues(object map[string]json.RawMessage) map[string]string {
+	values := make(map[string]string, len(object))
+	for k, raw := range object {
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			values[k] = s
+		} else {
+			values[k] = string(raw)
+		}
+	}
+	return values
+}
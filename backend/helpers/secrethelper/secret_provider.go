@@ -0,0 +1,258 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrethelper
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/config"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+const (
+	SecretProviderFile  = "file"
+	SecretProviderVault = "vault"
+)
+
+// SecretProvider fetches secrets from an external secrets store
+type SecretProvider interface {
+	// GetSecret returns all key/value pairs stored under the path
+	GetSecret(path string) (map[string]string, errors.Error)
+}
+
+// NewSecretProvider creates the SecretProvider configured by SECRET_PROVIDER, nil is returned when it is not set
+func NewSecretProvider(cfg config.ConfigReader) (SecretProvider, errors.Error) {
+	switch provider := strings.ToLower(strings.TrimSpace(cfg.GetString("SECRET_PROVIDER"))); provider {
+	case "":
+		return nil, nil
+	case SecretProviderFile:
+		return NewFileSecretProvider(cfg.GetString("SECRET_FILE_ROOT"))
+	case SecretProviderVault:
+		return NewVaultSecretProvider(&VaultSecretProviderOptions{
+			Address:   cfg.GetString("VAULT_ADDR"),
+			Token:     cfg.GetString("VAULT_TOKEN"),
+			Namespace: cfg.GetString("VAULT_NAMESPACE"),
+			Mount:     cfg.GetString("VAULT_KV_MOUNT"),
+			KvVersion: cfg.GetInt("VAULT_KV_VERSION"),
+			Timeout:   cfg.GetDuration("VAULT_TIMEOUT"),
+		})
+	default:
+		return nil, errors.BadInput.New(fmt.Sprintf("unsupported SECRET_PROVIDER %s", provider))
+	}
+}
+
+// ParseSecretRef splits a reference in the form of `secret://path#key` into path and key, key is optional
+func ParseSecretRef(ref string) (path string, key string, err errors.Error) {
+	if !plugin.IsSecretRef(ref) {
+		return "", "", errors.BadInput.New(fmt.Sprintf("%s is not a secret reference", ref))
+	}
+	path = strings.TrimPrefix(ref, plugin.SecretRefPrefix)
+	if i := strings.LastIndex(path, "#"); i >= 0 {
+		path, key = path[:i], path[i+1:]
+	}
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return "", "", errors.BadInput.New(fmt.Sprintf("secret reference %s has no path", ref))
+	}
+	return path, key, nil
+}
+
+type cachedSecret struct {
+	values    map[string]string
+	expiresAt time.Time
+}
+
+// SecretResolver resolves secret references with the SecretProvider, secrets are cached for a while so
+// the secrets store wouldn't be hit for every single api client
+type SecretResolver struct {
+	provider   SecretProvider
+	ttl        time.Duration
+	pathPrefix string
+	mu         sync.Mutex
+	cache      map[string]*cachedSecret
+	now        func() time.Time
+}
+
+// NewSecretResolver creates a new SecretResolver, provider could be nil when no secrets store is configured.
+// Only the secrets under pathPrefix could be referenced, any secret could be referenced if it is empty
+func NewSecretResolver(provider SecretProvider, ttl time.Duration, pathPrefix string) *SecretResolver {
+	return &SecretResolver{
+		provider:   provider,
+		ttl:        ttl,
+		pathPrefix: strings.Trim(pathPrefix, "/"),
+		cache:      make(map[string]*cachedSecret),
+		now:        time.Now,
+	}
+}
+
+// Resolve returns the secret referenced by the value, values other than secret references are returned as they are
+func (r *SecretResolver) Resolve(value string) (string, errors.Error) {
+	if !plugin.IsSecretRef(value) {
+		return value, nil
+	}
+	path, key, err := ParseSecretRef(value)
+	if err != nil {
+		return "", err
+	}
+	if !r.isAllowedPath(path) {
+		return "", errors.Forbidden.New(fmt.Sprintf("secret %s is out of SECRET_REF_PATH_PREFIX", path))
+	}
+	if r.provider == nil {
+		return "", errors.BadInput.New(fmt.Sprintf("unable to resolve %s, SECRET_PROVIDER is not configured", value))
+	}
+	values, err := r.getSecret(path)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		// the only value under the path can be referenced without key
+		if len(values) != 1 {
+			keys := make([]string, 0, len(values))
+			for k := range values {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			return "", errors.BadInput.New(fmt.Sprintf("secret %s has keys %v, please specify one with #key", path, keys))
+		}
+		for _, v := range values {
+			return v, nil
+		}
+	}
+	secret, ok := values[key]
+	if !ok {
+		return "", errors.NotFound.New(fmt.Sprintf("key %s not found in secret %s", key, path))
+	}
+	return secret, nil
+}
+
+func (r *SecretResolver) isAllowedPath(p string) bool {
+	if path.Clean(p) != p {
+		return false
+	}
+	return r.pathPrefix == "" || p == r.pathPrefix || strings.HasPrefix(p, r.pathPrefix+"/")
+}
+
+func (r *SecretResolver) getSecret(path string) (map[string]string, errors.Error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	if cached, ok := r.cache[path]; ok && now.Before(cached.expiresAt) {
+		return cached.values, nil
+	}
+	values, err := r.provider.GetSecret(path)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to load secret %s", path))
+	}
+	if r.ttl > 0 {
+		r.cache[path] = &cachedSecret{values: values, expiresAt: now.Add(r.ttl)}
+	}
+	return values, nil
+}
+
+// ResolveRefs replaces all secret references in the credential fields, the ones stored with the encdec serializer,
+// of the struct pointed by ptr with the secrets. Nested and embedded structs are walked through, but pointers, maps
+// and slices inside the struct are not, so a shallow copy of the struct can be resolved without touching the original one.
+func (r *SecretResolver) ResolveRefs(ptr interface{}) errors.Error {
+	return walkSecretRefs(reflect.ValueOf(ptr).Elem(), func(field reflect.Value) errors.Error {
+		secret, err := r.Resolve(field.String())
+		if err != nil {
+			return err
+		}
+		field.SetString(secret)
+		return nil
+	})
+}
+
+// HasSecretRefs tells if any credential field of the struct pointed by ptr is a secret reference
+func HasSecretRefs(ptr interface{}) bool {
+	found := false
+	_ = walkSecretRefs(reflect.ValueOf(ptr).Elem(), func(field reflect.Value) errors.Error {
+		found = true
+		return nil
+	})
+	return found
+}
+
+func walkSecretRefs(v reflect.Value, fn func(field reflect.Value) errors.Error) errors.Error {
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		switch field.Kind() {
+		case reflect.Struct:
+			if err := walkSecretRefs(field, fn); err != nil {
+				return err
+			}
+		case reflect.String:
+			if isCredentialField(v.Type().Field(i)) && plugin.IsSecretRef(field.String()) {
+				if err := fn(field); err != nil {
+					return errors.Default.Wrap(err, fmt.Sprintf("failed to resolve field %s", v.Type().Field(i).Name))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isCredentialField tells if the field is encrypted in the database, i.e. `gorm:"serializer:encdec"`
+func isCredentialField(field reflect.StructField) bool {
+	for _, setting := range strings.Split(field.Tag.Get("gorm"), ";") {
+		if strings.TrimSpace(setting) == "serializer:encdec" {
+			return true
+		}
+	}
+	return false
+}
+
+var resolverLock sync.Mutex
+var resolver *SecretResolver
+
+// GetSecretResolver returns the application wide SecretResolver configured by SECRET_PROVIDER, SECRET_CACHE_TTL
+// and SECRET_REF_PATH_PREFIX
+func GetSecretResolver(cfg config.ConfigReader) (*SecretResolver, errors.Error) {
+	resolverLock.Lock()
+	defer resolverLock.Unlock()
+	if resolver != nil {
+		return resolver, nil
+	}
+	provider, err := NewSecretProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	ttl := 5 * time.Minute
+	if cfg.IsSet("SECRET_CACHE_TTL") {
+		ttl = cfg.GetDuration("SECRET_CACHE_TTL")
+	}
+	pathPrefix := "devlake"
+	if cfg.IsSet("SECRET_REF_PATH_PREFIX") {
+		pathPrefix = cfg.GetString("SECRET_REF_PATH_PREFIX")
+	}
+	resolver = NewSecretResolver(provider, ttl, pathPrefix)
+	return resolver, nil
+}
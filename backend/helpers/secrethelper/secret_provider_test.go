@@ -0,0 +1,192 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrethelper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingSecretProvider struct {
+	secrets map[string]map[string]string
+	calls   int
+}
+
+func (p *countingSecretProvider) GetSecret(path string) (map[string]string, errors.Error) {
+	p.calls++
+	values, ok := p.secrets[path]
+	if !ok {
+		return nil, errors.NotFound.New("not found")
+	}
+	return values, nil
+}
+
+func TestParseSecretRef(t *testing.T) {
+	path, key, err := ParseSecretRef("secret://devlake/github/#token")
+	assert.Nil(t, err)
+	assert.Equal(t, "devlake/github", path)
+	assert.Equal(t, "token", key)
+
+	path, key, err = ParseSecretRef("secret://devlake/jira")
+	assert.Nil(t, err)
+	assert.Equal(t, "devlake/jira", path)
+	assert.Equal(t, "", key)
+
+	_, _, err = ParseSecretRef("secret://#token")
+	assert.NotNil(t, err)
+	_, _, err = ParseSecretRef("plain token")
+	assert.NotNil(t, err)
+}
+
+func TestSecretResolver(t *testing.T) {
+	provider := &countingSecretProvider{secrets: map[string]map[string]string{
+		"devlake/github": {"token": "ghp_123", "app_key": "key"},
+		"devlake/jira":   {"password": "pass"},
+	}}
+	now := time.Date(2025, 7, 3, 0, 0, 0, 0, time.UTC)
+	resolver := NewSecretResolver(provider, time.Minute, "devlake")
+	resolver.now = func() time.Time { return now }
+
+	secret, err := resolver.Resolve("plain token")
+	assert.Nil(t, err)
+	assert.Equal(t, "plain token", secret)
+	secret, err = resolver.Resolve("secret://devlake/github#token")
+	assert.Nil(t, err)
+	assert.Equal(t, "ghp_123", secret)
+	// the only key can be omitted
+	secret, err = resolver.Resolve("secret://devlake/jira")
+	assert.Nil(t, err)
+	assert.Equal(t, "pass", secret)
+	_, err = resolver.Resolve("secret://devlake/github")
+	assert.NotNil(t, err)
+	_, err = resolver.Resolve("secret://devlake/github#password")
+	assert.NotNil(t, err)
+	_, err = resolver.Resolve("secret://devlake/gitlab#token")
+	assert.NotNil(t, err)
+
+	// secrets are cached until expired
+	assert.Equal(t, 3, provider.calls)
+	now = now.Add(time.Minute)
+	_, err = resolver.Resolve("secret://devlake/github#app_key")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, provider.calls)
+
+	// no provider configured
+	_, err = NewSecretResolver(nil, 0, "").Resolve("secret://devlake/github#token")
+	assert.NotNil(t, err)
+
+	// secrets out of the path prefix can't be referenced
+	provider.secrets["admin/root"] = map[string]string{"token": "root"}
+	_, err = resolver.Resolve("secret://admin/root#token")
+	assert.NotNil(t, err)
+	_, err = resolver.Resolve("secret://devlake/../admin/root#token")
+	assert.NotNil(t, err)
+	_, err = resolver.Resolve("secret://devlakeadmin/root#token")
+	assert.NotNil(t, err)
+	calls := provider.calls
+	secret, err = NewSecretResolver(provider, 0, "").Resolve("secret://admin/root#token")
+	assert.Nil(t, err)
+	assert.Equal(t, "root", secret)
+	assert.Equal(t, calls+1, provider.calls)
+}
+
+func TestResolveRefs(t *testing.T) {
+	type Auth struct {
+		Username string
+		Password string `gorm:"serializer:encdec"`
+	}
+	type connection struct {
+		Auth
+		Name     string
+		Endpoint string
+		Token    string `gorm:"type:varchar(255);serializer:encdec"`
+		Proxy    *Auth
+		internal string
+	}
+	provider := &countingSecretProvider{secrets: map[string]map[string]string{
+		"jira": {"password": "pass", "token": "tok"},
+	}}
+	resolver := NewSecretResolver(provider, 0, "")
+	conn := &connection{
+		Auth:     Auth{Username: "me", Password: "secret://jira#password"},
+		Name:     "jira",
+		Endpoint: "https://jira.example.com",
+		Token:    "secret://jira#token",
+		Proxy:    &Auth{Password: "secret://jira#password"},
+		internal: "secret://jira#token",
+	}
+	assert.True(t, HasSecretRefs(conn))
+	copied := *conn
+	assert.Nil(t, resolver.ResolveRefs(&copied))
+	assert.Equal(t, "pass", copied.Password)
+	assert.Equal(t, "tok", copied.Token)
+	assert.Equal(t, "me", copied.Username)
+	// pointers and unexported fields are left alone, so the original connection is never touched
+	assert.Equal(t, "secret://jira#password", copied.Proxy.Password)
+	assert.Equal(t, "secret://jira#token", copied.internal)
+	assert.Equal(t, "secret://jira#token", conn.Token)
+	assert.False(t, HasSecretRefs(&copied))
+
+	// only credentials are resolved
+	conn.Name = "secret://jira#token"
+	copied = *conn
+	assert.Nil(t, resolver.ResolveRefs(&copied))
+	assert.Equal(t, "secret://jira#token", copied.Name)
+
+	conn.Token = "secret://jira#missing"
+	assert.NotNil(t, resolver.ResolveRefs(conn))
+}
+
+func TestFileSecretProvider(t *testing.T) {
+	root := t.TempDir()
+	// kubernetes style: one file per key
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, "github"), 0700))
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "github", "token"), []byte("ghp_123\n"), 0600))
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "github", ".hidden"), []byte("x"), 0600))
+	// json object
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "jira.json"), []byte(`{"password": "pass", "port": 8080}`), 0600))
+	// plain text
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "gitlab"), []byte("glpat\n"), 0600))
+
+	cfg := viper.New()
+	cfg.Set("SECRET_PROVIDER", "file")
+	cfg.Set("SECRET_FILE_ROOT", root)
+	provider, err := NewSecretProvider(cfg)
+	assert.Nil(t, err)
+
+	values, err := provider.GetSecret("github")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"token": "ghp_123"}, values)
+	values, err = provider.GetSecret("jira.json")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"password": "pass", "port": "8080"}, values)
+	values, err = provider.GetSecret("gitlab")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"value": "glpat"}, values)
+
+	_, err = provider.GetSecret("missing")
+	assert.NotNil(t, err)
+	_, err = provider.GetSecret("../etc/passwd")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,133 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrethelper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+)
+
+var _ SecretProvider = (*VaultSecretProvider)(nil)
+
+// VaultSecretProviderOptions holds the settings of VaultSecretProvider
+type VaultSecretProviderOptions struct {
+	Address   string        // i.e. https://vault.example.com:8200
+	Token     string        // sent as X-Vault-Token
+	Namespace string        // optional, sent as X-Vault-Namespace
+	Mount     string        // mount path of the KV secrets engine, default to `secret`
+	KvVersion int           // version of the KV secrets engine, 1 or 2, default to 2
+	Timeout   time.Duration // default to 10s
+}
+
+// VaultSecretProvider reads secrets from the KV secrets engine of a HashiCorp Vault compatible HTTP API
+type VaultSecretProvider struct {
+	opts   VaultSecretProviderOptions
+	client *http.Client
+}
+
+// NewVaultSecretProvider creates a new VaultSecretProvider
+func NewVaultSecretProvider(opts *VaultSecretProviderOptions) (*VaultSecretProvider, errors.Error) {
+	if opts.Address == "" || opts.Token == "" {
+		return nil, errors.BadInput.New("VAULT_ADDR and VAULT_TOKEN are required by the vault secret provider")
+	}
+	o := *opts
+	o.Address = strings.TrimRight(o.Address, "/")
+	o.Mount = strings.Trim(o.Mount, "/")
+	if o.Mount == "" {
+		o.Mount = "secret"
+	}
+	if o.KvVersion == 0 {
+		o.KvVersion = 2
+	}
+	if o.KvVersion != 1 && o.KvVersion != 2 {
+		return nil, errors.BadInput.New(fmt.Sprintf("unsupported VAULT_KV_VERSION %d", o.KvVersion))
+	}
+	if o.Timeout == 0 {
+		o.Timeout = 10 * time.Second
+	}
+	return &VaultSecretProvider{
+		opts:   o,
+		client: &http.Client{Timeout: o.Timeout},
+	}, nil
+}
+
+// GetSecret implements SecretProvider
+func (p *VaultSecretProvider) GetSecret(path string) (map[string]string, errors.Error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	apiUrl := fmt.Sprintf("%s/v1/%s/%s", p.opts.Address, p.opts.Mount, strings.Join(segments, "/"))
+	if p.opts.KvVersion == 2 {
+		apiUrl = fmt.Sprintf("%s/v1/%s/data/%s", p.opts.Address, p.opts.Mount, strings.Join(segments, "/"))
+	}
+	req, err := http.NewRequest(http.MethodGet, apiUrl, nil)
+	if err != nil {
+		return nil, errors.Convert(err)
+	}
+	req.Header.Set("X-Vault-Token", p.opts.Token)
+	if p.opts.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.opts.Namespace)
+	}
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to request vault")
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Convert(err)
+	}
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return nil, errors.NotFound.New(fmt.Sprintf("secret %s not found in vault", path))
+	case res.StatusCode == http.StatusForbidden || res.StatusCode == http.StatusUnauthorized:
+		return nil, errors.Forbidden.New(fmt.Sprintf("access to secret %s is denied by vault", path))
+	case res.StatusCode >= 300:
+		return nil, errors.HttpStatus(res.StatusCode).New(fmt.Sprintf("vault responded %d: %s", res.StatusCode, string(body)))
+	}
+	// kv v1: {"data": {...}}, kv v2: {"data": {"data": {...}, "metadata": {...}}}
+	var v1 struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	var v2 struct {
+		Data struct {
+			Data map[string]json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if p.opts.KvVersion == 2 {
+		err = json.Unmarshal(body, &v2)
+		v1.Data = v2.Data.Data
+	} else {
+		err = json.Unmarshal(body, &v1)
+	}
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to parse vault response")
+	}
+	if v1.Data == nil {
+		return nil, errors.NotFound.New(fmt.Sprintf("secret %s not found in vault", path))
+	}
+	return flattenSecretValues(v1.Data), nil
+}
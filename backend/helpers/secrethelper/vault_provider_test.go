@@ -0,0 +1,78 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secrethelper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVaultSecretProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/data/devlake/github":
+			assert.Equal(t, "team", r.Header.Get("X-Vault-Namespace"))
+			_, _ = w.Write([]byte(`{"data": {"data": {"token": "ghp_123", "expires": 30}, "metadata": {"version": 3}}}`))
+		case "/v1/legacy/devlake/jira":
+			_, _ = w.Write([]byte(`{"data": {"password": "pass"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors": []}`))
+		}
+	}))
+	defer server.Close()
+
+	v2, err := NewVaultSecretProvider(&VaultSecretProviderOptions{
+		Address:   server.URL + "/",
+		Token:     "root",
+		Namespace: "team",
+		Mount:     "/kv/",
+	})
+	assert.Nil(t, err)
+	values, err := v2.GetSecret("devlake/github")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"token": "ghp_123", "expires": "30"}, values)
+	_, err = v2.GetSecret("devlake/gitlab")
+	assert.NotNil(t, err)
+
+	v1, err := NewVaultSecretProvider(&VaultSecretProviderOptions{
+		Address:   server.URL,
+		Token:     "root",
+		Mount:     "legacy",
+		KvVersion: 1,
+	})
+	assert.Nil(t, err)
+	values, err = v1.GetSecret("devlake/jira")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"password": "pass"}, values)
+
+	denied, err := NewVaultSecretProvider(&VaultSecretProviderOptions{Address: server.URL, Token: "guest"})
+	assert.Nil(t, err)
+	_, err = denied.GetSecret("devlake/github")
+	assert.NotNil(t, err)
+
+	_, err = NewVaultSecretProvider(&VaultSecretProviderOptions{Address: server.URL})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,47 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dalgorm
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+
+	"gorm.io/gorm/schema"
+)
+
+var encDecSchemaCache = &sync.Map{}
+
+// GetEncDecColumns returns the primary key columns and the columns encrypted by the `encdec` serializer of the model
+func GetEncDecColumns(model dal.Tabler) (pkColumns []string, encDecColumns []string, err errors.Error) {
+	s, e := schema.Parse(model, encDecSchemaCache, schema.NamingStrategy{})
+	if e != nil {
+		return nil, nil, errors.Default.Wrap(e, "failed to parse model "+model.TableName())
+	}
+	for _, field := range s.PrimaryFields {
+		pkColumns = append(pkColumns, field.DBName)
+	}
+	for _, field := range s.Fields {
+		if field.DBName != "" && strings.EqualFold(field.TagSettings["SERIALIZER"], "encdec") {
+			encDecColumns = append(encDecColumns, field.DBName)
+		}
+	}
+	return
+}
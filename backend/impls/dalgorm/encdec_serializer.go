@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sync/atomic"
 
 	"github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/core/plugin"
@@ -33,8 +34,19 @@ var _ schema.SerializerInterface = (*EncDecSerializer)(nil)
 
 // EncDecSerializer is responsible for field encryption/decryption in Application Level
 // Ref: https://gorm.io/docs/serializer.html
+// Note that gorm copies the serializer for every field value, the secret is shared through a pointer so
+// it can be replaced when the ENCRYPTION_SECRET gets rotated.
 type EncDecSerializer struct {
-	encryptionSecret string
+	encryptionSecret *atomic.Pointer[string]
+}
+
+var encDecSerializer = &EncDecSerializer{encryptionSecret: &atomic.Pointer[string]{}}
+
+func (es *EncDecSerializer) secret() string {
+	if secret := es.encryptionSecret.Load(); secret != nil {
+		return *secret
+	}
+	return ""
 }
 
 // Scan implements serializer interface
@@ -51,8 +63,13 @@ func (es *EncDecSerializer) Scan(ctx context.Context, field *schema.Field, dst r
 		default:
 			return fmt.Errorf("failed to decrypt value: %#v", dbValue)
 		}
+		// references to external secrets are stored as they are, they get resolved right before being used
+		if fieldValue.Elem().Kind() == reflect.String && plugin.IsSecretRef(base64str) {
+			field.ReflectValueOf(ctx, dst).SetString(base64str)
+			return nil
+		}
 
-		decrypted, err := plugin.Decrypt(es.encryptionSecret, base64str)
+		decrypted, err := plugin.Decrypt(es.secret(), base64str)
 		if err != nil {
 			return err
 		}
@@ -82,6 +99,9 @@ func (es *EncDecSerializer) Value(ctx context.Context, field *schema.Field, dst
 	case json.RawMessage:
 		target = string(v)
 	case string:
+		if plugin.IsSecretRef(v) {
+			return v, nil
+		}
 		target = v
 	default:
 		// deal with complex type
@@ -96,10 +116,16 @@ func (es *EncDecSerializer) Value(ctx context.Context, field *schema.Field, dst
 		gormTag, ok := field.Tag.Lookup("gorm")
 		println(ok, gormTag)
 	}
-	return plugin.Encrypt(es.encryptionSecret, target)
+	return plugin.Encrypt(es.secret(), target)
 }
 
 // Init the encdec serializer
 func Init(encryptionSecret string) {
-	schema.RegisterSerializer("encdec", &EncDecSerializer{encryptionSecret: encryptionSecret})
+	SetEncryptionSecret(encryptionSecret)
+	schema.RegisterSerializer("encdec", encDecSerializer)
+}
+
+// SetEncryptionSecret replaces the secret used by the encdec serializer, it takes effect immediately for all models
+func SetEncryptionSecret(encryptionSecret string) {
+	encDecSerializer.encryptionSecret.Store(&encryptionSecret)
 }
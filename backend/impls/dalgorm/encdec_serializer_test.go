@@ -0,0 +1,81 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dalgorm
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm/schema"
+)
+
+type encDecTestConnection struct {
+	ID       uint64 `gorm:"primaryKey"`
+	Name     string
+	Token    string            `gorm:"serializer:encdec"`
+	Settings map[string]string `gorm:"column:extra_settings;serializer:encdec"`
+}
+
+func (encDecTestConnection) TableName() string {
+	return "_tool_encdec_test_connections"
+}
+
+func TestGetEncDecColumns(t *testing.T) {
+	Init("secret")
+	pks, cols, err := GetEncDecColumns(&encDecTestConnection{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id"}, pks)
+	assert.Equal(t, []string{"token", "extra_settings"}, cols)
+}
+
+func TestEncDecSerializer(t *testing.T) {
+	Init("old secret")
+	s, err := schema.Parse(&encDecTestConnection{}, &sync.Map{}, schema.NamingStrategy{})
+	assert.Nil(t, err)
+	field := s.LookUpField("Token")
+	ctx := context.Background()
+	conn := &encDecTestConnection{}
+	dst := reflect.ValueOf(conn)
+
+	// secrets are encrypted
+	encrypted, err := encDecSerializer.Value(ctx, field, dst, "my token")
+	assert.Nil(t, err)
+	assert.NotEqual(t, "my token", encrypted)
+	assert.Nil(t, encDecSerializer.Scan(ctx, field, dst, encrypted))
+	assert.Equal(t, "my token", conn.Token)
+
+	// references to external secrets are stored in plain text
+	ref, err := encDecSerializer.Value(ctx, field, dst, "secret://devlake/github#token")
+	assert.Nil(t, err)
+	assert.Equal(t, "secret://devlake/github#token", ref)
+	assert.Nil(t, encDecSerializer.Scan(ctx, field, dst, []byte("secret://devlake/github#token")))
+	assert.Equal(t, "secret://devlake/github#token", conn.Token)
+
+	// the rotated secret takes effect for copies of the serializer held by gorm
+	SetEncryptionSecret("new secret")
+	copied := *encDecSerializer
+	assert.NotNil(t, copied.Scan(ctx, field, dst, encrypted))
+	reEncrypted, e := plugin.Encrypt("new secret", "my token")
+	assert.Nil(t, e)
+	assert.Nil(t, copied.Scan(ctx, field, dst, reEncrypted))
+	assert.Equal(t, "my token", conn.Token)
+}
@@ -0,0 +1,68 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+// ImportCoverage accepts a code coverage report, parses and saves it to the code-quality domain tables
+// @Summary      Upload a code coverage report
+// @Description  Upload a Cobertura XML, LCOV or Go coverprofile report of a repo at a commit.
+// @Description  The coverage is saved into cq_repo_coverages and cq_file_coverages, cq_file_metrics only follows the newest commit,
+// @Description  and the coverage of the changed lines of the pull requests whose head is the commit is saved into cq_pull_request_coverages.
+// @Tags 		 plugins/customize
+// @Accept       multipart/form-data
+// @Param        repoId formData string true "the ID of the repo"
+// @Param        commitSha formData string true "the commit which was tested"
+// @Param        format formData string false "cobertura, lcov or gocover, detected from the content by default"
+// @Param        pathPrefix formData string false "the prefix to remove from the file paths to make them relative to the repo"
+// @Param        file formData file true "select file to upload"
+// @Produce      json
+// @Success      200  {object} service.CoverageImportResult
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/customize/codequality/coverage [post]
+func (h *Handlers) ImportCoverage(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	file, err := h.extractFile(input)
+	if err != nil {
+		return nil, err
+	}
+	// nolint
+	defer file.Close()
+
+	repoId := strings.TrimSpace(input.Request.FormValue("repoId"))
+	if repoId == "" {
+		return nil, errors.BadInput.New("empty repoId")
+	}
+	commitSha := strings.TrimSpace(input.Request.FormValue("commitSha"))
+	if commitSha == "" {
+		return nil, errors.BadInput.New("empty commitSha")
+	}
+	format := strings.ToLower(strings.TrimSpace(input.Request.FormValue("format")))
+	pathPrefix := strings.TrimSpace(input.Request.FormValue("pathPrefix"))
+	result, err := h.svc.ImportCoverage(repoId, commitSha, format, pathPrefix, file)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: result, Status: http.StatusOK}, nil
+}
@@ -0,0 +1,65 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+// ImportJUnitReports accepts JUnit/xUnit XML reports, parses and saves them to the qa domain tables
+// @Summary      Upload JUnit/xUnit XML test reports
+// @Description  Upload a JUnit/xUnit XML report, a gzipped report or a gzipped tarball of many reports, at most 512 MB decompressed.
+// @Description  Test cases are saved into qa_test_cases and their results into qa_test_case_executions.
+// @Tags 		 plugins/customize
+// @Accept       multipart/form-data
+// @Param        qaProjectId formData string true "the ID of the QA project"
+// @Param        qaProjectName formData string true "the name of the QA project"
+// @Param        cicdPipelineId formData string false "the ID of the cicd_pipeline which produced the reports"
+// @Param        file formData file true "select file to upload"
+// @Produce      json
+// @Success      200  {object} service.JUnitImportResult
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/customize/testreports/junit [post]
+func (h *Handlers) ImportJUnitReports(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	file, err := h.extractFile(input)
+	if err != nil {
+		return nil, err
+	}
+	// nolint
+	defer file.Close()
+
+	qaProjectId := strings.TrimSpace(input.Request.FormValue("qaProjectId"))
+	if qaProjectId == "" {
+		return nil, errors.BadInput.New("empty qaProjectId")
+	}
+	qaProjectName := strings.TrimSpace(input.Request.FormValue("qaProjectName"))
+	if qaProjectName == "" {
+		return nil, errors.BadInput.New("empty qaProjectName")
+	}
+	cicdPipelineId := strings.TrimSpace(input.Request.FormValue("cicdPipelineId"))
+	result, err := h.svc.ImportJUnitReports(qaProjectId, qaProjectName, cicdPipelineId, file)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: result, Status: http.StatusOK}, nil
+}
@@ -0,0 +1,65 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+// ImportSarif accepts a SARIF 2.1.0 log, parses and saves it to the code-quality domain tables
+// @Summary      Upload a SARIF file
+// @Description  Upload a SARIF 2.1.0 log produced by CodeQL, Semgrep, gosec or any other static analysis tool.
+// @Description  Results are saved into cq_issues and cq_issue_code_blocks under the given cq_project.
+// @Tags 		 plugins/customize
+// @Accept       multipart/form-data
+// @Param        cqProjectId formData string true "the ID of the code-quality project"
+// @Param        cqProjectName formData string true "the name of the code-quality project"
+// @Param        commitSha formData string false "the commit which was analyzed"
+// @Param        file formData file true "select file to upload"
+// @Produce      json
+// @Success      200  {object} service.SarifImportResult
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/customize/codequality/sarif [post]
+func (h *Handlers) ImportSarif(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	file, err := h.extractFile(input)
+	if err != nil {
+		return nil, err
+	}
+	// nolint
+	defer file.Close()
+
+	cqProjectId := strings.TrimSpace(input.Request.FormValue("cqProjectId"))
+	if cqProjectId == "" {
+		return nil, errors.BadInput.New("empty cqProjectId")
+	}
+	cqProjectName := strings.TrimSpace(input.Request.FormValue("cqProjectName"))
+	if cqProjectName == "" {
+		return nil, errors.BadInput.New("empty cqProjectName")
+	}
+	commitSha := strings.TrimSpace(input.Request.FormValue("commitSha"))
+	result, err := h.svc.ImportSarif(cqProjectId, cqProjectName, commitSha, file)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: result, Status: http.StatusOK}, nil
+}
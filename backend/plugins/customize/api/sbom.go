@@ -0,0 +1,67 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/sbom"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+// ImportSbom accepts a software bill of materials, parses and saves it to the sbom domain tables
+// @Summary      Upload a software bill of materials
+// @Description  Upload a CycloneDX JSON or SPDX JSON document of a repo or a cicd_deployment.
+// @Description  Components, their versions and known vulnerabilities are saved as a snapshot of the repo or the deployment.
+// @Tags 		 plugins/customize
+// @Accept       multipart/form-data
+// @Param        repoId formData string false "the ID of the repo, required if cicdDeploymentId is not given"
+// @Param        cicdDeploymentId formData string false "the ID of the cicd_deployment, required if repoId is not given"
+// @Param        commitSha formData string false "the commit the bom was generated from"
+// @Param        file formData file true "select file to upload"
+// @Produce      json
+// @Success      200  {object} service.SbomImportResult
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/customize/sbom [post]
+func (h *Handlers) ImportSbom(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	file, err := h.extractFile(input)
+	if err != nil {
+		return nil, err
+	}
+	// nolint
+	defer file.Close()
+
+	repoId := strings.TrimSpace(input.Request.FormValue("repoId"))
+	cicdDeploymentId := strings.TrimSpace(input.Request.FormValue("cicdDeploymentId"))
+	if (repoId == "") == (cicdDeploymentId == "") {
+		return nil, errors.BadInput.New("either repoId or cicdDeploymentId is required")
+	}
+	targetType, targetId := sbom.TARGET_REPO, repoId
+	if cicdDeploymentId != "" {
+		targetType, targetId = sbom.TARGET_CICD_DEPLOYMENT, cicdDeploymentId
+	}
+	commitSha := strings.TrimSpace(input.Request.FormValue("commitSha"))
+	result, err := h.svc.ImportSbom(targetType, targetId, commitSha, file)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: result, Status: http.StatusOK}, nil
+}
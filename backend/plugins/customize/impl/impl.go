@@ -117,5 +117,17 @@ func (p Customize) ApiResources() map[string]map[string]plugin.ApiResourceHandle
 		"csvfiles/qa_test_case_executions.csv": {
 			"POST": handlers.ImportQaTestCaseExecutions,
 		},
+		"testreports/junit": {
+			"POST": handlers.ImportJUnitReports,
+		},
+		"codequality/sarif": {
+			"POST": handlers.ImportSarif,
+		},
+		"codequality/coverage": {
+			"POST": handlers.ImportCoverage,
+		},
+		"sbom": {
+			"POST": handlers.ImportSbom,
+		},
 	}
 }
@@ -0,0 +1,672 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/codequality"
+)
+
+const (
+	COVERAGE_COBERTURA = "cobertura"
+	COVERAGE_LCOV      = "lcov"
+	COVERAGE_GOCOVER   = "gocover"
+)
+
+var coberturaConditionCoverage = regexp.MustCompile(`\((\d+)/(\d+)\)`)
+
+// fileCoverage collects the hits of every line and the branches of a file
+type fileCoverage struct {
+	path     string
+	lines    map[int]int
+	branches map[string]bool
+}
+
+// coverageReport is a parsed report, files are kept in the order they appear
+type coverageReport struct {
+	format string
+	files  map[string]*fileCoverage
+	paths  []string
+}
+
+func newCoverageReport(format string) *coverageReport {
+	return &coverageReport{format: format, files: make(map[string]*fileCoverage)}
+}
+
+func (r *coverageReport) file(filePath string) *fileCoverage {
+	f, ok := r.files[filePath]
+	if !ok {
+		f = &fileCoverage{path: filePath, lines: make(map[int]int), branches: make(map[string]bool)}
+		r.files[filePath] = f
+		r.paths = append(r.paths, filePath)
+	}
+	return f
+}
+
+func (f *fileCoverage) hit(line, hits int) {
+	if line > 0 {
+		f.lines[line] += hits
+	}
+}
+
+func (f *fileCoverage) branch(key string, covered bool) {
+	f.branches[key] = f.branches[key] || covered
+}
+
+// CoverageImportResult summarizes an imported coverage report
+type CoverageImportResult struct {
+	Format         string   `json:"format"`
+	Files          int      `json:"files"`
+	LinesToCover   int      `json:"linesToCover"`
+	CoveredLines   int      `json:"coveredLines"`
+	LineCoverage   float64  `json:"lineCoverage"`
+	BranchCoverage *float64 `json:"branchCoverage"`
+	PullRequests   int      `json:"pullRequests"`
+}
+
+// ImportCoverage parses a Cobertura, LCOV or Go coverprofile report of a repo at a commit.
+// The coverage of the commit is saved into cq_repo_coverages and cq_file_coverages, uploading the same commit again replaces it,
+// the coverage of the files in cq_file_metrics follows the newest commit, and the coverage of the lines changed by the pull
+// requests whose head is the commit is saved into cq_pull_request_coverages.
+// File paths are made relative to the repo by removing the pathPrefix, or the module path derived from the repo url for Go.
+func (s *Service) ImportCoverage(repoId, commitSha, format, pathPrefix string, file io.Reader) (*CoverageImportResult, errors.Error) {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, errors.Convert(err)
+	}
+	if format == "" {
+		format = detectCoverageFormat(content)
+	}
+	report, parseErr := parseCoverage(format, content)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	repo := &code.Repo{}
+	dbErr := s.dal.First(repo, dal.Where("id = ?", repoId))
+	if dbErr != nil && !s.dal.IsErrorNotFound(dbErr) {
+		return nil, errors.Default.Wrap(dbErr, "failed to query repos")
+	}
+	repoName := repo.Name
+	if repoName == "" {
+		repoName = repoId
+	}
+	prefixes := []string{pathPrefix}
+	if u, urlErr := url.Parse(repo.Url); urlErr == nil && u.Host != "" {
+		prefixes = append(prefixes, u.Host+strings.TrimSuffix(u.Path, ".git"))
+	}
+	report = normalizeCoveragePaths(report, prefixes)
+
+	now := time.Now()
+	repoCoverage, fileCoverages, fileMetrics := convertCoverageReport(repoId, commitSha, report, now)
+	newest, dbErr := s.isNewestCoverage(repoId, commitSha)
+	if dbErr != nil {
+		return nil, dbErr
+	}
+	if newest {
+		dbErr = s.dal.CreateOrUpdate(&codequality.CqProject{
+			DomainEntityExtended: domainlayer.DomainEntityExtended{
+				Id: repoId,
+			},
+			Name:             repoName,
+			LastAnalysisDate: &common.Iso8601Time{Time: now},
+			CommitSha:        commitSha,
+		})
+		if dbErr != nil {
+			return nil, dbErr
+		}
+	}
+	dbErr = s.dal.Delete(&codequality.CqFileCoverage{}, dal.Where("repo_id = ? AND commit_sha = ?", repoId, commitSha))
+	if dbErr != nil {
+		return nil, errors.Default.Wrap(dbErr, "failed to delete old cq_file_coverages")
+	}
+	for start := 0; start < len(fileCoverages); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(fileCoverages) {
+			end = len(fileCoverages)
+		}
+		if dbErr = s.dal.CreateOrUpdate(fileCoverages[start:end]); dbErr != nil {
+			return nil, errors.Default.Wrap(dbErr, "failed to save cq_file_coverages")
+		}
+	}
+	if dbErr = s.dal.CreateOrUpdate(repoCoverage); dbErr != nil {
+		return nil, errors.Default.Wrap(dbErr, "failed to save cq_repo_coverages")
+	}
+	// a report uploaded late for an older commit must not take over the current file metrics
+	if newest {
+		dbErr = s.dal.Delete(&codequality.CqFileMetrics{}, dal.Where("project_key = ? AND id LIKE ?", repoId, coverageFileMetricsIdPrefix+"%"))
+		if dbErr != nil {
+			return nil, errors.Default.Wrap(dbErr, "failed to delete old cq_file_metrics")
+		}
+		for start := 0; start < len(fileMetrics); start += importBatchSize {
+			end := start + importBatchSize
+			if end > len(fileMetrics) {
+				end = len(fileMetrics)
+			}
+			if dbErr = s.dal.CreateOrUpdate(fileMetrics[start:end]); dbErr != nil {
+				return nil, errors.Default.Wrap(dbErr, "failed to save cq_file_metrics")
+			}
+		}
+	}
+	prCoverages, dbErr := s.convertPullRequestCoverages(repoId, commitSha, report, now)
+	if dbErr != nil {
+		return nil, dbErr
+	}
+	if len(prCoverages) > 0 {
+		if dbErr = s.dal.CreateOrUpdate(prCoverages); dbErr != nil {
+			return nil, errors.Default.Wrap(dbErr, "failed to save cq_pull_request_coverages")
+		}
+	}
+	return &CoverageImportResult{
+		Format:         repoCoverage.Format,
+		Files:          repoCoverage.FileCount,
+		LinesToCover:   repoCoverage.LinesToCover,
+		CoveredLines:   repoCoverage.CoveredLines,
+		LineCoverage:   repoCoverage.LineCoverage,
+		BranchCoverage: repoCoverage.BranchCoverage,
+		PullRequests:   len(prCoverages),
+	}, nil
+}
+
+// isNewestCoverage tells whether the commit is not older than the one the file metrics come from, commits missing from
+// the commits table count as newer so that the reports of repos which are not collected keep following the uploads
+func (s *Service) isNewestCoverage(repoId, commitSha string) (bool, errors.Error) {
+	project := &codequality.CqProject{}
+	err := s.dal.First(project, dal.Where("id = ?", repoId))
+	if s.dal.IsErrorNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, errors.Default.Wrap(err, "failed to query cq_projects")
+	}
+	if project.CommitSha == "" || project.CommitSha == commitSha {
+		return true, nil
+	}
+	var commits []code.Commit
+	err = s.dal.All(&commits, dal.Select("sha, committed_date"), dal.Where("sha IN ?", []string{commitSha, project.CommitSha}))
+	if err != nil {
+		return false, errors.Default.Wrap(err, "failed to query commits")
+	}
+	committedDates := make(map[string]time.Time, len(commits))
+	for _, commit := range commits {
+		committedDates[commit.Sha] = commit.CommittedDate
+	}
+	current, currentOk := committedDates[project.CommitSha]
+	uploaded, uploadedOk := committedDates[commitSha]
+	if !currentOk || !uploadedOk {
+		return true, nil
+	}
+	return !uploaded.Before(current), nil
+}
+
+const (
+	lineAddition = "Addition"
+	lineDeletion = "Deletion"
+)
+
+// lineChange is an added or deleted line of commit_line_change
+type lineChange struct {
+	CommitSha   string
+	OldFilePath string
+	NewFilePath string
+	LineNoOld   int
+	LineNoNew   int
+	ChangedType string
+}
+
+// convertPullRequestCoverages computes the coverage of the lines changed by the pull requests whose head is the commit.
+// The changed lines come from commit_line_change, which gitextractor only fills when the commit files aren't skipped,
+// pull requests without any are left out.
+func (s *Service) convertPullRequestCoverages(repoId, commitSha string, report *coverageReport, now time.Time) ([]*codequality.CqPullRequestCoverage, errors.Error) {
+	var pullRequestIds []string
+	err := s.dal.Pluck("id", &pullRequestIds,
+		dal.From(&code.PullRequest{}),
+		dal.Where("head_commit_sha = ? AND (base_repo_id = ? OR head_repo_id = ?)", commitSha, repoId, repoId),
+	)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to query pull_requests")
+	}
+	var prCoverages []*codequality.CqPullRequestCoverage
+	for _, pullRequestId := range pullRequestIds {
+		// merge commits are left out, their diff to the first parent holds the lines merged from the base branch
+		var commitShas []string
+		err = s.dal.Pluck("pull_request_commits.commit_sha", &commitShas,
+			dal.From("pull_request_commits"),
+			dal.Join("LEFT JOIN commits ON commits.sha = pull_request_commits.commit_sha"),
+			dal.Where(`pull_request_commits.pull_request_id = ?
+				AND (SELECT COUNT(*) FROM commit_parents WHERE commit_parents.commit_sha = pull_request_commits.commit_sha) < 2`, pullRequestId),
+			dal.Orderby("commits.committed_date, pull_request_commits.commit_authored_date"),
+		)
+		if err != nil {
+			return nil, errors.Default.Wrap(err, "failed to query pull_request_commits")
+		}
+		if len(commitShas) == 0 {
+			continue
+		}
+		var changes []lineChange
+		err = s.dal.All(&changes,
+			dal.Select("commit_sha, old_file_path, new_file_path, line_no_old, line_no_new, changed_type"),
+			dal.From(&code.CommitLineChange{}),
+			dal.Where("commit_sha IN ? AND changed_type IN ?", commitShas, []string{lineAddition, lineDeletion}),
+		)
+		if err != nil {
+			return nil, errors.Default.Wrap(err, "failed to query commit_line_change")
+		}
+		lines := changedLines(commitShas, changes)
+		if len(lines) == 0 {
+			continue
+		}
+		prCoverages = append(prCoverages, convertPullRequestCoverage(pullRequestId, repoId, commitSha, lines, report, now))
+	}
+	return prCoverages, nil
+}
+
+// changedLines replays the diffs of the commits, oldest first, and returns the lines of every file at the last commit
+// which one of them added. A line moves with the lines added and deleted above it by the later commits and is dropped
+// when one of them deletes it.
+func changedLines(commitShas []string, changes []lineChange) map[string]map[int]bool {
+	type fileDiff struct {
+		added   []int
+		deleted []int
+	}
+	diffs := make(map[string]map[string]*fileDiff, len(commitShas))
+	diff := func(commitSha, filePath string) *fileDiff {
+		files, ok := diffs[commitSha]
+		if !ok {
+			files = make(map[string]*fileDiff)
+			diffs[commitSha] = files
+		}
+		d, ok := files[filePath]
+		if !ok {
+			d = &fileDiff{}
+			files[filePath] = d
+		}
+		return d
+	}
+	for _, change := range changes {
+		switch change.ChangedType {
+		case lineAddition:
+			d := diff(change.CommitSha, change.NewFilePath)
+			d.added = append(d.added, change.LineNoNew)
+		case lineDeletion:
+			d := diff(change.CommitSha, change.OldFilePath)
+			d.deleted = append(d.deleted, change.LineNoOld)
+		}
+	}
+	lines := make(map[string]map[int]bool)
+	for _, commitSha := range commitShas {
+		for filePath, d := range diffs[commitSha] {
+			sort.Ints(d.added)
+			sort.Ints(d.deleted)
+			deleted := make(map[int]bool, len(d.deleted))
+			for _, line := range d.deleted {
+				deleted[line] = true
+			}
+			moved := make(map[int]bool, len(lines[filePath])+len(d.added))
+			for line := range lines[filePath] {
+				if deleted[line] {
+					continue
+				}
+				// an unchanged line keeps its rank among the unchanged lines of the file
+				newLine := line - sort.SearchInts(d.deleted, line)
+				for _, added := range d.added {
+					if added > newLine {
+						break
+					}
+					newLine++
+				}
+				moved[newLine] = true
+			}
+			for _, added := range d.added {
+				moved[added] = true
+			}
+			if len(moved) == 0 {
+				delete(lines, filePath)
+			} else {
+				lines[filePath] = moved
+			}
+		}
+	}
+	return lines
+}
+
+// convertPullRequestCoverage counts the changed lines the report instruments, other changed lines aren't code to cover
+func convertPullRequestCoverage(pullRequestId, repoId, commitSha string, lines map[string]map[int]bool, report *coverageReport, now time.Time) *codequality.CqPullRequestCoverage {
+	prCoverage := &codequality.CqPullRequestCoverage{
+		PullRequestId: pullRequestId,
+		RepoId:        repoId,
+		CommitSha:     commitSha,
+		ReportedDate:  now,
+	}
+	for filePath, fileLines := range lines {
+		prCoverage.ChangedLines += len(fileLines)
+		f, ok := report.files[filePath]
+		if !ok {
+			continue
+		}
+		for line := range fileLines {
+			hits, ok := f.lines[line]
+			if !ok {
+				continue
+			}
+			prCoverage.LinesToCover++
+			if hits > 0 {
+				prCoverage.CoveredLines++
+			} else {
+				prCoverage.UncoveredLines++
+			}
+		}
+	}
+	prCoverage.LineCoverage = percentage(prCoverage.CoveredLines, prCoverage.LinesToCover)
+	return prCoverage
+}
+
+const coverageFileMetricsIdPrefix = "coverage:CqFileMetrics:"
+
+func convertCoverageReport(repoId, commitSha string, report *coverageReport, now time.Time) (*codequality.CqRepoCoverage, []*codequality.CqFileCoverage, []*codequality.CqFileMetrics) {
+	repoCoverage := &codequality.CqRepoCoverage{
+		RepoId:       repoId,
+		CommitSha:    commitSha,
+		Format:       report.format,
+		FileCount:    len(report.paths),
+		ReportedDate: now,
+	}
+	fileCoverages := make([]*codequality.CqFileCoverage, 0, len(report.paths))
+	fileMetrics := make([]*codequality.CqFileMetrics, 0, len(report.paths))
+	for _, filePath := range report.paths {
+		f := report.files[filePath]
+		lines := make([]int, 0, len(f.lines))
+		for line := range f.lines {
+			lines = append(lines, line)
+		}
+		sort.Ints(lines)
+		var covered, uncovered []int
+		for _, line := range lines {
+			if f.lines[line] > 0 {
+				covered = append(covered, line)
+			} else {
+				uncovered = append(uncovered, line)
+			}
+		}
+		coveredBranches := 0
+		for _, isCovered := range f.branches {
+			if isCovered {
+				coveredBranches++
+			}
+		}
+		fileCoverages = append(fileCoverages, &codequality.CqFileCoverage{
+			RepoId:              repoId,
+			CommitSha:           commitSha,
+			FilePath:            filePath,
+			LinesToCover:        len(lines),
+			CoveredLines:        len(covered),
+			UncoveredLines:      len(uncovered),
+			BranchesToCover:     len(f.branches),
+			CoveredBranches:     coveredBranches,
+			CoveredLineRanges:   formatLineRanges(covered),
+			UncoveredLineRanges: formatLineRanges(uncovered),
+		})
+		language := strings.TrimPrefix(path.Ext(filePath), ".")
+		if len(language) > 20 {
+			language = language[:20]
+		}
+		fileMetrics = append(fileMetrics, &codequality.CqFileMetrics{
+			DomainEntity: domainlayer.DomainEntity{
+				Id: toDomainId(fmt.Sprintf("%s%s:%s", coverageFileMetricsIdPrefix, repoId, filePath), maxIdLen),
+			},
+			ProjectKey:     repoId,
+			FileName:       path.Base(filePath),
+			FilePath:       filePath,
+			FileLanguage:   language,
+			LinesToCover:   len(lines),
+			UncoveredLines: len(uncovered),
+			Coverage:       percentage(len(covered), len(lines)),
+		})
+		repoCoverage.LinesToCover += len(lines)
+		repoCoverage.CoveredLines += len(covered)
+		repoCoverage.UncoveredLines += len(uncovered)
+		repoCoverage.BranchesToCover += len(f.branches)
+		repoCoverage.CoveredBranches += coveredBranches
+	}
+	repoCoverage.LineCoverage = percentage(repoCoverage.CoveredLines, repoCoverage.LinesToCover)
+	if repoCoverage.BranchesToCover > 0 {
+		branchCoverage := percentage(repoCoverage.CoveredBranches, repoCoverage.BranchesToCover)
+		repoCoverage.BranchCoverage = &branchCoverage
+	}
+	return repoCoverage, fileCoverages, fileMetrics
+}
+
+func detectCoverageFormat(content []byte) string {
+	trimmed := bytes.TrimSpace(content)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("mode:")):
+		return COVERAGE_GOCOVER
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return COVERAGE_COBERTURA
+	default:
+		return COVERAGE_LCOV
+	}
+}
+
+func parseCoverage(format string, content []byte) (*coverageReport, errors.Error) {
+	switch format {
+	case COVERAGE_COBERTURA:
+		return parseCobertura(content)
+	case COVERAGE_LCOV:
+		return parseLcov(content)
+	case COVERAGE_GOCOVER:
+		return parseGoCover(content)
+	default:
+		return nil, errors.BadInput.New(fmt.Sprintf("unsupported coverage format %s, expected %s, %s or %s", format, COVERAGE_COBERTURA, COVERAGE_LCOV, COVERAGE_GOCOVER))
+	}
+}
+
+// parseCobertura reads the lines of every class, classes of the same file are merged
+func parseCobertura(content []byte) (*coverageReport, errors.Error) {
+	var doc struct {
+		XMLName  xml.Name `xml:"coverage"`
+		Packages []struct {
+			Classes []struct {
+				Filename string `xml:"filename,attr"`
+				Lines    []struct {
+					Number            int    `xml:"number,attr"`
+					Hits              int    `xml:"hits,attr"`
+					ConditionCoverage string `xml:"condition-coverage,attr"`
+				} `xml:"lines>line"`
+			} `xml:"classes>class"`
+		} `xml:"packages>package"`
+	}
+	if err := xml.Unmarshal(content, &doc); err != nil {
+		return nil, errors.BadInput.Wrap(errors.Convert(err), "invalid cobertura report")
+	}
+	report := newCoverageReport(COVERAGE_COBERTURA)
+	for _, pkg := range doc.Packages {
+		for _, class := range pkg.Classes {
+			if class.Filename == "" {
+				continue
+			}
+			f := report.file(class.Filename)
+			for _, line := range class.Lines {
+				f.hit(line.Number, line.Hits)
+				// e.g. 50% (1/2)
+				if m := coberturaConditionCoverage.FindStringSubmatch(line.ConditionCoverage); m != nil {
+					covered, _ := strconv.Atoi(m[1])
+					total, _ := strconv.Atoi(m[2])
+					for i := 0; i < total; i++ {
+						f.branch(fmt.Sprintf("%d:%d", line.Number, i), i < covered)
+					}
+				}
+			}
+		}
+	}
+	return report, nil
+}
+
+// parseLcov reads the SF, DA and BRDA records of a tracefile, records of the same file are merged
+func parseLcov(content []byte) (*coverageReport, errors.Error) {
+	report := newCoverageReport(COVERAGE_LCOV)
+	var current *fileCoverage
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		record := strings.TrimSpace(scanner.Text())
+		key, value, _ := strings.Cut(record, ":")
+		switch key {
+		case "SF":
+			current = report.file(value)
+		case "end_of_record":
+			current = nil
+		case "DA", "BRDA":
+			if current == nil {
+				return nil, errors.BadInput.New(fmt.Sprintf("invalid lcov report, %s out of a file record at line %d", key, lineNo))
+			}
+			fields := strings.Split(value, ",")
+			if key == "DA" && len(fields) >= 2 {
+				line, lineErr := strconv.Atoi(fields[0])
+				hits, hitsErr := strconv.ParseFloat(fields[1], 64)
+				if lineErr != nil || hitsErr != nil {
+					return nil, errors.BadInput.New(fmt.Sprintf("invalid lcov report, malformed DA at line %d", lineNo))
+				}
+				current.hit(line, int(math.Min(hits, math.MaxInt32)))
+			} else if key == "BRDA" && len(fields) >= 4 {
+				// a taken count of - means the branch was never evaluated
+				taken, _ := strconv.ParseFloat(fields[3], 64)
+				current.branch(strings.Join(fields[:3], ":"), taken > 0)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.BadInput.Wrap(errors.Convert(err), "invalid lcov report")
+	}
+	if len(report.paths) == 0 {
+		return nil, errors.BadInput.New("invalid lcov report, no SF record found")
+	}
+	return report, nil
+}
+
+// parseGoCover reads a coverprofile, a line is covered if any of the statement blocks spanning it ran
+func parseGoCover(content []byte) (*coverageReport, errors.Error) {
+	report := newCoverageReport(COVERAGE_GOCOVER)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		record := strings.TrimSpace(scanner.Text())
+		if record == "" || strings.HasPrefix(record, "mode:") {
+			continue
+		}
+		// e.g. github.com/apache/incubator-devlake/core/utils/strings.go:30.52,33.2 2 1
+		sep := strings.LastIndex(record, ":")
+		fields := strings.Fields(record[sep+1:])
+		if sep < 0 || len(fields) != 3 {
+			return nil, errors.BadInput.New(fmt.Sprintf("invalid go coverprofile, malformed block at line %d", lineNo))
+		}
+		start, end, _ := strings.Cut(fields[0], ",")
+		startLine, startErr := strconv.Atoi(strings.SplitN(start, ".", 2)[0])
+		endLine, endErr := strconv.Atoi(strings.SplitN(end, ".", 2)[0])
+		count, countErr := strconv.Atoi(fields[2])
+		if startErr != nil || endErr != nil || countErr != nil {
+			return nil, errors.BadInput.New(fmt.Sprintf("invalid go coverprofile, malformed block at line %d", lineNo))
+		}
+		f := report.file(record[:sep])
+		for line := startLine; line <= endLine; line++ {
+			f.hit(line, count)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.BadInput.Wrap(errors.Convert(err), "invalid go coverprofile")
+	}
+	return report, nil
+}
+
+// normalizeCoveragePaths makes the paths relative to the repo, merging the files which end up with the same path
+func normalizeCoveragePaths(report *coverageReport, prefixes []string) *coverageReport {
+	normalized := newCoverageReport(report.format)
+	for _, filePath := range report.paths {
+		relPath := strings.ReplaceAll(filePath, "\\", "/")
+		for _, prefix := range prefixes {
+			prefix = strings.Trim(strings.ReplaceAll(prefix, "\\", "/"), "/")
+			if prefix != "" && strings.HasPrefix(strings.TrimPrefix(relPath, "/"), prefix+"/") {
+				relPath = strings.TrimPrefix(strings.TrimPrefix(relPath, "/"), prefix+"/")
+				break
+			}
+		}
+		relPath = strings.TrimPrefix(relPath, "./")
+		if len(relPath) > 400 {
+			// longer than cq_file_coverages.file_path
+			continue
+		}
+		f := normalized.file(relPath)
+		for line, hits := range report.files[filePath].lines {
+			f.hit(line, hits)
+		}
+		for key, covered := range report.files[filePath].branches {
+			f.branch(key, covered)
+		}
+	}
+	return normalized
+}
+
+// formatLineRanges compacts sorted line numbers, e.g. 1,2,3,5 into 1-3,5
+func formatLineRanges(lines []int) string {
+	var sb strings.Builder
+	for i := 0; i < len(lines); {
+		j := i
+		for j+1 < len(lines) && lines[j+1] == lines[j]+1 {
+			j++
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.Itoa(lines[i]))
+		if j > i {
+			sb.WriteByte('-')
+			sb.WriteString(strconv.Itoa(lines[j]))
+		}
+		i = j + 1
+	}
+	return sb.String()
+}
+
+func percentage(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(covered)*10000/float64(total)) / 100
+}
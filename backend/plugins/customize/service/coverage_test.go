@@ -0,0 +1,182 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const coberturaReport = `<?xml version="1.0" ?>
+<coverage line-rate="0.5" branch-rate="0.5" version="7.2">
+  <sources><source>/builds/app</source></sources>
+  <packages>
+    <package name="app">
+      <classes>
+        <class name="calc.py" filename="app/calc.py">
+          <lines>
+            <line number="1" hits="1"/>
+            <line number="2" hits="1" branch="true" condition-coverage="50% (1/2)"/>
+            <line number="3" hits="0"/>
+          </lines>
+        </class>
+        <class name="calc.Other" filename="app/calc.py">
+          <lines>
+            <line number="3" hits="2"/>
+            <line number="7" hits="0"/>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`
+
+const lcovReport = `TN:
+SF:/home/runner/work/web/src/index.js
+DA:1,1
+DA:2,0
+DA:3,0
+DA:5,4
+BRDA:2,0,0,1
+BRDA:2,0,1,-
+LF:4
+LH:2
+end_of_record
+SF:/home/runner/work/web/src/util.js
+DA:1,0
+end_of_record
+`
+
+const goCoverReport = `mode: set
+github.com/apache/incubator-devlake/core/utils/strings.go:30.52,33.2 2 1
+github.com/apache/incubator-devlake/core/utils/strings.go:33.2,35.3 1 0
+github.com/apache/incubator-devlake/core/utils/strings.go:40.1,41.2 1 0
+`
+
+func TestDetectCoverageFormat(t *testing.T) {
+	assert.Equal(t, COVERAGE_COBERTURA, detectCoverageFormat([]byte(coberturaReport)))
+	assert.Equal(t, COVERAGE_LCOV, detectCoverageFormat([]byte(lcovReport)))
+	assert.Equal(t, COVERAGE_GOCOVER, detectCoverageFormat([]byte(goCoverReport)))
+}
+
+func TestConvertCobertura(t *testing.T) {
+	report, err := parseCobertura([]byte(coberturaReport))
+	assert.Nil(t, err)
+	repoCoverage, files, metrics := convertCoverageReport("repo1", "sha1", report, time.Now())
+	assert.Len(t, files, 1)
+	assert.Equal(t, "app/calc.py", files[0].FilePath)
+	assert.Equal(t, 4, files[0].LinesToCover)
+	assert.Equal(t, "1-3", files[0].CoveredLineRanges)
+	assert.Equal(t, "7", files[0].UncoveredLineRanges)
+	assert.Equal(t, 2, files[0].BranchesToCover)
+	assert.Equal(t, 1, files[0].CoveredBranches)
+	assert.Equal(t, 75.0, repoCoverage.LineCoverage)
+	assert.Equal(t, 50.0, *repoCoverage.BranchCoverage)
+	assert.Equal(t, "calc.py", metrics[0].FileName)
+	assert.Equal(t, "py", metrics[0].FileLanguage)
+	assert.Equal(t, 1, metrics[0].UncoveredLines)
+	assert.Equal(t, 75.0, metrics[0].Coverage)
+	assert.Equal(t, "coverage:CqFileMetrics:repo1:app/calc.py", metrics[0].Id)
+}
+
+func TestConvertLcov(t *testing.T) {
+	report, err := parseLcov([]byte(lcovReport))
+	assert.Nil(t, err)
+	report = normalizeCoveragePaths(report, []string{"/home/runner/work/web"})
+	repoCoverage, files, _ := convertCoverageReport("repo1", "sha1", report, time.Now())
+	assert.Len(t, files, 2)
+	assert.Equal(t, "src/index.js", files[0].FilePath)
+	assert.Equal(t, "1,5", files[0].CoveredLineRanges)
+	assert.Equal(t, "2-3", files[0].UncoveredLineRanges)
+	assert.Equal(t, 2, files[0].BranchesToCover)
+	assert.Equal(t, 1, files[0].CoveredBranches)
+	assert.Equal(t, 5, repoCoverage.LinesToCover)
+	assert.Equal(t, 2, repoCoverage.CoveredLines)
+	assert.Equal(t, 40.0, repoCoverage.LineCoverage)
+
+	_, err = parseLcov([]byte("DA:1,1\n"))
+	assert.NotNil(t, err)
+}
+
+func TestConvertGoCover(t *testing.T) {
+	report, err := parseGoCover([]byte(goCoverReport))
+	assert.Nil(t, err)
+	// the module path is derived from the repo url
+	report = normalizeCoveragePaths(report, []string{"", "github.com/apache/incubator-devlake"})
+	repoCoverage, files, _ := convertCoverageReport("repo1", "sha1", report, time.Now())
+	assert.Len(t, files, 1)
+	assert.Equal(t, "core/utils/strings.go", files[0].FilePath)
+	// line 33 is shared by a covered and an uncovered block
+	assert.Equal(t, "30-33", files[0].CoveredLineRanges)
+	assert.Equal(t, "34-35,40-41", files[0].UncoveredLineRanges)
+	assert.Nil(t, repoCoverage.BranchCoverage)
+
+	_, err = parseGoCover([]byte("mode: set\nfoo.go 1 1\n"))
+	assert.NotNil(t, err)
+}
+
+func TestFormatLineRanges(t *testing.T) {
+	assert.Equal(t, "", formatLineRanges(nil))
+	assert.Equal(t, "1-3,5,7-8", formatLineRanges([]int{1, 2, 3, 5, 7, 8}))
+}
+
+func TestChangedLines(t *testing.T) {
+	changes := []lineChange{
+		// c1 adds lines 10 to 12
+		{CommitSha: "c1", OldFilePath: "app/calc.py", NewFilePath: "app/calc.py", LineNoOld: -1, LineNoNew: 10, ChangedType: lineAddition},
+		{CommitSha: "c1", OldFilePath: "app/calc.py", NewFilePath: "app/calc.py", LineNoOld: -1, LineNoNew: 11, ChangedType: lineAddition},
+		{CommitSha: "c1", OldFilePath: "app/calc.py", NewFilePath: "app/calc.py", LineNoOld: -1, LineNoNew: 12, ChangedType: lineAddition},
+		// c2 adds two lines on top, deletes line 11 of c1 and adds a file
+		{CommitSha: "c2", OldFilePath: "app/calc.py", NewFilePath: "app/calc.py", LineNoOld: -1, LineNoNew: 1, ChangedType: lineAddition},
+		{CommitSha: "c2", OldFilePath: "app/calc.py", NewFilePath: "app/calc.py", LineNoOld: -1, LineNoNew: 2, ChangedType: lineAddition},
+		{CommitSha: "c2", OldFilePath: "app/calc.py", NewFilePath: "app/calc.py", LineNoOld: 11, LineNoNew: -1, ChangedType: lineDeletion},
+		{CommitSha: "c2", OldFilePath: "app/util.py", NewFilePath: "app/util.py", LineNoOld: -1, LineNoNew: 1, ChangedType: lineAddition},
+	}
+	lines := changedLines([]string{"c1", "c2"}, changes)
+	assert.Equal(t, map[string]map[int]bool{
+		"app/calc.py": {1: true, 2: true, 12: true, 13: true},
+		"app/util.py": {1: true},
+	}, lines)
+
+	// a later commit deleting every added line leaves nothing changed
+	lines = changedLines([]string{"c1", "c3"}, append(append([]lineChange{}, changes[:3]...),
+		lineChange{CommitSha: "c3", OldFilePath: "app/calc.py", NewFilePath: "app/calc.py", LineNoOld: 10, LineNoNew: -1, ChangedType: lineDeletion},
+		lineChange{CommitSha: "c3", OldFilePath: "app/calc.py", NewFilePath: "app/calc.py", LineNoOld: 11, LineNoNew: -1, ChangedType: lineDeletion},
+		lineChange{CommitSha: "c3", OldFilePath: "app/calc.py", NewFilePath: "app/calc.py", LineNoOld: 12, LineNoNew: -1, ChangedType: lineDeletion},
+	))
+	assert.Empty(t, lines)
+}
+
+func TestConvertPullRequestCoverage(t *testing.T) {
+	report, err := parseCobertura([]byte(coberturaReport))
+	assert.Nil(t, err)
+	lines := map[string]map[int]bool{
+		"app/calc.py":  {1: true, 3: true, 7: true, 12: true},
+		"app/notes.md": {1: true},
+	}
+	prCoverage := convertPullRequestCoverage("pr1", "repo1", "sha1", lines, report, time.Now())
+	assert.Equal(t, "pr1", prCoverage.PullRequestId)
+	assert.Equal(t, 5, prCoverage.ChangedLines)
+	// line 12 and the markdown file aren't instrumented
+	assert.Equal(t, 3, prCoverage.LinesToCover)
+	assert.Equal(t, 2, prCoverage.CoveredLines)
+	assert.Equal(t, 1, prCoverage.UncoveredLines)
+	assert.Equal(t, 66.67, prCoverage.LineCoverage)
+}
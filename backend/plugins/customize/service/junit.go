@@ -0,0 +1,411 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/qa"
+)
+
+const (
+	importBatchSize = 500
+	// the lengths of the ids of domainlayer.DomainEntity and domainlayer.DomainEntityExtended
+	maxIdLen         = 255
+	maxExtendedIdLen = 500
+)
+
+// JUnitTestCase is a single <testcase> element of a JUnit/xUnit report
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *JUnitFailure `xml:"failure"`
+	Error     *JUnitFailure `xml:"error"`
+	Skipped   *JUnitFailure `xml:"skipped"`
+}
+
+// JUnitFailure holds the <failure>, <error> or <skipped> element of a test case
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnitTestSuite is a <testsuite> element, suites may be nested by some reporters
+type JUnitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Timestamp string           `xml:"timestamp,attr"`
+	TestCases []JUnitTestCase  `xml:"testcase"`
+	Suites    []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitImportResult summarizes an imported batch of reports
+type JUnitImportResult struct {
+	Reports   int `json:"reports"`
+	TestCases int `json:"testCases"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+}
+
+// ImportJUnitReports parses JUnit/xUnit XML reports and saves them into the qa domain tables.
+// The file may be a single XML report, a gzipped XML report or a gzipped tarball of many reports.
+// When cicdPipelineId is provided, executions are linked to that pipeline and re-uploading the same
+// reports for the same pipeline updates the previously imported executions instead of duplicating them.
+func (s *Service) ImportJUnitReports(qaProjectId, qaProjectName, cicdPipelineId string, file io.Reader) (*JUnitImportResult, errors.Error) {
+	suites, reports, err := readJUnitReports(file)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	testCases, executions, result := convertJUnitSuites(qaProjectId, cicdPipelineId, suites, now)
+	result.Reports = reports
+
+	err = s.dal.CreateOrUpdate(&qa.QaProject{
+		DomainEntityExtended: domainlayer.DomainEntityExtended{
+			Id: qaProjectId,
+		},
+		Name: qaProjectName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for start := 0; start < len(testCases); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(testCases) {
+			end = len(testCases)
+		}
+		batch := testCases[start:end]
+		// keep the create_time of test cases seen in earlier reports
+		if err = s.preserveTestCaseCreateTime(batch); err != nil {
+			return nil, err
+		}
+		if err = s.dal.CreateOrUpdate(batch); err != nil {
+			return nil, errors.Default.Wrap(err, "failed to save qa_test_cases")
+		}
+	}
+	for start := 0; start < len(executions); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(executions) {
+			end = len(executions)
+		}
+		if err = s.dal.CreateOrUpdate(executions[start:end]); err != nil {
+			return nil, errors.Default.Wrap(err, "failed to save qa_test_case_executions")
+		}
+	}
+	return result, nil
+}
+
+func (s *Service) preserveTestCaseCreateTime(testCases []*qa.QaTestCase) errors.Error {
+	ids := make([]string, 0, len(testCases))
+	for _, tc := range testCases {
+		ids = append(ids, tc.Id)
+	}
+	var existing []qa.QaTestCase
+	err := s.dal.All(&existing, dal.Select("id, create_time"), dal.From(&qa.QaTestCase{}), dal.Where("id IN ?", ids))
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to load existing qa_test_cases")
+	}
+	createTimes := make(map[string]time.Time, len(existing))
+	for _, tc := range existing {
+		createTimes[tc.Id] = tc.CreateTime
+	}
+	for _, tc := range testCases {
+		if createTime, ok := createTimes[tc.Id]; ok && !createTime.IsZero() {
+			tc.CreateTime = createTime
+		}
+	}
+	return nil
+}
+
+// maxJUnitArchiveSize bounds the decompressed size of gzipped uploads
+var maxJUnitArchiveSize int64 = 512 << 20
+
+// readJUnitReports parses every XML report contained in the uploaded file and returns their suites and the number
+// of reports, reports are decoded as they are decompressed so that an archive is never held in memory as a whole
+func readJUnitReports(file io.Reader) ([]JUnitTestSuite, int, errors.Error) {
+	reader := bufio.NewReader(file)
+	magic, _ := reader.Peek(2)
+	if len(magic) < 2 || magic[0] != 0x1f || magic[1] != 0x8b {
+		suites, err := parseJUnitReport(reader)
+		return suites, 1, err
+	}
+	gz, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, 0, errors.BadInput.Wrap(errors.Convert(err), "invalid gzip file")
+	}
+	// nolint
+	defer gz.Close()
+	// a small gzip file can inflate to gigabytes
+	limited := &sizeLimitedReader{reader: gz, remaining: maxJUnitArchiveSize}
+	suites, reports, parseErr := readJUnitArchive(bufio.NewReader(limited))
+	if limited.exceeded {
+		return nil, 0, errors.BadInput.New(fmt.Sprintf("the decompressed file exceeds %d MB", maxJUnitArchiveSize>>20))
+	}
+	return suites, reports, parseErr
+}
+
+// readJUnitArchive parses the decompressed content, which is either a single report or a tarball of reports
+func readJUnitArchive(reader *bufio.Reader) ([]JUnitTestSuite, int, errors.Error) {
+	header, _ := reader.Peek(262)
+	if !isTarball(header) {
+		suites, err := parseJUnitReport(reader)
+		return suites, 1, err
+	}
+	suites := make([]JUnitTestSuite, 0)
+	reports := 0
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, errors.BadInput.Wrap(errors.Convert(err), "invalid tar archive")
+		}
+		if header.Typeflag != tar.TypeReg || !strings.HasSuffix(strings.ToLower(header.Name), ".xml") {
+			continue
+		}
+		parsed, err := parseJUnitReport(tr)
+		if err != nil {
+			return nil, 0, errors.BadInput.Wrap(err, fmt.Sprintf("failed to read %s", header.Name))
+		}
+		suites = append(suites, parsed...)
+		reports++
+	}
+	if reports == 0 {
+		return nil, 0, errors.BadInput.New("no xml report found in the archive")
+	}
+	return suites, reports, nil
+}
+
+func isTarball(header []byte) bool {
+	return len(header) >= 262 && string(header[257:262]) == "ustar"
+}
+
+// sizeLimitedReader fails the read once more than remaining bytes are read from the reader
+type sizeLimitedReader struct {
+	reader    io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (r *sizeLimitedReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		// probe a byte to tell a file of exactly the max size from a larger one
+		n, err := r.reader.Read(make([]byte, 1))
+		if n > 0 {
+			r.exceeded = true
+			return 0, fmt.Errorf("the decompressed file exceeds %d bytes", maxJUnitArchiveSize)
+		}
+		return 0, err
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
+
+// parseJUnitReport accepts both a <testsuites> root and a single <testsuite> root
+func parseJUnitReport(report io.Reader) ([]JUnitTestSuite, errors.Error) {
+	var root struct {
+		XMLName xml.Name
+		JUnitTestSuite
+	}
+	if err := xml.NewDecoder(report).Decode(&root); err != nil {
+		return nil, errors.BadInput.Wrap(errors.Convert(err), "invalid junit xml report")
+	}
+	switch root.XMLName.Local {
+	case "testsuites":
+		return root.Suites, nil
+	case "testsuite":
+		return []JUnitTestSuite{root.JUnitTestSuite}, nil
+	default:
+		return nil, errors.BadInput.New(fmt.Sprintf("unexpected root element <%s> in junit xml report", root.XMLName.Local))
+	}
+}
+
+// convertJUnitSuites maps the parsed suites to qa_test_cases and qa_test_case_executions
+func convertJUnitSuites(qaProjectId, cicdPipelineId string, suites []JUnitTestSuite, now time.Time) ([]*qa.QaTestCase, []*qa.QaTestCaseExecution, *JUnitImportResult) {
+	result := &JUnitImportResult{}
+	testCases := make([]*qa.QaTestCase, 0)
+	executions := make([]*qa.QaTestCaseExecution, 0)
+	seenTestCases := make(map[string]bool)
+	seenExecutions := make(map[string]int)
+	rawDataOrigin := common.RawDataOrigin{RawDataParams: qaProjectId}
+	// the run key distinguishes executions of the same test case across uploads
+	runKey := cicdPipelineId
+	if runKey == "" {
+		runKey = now.UTC().Format(time.RFC3339Nano)
+	}
+
+	var walk func(suite JUnitTestSuite, parentName string, startTime time.Time)
+	walk = func(suite JUnitTestSuite, parentName string, startTime time.Time) {
+		suiteName := suite.Name
+		if suiteName == "" {
+			suiteName = parentName
+		}
+		if timestamp := parseJUnitTimestamp(suite.Timestamp); timestamp != nil {
+			startTime = *timestamp
+		}
+		offset := time.Duration(0)
+		for _, junitCase := range suite.TestCases {
+			scope := junitCase.ClassName
+			if scope == "" {
+				scope = suiteName
+			}
+			fullName := junitCase.Name
+			if scope != "" {
+				fullName = scope + "." + junitCase.Name
+			}
+			testCaseId := toDomainId(fmt.Sprintf("junit:QaTestCase:%s:%s", qaProjectId, fullName), maxExtendedIdLen)
+			if !seenTestCases[testCaseId] {
+				seenTestCases[testCaseId] = true
+				testCases = append(testCases, &qa.QaTestCase{
+					DomainEntityExtended: domainlayer.DomainEntityExtended{
+						Id:        testCaseId,
+						NoPKModel: common.NoPKModel{RawDataOrigin: rawDataOrigin},
+					},
+					Name:        truncate(fullName, 255),
+					CreateTime:  startTime,
+					Type:        "functional",
+					QaProjectId: qaProjectId,
+				})
+				result.TestCases++
+			}
+			if junitCase.Skipped != nil {
+				result.Skipped++
+				continue
+			}
+			status := "SUCCESS"
+			failureMessage := ""
+			if failure := firstNonNil(junitCase.Failure, junitCase.Error); failure != nil {
+				status = "FAILED"
+				failureMessage = failure.String()
+				result.Failed++
+			} else {
+				result.Succeeded++
+			}
+			var durationMs *int64
+			duration := time.Duration(0)
+			if seconds, err := strconv.ParseFloat(strings.ReplaceAll(junitCase.Time, ",", ""), 64); err == nil && seconds >= 0 {
+				ms := int64(math.Round(seconds * 1000))
+				durationMs = &ms
+				duration = time.Duration(ms) * time.Millisecond
+			}
+			// a test case may be executed more than once in a run, e.g. when retried by the runner
+			attempt := seenExecutions[testCaseId]
+			seenExecutions[testCaseId]++
+			executionKey := fmt.Sprintf("junit:QaTestCaseExecution:%s:%s:%d", testCaseId, runKey, attempt)
+			executionStart := startTime.Add(offset)
+			offset += duration
+			executions = append(executions, &qa.QaTestCaseExecution{
+				DomainEntityExtended: domainlayer.DomainEntityExtended{
+					Id:        toDomainId(executionKey, maxExtendedIdLen),
+					NoPKModel: common.NoPKModel{RawDataOrigin: rawDataOrigin},
+				},
+				QaProjectId:    qaProjectId,
+				QaTestCaseId:   testCaseId,
+				CreateTime:     executionStart,
+				StartTime:      executionStart,
+				FinishTime:     executionStart.Add(duration),
+				Status:         status,
+				DurationMs:     durationMs,
+				FailureMessage: failureMessage,
+				CicdPipelineId: cicdPipelineId,
+			})
+		}
+		for _, child := range suite.Suites {
+			walk(child, suiteName, startTime)
+		}
+	}
+	for _, suite := range suites {
+		walk(suite, "", now)
+	}
+	return testCases, executions, result
+}
+
+func (f *JUnitFailure) String() string {
+	message := strings.TrimSpace(f.Message)
+	text := strings.TrimSpace(f.Text)
+	// most reporters repeat the message at the head of the stack trace
+	if message == "" || strings.HasPrefix(text, message) {
+		return text
+	}
+	if text == "" {
+		return message
+	}
+	return message + "\n" + text
+}
+
+func firstNonNil(failures ...*JUnitFailure) *JUnitFailure {
+	for _, f := range failures {
+		if f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func parseJUnitTimestamp(timestamp string) *time.Time {
+	timestamp = strings.TrimSpace(timestamp)
+	if timestamp == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, timestamp); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// toDomainId keeps ids within the length of the id column by hashing the tail of long ones
+func toDomainId(id string, maxLen int) string {
+	if len(id) <= maxLen {
+		return id
+	}
+	sum := sha256.Sum256([]byte(id))
+	hash := hex.EncodeToString(sum[:])
+	return id[:maxLen-len(hash)-1] + ":" + hash
+}
+
+func truncate(s string, size int) string {
+	if len(s) <= size {
+		return s
+	}
+	return s[:size]
+}
@@ -0,0 +1,169 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const junitReport = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="calc" timestamp="2025-08-01T10:00:00Z">
+    <testcase classname="calc.AddTest" name="testAdd" time="0.25"/>
+    <testcase classname="calc.AddTest" name="testOverflow" time="1.5">
+      <failure message="expected 1 but was 2" type="AssertionError">expected 1 but was 2
+	at calc.AddTest.testOverflow(AddTest.java:42)</failure>
+    </testcase>
+    <testcase classname="calc.AddTest" name="testIgnored"><skipped/></testcase>
+    <testsuite name="nested">
+      <testcase name="testNested" time="1,000.0"><error message="boom"/></testcase>
+    </testsuite>
+  </testsuite>
+</testsuites>`
+
+const xunitReport = `<testsuite name="single"><testcase name="testOne" time="0.1"/><testcase name="testOne" time="0.2"/></testsuite>`
+
+func TestConvertJUnitSuites(t *testing.T) {
+	suites, err := parseJUnitReport(strings.NewReader(junitReport))
+	assert.Nil(t, err)
+	now := time.Date(2025, 8, 2, 0, 0, 0, 0, time.UTC)
+	testCases, executions, result := convertJUnitSuites("qa1", "github:GithubRun:1:100", suites, now)
+
+	assert.Equal(t, &JUnitImportResult{TestCases: 4, Succeeded: 1, Failed: 2, Skipped: 1}, result)
+	assert.Len(t, testCases, 4)
+	assert.Equal(t, "junit:QaTestCase:qa1:calc.AddTest.testAdd", testCases[0].Id)
+	assert.Equal(t, "calc.AddTest.testAdd", testCases[0].Name)
+	assert.Equal(t, "nested.testNested", testCases[3].Name)
+
+	assert.Len(t, executions, 3)
+	start := time.Date(2025, 8, 1, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, "SUCCESS", executions[0].Status)
+	assert.Equal(t, int64(250), *executions[0].DurationMs)
+	assert.Equal(t, start, executions[0].StartTime)
+	assert.Equal(t, start.Add(250*time.Millisecond), executions[0].FinishTime)
+	assert.Equal(t, "github:GithubRun:1:100", executions[0].CicdPipelineId)
+
+	assert.Equal(t, "FAILED", executions[1].Status)
+	assert.Equal(t, start.Add(250*time.Millisecond), executions[1].StartTime)
+	assert.True(t, strings.HasPrefix(executions[1].FailureMessage, "expected 1 but was 2\n\tat calc.AddTest"))
+
+	// nested suites inherit the timestamp of their parent
+	assert.Equal(t, "FAILED", executions[2].Status)
+	assert.Equal(t, "boom", executions[2].FailureMessage)
+	assert.Equal(t, int64(1000000), *executions[2].DurationMs)
+	assert.Equal(t, start, executions[2].StartTime)
+
+	// the same report for the same pipeline maps to the same ids
+	_, again, _ := convertJUnitSuites("qa1", "github:GithubRun:1:100", suites, now.Add(time.Hour))
+	assert.Equal(t, executions[1].Id, again[1].Id)
+}
+
+func TestConvertJUnitSuitesRetriedCase(t *testing.T) {
+	suites, err := parseJUnitReport(strings.NewReader(xunitReport))
+	assert.Nil(t, err)
+	testCases, executions, _ := convertJUnitSuites("qa1", "", suites, time.Now())
+	assert.Len(t, testCases, 1)
+	assert.Len(t, executions, 2)
+	assert.NotEqual(t, executions[0].Id, executions[1].Id)
+}
+
+func TestReadJUnitReports(t *testing.T) {
+	// plain xml
+	suites, reports, err := readJUnitReports(strings.NewReader(xunitReport))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, reports)
+	if assert.Len(t, suites, 1) {
+		assert.Equal(t, "single", suites[0].Name)
+	}
+
+	// gzipped xml
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	_, _ = gz.Write([]byte(junitReport))
+	_ = gz.Close()
+	suites, reports, err = readJUnitReports(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, reports)
+	if assert.Len(t, suites, 1) {
+		assert.Equal(t, "calc", suites[0].Name)
+	}
+
+	// gzipped tarball, non-xml entries are ignored
+	buf = &bytes.Buffer{}
+	gz = gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range map[string]string{"a/TEST-a.xml": junitReport, "b/TEST-b.xml": xunitReport, "README.md": "# reports"} {
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte(content))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	suites, reports, err = readJUnitReports(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, reports)
+	assert.Len(t, suites, 2)
+}
+
+func TestReadJUnitReportsTooLarge(t *testing.T) {
+	defer func(size int64) { maxJUnitArchiveSize = size }(maxJUnitArchiveSize)
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{"TEST-a.xml", "TEST-b.xml"} {
+		_ = tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(junitReport)), Typeflag: tar.TypeReg})
+		_, _ = tw.Write([]byte(junitReport))
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	archive := buf.Bytes()
+
+	maxJUnitArchiveSize = int64(len(junitReport)) * 2
+	_, _, err := readJUnitReports(bytes.NewReader(archive))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, errors.BadInput, err.GetType())
+		assert.Contains(t, err.Error(), "exceeds")
+	}
+
+	maxJUnitArchiveSize = 1 << 20
+	_, reports, err := readJUnitReports(bytes.NewReader(archive))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, reports)
+}
+
+func TestParseJUnitReportInvalidRoot(t *testing.T) {
+	_, err := parseJUnitReport(strings.NewReader(`<html></html>`))
+	assert.NotNil(t, err)
+	_, err = parseJUnitReport(strings.NewReader(`not xml`))
+	assert.NotNil(t, err)
+}
+
+func TestToDomainId(t *testing.T) {
+	assert.Equal(t, "short", toDomainId("short", maxExtendedIdLen))
+	long := strings.Repeat("a", 600)
+	id := toDomainId(long, maxExtendedIdLen)
+	assert.Len(t, id, maxExtendedIdLen)
+	assert.NotEqual(t, id, toDomainId(long+"b", maxExtendedIdLen))
+}
@@ -0,0 +1,549 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/codequality"
+	"github.com/apache/incubator-devlake/core/utils"
+)
+
+const (
+	sarifStatusOpen     = "OPEN"
+	sarifStatusResolved = "RESOLVED"
+	sarifStatusClosed   = "CLOSED"
+	// the length of cq_issues.project_key
+	maxCqProjectKeyLen = 100
+	// the length of cq_issues.hash
+	maxCqIssueHashLen = 100
+)
+
+var sarifToolNameSanitizer = regexp.MustCompile(`[^a-z0-9]+`)
+var sarifCweTag = regexp.MustCompile(`(?i)cwe-\d+`)
+
+// SarifLog is the subset of a SARIF 2.1.0 log needed to fill the code-quality domain
+type SarifLog struct {
+	Version string     `json:"version"`
+	Runs    []SarifRun `json:"runs"`
+}
+
+type SarifRun struct {
+	Tool struct {
+		Driver     SarifToolComponent   `json:"driver"`
+		Extensions []SarifToolComponent `json:"extensions"`
+	} `json:"tool"`
+	Invocations []struct {
+		EndTimeUtc string `json:"endTimeUtc"`
+	} `json:"invocations"`
+	Results []SarifResult `json:"results"`
+}
+
+type SarifToolComponent struct {
+	Name  string      `json:"name"`
+	Rules []SarifRule `json:"rules"`
+}
+
+type SarifRule struct {
+	Id                   string       `json:"id"`
+	ShortDescription     SarifMessage `json:"shortDescription"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+	Properties SarifProperties `json:"properties"`
+}
+
+type SarifProperties struct {
+	Tags             []string    `json:"tags"`
+	SecuritySeverity interface{} `json:"security-severity"`
+}
+
+type SarifMessage struct {
+	Text string `json:"text"`
+}
+
+type SarifResult struct {
+	RuleId    string `json:"ruleId"`
+	RuleIndex *int   `json:"ruleIndex"`
+	Rule      *struct {
+		Id            string `json:"id"`
+		Index         *int   `json:"index"`
+		ToolComponent *struct {
+			Index *int `json:"index"`
+		} `json:"toolComponent"`
+	} `json:"rule"`
+	Level               string            `json:"level"`
+	Message             SarifMessage      `json:"message"`
+	Locations           []SarifLocation   `json:"locations"`
+	RelatedLocations    []SarifLocation   `json:"relatedLocations"`
+	Fingerprints        map[string]string `json:"fingerprints"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	CodeFlows           []struct {
+		ThreadFlows []struct {
+			Locations []struct {
+				Location SarifLocation `json:"location"`
+			} `json:"locations"`
+		} `json:"threadFlows"`
+	} `json:"codeFlows"`
+	Suppressions []struct {
+		Status string `json:"status"`
+	} `json:"suppressions"`
+	Properties SarifProperties `json:"properties"`
+}
+
+type SarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			Uri string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region struct {
+			StartLine   int `json:"startLine"`
+			StartColumn int `json:"startColumn"`
+			EndLine     int `json:"endLine"`
+			EndColumn   int `json:"endColumn"`
+		} `json:"region"`
+	} `json:"physicalLocation"`
+	Message SarifMessage `json:"message"`
+}
+
+// SarifImportResult summarizes an imported SARIF log
+type SarifImportResult struct {
+	Runs   int `json:"runs"`
+	Issues int `json:"issues"`
+	Closed int `json:"closed"`
+}
+
+// sarifRunIssues are the issues a single tool reported in a run
+type sarifRunIssues struct {
+	tool       string
+	analyzedAt time.Time
+	issues     []*codequality.CqIssue
+	codeBlocks []*codequality.CqIssueCodeBlock
+}
+
+// ImportSarif parses a SARIF 2.1.0 log and saves its runs into cq_projects, cq_issues and cq_issue_code_blocks.
+// Issues are identified by the tool, rule and fingerprint, so uploading the same log again updates them in place.
+// Issues previously reported by a tool for the project but absent from its new run are closed.
+func (s *Service) ImportSarif(cqProjectId, cqProjectName, commitSha string, file io.Reader) (*SarifImportResult, errors.Error) {
+	if len(cqProjectId) > maxCqProjectKeyLen {
+		return nil, errors.BadInput.New(fmt.Sprintf("cqProjectId must be at most %d characters", maxCqProjectKeyLen))
+	}
+	var log SarifLog
+	if err := json.NewDecoder(file).Decode(&log); err != nil {
+		return nil, errors.BadInput.Wrap(errors.Convert(err), "invalid sarif file")
+	}
+	if !strings.HasPrefix(log.Version, "2.1") {
+		return nil, errors.BadInput.New(fmt.Sprintf("unsupported sarif version %s, only 2.1.0 is supported", log.Version))
+	}
+	now := time.Now()
+	runs := convertSarifRuns(cqProjectId, &log, now)
+
+	lastAnalysisDate := now
+	if len(runs) > 0 {
+		lastAnalysisDate = runs[0].analyzedAt
+		for _, run := range runs[1:] {
+			if run.analyzedAt.After(lastAnalysisDate) {
+				lastAnalysisDate = run.analyzedAt
+			}
+		}
+	}
+	err := s.dal.CreateOrUpdate(&codequality.CqProject{
+		DomainEntityExtended: domainlayer.DomainEntityExtended{
+			Id: cqProjectId,
+		},
+		Name:             cqProjectName,
+		LastAnalysisDate: &common.Iso8601Time{Time: lastAnalysisDate},
+		CommitSha:        commitSha,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SarifImportResult{Runs: len(runs)}
+	for _, run := range runs {
+		closed, err := s.saveSarifRun(cqProjectId, run)
+		if err != nil {
+			return nil, err
+		}
+		result.Issues += len(run.issues)
+		result.Closed += closed
+	}
+	return result, nil
+}
+
+// saveSarifRun upserts the issues of a run, keeping the created date of those reported before,
+// and closes the issues of the same tool which are no longer reported
+func (s *Service) saveSarifRun(cqProjectId string, run *sarifRunIssues) (int, errors.Error) {
+	var existing []codequality.CqIssue
+	err := s.dal.All(&existing,
+		dal.Select("id, status, created_date"),
+		dal.From(&codequality.CqIssue{}),
+		dal.Where("project_key = ? AND id LIKE ?", cqProjectId, sarifIssueIdPrefix(run.tool)+"%"),
+	)
+	if err != nil {
+		return 0, errors.Default.Wrap(err, "failed to load existing cq_issues")
+	}
+	reported := make(map[string]bool, len(run.issues))
+	issueIds := make([]string, 0, len(run.issues))
+	for _, issue := range run.issues {
+		reported[issue.Id] = true
+		issueIds = append(issueIds, issue.Id)
+	}
+	closedIds := make([]string, 0)
+	for _, issue := range existing {
+		if reported[issue.Id] {
+			continue
+		}
+		if issue.Status != sarifStatusClosed {
+			closedIds = append(closedIds, issue.Id)
+		}
+	}
+	createdDates := make(map[string]*common.Iso8601Time, len(existing))
+	for i := range existing {
+		if existing[i].CreatedDate != nil && existing[i].Status != sarifStatusClosed {
+			createdDates[existing[i].Id] = existing[i].CreatedDate
+		}
+	}
+	for _, issue := range run.issues {
+		if createdDate, ok := createdDates[issue.Id]; ok {
+			issue.CreatedDate = createdDate
+		}
+	}
+
+	for start := 0; start < len(issueIds); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(issueIds) {
+			end = len(issueIds)
+		}
+		err = s.dal.Delete(&codequality.CqIssueCodeBlock{}, dal.Where("issue_key IN ?", issueIds[start:end]))
+		if err != nil {
+			return 0, errors.Default.Wrap(err, "failed to delete old cq_issue_code_blocks")
+		}
+		if err = s.dal.CreateOrUpdate(run.issues[start:end]); err != nil {
+			return 0, errors.Default.Wrap(err, "failed to save cq_issues")
+		}
+	}
+	for start := 0; start < len(run.codeBlocks); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(run.codeBlocks) {
+			end = len(run.codeBlocks)
+		}
+		if err = s.dal.CreateOrUpdate(run.codeBlocks[start:end]); err != nil {
+			return 0, errors.Default.Wrap(err, "failed to save cq_issue_code_blocks")
+		}
+	}
+	if len(closedIds) > 0 {
+		err = s.dal.UpdateColumns(&codequality.CqIssue{}, []dal.DalSet{
+			{ColumnName: "status", Value: sarifStatusClosed},
+			{ColumnName: "updated_date", Value: run.analyzedAt},
+		}, dal.Where("id IN ?", closedIds))
+		if err != nil {
+			return 0, errors.Default.Wrap(err, "failed to close fixed cq_issues")
+		}
+	}
+	return len(closedIds), nil
+}
+
+// convertSarifRuns maps the results of every run to cq_issues and cq_issue_code_blocks,
+// runs of the same tool are merged since some tools split their results into several runs
+func convertSarifRuns(cqProjectId string, log *SarifLog, now time.Time) []*sarifRunIssues {
+	runs := make([]*sarifRunIssues, 0)
+	runsByTool := make(map[string]*sarifRunIssues)
+	seen := make(map[string]bool)
+	for i := range log.Runs {
+		run := &log.Runs[i]
+		tool := sarifToolName(run.Tool.Driver.Name)
+		converted, ok := runsByTool[tool]
+		if !ok {
+			converted = &sarifRunIssues{tool: tool, analyzedAt: now}
+			runsByTool[tool] = converted
+			runs = append(runs, converted)
+		}
+		for _, invocation := range run.Invocations {
+			if endTime, err := time.Parse(time.RFC3339, invocation.EndTimeUtc); err == nil {
+				converted.analyzedAt = endTime
+			}
+		}
+		for j := range run.Results {
+			issue, codeBlocks := convertSarifResult(cqProjectId, tool, run, &run.Results[j], converted.analyzedAt)
+			// the same finding reported twice, e.g. by overlapping scans
+			if seen[issue.Id] {
+				continue
+			}
+			seen[issue.Id] = true
+			converted.issues = append(converted.issues, issue)
+			converted.codeBlocks = append(converted.codeBlocks, codeBlocks...)
+		}
+	}
+	return runs
+}
+
+func convertSarifResult(cqProjectId, tool string, run *SarifRun, result *SarifResult, analyzedAt time.Time) (*codequality.CqIssue, []*codequality.CqIssueCodeBlock) {
+	rule := findSarifRule(run, result)
+	ruleId := result.RuleId
+	if ruleId == "" && result.Rule != nil {
+		ruleId = result.Rule.Id
+	}
+	if ruleId == "" && rule != nil {
+		ruleId = rule.Id
+	}
+	var tags []string
+	var securitySeverity interface{}
+	level := result.Level
+	message := result.Message.Text
+	if rule != nil {
+		tags = rule.Properties.Tags
+		securitySeverity = rule.Properties.SecuritySeverity
+		if level == "" {
+			level = rule.DefaultConfiguration.Level
+		}
+		if message == "" {
+			message = rule.ShortDescription.Text
+		}
+	}
+	tags = append(tags, result.Properties.Tags...)
+	if result.Properties.SecuritySeverity != nil {
+		securitySeverity = result.Properties.SecuritySeverity
+	}
+	score, hasScore := parseSecuritySeverity(securitySeverity)
+
+	fingerprint := sarifFingerprint(result)
+	sum := sha256.Sum256([]byte(strings.Join([]string{cqProjectId, ruleId, fingerprint}, "\x00")))
+	issueId := sarifIssueIdPrefix(tool) + hex.EncodeToString(sum[:])
+	hash := fingerprint
+	if len(hash) > maxCqIssueHashLen {
+		hash = hex.EncodeToString(sum[:])
+	}
+	status := sarifStatusOpen
+	for _, suppression := range result.Suppressions {
+		if suppression.Status == "" || suppression.Status == "accepted" {
+			status = sarifStatusResolved
+		}
+	}
+	issue := &codequality.CqIssue{
+		DomainEntity: domainlayer.DomainEntity{Id: issueId},
+		Rule:         ruleId,
+		Severity:     sarifSeverity(level, score, hasScore),
+		ProjectKey:   cqProjectId,
+		Status:       status,
+		Message:      message,
+		Hash:         hash,
+		Tags:         strings.Join(utils.StringsUniq(tags), ","),
+		Type:         sarifIssueType(tags, hasScore),
+		CreatedDate:  &common.Iso8601Time{Time: analyzedAt},
+		UpdatedDate:  &common.Iso8601Time{Time: analyzedAt},
+	}
+	for _, tag := range tags {
+		if cwe := sarifCweTag.FindString(tag); cwe != "" {
+			issue.SecurityCategory = strings.ToUpper(cwe)
+			break
+		}
+	}
+	if hasScore {
+		issue.VulnerabilityProbability = strconv.FormatFloat(score, 'f', -1, 64)
+	}
+	if len(result.Locations) > 0 {
+		primary := result.Locations[0].PhysicalLocation
+		issue.Component = sarifUri(primary.ArtifactLocation.Uri)
+		issue.Line = primary.Region.StartLine
+		issue.StartLine = primary.Region.StartLine
+		issue.EndLine = primary.Region.EndLine
+		if issue.EndLine == 0 {
+			issue.EndLine = issue.StartLine
+		}
+		issue.StartOffset = primary.Region.StartColumn
+		issue.EndOffset = primary.Region.EndColumn
+	}
+
+	// secondary locations, related locations and the steps of code flows make up the code blocks
+	locations := make([]SarifLocation, 0)
+	if len(result.Locations) > 1 {
+		locations = append(locations, result.Locations[1:]...)
+	}
+	locations = append(locations, result.RelatedLocations...)
+	for _, codeFlow := range result.CodeFlows {
+		for _, threadFlow := range codeFlow.ThreadFlows {
+			for _, step := range threadFlow.Locations {
+				locations = append(locations, step.Location)
+			}
+		}
+	}
+	codeBlocks := make([]*codequality.CqIssueCodeBlock, 0, len(locations))
+	for i, location := range locations {
+		region := location.PhysicalLocation.Region
+		endLine := region.EndLine
+		if endLine == 0 {
+			endLine = region.StartLine
+		}
+		codeBlocks = append(codeBlocks, &codequality.CqIssueCodeBlock{
+			DomainEntity: domainlayer.DomainEntity{Id: fmt.Sprintf("%s:%d", issueId, i)},
+			IssueKey:     issueId,
+			Component:    sarifUri(location.PhysicalLocation.ArtifactLocation.Uri),
+			StartLine:    region.StartLine,
+			EndLine:      endLine,
+			StartOffset:  region.StartColumn,
+			EndOffset:    region.EndColumn,
+			Msg:          location.Message.Text,
+		})
+	}
+	return issue, codeBlocks
+}
+
+// findSarifRule looks up the rule metadata of a result in the driver or the extension it refers to
+func findSarifRule(run *SarifRun, result *SarifResult) *SarifRule {
+	component := &run.Tool.Driver
+	ruleIndex := result.RuleIndex
+	ruleId := result.RuleId
+	if result.Rule != nil {
+		if result.Rule.Index != nil {
+			ruleIndex = result.Rule.Index
+		}
+		if result.Rule.Id != "" {
+			ruleId = result.Rule.Id
+		}
+		if tc := result.Rule.ToolComponent; tc != nil && tc.Index != nil && *tc.Index >= 0 && *tc.Index < len(run.Tool.Extensions) {
+			component = &run.Tool.Extensions[*tc.Index]
+		}
+	}
+	if ruleIndex != nil && *ruleIndex >= 0 && *ruleIndex < len(component.Rules) {
+		return &component.Rules[*ruleIndex]
+	}
+	for i := range component.Rules {
+		if component.Rules[i].Id == ruleId {
+			return &component.Rules[i]
+		}
+	}
+	return nil
+}
+
+// sarifFingerprint prefers the stable fingerprints computed by the tool, then the partial ones,
+// and falls back to the location and message of the result
+func sarifFingerprint(result *SarifResult) string {
+	for _, fingerprints := range []map[string]string{result.Fingerprints, result.PartialFingerprints} {
+		if len(fingerprints) == 0 {
+			continue
+		}
+		keys := make([]string, 0, len(fingerprints))
+		for k := range fingerprints {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, k+"="+fingerprints[k])
+		}
+		return strings.Join(parts, ";")
+	}
+	var uri string
+	var line, column int
+	if len(result.Locations) > 0 {
+		uri = sarifUri(result.Locations[0].PhysicalLocation.ArtifactLocation.Uri)
+		line = result.Locations[0].PhysicalLocation.Region.StartLine
+		column = result.Locations[0].PhysicalLocation.Region.StartColumn
+	}
+	return fmt.Sprintf("%s:%d:%d:%s", uri, line, column, result.Message.Text)
+}
+
+// sarifSeverity maps the CVSS based security-severity property, or the SARIF level, to the sonarqube severities
+func sarifSeverity(level string, score float64, hasScore bool) string {
+	if hasScore {
+		switch {
+		case score >= 9:
+			return "BLOCKER"
+		case score >= 7:
+			return "CRITICAL"
+		case score >= 4:
+			return "MAJOR"
+		case score > 0:
+			return "MINOR"
+		default:
+			return "INFO"
+		}
+	}
+	switch level {
+	case "error":
+		return "CRITICAL"
+	case "note":
+		return "MINOR"
+	case "none":
+		return "INFO"
+	default:
+		// warning is the default level of SARIF
+		return "MAJOR"
+	}
+}
+
+func sarifIssueType(tags []string, hasScore bool) string {
+	if hasScore {
+		return "VULNERABILITY"
+	}
+	for _, tag := range tags {
+		switch strings.ToLower(tag) {
+		case "security":
+			return "VULNERABILITY"
+		case "correctness", "reliability", "bug":
+			return "BUG"
+		}
+	}
+	return "CODE_SMELL"
+}
+
+func parseSecuritySeverity(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		score, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return score, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func sarifToolName(name string) string {
+	tool := strings.Trim(sarifToolNameSanitizer.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if tool == "" {
+		tool = "unknown"
+	}
+	if len(tool) > 50 {
+		tool = tool[:50]
+	}
+	return tool
+}
+
+func sarifIssueIdPrefix(tool string) string {
+	return fmt.Sprintf("sarif:CqIssue:%s:", tool)
+}
+
+func sarifUri(uri string) string {
+	uri = strings.TrimPrefix(uri, "file://")
+	return strings.TrimPrefix(uri, "./")
+}
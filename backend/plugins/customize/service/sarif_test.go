@@ -0,0 +1,152 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sarifLog = `{
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {"name": "CodeQL", "rules": []},
+        "extensions": [{
+          "name": "codeql/go-queries",
+          "rules": [{
+            "id": "go/sql-injection",
+            "shortDescription": {"text": "Database query built from user-controlled sources"},
+            "defaultConfiguration": {"level": "error"},
+            "properties": {"tags": ["security", "external/cwe/cwe-089"], "security-severity": "8.8"}
+          }]
+        }]
+      },
+      "invocations": [{"endTimeUtc": "2025-08-01T10:00:00Z"}],
+      "results": [
+        {
+          "ruleId": "go/sql-injection",
+          "rule": {"id": "go/sql-injection", "index": 0, "toolComponent": {"index": 0}},
+          "message": {"text": "This query depends on a user-provided value."},
+          "locations": [{"physicalLocation": {"artifactLocation": {"uri": "./db/query.go"}, "region": {"startLine": 42, "startColumn": 5, "endColumn": 30}}}],
+          "partialFingerprints": {"primaryLocationLineHash": "abc123:1"},
+          "codeFlows": [{"threadFlows": [{"locations": [
+            {"location": {"physicalLocation": {"artifactLocation": {"uri": "api/handler.go"}, "region": {"startLine": 10, "endLine": 12}}, "message": {"text": "source"}}},
+            {"location": {"physicalLocation": {"artifactLocation": {"uri": "db/query.go"}, "region": {"startLine": 42}}, "message": {"text": "sink"}}}
+          ]}]}]
+        },
+        {
+          "ruleId": "go/sql-injection",
+          "rule": {"id": "go/sql-injection", "index": 0, "toolComponent": {"index": 0}},
+          "message": {"text": "This query depends on a user-provided value."},
+          "locations": [{"physicalLocation": {"artifactLocation": {"uri": "./db/query.go"}, "region": {"startLine": 42}}}],
+          "partialFingerprints": {"primaryLocationLineHash": "abc123:1"}
+        }
+      ]
+    },
+    {
+      "tool": {"driver": {"name": "gosec", "rules": [{"id": "G104", "properties": {"tags": ["reliability"]}}]}},
+      "results": [
+        {
+          "ruleId": "G104",
+          "ruleIndex": 0,
+          "level": "warning",
+          "message": {"text": "Errors unhandled."},
+          "locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.go"}, "region": {"startLine": 7}}}],
+          "suppressions": [{"kind": "inSource"}]
+        },
+        {
+          "ruleId": "G101",
+          "level": "note",
+          "message": {"text": "Potential hardcoded credentials"},
+          "locations": [{"physicalLocation": {"artifactLocation": {"uri": "config.go"}, "region": {"startLine": 3}}}]
+        }
+      ]
+    }
+  ]
+}`
+
+func TestConvertSarifRuns(t *testing.T) {
+	var log SarifLog
+	assert.Nil(t, json.Unmarshal([]byte(sarifLog), &log))
+	now := time.Date(2025, 8, 2, 0, 0, 0, 0, time.UTC)
+	runs := convertSarifRuns("cq1", &log, now)
+	assert.Len(t, runs, 2)
+
+	codeql := runs[0]
+	assert.Equal(t, "codeql", codeql.tool)
+	assert.Equal(t, time.Date(2025, 8, 1, 10, 0, 0, 0, time.UTC), codeql.analyzedAt)
+	// the duplicated finding is saved once
+	assert.Len(t, codeql.issues, 1)
+	issue := codeql.issues[0]
+	assert.True(t, strings.HasPrefix(issue.Id, "sarif:CqIssue:codeql:"))
+	assert.Equal(t, "go/sql-injection", issue.Rule)
+	assert.Equal(t, "CRITICAL", issue.Severity)
+	assert.Equal(t, "VULNERABILITY", issue.Type)
+	assert.Equal(t, "CWE-089", issue.SecurityCategory)
+	assert.Equal(t, "8.8", issue.VulnerabilityProbability)
+	assert.Equal(t, "OPEN", issue.Status)
+	assert.Equal(t, "cq1", issue.ProjectKey)
+	assert.Equal(t, "db/query.go", issue.Component)
+	assert.Equal(t, 42, issue.StartLine)
+	assert.Equal(t, 42, issue.EndLine)
+	assert.Equal(t, 5, issue.StartOffset)
+	assert.Equal(t, "primaryLocationLineHash=abc123:1", issue.Hash)
+	assert.Len(t, codeql.codeBlocks, 2)
+	assert.Equal(t, issue.Id, codeql.codeBlocks[0].IssueKey)
+	assert.Equal(t, "api/handler.go", codeql.codeBlocks[0].Component)
+	assert.Equal(t, 12, codeql.codeBlocks[0].EndLine)
+	assert.Equal(t, "sink", codeql.codeBlocks[1].Msg)
+
+	gosec := runs[1]
+	assert.Equal(t, "gosec", gosec.tool)
+	assert.Equal(t, now, gosec.analyzedAt)
+	assert.Len(t, gosec.issues, 2)
+	assert.Equal(t, "MAJOR", gosec.issues[0].Severity)
+	assert.Equal(t, "BUG", gosec.issues[0].Type)
+	assert.Equal(t, "RESOLVED", gosec.issues[0].Status)
+	assert.Equal(t, "MINOR", gosec.issues[1].Severity)
+	assert.Equal(t, "CODE_SMELL", gosec.issues[1].Type)
+
+	// the same log maps to the same ids, whenever it is uploaded
+	again := convertSarifRuns("cq1", &log, now.Add(time.Hour))
+	assert.Equal(t, issue.Id, again[0].issues[0].Id)
+	assert.Equal(t, gosec.issues[1].Id, again[1].issues[1].Id)
+	// but not across projects
+	other := convertSarifRuns("cq2", &log, now)
+	assert.NotEqual(t, issue.Id, other[0].issues[0].Id)
+}
+
+func TestSarifSeverity(t *testing.T) {
+	assert.Equal(t, "BLOCKER", sarifSeverity("note", 9.8, true))
+	assert.Equal(t, "MAJOR", sarifSeverity("", 5, true))
+	assert.Equal(t, "INFO", sarifSeverity("error", 0, true))
+	assert.Equal(t, "CRITICAL", sarifSeverity("error", 0, false))
+	assert.Equal(t, "MAJOR", sarifSeverity("", 0, false))
+	assert.Equal(t, "INFO", sarifSeverity("none", 0, false))
+}
+
+func TestSarifToolName(t *testing.T) {
+	assert.Equal(t, "semgrep-oss", sarifToolName("Semgrep OSS"))
+	assert.Equal(t, "unknown", sarifToolName("_%"))
+}
@@ -0,0 +1,620 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/sbom"
+	"github.com/apache/incubator-devlake/core/utils"
+)
+
+const (
+	SBOM_CYCLONEDX = "cyclonedx"
+	SBOM_SPDX      = "spdx"
+)
+
+// sbomEntry is a component listed by a bom, whatever its format
+type sbomEntry struct {
+	ref       string
+	typ       string
+	namespace string
+	name      string
+	version   string
+	purl      string
+	licenses  []string
+	supplier  string
+	sha256    string
+	scope     string
+}
+
+// sbomVulnerability is a known vulnerability reported by a bom for some of its components
+type sbomVulnerability struct {
+	id          string
+	source      string
+	url         string
+	severity    string
+	score       *float64
+	cwes        []string
+	description string
+	state       string
+	refs        []string
+}
+
+// sbomDocument is a parsed CycloneDX or SPDX document
+type sbomDocument struct {
+	format          string
+	specVersion     string
+	documentId      string
+	tool            string
+	generated       *time.Time
+	entries         []*sbomEntry
+	directRefs      map[string]bool
+	vulnerabilities []*sbomVulnerability
+}
+
+// SbomImportResult summarizes an imported bom
+type SbomImportResult struct {
+	SnapshotId      string `json:"snapshotId"`
+	Format          string `json:"format"`
+	Components      int    `json:"components"`
+	Vulnerabilities int    `json:"vulnerabilities"`
+}
+
+// sbomRecords are the domain records converted from a document
+type sbomRecords struct {
+	snapshot        *sbom.SbomSnapshot
+	components      []*sbom.SbomComponent
+	versions        []*sbom.SbomComponentVersion
+	links           []*sbom.SbomSnapshotComponent
+	vulnerabilities []*sbom.SbomComponentVulnerability
+}
+
+// ImportSbom parses a CycloneDX JSON or SPDX JSON document and saves it as a snapshot of a repo or a cicd_deployment.
+// The snapshot is identified by the commit if any, otherwise by the serial number or namespace of the document,
+// so uploading the same bom again replaces the previous snapshot.
+func (s *Service) ImportSbom(targetType, targetId, commitSha string, file io.Reader) (*SbomImportResult, errors.Error) {
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, errors.Convert(err)
+	}
+	doc, parseErr := parseSbom(content)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	records := convertSbomDocument(targetType, targetId, commitSha, doc, content)
+
+	dbErr := s.dal.Delete(&sbom.SbomSnapshotComponent{}, dal.Where("snapshot_id = ?", records.snapshot.Id))
+	if dbErr != nil {
+		return nil, errors.Default.Wrap(dbErr, "failed to delete old sbom_snapshot_components")
+	}
+	if dbErr = s.dal.CreateOrUpdate(records.snapshot); dbErr != nil {
+		return nil, errors.Default.Wrap(dbErr, "failed to save sbom_snapshots")
+	}
+	for _, batch := range []struct {
+		table string
+		size  int
+		slice func(start, end int) interface{}
+	}{
+		{"sbom_components", len(records.components), func(start, end int) interface{} { return records.components[start:end] }},
+		{"sbom_component_versions", len(records.versions), func(start, end int) interface{} { return records.versions[start:end] }},
+		{"sbom_snapshot_components", len(records.links), func(start, end int) interface{} { return records.links[start:end] }},
+		{"sbom_component_vulnerabilities", len(records.vulnerabilities), func(start, end int) interface{} { return records.vulnerabilities[start:end] }},
+	} {
+		for start := 0; start < batch.size; start += importBatchSize {
+			end := start + importBatchSize
+			if end > batch.size {
+				end = batch.size
+			}
+			if dbErr = s.dal.CreateOrUpdate(batch.slice(start, end)); dbErr != nil {
+				return nil, errors.Default.Wrap(dbErr, "failed to save "+batch.table)
+			}
+		}
+	}
+	return &SbomImportResult{
+		SnapshotId:      records.snapshot.Id,
+		Format:          doc.format,
+		Components:      len(records.links),
+		Vulnerabilities: len(records.vulnerabilities),
+	}, nil
+}
+
+func convertSbomDocument(targetType, targetId, commitSha string, doc *sbomDocument, content []byte) *sbomRecords {
+	snapshotKey := commitSha
+	if snapshotKey == "" {
+		snapshotKey = doc.documentId
+	}
+	if snapshotKey == "" {
+		sum := sha256.Sum256(content)
+		snapshotKey = hex.EncodeToString(sum[:])
+	}
+	records := &sbomRecords{
+		snapshot: &sbom.SbomSnapshot{
+			DomainEntity: domainlayer.DomainEntity{
+				Id: toDomainId(fmt.Sprintf("sbom:SbomSnapshot:%s:%s:%s", targetType, targetId, snapshotKey), maxIdLen),
+			},
+			TargetType:    targetType,
+			TargetId:      targetId,
+			CommitSha:     commitSha,
+			Format:        doc.format,
+			SpecVersion:   doc.specVersion,
+			DocumentId:    truncate(doc.documentId, 500),
+			Tool:          truncate(doc.tool, 255),
+			GeneratedDate: doc.generated,
+		},
+	}
+
+	seenComponents := make(map[string]bool)
+	links := make(map[string]*sbom.SbomSnapshotComponent)
+	versionIdsByRef := make(map[string]string)
+	for _, entry := range doc.entries {
+		key := entry.namespace + "/" + entry.name
+		if entry.namespace == "" {
+			key = entry.name
+		}
+		key = entry.typ + "/" + key
+		componentId := toDomainId("sbom:SbomComponent:"+key, maxIdLen)
+		versionId := toDomainId("sbom:SbomComponentVersion:"+key+"@"+entry.version, maxIdLen)
+		if entry.ref != "" {
+			versionIdsByRef[entry.ref] = versionId
+		}
+		if !seenComponents[componentId] {
+			seenComponents[componentId] = true
+			purl := ""
+			if entry.purl != "" {
+				purl = "pkg:" + key
+			}
+			records.components = append(records.components, &sbom.SbomComponent{
+				DomainEntity: domainlayer.DomainEntity{Id: componentId},
+				Type:         truncate(entry.typ, 100),
+				Namespace:    truncate(entry.namespace, 255),
+				Name:         truncate(entry.name, 255),
+				Purl:         truncate(purl, 500),
+			})
+		}
+		if link, ok := links[versionId]; ok {
+			// the same version listed twice, e.g. by several modules of the project
+			link.IsDirect = link.IsDirect || doc.directRefs[entry.ref]
+			continue
+		}
+		records.versions = append(records.versions, &sbom.SbomComponentVersion{
+			DomainEntity: domainlayer.DomainEntity{Id: versionId},
+			ComponentId:  componentId,
+			Version:      truncate(entry.version, 255),
+			Purl:         truncate(entry.purl, 1000),
+			Licenses:     truncate(strings.Join(utils.StringsUniq(entry.licenses), ","), 1000),
+			Supplier:     truncate(entry.supplier, 255),
+			Sha256:       entry.sha256,
+		})
+		link := &sbom.SbomSnapshotComponent{
+			SnapshotId:         records.snapshot.Id,
+			ComponentVersionId: versionId,
+			IsDirect:           doc.directRefs[entry.ref],
+			Scope:              truncate(entry.scope, 50),
+		}
+		links[versionId] = link
+		records.links = append(records.links, link)
+	}
+	records.snapshot.ComponentCount = len(records.links)
+
+	seenVulnerabilities := make(map[string]bool)
+	for _, vulnerability := range doc.vulnerabilities {
+		for _, ref := range vulnerability.refs {
+			versionId, ok := versionIdsByRef[ref]
+			if !ok || seenVulnerabilities[versionId+"\x00"+vulnerability.id] {
+				continue
+			}
+			seenVulnerabilities[versionId+"\x00"+vulnerability.id] = true
+			records.vulnerabilities = append(records.vulnerabilities, &sbom.SbomComponentVulnerability{
+				ComponentVersionId: versionId,
+				VulnerabilityId:    truncate(vulnerability.id, 100),
+				Source:             truncate(vulnerability.source, 100),
+				Url:                truncate(vulnerability.url, 1000),
+				Severity:           vulnerability.severity,
+				Score:              vulnerability.score,
+				Cwes:               truncate(strings.Join(vulnerability.cwes, ","), 255),
+				Description:        vulnerability.description,
+				AnalysisState:      truncate(vulnerability.state, 50),
+			})
+		}
+	}
+	return records
+}
+
+func parseSbom(content []byte) (*sbomDocument, errors.Error) {
+	var probe struct {
+		BomFormat   string `json:"bomFormat"`
+		SpdxVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(content, &probe); err != nil {
+		return nil, errors.BadInput.Wrap(errors.Convert(err), "invalid sbom, only CycloneDX JSON and SPDX JSON are supported")
+	}
+	switch {
+	case strings.EqualFold(probe.BomFormat, "CycloneDX"):
+		return parseCycloneDx(content)
+	case probe.SpdxVersion != "":
+		return parseSpdx(content)
+	default:
+		return nil, errors.BadInput.New("invalid sbom, neither bomFormat nor spdxVersion found")
+	}
+}
+
+type cycloneDxComponent struct {
+	BomRef   string `json:"bom-ref"`
+	Type     string `json:"type"`
+	Group    string `json:"group"`
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Purl     string `json:"purl"`
+	Scope    string `json:"scope"`
+	Supplier *struct {
+		Name string `json:"name"`
+	} `json:"supplier"`
+	Licenses []struct {
+		License *struct {
+			Id   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"license"`
+		Expression string `json:"expression"`
+	} `json:"licenses"`
+	Hashes []struct {
+		Alg     string `json:"alg"`
+		Content string `json:"content"`
+	} `json:"hashes"`
+	Components []cycloneDxComponent `json:"components"`
+}
+
+type cycloneDxTool struct {
+	Vendor  string `json:"vendor"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func parseCycloneDx(content []byte) (*sbomDocument, errors.Error) {
+	var bom struct {
+		SpecVersion  string `json:"specVersion"`
+		SerialNumber string `json:"serialNumber"`
+		Metadata     struct {
+			Timestamp string              `json:"timestamp"`
+			Tools     json.RawMessage     `json:"tools"`
+			Component *cycloneDxComponent `json:"component"`
+		} `json:"metadata"`
+		Components   []cycloneDxComponent `json:"components"`
+		Dependencies []struct {
+			Ref       string   `json:"ref"`
+			DependsOn []string `json:"dependsOn"`
+		} `json:"dependencies"`
+		Vulnerabilities []struct {
+			Id     string `json:"id"`
+			Source struct {
+				Name string `json:"name"`
+				Url  string `json:"url"`
+			} `json:"source"`
+			Ratings []struct {
+				Score    *float64 `json:"score"`
+				Severity string   `json:"severity"`
+			} `json:"ratings"`
+			Cwes        []int  `json:"cwes"`
+			Description string `json:"description"`
+			Analysis    struct {
+				State string `json:"state"`
+			} `json:"analysis"`
+			Affects []struct {
+				Ref string `json:"ref"`
+			} `json:"affects"`
+		} `json:"vulnerabilities"`
+	}
+	if err := json.Unmarshal(content, &bom); err != nil {
+		return nil, errors.BadInput.Wrap(errors.Convert(err), "invalid CycloneDX document")
+	}
+	doc := &sbomDocument{
+		format:      SBOM_CYCLONEDX,
+		specVersion: bom.SpecVersion,
+		documentId:  bom.SerialNumber,
+		generated:   parseSbomTime(bom.Metadata.Timestamp),
+		directRefs:  make(map[string]bool),
+	}
+	// tools are an array up to 1.4, and an object of components and services since 1.5
+	var tools []cycloneDxTool
+	if err := json.Unmarshal(bom.Metadata.Tools, &tools); err != nil {
+		var toolsObject struct {
+			Components []cycloneDxTool `json:"components"`
+		}
+		_ = json.Unmarshal(bom.Metadata.Tools, &toolsObject)
+		tools = toolsObject.Components
+	}
+	if len(tools) > 0 {
+		doc.tool = strings.TrimSpace(tools[0].Name + " " + tools[0].Version)
+	}
+
+	rootRef := ""
+	if bom.Metadata.Component != nil {
+		rootRef = bom.Metadata.Component.BomRef
+	}
+	for _, dependency := range bom.Dependencies {
+		if dependency.Ref == rootRef && rootRef != "" {
+			for _, ref := range dependency.DependsOn {
+				doc.directRefs[ref] = true
+			}
+		}
+	}
+	var walk func(components []cycloneDxComponent)
+	walk = func(components []cycloneDxComponent) {
+		for i := range components {
+			c := &components[i]
+			if c.Name != "" {
+				doc.entries = append(doc.entries, cycloneDxEntry(c))
+			}
+			walk(c.Components)
+		}
+	}
+	walk(bom.Components)
+
+	for _, v := range bom.Vulnerabilities {
+		vulnerability := &sbomVulnerability{
+			id:          v.Id,
+			source:      v.Source.Name,
+			url:         v.Source.Url,
+			severity:    "UNKNOWN",
+			description: v.Description,
+			state:       v.Analysis.State,
+		}
+		// the highest rating wins
+		for _, rating := range v.Ratings {
+			if rating.Score != nil && (vulnerability.score == nil || *rating.Score > *vulnerability.score) {
+				vulnerability.score = rating.Score
+				if rating.Severity != "" {
+					vulnerability.severity = normalizeSbomSeverity(rating.Severity)
+				}
+			} else if vulnerability.score == nil && rating.Severity != "" && vulnerability.severity == "UNKNOWN" {
+				vulnerability.severity = normalizeSbomSeverity(rating.Severity)
+			}
+		}
+		for _, cwe := range v.Cwes {
+			vulnerability.cwes = append(vulnerability.cwes, "CWE-"+strconv.Itoa(cwe))
+		}
+		for _, affect := range v.Affects {
+			// refs may be bom-links, e.g. urn:cdx:<serial>/1#<bom-ref>
+			ref := affect.Ref
+			if strings.HasPrefix(ref, "urn:cdx:") {
+				if i := strings.Index(ref, "#"); i >= 0 {
+					ref, _ = url.PathUnescape(ref[i+1:])
+				}
+			}
+			vulnerability.refs = append(vulnerability.refs, ref)
+		}
+		if vulnerability.id != "" {
+			doc.vulnerabilities = append(doc.vulnerabilities, vulnerability)
+		}
+	}
+	return doc, nil
+}
+
+func cycloneDxEntry(c *cycloneDxComponent) *sbomEntry {
+	entry := &sbomEntry{
+		ref:       c.BomRef,
+		typ:       "generic",
+		namespace: c.Group,
+		name:      c.Name,
+		version:   c.Version,
+		scope:     c.Scope,
+	}
+	if entry.scope == "" {
+		entry.scope = "required"
+	}
+	if c.Supplier != nil {
+		entry.supplier = c.Supplier.Name
+	}
+	for _, license := range c.Licenses {
+		switch {
+		case license.Expression != "":
+			entry.licenses = append(entry.licenses, license.Expression)
+		case license.License != nil && license.License.Id != "":
+			entry.licenses = append(entry.licenses, license.License.Id)
+		case license.License != nil && license.License.Name != "":
+			entry.licenses = append(entry.licenses, license.License.Name)
+		}
+	}
+	for _, hash := range c.Hashes {
+		if strings.EqualFold(hash.Alg, "SHA-256") {
+			entry.sha256 = strings.ToLower(hash.Content)
+		}
+	}
+	applyPurl(entry, c.Purl)
+	return entry
+}
+
+type spdxPackage struct {
+	SpdxId           string `json:"SPDXID"`
+	Name             string `json:"name"`
+	VersionInfo      string `json:"versionInfo"`
+	Supplier         string `json:"supplier"`
+	LicenseConcluded string `json:"licenseConcluded"`
+	LicenseDeclared  string `json:"licenseDeclared"`
+	ExternalRefs     []struct {
+		ReferenceCategory string `json:"referenceCategory"`
+		ReferenceType     string `json:"referenceType"`
+		ReferenceLocator  string `json:"referenceLocator"`
+	} `json:"externalRefs"`
+	Checksums []struct {
+		Algorithm     string `json:"algorithm"`
+		ChecksumValue string `json:"checksumValue"`
+	} `json:"checksums"`
+}
+
+func parseSpdx(content []byte) (*sbomDocument, errors.Error) {
+	var spdx struct {
+		SpdxVersion       string `json:"spdxVersion"`
+		DocumentNamespace string `json:"documentNamespace"`
+		CreationInfo      struct {
+			Created  string   `json:"created"`
+			Creators []string `json:"creators"`
+		} `json:"creationInfo"`
+		DocumentDescribes []string      `json:"documentDescribes"`
+		Packages          []spdxPackage `json:"packages"`
+		Relationships     []struct {
+			SpdxElementId      string `json:"spdxElementId"`
+			RelationshipType   string `json:"relationshipType"`
+			RelatedSpdxElement string `json:"relatedSpdxElement"`
+		} `json:"relationships"`
+	}
+	if err := json.Unmarshal(content, &spdx); err != nil {
+		return nil, errors.BadInput.Wrap(errors.Convert(err), "invalid SPDX document")
+	}
+	doc := &sbomDocument{
+		format:      SBOM_SPDX,
+		specVersion: strings.TrimPrefix(spdx.SpdxVersion, "SPDX-"),
+		documentId:  spdx.DocumentNamespace,
+		generated:   parseSbomTime(spdx.CreationInfo.Created),
+		directRefs:  make(map[string]bool),
+	}
+	for _, creator := range spdx.CreationInfo.Creators {
+		if tool, ok := strings.CutPrefix(creator, "Tool:"); ok {
+			doc.tool = strings.TrimSpace(tool)
+			break
+		}
+	}
+
+	// the described packages are the roots, e.g. the scanned image or directory
+	roots := make(map[string]bool)
+	for _, id := range spdx.DocumentDescribes {
+		roots[id] = true
+	}
+	for _, r := range spdx.Relationships {
+		if r.SpdxElementId == "SPDXRef-DOCUMENT" && r.RelationshipType == "DESCRIBES" {
+			roots[r.RelatedSpdxElement] = true
+		}
+	}
+	for _, r := range spdx.Relationships {
+		switch {
+		case roots[r.SpdxElementId] && (r.RelationshipType == "DEPENDS_ON" || r.RelationshipType == "CONTAINS"):
+			doc.directRefs[r.RelatedSpdxElement] = true
+		case roots[r.RelatedSpdxElement] && (r.RelationshipType == "DEPENDENCY_OF" || r.RelationshipType == "CONTAINED_BY"):
+			doc.directRefs[r.SpdxElementId] = true
+		}
+	}
+	for i := range spdx.Packages {
+		p := &spdx.Packages[i]
+		if roots[p.SpdxId] || p.Name == "" {
+			continue
+		}
+		doc.entries = append(doc.entries, spdxEntry(p))
+	}
+	return doc, nil
+}
+
+func spdxEntry(p *spdxPackage) *sbomEntry {
+	entry := &sbomEntry{
+		ref:     p.SpdxId,
+		typ:     "generic",
+		name:    p.Name,
+		version: p.VersionInfo,
+		scope:   "required",
+	}
+	if supplier := spdxValue(p.Supplier); supplier != "" {
+		if _, name, ok := strings.Cut(supplier, ":"); ok {
+			supplier = strings.TrimSpace(name)
+		}
+		entry.supplier = supplier
+	}
+	if license := spdxValue(p.LicenseConcluded); license != "" {
+		entry.licenses = append(entry.licenses, license)
+	} else if license := spdxValue(p.LicenseDeclared); license != "" {
+		entry.licenses = append(entry.licenses, license)
+	}
+	for _, checksum := range p.Checksums {
+		if strings.EqualFold(checksum.Algorithm, "SHA256") {
+			entry.sha256 = strings.ToLower(checksum.ChecksumValue)
+		}
+	}
+	for _, ref := range p.ExternalRefs {
+		if ref.ReferenceType == "purl" {
+			applyPurl(entry, ref.ReferenceLocator)
+			break
+		}
+	}
+	return entry
+}
+
+// spdxValue drops the NOASSERTION and NONE placeholders
+func spdxValue(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "NOASSERTION" || value == "NONE" {
+		return ""
+	}
+	return value
+}
+
+// applyPurl takes the identity of a component from its package url, e.g. pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1
+func applyPurl(entry *sbomEntry, purl string) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(purl), "pkg:")
+	if !ok {
+		return
+	}
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest = rest[:i]
+	}
+	version := ""
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		version, _ = url.PathUnescape(rest[i+1:])
+		rest = rest[:i]
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	if len(parts) < 2 {
+		return
+	}
+	for i := range parts {
+		parts[i], _ = url.PathUnescape(parts[i])
+	}
+	entry.purl = strings.TrimSpace(purl)
+	entry.typ = strings.ToLower(parts[0])
+	entry.name = parts[len(parts)-1]
+	entry.namespace = strings.Join(parts[1:len(parts)-1], "/")
+	if version != "" {
+		entry.version = version
+	}
+}
+
+func normalizeSbomSeverity(severity string) string {
+	switch strings.ToUpper(severity) {
+	case "CRITICAL", "HIGH", "MEDIUM", "LOW", "INFO":
+		return strings.ToUpper(severity)
+	case "NONE":
+		return "INFO"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+func parseSbomTime(value string) *time.Time {
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(value)); err == nil {
+		return &t
+	}
+	return nil
+}
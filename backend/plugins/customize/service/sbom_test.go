@@ -0,0 +1,172 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/sbom"
+	"github.com/stretchr/testify/assert"
+)
+
+const cycloneDxBom = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "serialNumber": "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+  "metadata": {
+    "timestamp": "2025-08-01T10:00:00Z",
+    "tools": {"components": [{"name": "syft", "version": "1.0.0"}]},
+    "component": {"bom-ref": "app", "type": "application", "name": "app"}
+  },
+  "components": [
+    {
+      "bom-ref": "log4j",
+      "type": "library",
+      "group": "org.apache.logging.log4j",
+      "name": "log4j-core",
+      "version": "2.14.1",
+      "purl": "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1?type=jar",
+      "licenses": [{"license": {"id": "Apache-2.0"}}],
+      "hashes": [{"alg": "SHA-256", "content": "ABC"}],
+      "components": [
+        {"bom-ref": "log4j-api", "name": "log4j-api", "version": "2.14.1", "purl": "pkg:maven/org.apache.logging.log4j/log4j-api@2.14.1", "scope": "optional"}
+      ]
+    },
+    {"bom-ref": "log4j-again", "name": "log4j-core", "version": "2.14.1", "purl": "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"},
+    {"bom-ref": "vendored", "group": "acme", "name": "util", "version": "1.0", "licenses": [{"expression": "MIT OR Apache-2.0"}]}
+  ],
+  "dependencies": [
+    {"ref": "app", "dependsOn": ["log4j-again", "vendored"]},
+    {"ref": "log4j", "dependsOn": ["log4j-api"]}
+  ],
+  "vulnerabilities": [
+    {
+      "id": "CVE-2021-44228",
+      "source": {"name": "NVD", "url": "https://nvd.nist.gov/vuln/detail/CVE-2021-44228"},
+      "ratings": [{"score": 9.0, "severity": "high"}, {"score": 10.0, "severity": "critical"}],
+      "cwes": [502, 400],
+      "analysis": {"state": "exploitable"},
+      "affects": [{"ref": "urn:cdx:3e671687-395b-41f5-a30f-a58921a69b79/1#log4j"}, {"ref": "unknown"}]
+    }
+  ]
+}`
+
+const spdxDocument = `{
+  "spdxVersion": "SPDX-2.3",
+  "documentNamespace": "https://anchore.com/syft/image/app-1",
+  "creationInfo": {"created": "2025-08-01T10:00:00Z", "creators": ["Organization: Anchore", "Tool: syft-1.0.0"]},
+  "packages": [
+    {"SPDXID": "SPDXRef-image", "name": "app-image"},
+    {
+      "SPDXID": "SPDXRef-lodash",
+      "name": "lodash",
+      "versionInfo": "4.17.20",
+      "supplier": "Person: John-David Dalton",
+      "licenseConcluded": "NOASSERTION",
+      "licenseDeclared": "MIT",
+      "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/lodash@4.17.20"}],
+      "checksums": [{"algorithm": "SHA256", "checksumValue": "DEF"}]
+    },
+    {
+      "SPDXID": "SPDXRef-types-node",
+      "name": "@types/node",
+      "versionInfo": "20.0.0",
+      "externalRefs": [{"referenceCategory": "PACKAGE_MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/%40types/node@20.0.0"}]
+    }
+  ],
+  "relationships": [
+    {"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-image"},
+    {"spdxElementId": "SPDXRef-image", "relationshipType": "CONTAINS", "relatedSpdxElement": "SPDXRef-lodash"},
+    {"spdxElementId": "SPDXRef-lodash", "relationshipType": "DEPENDS_ON", "relatedSpdxElement": "SPDXRef-types-node"}
+  ]
+}`
+
+func TestConvertCycloneDx(t *testing.T) {
+	doc, err := parseSbom([]byte(cycloneDxBom))
+	assert.Nil(t, err)
+	assert.Equal(t, SBOM_CYCLONEDX, doc.format)
+	assert.Equal(t, "syft 1.0.0", doc.tool)
+	records := convertSbomDocument(sbom.TARGET_CICD_DEPLOYMENT, "deploy1", "", doc, []byte(cycloneDxBom))
+
+	assert.Equal(t, "sbom:SbomSnapshot:cicd_deployments:deploy1:urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79", records.snapshot.Id)
+	assert.Equal(t, "1.5", records.snapshot.SpecVersion)
+	assert.Equal(t, 3, records.snapshot.ComponentCount)
+	assert.Len(t, records.components, 3)
+	assert.Equal(t, "sbom:SbomComponent:maven/org.apache.logging.log4j/log4j-core", records.components[0].Id)
+	assert.Equal(t, "pkg:maven/org.apache.logging.log4j/log4j-core", records.components[0].Purl)
+	assert.Equal(t, "maven", records.components[0].Type)
+	assert.Equal(t, "sbom:SbomComponent:generic/acme/util", records.components[2].Id)
+	assert.Equal(t, "", records.components[2].Purl)
+
+	assert.Len(t, records.versions, 3)
+	log4j := records.versions[0]
+	assert.Equal(t, "sbom:SbomComponentVersion:maven/org.apache.logging.log4j/log4j-core@2.14.1", log4j.Id)
+	assert.Equal(t, "2.14.1", log4j.Version)
+	assert.Equal(t, "Apache-2.0", log4j.Licenses)
+	assert.Equal(t, "abc", log4j.Sha256)
+	assert.Equal(t, "MIT OR Apache-2.0", records.versions[2].Licenses)
+
+	// log4j-core is listed twice, once as a direct dependency
+	assert.Len(t, records.links, 3)
+	assert.True(t, records.links[0].IsDirect)
+	assert.False(t, records.links[1].IsDirect)
+	assert.Equal(t, "optional", records.links[1].Scope)
+	assert.True(t, records.links[2].IsDirect)
+
+	assert.Len(t, records.vulnerabilities, 1)
+	vulnerability := records.vulnerabilities[0]
+	assert.Equal(t, log4j.Id, vulnerability.ComponentVersionId)
+	assert.Equal(t, "CVE-2021-44228", vulnerability.VulnerabilityId)
+	assert.Equal(t, "CRITICAL", vulnerability.Severity)
+	assert.Equal(t, 10.0, *vulnerability.Score)
+	assert.Equal(t, "CWE-502,CWE-400", vulnerability.Cwes)
+	assert.Equal(t, "exploitable", vulnerability.AnalysisState)
+
+	// the commit identifies the snapshot of a repo
+	records = convertSbomDocument(sbom.TARGET_REPO, "repo1", "sha1", doc, []byte(cycloneDxBom))
+	assert.Equal(t, "sbom:SbomSnapshot:repos:repo1:sha1", records.snapshot.Id)
+}
+
+func TestConvertSpdx(t *testing.T) {
+	doc, err := parseSbom([]byte(spdxDocument))
+	assert.Nil(t, err)
+	assert.Equal(t, SBOM_SPDX, doc.format)
+	assert.Equal(t, "2.3", doc.specVersion)
+	assert.Equal(t, "syft-1.0.0", doc.tool)
+	records := convertSbomDocument(sbom.TARGET_REPO, "repo1", "", doc, []byte(spdxDocument))
+
+	// the described image is the root, not a component
+	assert.Len(t, records.versions, 2)
+	lodash := records.versions[0]
+	assert.Equal(t, "sbom:SbomComponentVersion:npm/lodash@4.17.20", lodash.Id)
+	assert.Equal(t, "MIT", lodash.Licenses)
+	assert.Equal(t, "John-David Dalton", lodash.Supplier)
+	assert.Equal(t, "def", lodash.Sha256)
+	assert.Equal(t, "sbom:SbomComponent:npm/@types/node", records.components[1].Id)
+	assert.Equal(t, "@types", records.components[1].Namespace)
+	assert.True(t, records.links[0].IsDirect)
+	assert.False(t, records.links[1].IsDirect)
+	assert.Empty(t, records.vulnerabilities)
+}
+
+func TestParseSbomUnknownFormat(t *testing.T) {
+	_, err := parseSbom([]byte(`{"foo": "bar"}`))
+	assert.NotNil(t, err)
+	_, err = parseSbom([]byte(`<bom/>`))
+	assert.NotNil(t, err)
+}
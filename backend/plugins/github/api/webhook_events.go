@@ -0,0 +1,378 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	coreModels "github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/github/models"
+	"github.com/apache/incubator-devlake/plugins/github/tasks"
+	graphqlTasks "github.com/apache/incubator-devlake/plugins/github_graphql/tasks"
+)
+
+const (
+	webhookEventHeader     = "X-GitHub-Event"
+	webhookSignatureHeader = "X-Hub-Signature-256"
+	webhookRefreshDelay    = 10 * time.Second
+)
+
+var webhookRefresher *api.WebhookRefresher
+
+// WebhookEventResult tells what was done with a webhook event
+type WebhookEventResult struct {
+	Event    string `json:"event"`
+	Accepted bool   `json:"accepted"`
+	Message  string `json:"message,omitempty"`
+}
+
+// githubWebhookRecord is a webhook event turned into a row of the raw table a collector would have written
+type githubWebhookRecord struct {
+	repoFullName string
+	table        string
+	url          string
+	data         []byte
+	subtasks     []string
+}
+
+type githubWebhookRepository struct {
+	NodeId   string `json:"node_id"`
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	HtmlUrl  string `json:"html_url"`
+}
+
+type githubWebhookUser struct {
+	Id        int    `json:"id"`
+	Login     string `json:"login"`
+	AvatarUrl string `json:"avatar_url"`
+	HtmlUrl   string `json:"html_url"`
+}
+
+type githubWebhookPullRequest struct {
+	Id        int                 `json:"id"`
+	Number    int                 `json:"number"`
+	State     string              `json:"state"`
+	Title     string              `json:"title"`
+	Body      string              `json:"body"`
+	Url       string              `json:"url"`
+	HtmlUrl   string              `json:"html_url"`
+	Draft     bool                `json:"draft"`
+	User      *githubWebhookUser  `json:"user"`
+	Assignees []githubWebhookUser `json:"assignees"`
+	Labels    []struct {
+		NodeId string `json:"node_id"`
+		Name   string `json:"name"`
+	} `json:"labels"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+	ClosedAt       *time.Time         `json:"closed_at"`
+	MergedAt       *time.Time         `json:"merged_at"`
+	Merged         bool               `json:"merged"`
+	MergedBy       *githubWebhookUser `json:"merged_by"`
+	MergeCommitSha string             `json:"merge_commit_sha"`
+	Head           struct {
+		Ref string `json:"ref"`
+		Sha string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+		Sha string `json:"sha"`
+	} `json:"base"`
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+}
+
+type githubWebhookEvent struct {
+	Action      string                  `json:"action"`
+	Repository  githubWebhookRepository `json:"repository"`
+	PullRequest json.RawMessage         `json:"pull_request"`
+	WorkflowRun json.RawMessage         `json:"workflow_run"`
+	Deployment  *struct {
+		NodeId      string          `json:"node_id"`
+		Id          uint            `json:"id"`
+		Url         string          `json:"url"`
+		Sha         string          `json:"sha"`
+		Ref         string          `json:"ref"`
+		Task        string          `json:"task"`
+		Environment string          `json:"environment"`
+		Description string          `json:"description"`
+		Payload     json.RawMessage `json:"payload"`
+		CreatedAt   time.Time       `json:"created_at"`
+		UpdatedAt   time.Time       `json:"updated_at"`
+	} `json:"deployment"`
+	DeploymentStatus *struct {
+		NodeId    string    `json:"node_id"`
+		State     string    `json:"state"`
+		UpdatedAt time.Time `json:"updated_at"`
+	} `json:"deployment_status"`
+}
+
+// PostWebhookEvents receives the native webhook events of GitHub
+// @Summary receive github webhook events
+// @Description Keeps pull requests, workflow runs and deployments of the repos in scope fresh between full syncs.
+// @Description Configure a repo or org webhook with content type application/json, pointing to this endpoint and
+// @Description signed with the webhookSecret of the connection. pull_request, workflow_run and deployment_status
+// @Description events are stored into the raw tables and extracted/converted by a pipeline queued shortly after, other events are ignored.
+// @Tags plugins/github
+// @Param connectionId path int true "connection ID"
+// @Param X-GitHub-Event header string true "event name"
+// @Param X-Hub-Signature-256 header string true "HMAC-SHA256 signature of the payload"
+// @Success 200  {object} WebhookEventResult
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 401  {string} errcode.Error "Invalid Signature"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/github/connections/{connectionId}/webhook-events [POST]
+func PostWebhookEvents(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection, err := dsHelper.ConnApi.FindByPk(input)
+	if err != nil {
+		return nil, err
+	}
+	if input.Request == nil || input.Request.Body == nil {
+		return nil, errors.BadInput.New("empty body")
+	}
+	payload, readErr := io.ReadAll(input.Request.Body)
+	if readErr != nil {
+		return nil, errors.BadInput.Wrap(readErr, "failed to read the payload")
+	}
+	if connection.WebhookSecret == "" {
+		return nil, errors.Forbidden.New("webhookSecret of the connection is not set")
+	}
+	if !api.VerifyHmacSha256Signature(connection.WebhookSecret, payload, input.Request.Header.Get(webhookSignatureHeader)) {
+		return nil, errors.Unauthorized.New("invalid webhook signature")
+	}
+	event := input.Request.Header.Get(webhookEventHeader)
+	result := &WebhookEventResult{Event: event}
+	record, err := parseWebhookEvent(event, payload, connection.EnableGraphql)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		result.Message = "event ignored"
+		return &plugin.ApiResourceOutput{Body: result, Status: http.StatusOK}, nil
+	}
+	db := basicRes.GetDal()
+	repo := &models.GithubRepo{}
+	err = db.First(repo, dal.Where("connection_id = ? AND full_name = ?", connection.ID, record.repoFullName))
+	if db.IsErrorNotFound(err) {
+		result.Message = fmt.Sprintf("repo %s is not in scope", record.repoFullName)
+		return &plugin.ApiResourceOutput{Body: result, Status: http.StatusOK}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	params := models.GithubApiParams{ConnectionId: connection.ID, Name: repo.FullName}
+	err = api.SaveWebhookRawData(db, record.table, params, record.url, record.data)
+	if err != nil {
+		return nil, err
+	}
+	// the pipelines of the repo run the graphql plugin when it is enabled, the webhook one has to run the same
+	pluginName := "github"
+	if connection.EnableGraphql {
+		pluginName = "github_graphql"
+	}
+	webhookRefresher.Schedule(fmt.Sprintf("%d:%s", connection.ID, repo.FullName), &coreModels.PipelineTask{
+		Plugin:   pluginName,
+		Subtasks: record.subtasks,
+		Options: map[string]interface{}{
+			"connectionId":  connection.ID,
+			"githubId":      repo.GithubId,
+			"name":          repo.FullName,
+			"fullName":      repo.FullName,
+			"scopeConfigId": repo.ScopeConfigId,
+		},
+	})
+	result.Accepted = true
+	return &plugin.ApiResourceOutput{Body: result, Status: http.StatusOK}, nil
+}
+
+// parseWebhookEvent maps the supported events to rows of the raw tables of the collectors in use, which depend on
+// whether the connection enables graphql, nil is returned for the other events
+func parseWebhookEvent(event string, payload []byte, enableGraphql bool) (*githubWebhookRecord, errors.Error) {
+	body := &githubWebhookEvent{}
+	if err := json.Unmarshal(payload, body); err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid webhook payload")
+	}
+	record := &githubWebhookRecord{repoFullName: body.Repository.FullName}
+	switch event {
+	case "pull_request":
+		if len(body.PullRequest) == 0 {
+			return nil, errors.BadInput.New("pull_request is missing from the payload")
+		}
+		pr := &githubWebhookPullRequest{}
+		if err := json.Unmarshal(body.PullRequest, pr); err != nil {
+			return nil, errors.BadInput.Wrap(err, "invalid pull_request")
+		}
+		record.url = pr.Url
+		if enableGraphql {
+			data, err := errors.Convert01(json.Marshal(toGraphqlPr(pr)))
+			if err != nil {
+				return nil, err
+			}
+			record.table = graphqlTasks.RAW_PRS_TABLE
+			record.data = data
+			record.subtasks = []string{graphqlTasks.ExtractPrsMeta.Name, tasks.ConvertPullRequestsMeta.Name}
+		} else {
+			record.table = tasks.RAW_PULL_REQUEST_TABLE
+			record.data = body.PullRequest
+			record.subtasks = []string{tasks.ExtractApiPullRequestsMeta.Name, tasks.ConvertPullRequestsMeta.Name}
+		}
+	case "workflow_run":
+		if len(body.WorkflowRun) == 0 {
+			return nil, errors.BadInput.New("workflow_run is missing from the payload")
+		}
+		var run struct {
+			Url string `json:"url"`
+		}
+		_ = json.Unmarshal(body.WorkflowRun, &run)
+		// workflow runs are collected from the rest api by both plugins
+		record.table = tasks.RAW_RUN_TABLE
+		record.url = run.Url
+		record.data = body.WorkflowRun
+		record.subtasks = []string{tasks.ExtractRunsMeta.Name, tasks.ConvertRunsMeta.Name}
+	case "deployment_status":
+		// deployments are only collected by the graphql plugin
+		if !enableGraphql {
+			return nil, nil
+		}
+		if body.Deployment == nil || body.DeploymentStatus == nil {
+			return nil, errors.BadInput.New("deployment or deployment_status is missing from the payload")
+		}
+		data, err := errors.Convert01(json.Marshal(toGraphqlDeployment(body)))
+		if err != nil {
+			return nil, err
+		}
+		record.table = graphqlTasks.RAW_DEPLOYMENT
+		record.url = body.Deployment.Url
+		record.data = data
+		record.subtasks = []string{graphqlTasks.ExtractDeploymentsMeta.Name, graphqlTasks.ConvertDeploymentsMeta.Name}
+	default:
+		return nil, nil
+	}
+	if record.repoFullName == "" {
+		return nil, errors.BadInput.New("repository is missing from the payload")
+	}
+	return record, nil
+}
+
+// toGraphqlPr reshapes the rest flavored pull request of the event into the node the graphql collector stores, the
+// commits and reviews aren't part of the event, they stay as extracted from the rows collected before
+func toGraphqlPr(pr *githubWebhookPullRequest) *graphqlTasks.GraphqlQueryPr {
+	node := &graphqlTasks.GraphqlQueryPr{
+		DatabaseId:  pr.Id,
+		Number:      pr.Number,
+		State:       strings.ToUpper(pr.State),
+		Title:       pr.Title,
+		IsDraft:     pr.Draft,
+		Body:        pr.Body,
+		Url:         pr.HtmlUrl,
+		Author:      toGraphqlAccount(pr.User),
+		ClosedAt:    pr.ClosedAt,
+		MergedAt:    pr.MergedAt,
+		UpdatedAt:   pr.UpdatedAt,
+		CreatedAt:   pr.CreatedAt,
+		HeadRefName: pr.Head.Ref,
+		HeadRefOid:  pr.Head.Sha,
+		BaseRefName: pr.Base.Ref,
+		BaseRefOid:  pr.Base.Sha,
+		Additions:   pr.Additions,
+		Deletions:   pr.Deletions,
+		MergedBy:    toGraphqlAccount(pr.MergedBy),
+	}
+	// the rest api reports merged pull requests as closed, and a test merge commit for the open ones
+	if pr.Merged {
+		node.State = "MERGED"
+		node.MergeCommit = &struct {
+			Oid string
+		}{Oid: pr.MergeCommitSha}
+	}
+	for _, label := range pr.Labels {
+		node.Labels.Nodes = append(node.Labels.Nodes, struct {
+			Id   string
+			Name string
+		}{Id: label.NodeId, Name: label.Name})
+	}
+	for i := range pr.Assignees {
+		node.Assignees.Assignees = append(node.Assignees.Assignees, *toGraphqlAccount(&pr.Assignees[i]))
+	}
+	return node
+}
+
+func toGraphqlAccount(user *githubWebhookUser) *graphqlTasks.GraphqlInlineAccountQuery {
+	if user == nil {
+		return nil
+	}
+	return &graphqlTasks.GraphqlInlineAccountQuery{
+		GithubAccountEdge: graphqlTasks.GithubAccountEdge{
+			Login:     user.Login,
+			Id:        user.Id,
+			AvatarUrl: user.AvatarUrl,
+			HtmlUrl:   user.HtmlUrl,
+		},
+	}
+}
+
+// toGraphqlDeployment reshapes the REST flavored deployment of the event into the node the graphql collector stores
+func toGraphqlDeployment(body *githubWebhookEvent) *graphqlTasks.GraphqlQueryDeploymentDeployment {
+	deployment, status := body.Deployment, body.DeploymentStatus
+	node := &graphqlTasks.GraphqlQueryDeploymentDeployment{
+		Task:        deployment.Task,
+		Id:          deployment.NodeId,
+		CommitOid:   deployment.Sha,
+		Environment: deployment.Environment,
+		State:       strings.ToUpper(status.State),
+		DatabaseId:  deployment.Id,
+		Description: deployment.Description,
+		CreatedAt:   deployment.CreatedAt,
+		UpdatedAt:   deployment.UpdatedAt,
+	}
+	// a successful deployment is reported as ACTIVE by the graphql api
+	if node.State == tasks.StatusSuccess {
+		node.State = tasks.StatusActive
+	}
+	if status.UpdatedAt.After(node.UpdatedAt) {
+		node.UpdatedAt = status.UpdatedAt
+	}
+	if len(deployment.Payload) > 0 && string(deployment.Payload) != "null" {
+		node.Payload = string(deployment.Payload)
+	}
+	if deployment.Ref != "" && deployment.Ref != deployment.Sha {
+		node.Ref = &struct {
+			ID     string `graphql:"id"`
+			Name   string `graphql:"name"`
+			Prefix string `graphql:"prefix"`
+		}{Name: deployment.Ref}
+	}
+	node.LatestStatus.Id = status.NodeId
+	node.LatestStatus.State = strings.ToUpper(status.State)
+	node.LatestStatus.UpdatedAt = &status.UpdatedAt
+	node.Repository.Id = body.Repository.NodeId
+	node.Repository.Name = body.Repository.Name
+	node.Repository.Url = body.Repository.HtmlUrl
+	node.Commit.Oid = deployment.Sha
+	return node
+}
@@ -0,0 +1,117 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/apache/incubator-devlake/plugins/github/tasks"
+	graphqlTasks "github.com/apache/incubator-devlake/plugins/github_graphql/tasks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWebhookEvent(t *testing.T) {
+	record, err := parseWebhookEvent("pull_request", []byte(`{
+		"action": "opened",
+		"pull_request": {"id": 1, "url": "https://api.github.com/repos/apache/devlake/pulls/1"},
+		"repository": {"full_name": "apache/devlake"}
+	}`), false)
+	assert.Nil(t, err)
+	assert.Equal(t, "apache/devlake", record.repoFullName)
+	assert.Equal(t, tasks.RAW_PULL_REQUEST_TABLE, record.table)
+	assert.Equal(t, "https://api.github.com/repos/apache/devlake/pulls/1", record.url)
+	assert.JSONEq(t, `{"id": 1, "url": "https://api.github.com/repos/apache/devlake/pulls/1"}`, string(record.data))
+	assert.Equal(t, []string{tasks.ExtractApiPullRequestsMeta.Name, tasks.ConvertPullRequestsMeta.Name}, record.subtasks)
+
+	record, err = parseWebhookEvent("workflow_run", []byte(`{"workflow_run": {"id": 2}, "repository": {"full_name": "apache/devlake"}}`), true)
+	assert.Nil(t, err)
+	assert.Equal(t, tasks.RAW_RUN_TABLE, record.table)
+
+	record, err = parseWebhookEvent("ping", []byte(`{"zen": "Keep it logically awesome."}`), true)
+	assert.Nil(t, err)
+	assert.Nil(t, record)
+
+	_, err = parseWebhookEvent("pull_request", []byte(`{"repository": {"full_name": "apache/devlake"}}`), true)
+	assert.NotNil(t, err)
+	_, err = parseWebhookEvent("workflow_run", []byte(`{"workflow_run": {"id": 2}}`), true)
+	assert.NotNil(t, err)
+}
+
+func TestParseWebhookDeploymentStatus(t *testing.T) {
+	record, err := parseWebhookEvent("deployment_status", []byte(`{
+		"action": "created",
+		"deployment_status": {"node_id": "DES_1", "state": "success", "updated_at": "2025-09-01T10:05:00Z"},
+		"deployment": {
+			"node_id": "DE_1", "id": 42, "sha": "abc", "ref": "main", "task": "deploy", "environment": "prod",
+			"payload": {"version": "1.0"}, "created_at": "2025-09-01T10:00:00Z", "updated_at": "2025-09-01T10:00:00Z"
+		},
+		"repository": {"node_id": "R_1", "name": "devlake", "full_name": "apache/devlake", "html_url": "https://github.com/apache/devlake"}
+	}`), true)
+	assert.Nil(t, err)
+	assert.Equal(t, graphqlTasks.RAW_DEPLOYMENT, record.table)
+	deployment := &graphqlTasks.GraphqlQueryDeploymentDeployment{}
+	assert.Nil(t, json.Unmarshal(record.data, deployment))
+	assert.Equal(t, "DE_1", deployment.Id)
+	assert.Equal(t, uint(42), deployment.DatabaseId)
+	assert.Equal(t, tasks.StatusActive, deployment.State)
+	assert.Equal(t, tasks.StatusSuccess, deployment.LatestStatus.State)
+	assert.Equal(t, "main", deployment.Ref.Name)
+	assert.Equal(t, `{"version": "1.0"}`, deployment.Payload)
+	assert.Equal(t, "2025-09-01T10:05:00Z", deployment.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"))
+	assert.Equal(t, "https://github.com/apache/devlake", deployment.Repository.Url)
+	assert.Equal(t, "abc", deployment.Commit.Oid)
+}
+
+func TestParseWebhookGraphqlPullRequest(t *testing.T) {
+	payload := []byte(`{
+		"action": "closed",
+		"pull_request": {
+			"id": 7, "number": 3, "state": "closed", "title": "fix", "draft": false,
+			"url": "https://api.github.com/repos/apache/devlake/pulls/3", "html_url": "https://github.com/apache/devlake/pull/3",
+			"user": {"id": 11, "login": "alice"}, "merged_by": {"id": 12, "login": "bob"},
+			"labels": [{"node_id": "LA_1", "name": "bug"}],
+			"created_at": "2025-09-01T10:00:00Z", "updated_at": "2025-09-01T11:00:00Z",
+			"closed_at": "2025-09-01T11:00:00Z", "merged_at": "2025-09-01T11:00:00Z",
+			"merged": true, "merge_commit_sha": "m1",
+			"head": {"ref": "fix", "sha": "h1"}, "base": {"ref": "main", "sha": "b1"},
+			"additions": 5, "deletions": 2
+		},
+		"repository": {"full_name": "apache/devlake"}
+	}`)
+	record, err := parseWebhookEvent("pull_request", payload, true)
+	assert.Nil(t, err)
+	assert.Equal(t, graphqlTasks.RAW_PRS_TABLE, record.table)
+	assert.Equal(t, []string{graphqlTasks.ExtractPrsMeta.Name, tasks.ConvertPullRequestsMeta.Name}, record.subtasks)
+	pr := &graphqlTasks.GraphqlQueryPr{}
+	assert.Nil(t, json.Unmarshal(record.data, pr))
+	assert.Equal(t, 7, pr.DatabaseId)
+	assert.Equal(t, "MERGED", pr.State)
+	assert.Equal(t, "https://github.com/apache/devlake/pull/3", pr.Url)
+	assert.Equal(t, "m1", pr.MergeCommit.Oid)
+	assert.Equal(t, "h1", pr.HeadRefOid)
+	assert.Equal(t, "main", pr.BaseRefName)
+	assert.Equal(t, "alice", pr.Author.Login)
+	assert.Equal(t, 12, pr.MergedBy.Id)
+	assert.Equal(t, "bug", pr.Labels.Nodes[0].Name)
+
+	// deployments are only collected by the graphql plugin
+	record, err = parseWebhookEvent("deployment_status", []byte(`{"repository": {"full_name": "apache/devlake"}}`), false)
+	assert.Nil(t, err)
+	assert.Nil(t, record)
+}
@@ -99,6 +99,8 @@ type GithubConnection struct {
 	helper.BaseConnection `mapstructure:",squash"`
 	GithubConn            `mapstructure:",squash"`
 	EnableGraphql         bool `mapstructure:"enableGraphql" json:"enableGraphql"`
+	// WebhookSecret signs the payloads GitHub posts to the webhook-events endpoint of the connection
+	WebhookSecret string `mapstructure:"webhookSecret" json:"webhookSecret" gorm:"serializer:encdec"`
 }
 
 const (
@@ -134,6 +136,10 @@ func (connection *GithubConnection) Merge(existed, modified *GithubConnection, b
 	if _, ok := body["enableGraphql"]; ok {
 		existed.EnableGraphql = modified.EnableGraphql
 	}
+	// the webhook secret is only returned sanitized, keep it unless a different one is sent
+	if _, ok := body["webhookSecret"]; ok && modified.WebhookSecret != utils.SanitizeString(existed.WebhookSecret) {
+		existed.WebhookSecret = modified.WebhookSecret
+	}
 	existed.AppId = modified.AppId
 	existed.SecretKey = modified.SecretKey
 	existed.InstallationID = modified.InstallationID
@@ -266,6 +272,7 @@ func (conn *GithubConn) typeIs(token string) string {
 
 func (connection GithubConnection) Sanitize() GithubConnection {
 	connection.GithubConn = connection.GithubConn.Sanitize()
+	connection.WebhookSecret = utils.SanitizeString(connection.WebhookSecret)
 	return connection
 }
 
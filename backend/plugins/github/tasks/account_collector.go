@@ -80,6 +80,8 @@ func CollectAccounts(taskCtx plugin.SubTaskContext) errors.Error {
 		ApiClient:   data.ApiClient,
 		Input:       iterator,
 		UrlTemplate: "/users/{{ .Input.Login }}",
+		// user profiles rarely change, and 304 responses don't count against the rate limit
+		ConditionalRequests: true,
 		ResponseParser: func(res *http.Response) ([]json.RawMessage, errors.Error) {
 			body, err := io.ReadAll(res.Body)
 			if err != nil {
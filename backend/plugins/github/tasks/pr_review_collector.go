@@ -93,6 +93,8 @@ func CollectApiPullRequestReviews(taskCtx plugin.SubTaskContext) errors.Error {
 		ApiClient: data.ApiClient,
 		PageSize:  100,
 		Input:     iterator,
+		// reviews of a pull request failed to be fetched would be re-collected by the next run
+		SkipFailedPages: true,
 
 		UrlTemplate: "repos/{{ .Params.Name }}/pulls/{{ .Input.Number }}/reviews",
 
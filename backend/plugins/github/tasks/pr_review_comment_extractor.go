@@ -116,6 +116,8 @@ func ExtractApiPrReviewComments(taskCtx plugin.SubTaskContext) errors.Error {
 			results = append(results, githubPrComment)
 			return results, nil
 		},
+		// review comments of large repos take long to extract, Extract shares nothing but the dal so it is safe to run in parallel
+		Workers: 4,
 	})
 
 	if err != nil {
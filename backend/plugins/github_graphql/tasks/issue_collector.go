@@ -112,6 +112,8 @@ func CollectIssues(taskCtx plugin.SubTaskContext) errors.Error {
 	err = apiCollector.InitGraphQLCollector(api.GraphqlCollectorArgs{
 		GraphqlClient: data.GraphqlClient,
 		PageSize:      10,
+		// resume from the last cursor if the previous collection crashed
+		CheckpointInterval: 20,
 		BuildQuery: func(reqData *api.GraphqlRequestData) (interface{}, map[string]interface{}, error) {
 			query := &GraphqlQueryIssueWrapper{}
 			if reqData == nil {
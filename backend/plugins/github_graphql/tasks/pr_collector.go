@@ -186,6 +186,8 @@ func CollectPrs(taskCtx plugin.SubTaskContext) errors.Error {
 	err = apiCollector.InitGraphQLCollector(api.GraphqlCollectorArgs{
 		GraphqlClient: data.GraphqlClient,
 		PageSize:      10,
+		// resume from the last cursor if the previous collection crashed
+		CheckpointInterval: 20,
 		/*
 			(Optional) Return query string for request, or you can plug them into UrlTemplate directly
 		*/
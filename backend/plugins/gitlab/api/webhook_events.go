@@ -0,0 +1,348 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	coreModels "github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/gitlab/models"
+	"github.com/apache/incubator-devlake/plugins/gitlab/tasks"
+)
+
+const (
+	webhookEventHeader  = "X-Gitlab-Event"
+	webhookTokenHeader  = "X-Gitlab-Token"
+	webhookRefreshDelay = 10 * time.Second
+)
+
+var webhookRefresher *api.WebhookRefresher
+
+// webhooks format times like `2025-09-01 10:00:00 UTC` which the extractors don't understand
+var webhookTimePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2} (UTC|[+-]\d{4})$`)
+
+// WebhookEventResult tells what was done with a webhook event
+type WebhookEventResult struct {
+	Event    string `json:"event"`
+	Accepted bool   `json:"accepted"`
+	Message  string `json:"message,omitempty"`
+}
+
+// gitlabWebhookRecord is a row for the raw table a collector would have written
+type gitlabWebhookRecord struct {
+	table string
+	url   string
+	data  []byte
+}
+
+// gitlabWebhookEvents are the raw records of an event, in the REST shape the collectors store
+type gitlabWebhookEvents struct {
+	projectId int
+	records   []*gitlabWebhookRecord
+	subtasks  []string
+}
+
+type gitlabWebhookUser struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	Username  string `json:"username"`
+	State     string `json:"state,omitempty"`
+	AvatarUrl string `json:"avatar_url"`
+	WebUrl    string `json:"web_url,omitempty"`
+}
+
+type gitlabWebhookEvent struct {
+	ObjectKind string            `json:"object_kind"`
+	User       gitlabWebhookUser `json:"user"`
+	Project    struct {
+		Id     int    `json:"id"`
+		WebUrl string `json:"web_url"`
+	} `json:"project"`
+	ObjectAttributes json.RawMessage     `json:"object_attributes"`
+	Assignees        []gitlabWebhookUser `json:"assignees"`
+	Reviewers        []gitlabWebhookUser `json:"reviewers"`
+}
+
+type gitlabWebhookMergeRequest struct {
+	Id              int    `json:"id"`
+	Iid             int    `json:"iid"`
+	SourceProjectId int    `json:"source_project_id"`
+	TargetProjectId int    `json:"target_project_id"`
+	AuthorId        int    `json:"author_id"`
+	State           string `json:"state"`
+	Action          string `json:"action"`
+	Title           string `json:"title"`
+	Description     string `json:"description"`
+	Url             string `json:"url"`
+	SourceBranch    string `json:"source_branch"`
+	TargetBranch    string `json:"target_branch"`
+	WorkInProgress  bool   `json:"work_in_progress"`
+	Draft           bool   `json:"draft"`
+	MergeCommitSha  string `json:"merge_commit_sha"`
+	CreatedAt       string `json:"created_at"`
+	UpdatedAt       string `json:"updated_at"`
+	LastCommit      struct {
+		Id string `json:"id"`
+	} `json:"last_commit"`
+	Labels []struct {
+		Title string `json:"title"`
+	} `json:"labels"`
+}
+
+type gitlabWebhookPipeline struct {
+	Id             int      `json:"id"`
+	Ref            string   `json:"ref"`
+	Tag            bool     `json:"tag"`
+	Sha            string   `json:"sha"`
+	Source         string   `json:"source"`
+	Status         string   `json:"status"`
+	Url            string   `json:"url"`
+	Duration       *int     `json:"duration"`
+	QueuedDuration *float64 `json:"queued_duration"`
+	CreatedAt      string   `json:"created_at"`
+	FinishedAt     string   `json:"finished_at"`
+}
+
+// PostWebhookEvents receives the native webhook events of GitLab
+// @Summary receive gitlab webhook events
+// @Description Keeps merge requests and pipelines of the projects in scope fresh between full syncs.
+// @Description Configure a project or group webhook pointing to this endpoint with the webhookSecret of the connection
+// @Description as its secret token. Merge request and pipeline events are stored into the raw tables and
+// @Description extracted/converted by a pipeline queued shortly after, other events are ignored.
+// @Tags plugins/gitlab
+// @Param connectionId path int true "connection ID"
+// @Param X-Gitlab-Event header string true "event name"
+// @Param X-Gitlab-Token header string true "secret token"
+// @Success 200  {object} WebhookEventResult
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 401  {string} errcode.Error "Invalid Token"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/gitlab/connections/{connectionId}/webhook-events [POST]
+func PostWebhookEvents(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection, err := dsHelper.ConnApi.FindByPk(input)
+	if err != nil {
+		return nil, err
+	}
+	if input.Request == nil || input.Request.Body == nil {
+		return nil, errors.BadInput.New("empty body")
+	}
+	if connection.WebhookSecret == "" {
+		return nil, errors.Forbidden.New("webhookSecret of the connection is not set")
+	}
+	if !api.VerifySecretToken(connection.WebhookSecret, input.Request.Header.Get(webhookTokenHeader)) {
+		return nil, errors.Unauthorized.New("invalid webhook token")
+	}
+	payload, readErr := io.ReadAll(input.Request.Body)
+	if readErr != nil {
+		return nil, errors.BadInput.Wrap(readErr, "failed to read the payload")
+	}
+	event := input.Request.Header.Get(webhookEventHeader)
+	result := &WebhookEventResult{Event: event}
+	events, err := parseWebhookEvent(event, payload)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		result.Message = "event ignored"
+		return &plugin.ApiResourceOutput{Body: result, Status: http.StatusOK}, nil
+	}
+	db := basicRes.GetDal()
+	project := &models.GitlabProject{}
+	err = db.First(project, dal.Where("connection_id = ? AND gitlab_id = ?", connection.ID, events.projectId))
+	if db.IsErrorNotFound(err) {
+		result.Message = fmt.Sprintf("project %d is not in scope", events.projectId)
+		return &plugin.ApiResourceOutput{Body: result, Status: http.StatusOK}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	params := models.GitlabApiParams{ConnectionId: connection.ID, ProjectId: project.GitlabId}
+	for _, record := range events.records {
+		err = api.SaveWebhookRawData(db, record.table, params, record.url, record.data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	webhookRefresher.Schedule(fmt.Sprintf("%d:%d", connection.ID, project.GitlabId), &coreModels.PipelineTask{
+		Plugin:   "gitlab",
+		Subtasks: events.subtasks,
+		Options: map[string]interface{}{
+			"connectionId":  connection.ID,
+			"projectId":     project.GitlabId,
+			"fullName":      project.PathWithNamespace,
+			"scopeConfigId": project.ScopeConfigId,
+		},
+	})
+	result.Accepted = true
+	return &plugin.ApiResourceOutput{Body: result, Status: http.StatusOK}, nil
+}
+
+// parseWebhookEvent maps merge request and pipeline events to raw records, nil is returned for the others
+func parseWebhookEvent(event string, payload []byte) (*gitlabWebhookEvents, errors.Error) {
+	body := &gitlabWebhookEvent{}
+	if err := json.Unmarshal(payload, body); err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid webhook payload")
+	}
+	if body.Project.Id == 0 || len(body.ObjectAttributes) == 0 {
+		if event == "Merge Request Hook" || event == "Pipeline Hook" {
+			return nil, errors.BadInput.New("project or object_attributes is missing from the payload")
+		}
+		return nil, nil
+	}
+	events := &gitlabWebhookEvents{projectId: body.Project.Id}
+	var err errors.Error
+	switch {
+	case event == "Merge Request Hook" || (event == "" && body.ObjectKind == "merge_request"):
+		var data []byte
+		mr := &gitlabWebhookMergeRequest{}
+		if err = errors.Convert(json.Unmarshal(body.ObjectAttributes, mr)); err != nil {
+			return nil, errors.BadInput.Wrap(err, "invalid object_attributes")
+		}
+		if data, err = toApiMergeRequest(body, mr); err != nil {
+			return nil, err
+		}
+		events.records = []*gitlabWebhookRecord{{table: tasks.RAW_MERGE_REQUEST_TABLE, url: mr.Url, data: data}}
+		events.subtasks = []string{tasks.ExtractApiMergeRequestsMeta.Name, tasks.ConvertApiMergeRequestsMeta.Name}
+	case event == "Pipeline Hook" || (event == "" && body.ObjectKind == "pipeline"):
+		var data []byte
+		pipeline := &gitlabWebhookPipeline{}
+		if err = errors.Convert(json.Unmarshal(body.ObjectAttributes, pipeline)); err != nil {
+			return nil, errors.BadInput.Wrap(err, "invalid object_attributes")
+		}
+		if pipeline.Url == "" && body.Project.WebUrl != "" {
+			pipeline.Url = fmt.Sprintf("%s/-/pipelines/%d", body.Project.WebUrl, pipeline.Id)
+		}
+		if data, err = toApiPipeline(pipeline); err != nil {
+			return nil, err
+		}
+		// the pipeline list and the pipeline details are collected into two tables, both carry the same fields
+		events.records = []*gitlabWebhookRecord{
+			{table: tasks.RAW_PIPELINE_TABLE, url: pipeline.Url, data: data},
+			{table: tasks.RAW_PIPELINE_DETAILS_TABLE, url: pipeline.Url, data: data},
+		}
+		events.subtasks = []string{
+			tasks.ExtractApiPipelinesMeta.Name,
+			tasks.ExtractApiPipelineDetailsMeta.Name,
+			tasks.ConvertDetailPipelineMeta.Name,
+			tasks.ConvertPipelineCommitMeta.Name,
+		}
+	default:
+		return nil, nil
+	}
+	return events, nil
+}
+
+func toApiMergeRequest(body *gitlabWebhookEvent, mr *gitlabWebhookMergeRequest) ([]byte, errors.Error) {
+	labels := make([]string, len(mr.Labels))
+	for i, label := range mr.Labels {
+		labels[i] = label.Title
+	}
+	author := map[string]interface{}{"id": mr.AuthorId}
+	if body.User.Id == mr.AuthorId {
+		author["username"] = body.User.Username
+	}
+	apiMr := map[string]interface{}{
+		"id":                mr.Id,
+		"iid":               mr.Iid,
+		"project_id":        mr.TargetProjectId,
+		"source_project_id": mr.SourceProjectId,
+		"target_project_id": mr.TargetProjectId,
+		"state":             mr.State,
+		"title":             mr.Title,
+		"description":       mr.Description,
+		"web_url":           mr.Url,
+		"work_in_progress":  mr.WorkInProgress || mr.Draft,
+		"source_branch":     mr.SourceBranch,
+		"target_branch":     mr.TargetBranch,
+		"created_at":        normalizeWebhookTime(mr.CreatedAt),
+		"updated_at":        normalizeWebhookTime(mr.UpdatedAt),
+		"merge_commit_sha":  mr.MergeCommitSha,
+		"sha":               mr.LastCommit.Id,
+		"author":            author,
+		"labels":            labels,
+		"assignees":         body.Assignees,
+		"reviewers":         body.Reviewers,
+	}
+	// the events don't carry merged_at/closed_at, only the merge and close actions tell when it happened,
+	// otherwise the keys are left out so the extractor flags the merge request for a detail collection
+	switch mr.Action {
+	case "merge":
+		apiMr["merged_at"] = apiMr["updated_at"]
+		apiMr["merged_by"] = map[string]interface{}{"username": body.User.Username}
+	case "close":
+		apiMr["closed_at"] = apiMr["updated_at"]
+	}
+	return errors.Convert01(json.Marshal(apiMr))
+}
+
+func toApiPipeline(pipeline *gitlabWebhookPipeline) ([]byte, errors.Error) {
+	createdAt := normalizeWebhookTime(pipeline.CreatedAt)
+	finishedAt := normalizeWebhookTime(pipeline.FinishedAt)
+	apiPipeline := map[string]interface{}{
+		"id":              pipeline.Id,
+		"ref":             pipeline.Ref,
+		"sha":             pipeline.Sha,
+		"status":          pipeline.Status,
+		"tag":             pipeline.Tag,
+		"source":          pipeline.Source,
+		"web_url":         pipeline.Url,
+		"queued_duration": pipeline.QueuedDuration,
+		"created_at":      createdAt,
+		"updated_at":      createdAt,
+	}
+	if finishedAt != nil {
+		apiPipeline["finished_at"] = finishedAt
+		apiPipeline["updated_at"] = finishedAt
+	}
+	if pipeline.Duration != nil {
+		apiPipeline["duration"] = *pipeline.Duration
+		// started_at is not part of the event, it is what the duration leaves before the end
+		if finishedAt != nil {
+			if finished, err := time.Parse(time.RFC3339, *finishedAt); err == nil {
+				apiPipeline["started_at"] = finished.Add(-time.Duration(*pipeline.Duration) * time.Second).Format(time.RFC3339)
+			}
+		}
+	}
+	return errors.Convert01(json.Marshal(apiPipeline))
+}
+
+// normalizeWebhookTime converts the webhook time format to RFC3339, nil is returned for empty values
+func normalizeWebhookTime(value string) *string {
+	if value == "" {
+		return nil
+	}
+	if webhookTimePattern.MatchString(value) {
+		layout := "2006-01-02 15:04:05 -0700"
+		if value[len(value)-3:] == "UTC" {
+			layout = "2006-01-02 15:04:05 MST"
+		}
+		if t, err := time.Parse(layout, value); err == nil {
+			value = t.UTC().Format(time.RFC3339)
+		}
+	}
+	return &value
+}
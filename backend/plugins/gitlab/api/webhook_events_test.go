@@ -0,0 +1,92 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/apache/incubator-devlake/plugins/gitlab/tasks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWebhookMergeRequest(t *testing.T) {
+	events, err := parseWebhookEvent("Merge Request Hook", []byte(`{
+		"object_kind": "merge_request",
+		"user": {"id": 7, "username": "alice"},
+		"project": {"id": 15, "web_url": "https://gitlab.com/apache/devlake"},
+		"object_attributes": {
+			"id": 99, "iid": 3, "source_project_id": 15, "target_project_id": 15, "author_id": 5,
+			"state": "merged", "action": "merge", "title": "fix", "url": "https://gitlab.com/apache/devlake/-/merge_requests/3",
+			"source_branch": "fix", "target_branch": "main", "draft": false,
+			"created_at": "2025-09-01 10:00:00 UTC", "updated_at": "2025-09-01 12:00:00 +0200",
+			"last_commit": {"id": "abc"}, "labels": [{"title": "bug"}]
+		},
+		"reviewers": [{"id": 8, "username": "bob"}]
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 15, events.projectId)
+	assert.Len(t, events.records, 1)
+	assert.Equal(t, tasks.RAW_MERGE_REQUEST_TABLE, events.records[0].table)
+
+	mr := &tasks.MergeRequestRes{}
+	assert.Nil(t, json.Unmarshal(events.records[0].data, mr))
+	assert.Equal(t, 99, mr.GitlabId)
+	assert.Equal(t, 15, mr.ProjectId)
+	assert.Equal(t, "abc", mr.DiffHeadSha)
+	assert.Equal(t, "https://gitlab.com/apache/devlake/-/merge_requests/3", mr.WebUrl)
+	assert.Equal(t, []string{"bug"}, mr.Labels)
+	assert.Equal(t, 5, mr.Author.Id)
+	assert.Equal(t, "alice", mr.MergedBy.Username)
+	assert.Equal(t, "2025-09-01T10:00:00Z", mr.GitlabCreatedAt.ToTime().UTC().Format("2006-01-02T15:04:05Z07:00"))
+	assert.Equal(t, "2025-09-01T10:00:00Z", mr.MergedAt.ToTime().UTC().Format("2006-01-02T15:04:05Z07:00"))
+	assert.Len(t, mr.Reviewers, 1)
+	assert.Equal(t, "bob", mr.Reviewers[0].Username)
+}
+
+func TestParseWebhookPipeline(t *testing.T) {
+	events, err := parseWebhookEvent("Pipeline Hook", []byte(`{
+		"object_kind": "pipeline",
+		"project": {"id": 15, "web_url": "https://gitlab.com/apache/devlake"},
+		"object_attributes": {
+			"id": 31, "ref": "main", "sha": "abc", "status": "success", "source": "push", "duration": 60,
+			"created_at": "2025-09-01 10:00:00 UTC", "finished_at": "2025-09-01 10:02:00 UTC"
+		}
+	}`))
+	assert.Nil(t, err)
+	assert.Len(t, events.records, 2)
+	assert.Equal(t, tasks.RAW_PIPELINE_TABLE, events.records[0].table)
+	assert.Equal(t, tasks.RAW_PIPELINE_DETAILS_TABLE, events.records[1].table)
+
+	pipeline := &tasks.ApiPipeline{}
+	assert.Nil(t, json.Unmarshal(events.records[1].data, pipeline))
+	assert.Equal(t, 31, pipeline.Id)
+	assert.Equal(t, "https://gitlab.com/apache/devlake/-/pipelines/31", pipeline.WebUrl)
+	assert.Equal(t, 60, pipeline.Duration)
+	assert.Equal(t, "2025-09-01T10:01:00Z", pipeline.StartedAt.ToTime().UTC().Format("2006-01-02T15:04:05Z07:00"))
+	assert.Equal(t, "2025-09-01T10:02:00Z", pipeline.UpdatedAt.ToTime().UTC().Format("2006-01-02T15:04:05Z07:00"))
+}
+
+func TestParseWebhookIgnoredEvent(t *testing.T) {
+	events, err := parseWebhookEvent("Push Hook", []byte(`{"object_kind": "push", "project": {"id": 15}}`))
+	assert.Nil(t, err)
+	assert.Nil(t, events)
+
+	_, err = parseWebhookEvent("Pipeline Hook", []byte(`{"object_kind": "pipeline"}`))
+	assert.NotNil(t, err)
+}
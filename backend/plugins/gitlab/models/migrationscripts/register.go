@@ -52,5 +52,6 @@ func All() []plugin.MigrationScript {
 		new(addGitlabAssigneeAndReviewerPrimaryKey),
 		new(changeIssueComponentType),
 		new(addIsChildToPipelines240906),
+		new(addWebhookSecretToConnection),
 	}
 }
@@ -67,6 +67,8 @@ func CollectAccounts(taskCtx plugin.SubTaskContext) errors.Error {
 		ApiClient:          data.ApiClient,
 		UrlTemplate:        urlTemplate,
 		PageSize:           100,
+		// users are collected in full every run, reuse the pages that were not modified
+		ConditionalRequests: true,
 		Query: func(reqData *api.RequestData) (url.Values, errors.Error) {
 			query := url.Values{}
 			query.Set("page", fmt.Sprintf("%v", reqData.Pager.Page))
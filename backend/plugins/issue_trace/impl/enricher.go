@@ -90,6 +90,10 @@ func (p IssueTrace) SubTaskMetas() []plugin.SubTaskMeta {
 		tasks.ConvertIssueStatusHistoryMeta,
 		// issue_assignee_history
 		tasks.ConvertIssueAssigneeHistoryMeta,
+		// issue_flow_metrics, board_wip_snapshots and board_cumulative_flows
+		tasks.CalculateIssueFlowMetricsMeta,
+		// sprint_metrics, sprint_issue_metrics and sprint_burndowns
+		tasks.CalculateSprintMetricsMeta,
 	}
 }
 
@@ -137,6 +141,8 @@ func (p IssueTrace) RootPkgPath() string {
 func (p IssueTrace) MigrationScripts() []plugin.MigrationScript {
 	return []plugin.MigrationScript{
 		&migrationscripts.NewIssueTable{},
+		&migrationscripts.AddIssueFlowTables{},
+		&migrationscripts.AddSprintMetricTables{},
 	}
 }
 
@@ -148,6 +154,12 @@ func (p IssueTrace) GetTablesInfo() []dal.Tabler {
 	return []dal.Tabler{
 		&models.IssueAssigneeHistory{},
 		&models.IssueStatusHistory{},
+		&models.IssueFlowMetric{},
+		&models.BoardWipSnapshot{},
+		&models.BoardCumulativeFlow{},
+		&models.SprintMetric{},
+		&models.SprintIssueMetric{},
+		&models.SprintBurndown{},
 	}
 }
 
@@ -165,12 +177,16 @@ func (p IssueTrace) MakeMetricPluginPipelinePlanV200(projectName string, options
 			{
 				Plugin: "issue_trace",
 				Options: map[string]interface{}{
-					"projectName": projectName,
-					"scopeIds":    op.ScopeIds,
+					"projectName":  projectName,
+					"scopeIds":     op.ScopeIds,
+					"waitStatuses": op.WaitStatuses,
+					"flowDays":     op.FlowDays,
 				},
 				Subtasks: []string{
 					"ConvertIssueStatusHistory",
 					"ConvertIssueAssigneeHistory",
+					"CalculateIssueFlowMetrics",
+					"CalculateSprintMetrics",
 				},
 			},
 		},
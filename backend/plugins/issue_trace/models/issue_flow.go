@@ -0,0 +1,74 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// IssueFlowMetric is the cycle of an issue on a board, from the first time it was in progress until it was done
+type IssueFlowMetric struct {
+	common.NoPKModel
+	ProjectName    string `gorm:"primaryKey;type:varchar(255)"`
+	BoardId        string `gorm:"primaryKey;type:varchar(255)"`
+	IssueId        string `gorm:"primaryKey;type:varchar(255)"`
+	InProgressDate *time.Time
+	// DoneDate is nil while the issue is not done, the times are counted until now then
+	DoneDate           *time.Time
+	CycleTimeMinutes   *int64
+	ActiveTimeMinutes  int64
+	WaitingTimeMinutes int64
+	// FlowEfficiency is the ratio of active time to the whole cycle
+	FlowEfficiency *float64
+}
+
+func (IssueFlowMetric) TableName() string {
+	return "issue_flow_metrics"
+}
+
+// BoardWipSnapshot is the number of issues in progress on a board at the end of a day
+type BoardWipSnapshot struct {
+	common.NoPKModel
+	ProjectName string    `gorm:"primaryKey;type:varchar(255)"`
+	BoardId     string    `gorm:"primaryKey;type:varchar(255)"`
+	Date        time.Time `gorm:"primaryKey;type:date"`
+	WipCount    int
+	// WaitingCount are the issues in progress but in a wait status
+	WaitingCount int
+}
+
+func (BoardWipSnapshot) TableName() string {
+	return "board_wip_snapshots"
+}
+
+// BoardCumulativeFlow is the number of issues in each status of a board at the end of a day, the data of a cumulative flow diagram
+type BoardCumulativeFlow struct {
+	common.NoPKModel
+	ProjectName    string    `gorm:"primaryKey;type:varchar(255)"`
+	BoardId        string    `gorm:"primaryKey;type:varchar(255)"`
+	Date           time.Time `gorm:"primaryKey;type:date"`
+	OriginalStatus string    `gorm:"primaryKey;type:varchar(255)"`
+	Status         string    `gorm:"type:varchar(100)"`
+	IssueCount     int
+}
+
+func (BoardCumulativeFlow) TableName() string {
+	return "board_cumulative_flows"
+}
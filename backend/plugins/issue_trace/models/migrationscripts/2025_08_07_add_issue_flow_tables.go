@@ -0,0 +1,86 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+type AddIssueFlowTables struct {
+}
+
+func (*AddIssueFlowTables) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, &IssueFlowMetric20250807{}, &BoardWipSnapshot20250807{}, &BoardCumulativeFlow20250807{})
+}
+
+func (*AddIssueFlowTables) Version() uint64 {
+	return 20250807100000
+}
+
+func (*AddIssueFlowTables) Name() string {
+	return "add issue_flow_metrics, board_wip_snapshots and board_cumulative_flows"
+}
+
+type IssueFlowMetric20250807 struct {
+	archived.NoPKModel
+	ProjectName        string `gorm:"primaryKey;type:varchar(255)"`
+	BoardId            string `gorm:"primaryKey;type:varchar(255)"`
+	IssueId            string `gorm:"primaryKey;type:varchar(255)"`
+	InProgressDate     *time.Time
+	DoneDate           *time.Time
+	CycleTimeMinutes   *int64
+	ActiveTimeMinutes  int64
+	WaitingTimeMinutes int64
+	FlowEfficiency     *float64
+}
+
+func (IssueFlowMetric20250807) TableName() string {
+	return "issue_flow_metrics"
+}
+
+type BoardWipSnapshot20250807 struct {
+	archived.NoPKModel
+	ProjectName  string    `gorm:"primaryKey;type:varchar(255)"`
+	BoardId      string    `gorm:"primaryKey;type:varchar(255)"`
+	Date         time.Time `gorm:"primaryKey;type:date"`
+	WipCount     int
+	WaitingCount int
+}
+
+func (BoardWipSnapshot20250807) TableName() string {
+	return "board_wip_snapshots"
+}
+
+type BoardCumulativeFlow20250807 struct {
+	archived.NoPKModel
+	ProjectName    string    `gorm:"primaryKey;type:varchar(255)"`
+	BoardId        string    `gorm:"primaryKey;type:varchar(255)"`
+	Date           time.Time `gorm:"primaryKey;type:date"`
+	OriginalStatus string    `gorm:"primaryKey;type:varchar(255)"`
+	Status         string    `gorm:"type:varchar(100)"`
+	IssueCount     int
+}
+
+func (BoardCumulativeFlow20250807) TableName() string {
+	return "board_cumulative_flows"
+}
@@ -0,0 +1,94 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+type AddSprintMetricTables struct {
+}
+
+func (*AddSprintMetricTables) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, &SprintMetric20250814{}, &SprintIssueMetric20250814{}, &SprintBurndown20250814{})
+}
+
+func (*AddSprintMetricTables) Version() uint64 {
+	return 20250814100000
+}
+
+func (*AddSprintMetricTables) Name() string {
+	return "add sprint_metrics, sprint_issue_metrics and sprint_burndowns"
+}
+
+type SprintMetric20250814 struct {
+	archived.NoPKModel
+	SprintId               string `gorm:"primaryKey;type:varchar(255)"`
+	StartedDate            *time.Time
+	EndedDate              *time.Time
+	IsClosed               bool
+	CommittedIssues        int
+	CommittedStoryPoints   float64
+	AddedIssues            int
+	AddedStoryPoints       float64
+	RemovedIssues          int
+	RemovedStoryPoints     float64
+	CompletedIssues        int
+	CompletedStoryPoints   float64
+	CarriedOverIssues      int
+	CarriedOverStoryPoints float64
+}
+
+func (SprintMetric20250814) TableName() string {
+	return "sprint_metrics"
+}
+
+type SprintIssueMetric20250814 struct {
+	archived.NoPKModel
+	SprintId      string `gorm:"primaryKey;type:varchar(255)"`
+	IssueId       string `gorm:"primaryKey;type:varchar(255)"`
+	StoryPoint    float64
+	IsCommitted   bool
+	AddedDate     *time.Time
+	RemovedDate   *time.Time
+	IsCompleted   bool
+	IsCarriedOver bool
+}
+
+func (SprintIssueMetric20250814) TableName() string {
+	return "sprint_issue_metrics"
+}
+
+type SprintBurndown20250814 struct {
+	archived.NoPKModel
+	SprintId             string    `gorm:"primaryKey;type:varchar(255)"`
+	Date                 time.Time `gorm:"primaryKey;type:date"`
+	ScopeIssues          int
+	ScopeStoryPoints     float64
+	RemainingIssues      int
+	RemainingStoryPoints float64
+}
+
+func (SprintBurndown20250814) TableName() string {
+	return "sprint_burndowns"
+}
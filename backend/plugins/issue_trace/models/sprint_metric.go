@@ -0,0 +1,82 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// SprintMetric compares what a sprint committed to at its start with what it completed and how its scope changed
+type SprintMetric struct {
+	common.NoPKModel
+	SprintId    string `gorm:"primaryKey;type:varchar(255)"`
+	StartedDate *time.Time
+	// EndedDate is when the sprint was completed, or is planned to end if it is still active
+	EndedDate              *time.Time
+	IsClosed               bool
+	CommittedIssues        int
+	CommittedStoryPoints   float64
+	AddedIssues            int
+	AddedStoryPoints       float64
+	RemovedIssues          int
+	RemovedStoryPoints     float64
+	CompletedIssues        int
+	CompletedStoryPoints   float64
+	CarriedOverIssues      int
+	CarriedOverStoryPoints float64
+}
+
+func (SprintMetric) TableName() string {
+	return "sprint_metrics"
+}
+
+// SprintIssueMetric is how an issue took part in a sprint
+type SprintIssueMetric struct {
+	common.NoPKModel
+	SprintId    string `gorm:"primaryKey;type:varchar(255)"`
+	IssueId     string `gorm:"primaryKey;type:varchar(255)"`
+	StoryPoint  float64
+	IsCommitted bool
+	// AddedDate is set for issues added after the sprint started
+	AddedDate *time.Time
+	// RemovedDate is set for issues removed before the sprint ended
+	RemovedDate   *time.Time
+	IsCompleted   bool
+	IsCarriedOver bool
+}
+
+func (SprintIssueMetric) TableName() string {
+	return "sprint_issue_metrics"
+}
+
+// SprintBurndown is the scope and the remaining work of a sprint at the end of a day
+type SprintBurndown struct {
+	common.NoPKModel
+	SprintId             string    `gorm:"primaryKey;type:varchar(255)"`
+	Date                 time.Time `gorm:"primaryKey;type:date"`
+	ScopeIssues          int
+	ScopeStoryPoints     float64
+	RemainingIssues      int
+	RemainingStoryPoints float64
+}
+
+func (SprintBurndown) TableName() string {
+	return "sprint_burndowns"
+}
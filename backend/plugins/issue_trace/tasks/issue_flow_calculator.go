@@ -0,0 +1,345 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/issue_trace/models"
+	"github.com/apache/incubator-devlake/plugins/issue_trace/utils"
+)
+
+// DEFAULT_FLOW_DAYS is how many days of WIP snapshots and cumulative flow are kept by default
+const DEFAULT_FLOW_DAYS = 90
+
+// StatusPeriod is a row of issue_status_history seen from a board
+type StatusPeriod struct {
+	BoardId         string
+	IssueId         string
+	Status          string
+	OriginalStatus  string
+	StartDate       time.Time
+	EndDate         *time.Time
+	IsCurrentStatus bool
+}
+
+var CalculateIssueFlowMetricsMeta = plugin.SubTaskMeta{
+	Name:             "CalculateIssueFlowMetrics",
+	EntryPoint:       CalculateIssueFlowMetrics,
+	EnabledByDefault: true,
+	Description:      "Calculate cycle time, flow efficiency, daily WIP and cumulative flow of issues from the status history",
+}
+
+func CalculateIssueFlowMetrics(taskCtx plugin.SubTaskContext) errors.Error {
+	logger := taskCtx.GetLogger()
+	data := taskCtx.GetData().(*TaskData)
+	db := taskCtx.GetDal()
+	if len(data.ScopeIds) == 0 {
+		logger.Info("no board to calculate issue flow metrics for")
+		return nil
+	}
+	flowDays := data.Options.FlowDays
+	if flowDays <= 0 {
+		flowDays = DEFAULT_FLOW_DAYS
+	}
+	calculator := NewIssueFlowCalculator(data.Options.WaitStatuses, flowDays, time.Now())
+
+	// the metrics are calculated from the whole history every time
+	for _, table := range []dal.Tabler{&models.IssueFlowMetric{}, &models.BoardWipSnapshot{}, &models.BoardCumulativeFlow{}} {
+		err := db.Delete(table, dal.Where("project_name = ? AND board_id IN ?", data.ProjectName, data.ScopeIds))
+		if err != nil {
+			return errors.Default.Wrap(err, "failed to delete "+table.TableName())
+		}
+	}
+
+	inserter := helper.NewBatchSaveDivider(taskCtx, utils.BATCH_SIZE, "", "")
+	defer inserter.Close()
+	metricInserter, err := inserter.ForType(reflect.TypeOf(&models.IssueFlowMetric{}))
+	if err != nil {
+		return err
+	}
+	cursor, err := db.Cursor(
+		dal.Select("board_issues.board_id, h.issue_id, h.status, h.original_status, h.start_date, h.end_date, h.is_current_status"),
+		dal.From("issue_status_history h"),
+		dal.Join("INNER JOIN board_issues ON board_issues.issue_id = h.issue_id"),
+		dal.Where("board_issues.board_id IN ?", data.ScopeIds),
+		dal.Orderby("board_issues.board_id ASC, h.issue_id ASC, h.start_date ASC"),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to query issue status history")
+	}
+	defer cursor.Close()
+
+	var periods []*StatusPeriod
+	flush := func() errors.Error {
+		if len(periods) == 0 {
+			return nil
+		}
+		metric := calculator.AddIssue(periods)
+		periods = nil
+		if metric == nil {
+			return nil
+		}
+		metric.ProjectName = data.ProjectName
+		return metricInserter.Add(metric)
+	}
+	for cursor.Next() {
+		if err = utils.CheckCancel(taskCtx); err != nil {
+			return err
+		}
+		period := &StatusPeriod{}
+		if err = db.Fetch(cursor, period); err != nil {
+			return err
+		}
+		if len(periods) > 0 && (periods[0].BoardId != period.BoardId || periods[0].IssueId != period.IssueId) {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+		periods = append(periods, period)
+	}
+	if err = flush(); err != nil {
+		return err
+	}
+
+	wipInserter, err := inserter.ForType(reflect.TypeOf(&models.BoardWipSnapshot{}))
+	if err != nil {
+		return err
+	}
+	for _, snapshot := range calculator.WipSnapshots() {
+		snapshot.ProjectName = data.ProjectName
+		if err = wipInserter.Add(snapshot); err != nil {
+			return err
+		}
+	}
+	flowInserter, err := inserter.ForType(reflect.TypeOf(&models.BoardCumulativeFlow{}))
+	if err != nil {
+		return err
+	}
+	for _, flow := range calculator.CumulativeFlows() {
+		flow.ProjectName = data.ProjectName
+		if err = flowInserter.Add(flow); err != nil {
+			return err
+		}
+	}
+	logger.Info("issue flow metrics of boards %s calculated", data.ScopeIds)
+	return nil
+}
+
+// IssueFlowCalculator accumulates the flow of the issues of the boards day by day
+type IssueFlowCalculator struct {
+	waitStatuses map[string]bool
+	// days are the starts of the days in UTC, the status of a day is the one at its end
+	days   []time.Time
+	now    time.Time
+	boards map[string]*boardFlow
+}
+
+type boardFlow struct {
+	wip        []int
+	waiting    []int
+	cumulative []map[string]int
+	// statuses maps the original statuses to the standard ones
+	statuses map[string]string
+}
+
+// NewIssueFlowCalculator takes the original statuses meaning an issue is waiting, they are matched ignoring case
+func NewIssueFlowCalculator(waitStatuses []string, flowDays int, now time.Time) *IssueFlowCalculator {
+	c := &IssueFlowCalculator{
+		waitStatuses: make(map[string]bool),
+		now:          now,
+		boards:       make(map[string]*boardFlow),
+	}
+	for _, s := range waitStatuses {
+		c.waitStatuses[strings.ToLower(strings.TrimSpace(s))] = true
+	}
+	today := now.UTC().Truncate(24 * time.Hour)
+	for i := flowDays - 1; i >= 0; i-- {
+		c.days = append(c.days, today.AddDate(0, 0, -i))
+	}
+	return c
+}
+
+func (c *IssueFlowCalculator) isWaiting(p *StatusPeriod) bool {
+	return p.Status != ticket.IN_PROGRESS || c.waitStatuses[strings.ToLower(strings.TrimSpace(p.OriginalStatus))]
+}
+
+// end returns when the period ends, nil for the current status
+func (c *IssueFlowCalculator) end(p *StatusPeriod) *time.Time {
+	if p.IsCurrentStatus {
+		return nil
+	}
+	return p.EndDate
+}
+
+// AddIssue takes the status history of an issue on a board ordered by start date, and returns its flow metric,
+// nil if the issue has never been in progress
+func (c *IssueFlowCalculator) AddIssue(periods []*StatusPeriod) *models.IssueFlowMetric {
+	c.accumulateDays(periods)
+
+	started := -1
+	for i, p := range periods {
+		if p.Status == ticket.IN_PROGRESS {
+			started = i
+			break
+		}
+	}
+	if started < 0 {
+		return nil
+	}
+	metric := &models.IssueFlowMetric{
+		BoardId:        periods[0].BoardId,
+		IssueId:        periods[0].IssueId,
+		InProgressDate: &periods[started].StartDate,
+	}
+	// an issue reopened is not done until the last time it was moved to done
+	cycleEnd := c.now
+	if last := periods[len(periods)-1]; last.Status == ticket.DONE && last.IsCurrentStatus {
+		done := len(periods) - 1
+		for done > started+1 && periods[done-1].Status == ticket.DONE {
+			done--
+		}
+		metric.DoneDate = &periods[done].StartDate
+		cycleEnd = periods[done].StartDate
+	}
+	for _, p := range periods[started:] {
+		start, end := p.StartDate, cycleEnd
+		if e := c.end(p); e != nil && e.Before(end) {
+			end = *e
+		}
+		if !end.After(start) {
+			continue
+		}
+		minutes := int64(end.Sub(start) / time.Minute)
+		if c.isWaiting(p) {
+			metric.WaitingTimeMinutes += minutes
+		} else {
+			metric.ActiveTimeMinutes += minutes
+		}
+	}
+	if metric.DoneDate != nil {
+		cycleTime := int64(metric.DoneDate.Sub(*metric.InProgressDate) / time.Minute)
+		metric.CycleTimeMinutes = &cycleTime
+	}
+	if total := metric.ActiveTimeMinutes + metric.WaitingTimeMinutes; total > 0 {
+		efficiency := float64(metric.ActiveTimeMinutes) / float64(total)
+		metric.FlowEfficiency = &efficiency
+	}
+	return metric
+}
+
+// accumulateDays counts the issue in the status it was at the end of each day
+func (c *IssueFlowCalculator) accumulateDays(periods []*StatusPeriod) {
+	board := c.boards[periods[0].BoardId]
+	if board == nil {
+		board = &boardFlow{
+			wip:        make([]int, len(c.days)),
+			waiting:    make([]int, len(c.days)),
+			cumulative: make([]map[string]int, len(c.days)),
+			statuses:   make(map[string]string),
+		}
+		c.boards[periods[0].BoardId] = board
+	}
+	j := 0
+	for i, day := range c.days {
+		at := day.Add(24 * time.Hour)
+		if at.After(c.now) {
+			at = c.now
+		}
+		for j < len(periods) && c.end(periods[j]) != nil && !c.end(periods[j]).After(at) {
+			j++
+		}
+		if j == len(periods) {
+			break
+		}
+		p := periods[j]
+		if p.StartDate.After(at) {
+			continue
+		}
+		if board.cumulative[i] == nil {
+			board.cumulative[i] = make(map[string]int)
+		}
+		board.cumulative[i][p.OriginalStatus]++
+		board.statuses[p.OriginalStatus] = p.Status
+		if p.Status == ticket.IN_PROGRESS {
+			board.wip[i]++
+			if c.isWaiting(p) {
+				board.waiting[i]++
+			}
+		}
+	}
+}
+
+// WipSnapshots returns the daily WIP of the boards
+func (c *IssueFlowCalculator) WipSnapshots() []*models.BoardWipSnapshot {
+	var snapshots []*models.BoardWipSnapshot
+	for _, boardId := range c.boardIds() {
+		board := c.boards[boardId]
+		for i, day := range c.days {
+			snapshots = append(snapshots, &models.BoardWipSnapshot{
+				BoardId:      boardId,
+				Date:         day,
+				WipCount:     board.wip[i],
+				WaitingCount: board.waiting[i],
+			})
+		}
+	}
+	return snapshots
+}
+
+// CumulativeFlows returns the daily number of issues in each status of the boards, statuses without issues are left out
+func (c *IssueFlowCalculator) CumulativeFlows() []*models.BoardCumulativeFlow {
+	var flows []*models.BoardCumulativeFlow
+	for _, boardId := range c.boardIds() {
+		board := c.boards[boardId]
+		for i, day := range c.days {
+			statuses := make([]string, 0, len(board.cumulative[i]))
+			for s := range board.cumulative[i] {
+				statuses = append(statuses, s)
+			}
+			sort.Strings(statuses)
+			for _, s := range statuses {
+				flows = append(flows, &models.BoardCumulativeFlow{
+					BoardId:        boardId,
+					Date:           day,
+					OriginalStatus: s,
+					Status:         board.statuses[s],
+					IssueCount:     board.cumulative[i][s],
+				})
+			}
+		}
+	}
+	return flows
+}
+
+func (c *IssueFlowCalculator) boardIds() []string {
+	ids := make([]string, 0, len(c.boards))
+	for id := range c.boards {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
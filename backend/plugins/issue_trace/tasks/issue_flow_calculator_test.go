@@ -0,0 +1,102 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/stretchr/testify/assert"
+)
+
+func period(issueId, status, originalStatus, start, end string) *StatusPeriod {
+	p := &StatusPeriod{BoardId: "b1", IssueId: issueId, Status: status, OriginalStatus: originalStatus}
+	p.StartDate, _ = time.Parse(time.RFC3339, start)
+	if end == "" {
+		p.IsCurrentStatus = true
+		return p
+	}
+	endDate, _ := time.Parse(time.RFC3339, end)
+	p.EndDate = &endDate
+	return p
+}
+
+func TestIssueFlowCalculator(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2025-08-10T12:00:00Z")
+	c := NewIssueFlowCalculator([]string{" blocked "}, 3, now)
+
+	done := c.AddIssue([]*StatusPeriod{
+		period("a", ticket.TODO, "Open", "2025-08-07T00:00:00Z", "2025-08-08T10:00:00Z"),
+		period("a", ticket.IN_PROGRESS, "In Progress", "2025-08-08T10:00:00Z", "2025-08-09T10:00:00Z"),
+		period("a", ticket.IN_PROGRESS, "Blocked", "2025-08-09T10:00:00Z", "2025-08-09T16:00:00Z"),
+		period("a", ticket.IN_PROGRESS, "In Progress", "2025-08-09T16:00:00Z", "2025-08-10T08:00:00Z"),
+		period("a", ticket.DONE, "Closed", "2025-08-10T08:00:00Z", ""),
+	})
+	assert.Equal(t, "2025-08-08T10:00:00Z", done.InProgressDate.Format(time.RFC3339))
+	assert.Equal(t, "2025-08-10T08:00:00Z", done.DoneDate.Format(time.RFC3339))
+	assert.Equal(t, int64(46*60), *done.CycleTimeMinutes)
+	assert.Equal(t, int64(40*60), done.ActiveTimeMinutes)
+	assert.Equal(t, int64(6*60), done.WaitingTimeMinutes)
+	assert.InDelta(t, 40.0/46.0, *done.FlowEfficiency, 1e-9)
+
+	assert.Nil(t, c.AddIssue([]*StatusPeriod{
+		period("b", ticket.TODO, "Open", "2025-08-09T12:00:00Z", ""),
+	}))
+
+	blocked := c.AddIssue([]*StatusPeriod{
+		period("c", ticket.IN_PROGRESS, "Blocked", "2025-08-08T00:00:00Z", ""),
+	})
+	assert.Nil(t, blocked.DoneDate)
+	assert.Nil(t, blocked.CycleTimeMinutes)
+	assert.Equal(t, int64(60*60), blocked.WaitingTimeMinutes)
+	assert.Equal(t, 0.0, *blocked.FlowEfficiency)
+
+	wip := c.WipSnapshots()
+	assert.Len(t, wip, 3)
+	assert.Equal(t, []int{2, 2, 1}, []int{wip[0].WipCount, wip[1].WipCount, wip[2].WipCount})
+	assert.Equal(t, []int{1, 1, 1}, []int{wip[0].WaitingCount, wip[1].WaitingCount, wip[2].WaitingCount})
+	assert.Equal(t, "2025-08-08", wip[0].Date.Format("2006-01-02"))
+
+	var flows []string
+	for _, f := range c.CumulativeFlows() {
+		flows = append(flows, f.Date.Format("01-02")+" "+f.OriginalStatus+" "+f.Status)
+		assert.Equal(t, 1, f.IssueCount)
+	}
+	assert.Equal(t, []string{
+		"08-08 Blocked IN_PROGRESS", "08-08 In Progress IN_PROGRESS",
+		"08-09 Blocked IN_PROGRESS", "08-09 In Progress IN_PROGRESS", "08-09 Open TODO",
+		"08-10 Blocked IN_PROGRESS", "08-10 Closed DONE", "08-10 Open TODO",
+	}, flows)
+}
+
+func TestIssueFlowCalculatorReopened(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2025-08-10T12:00:00Z")
+	c := NewIssueFlowCalculator(nil, 1, now)
+	metric := c.AddIssue([]*StatusPeriod{
+		period("a", ticket.IN_PROGRESS, "In Progress", "2025-08-01T00:00:00Z", "2025-08-02T00:00:00Z"),
+		period("a", ticket.DONE, "Done", "2025-08-02T00:00:00Z", "2025-08-03T00:00:00Z"),
+		period("a", ticket.IN_PROGRESS, "In Progress", "2025-08-03T00:00:00Z", "2025-08-04T00:00:00Z"),
+		period("a", ticket.DONE, "Resolved", "2025-08-04T00:00:00Z", "2025-08-05T00:00:00Z"),
+		period("a", ticket.DONE, "Closed", "2025-08-05T00:00:00Z", ""),
+	})
+	assert.Equal(t, "2025-08-04T00:00:00Z", metric.DoneDate.Format(time.RFC3339))
+	assert.Equal(t, int64(3*24*60), *metric.CycleTimeMinutes)
+	assert.Equal(t, int64(2*24*60), metric.ActiveTimeMinutes)
+	assert.Equal(t, int64(24*60), metric.WaitingTimeMinutes)
+}
@@ -0,0 +1,391 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/issue_trace/models"
+	"github.com/apache/incubator-devlake/plugins/issue_trace/utils"
+)
+
+const SPRINT_CLOSED = "CLOSED"
+
+type SprintRow struct {
+	Id            string
+	Status        string
+	StartedDate   *time.Time
+	EndedDate     *time.Time
+	CompletedDate *time.Time
+}
+
+type SprintIssueRow struct {
+	Id             string
+	CreatedDate    time.Time
+	ResolutionDate *time.Time
+	Status         string
+	StoryPoint     *float64
+}
+
+// SprintChangelogRow moves an issue between sprints, the values are comma separated domain sprint ids
+type SprintChangelogRow struct {
+	IssueId           string
+	OriginalFromValue string
+	OriginalToValue   string
+	CreatedDate       time.Time
+}
+
+// sprintCandidate is an issue linked to a sprint now or at some point of its changelogs
+type sprintCandidate struct {
+	issue    *SprintIssueRow
+	inSprint bool
+	changes  []*SprintChangelogRow
+}
+
+var CalculateSprintMetricsMeta = plugin.SubTaskMeta{
+	Name:             "CalculateSprintMetrics",
+	EntryPoint:       CalculateSprintMetrics,
+	EnabledByDefault: true,
+	Description:      "Calculate commitment, completion, scope change, carry-over and burndown of sprints from the sprint changelogs",
+}
+
+func CalculateSprintMetrics(taskCtx plugin.SubTaskContext) errors.Error {
+	logger := taskCtx.GetLogger()
+	data := taskCtx.GetData().(*TaskData)
+	db := taskCtx.GetDal()
+	if len(data.ScopeIds) == 0 {
+		logger.Info("no board to calculate sprint metrics for")
+		return nil
+	}
+
+	var sprints []*SprintRow
+	err := db.All(&sprints,
+		dal.Select("DISTINCT s.id, s.status, s.started_date, s.ended_date, s.completed_date"),
+		dal.From("sprints s"),
+		dal.Join("INNER JOIN board_sprints bs ON bs.sprint_id = s.id"),
+		dal.Where("bs.board_id IN ? AND s.started_date IS NOT NULL", data.ScopeIds),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to query sprints")
+	}
+	if len(sprints) == 0 {
+		logger.Info("no started sprint on boards %s", data.ScopeIds)
+		return nil
+	}
+	sprintIds := make([]string, 0, len(sprints))
+	for _, s := range sprints {
+		sprintIds = append(sprintIds, s.Id)
+	}
+	for _, table := range []dal.Tabler{&models.SprintMetric{}, &models.SprintIssueMetric{}, &models.SprintBurndown{}} {
+		if err = db.Delete(table, dal.Where("sprint_id IN ?", sprintIds)); err != nil {
+			return errors.Default.Wrap(err, "failed to delete "+table.TableName())
+		}
+	}
+
+	// the issues of the boards and those of their sprints, which may live on other boards
+	boardIssues := "SELECT issue_id FROM board_issues WHERE board_id IN ?"
+	sprintIssues := "SELECT issue_id FROM sprint_issues WHERE sprint_id IN ?"
+	var issues []*SprintIssueRow
+	err = db.All(&issues,
+		dal.Select("id, created_date, resolution_date, status, story_point"),
+		dal.From("issues"),
+		dal.Where(fmt.Sprintf("id IN (%s) OR id IN (%s)", boardIssues, sprintIssues), data.ScopeIds, sprintIds),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to query issues")
+	}
+	var changelogs []*SprintChangelogRow
+	err = db.All(&changelogs,
+		dal.Select("issue_id, original_from_value, original_to_value, created_date"),
+		dal.From("issue_changelogs"),
+		dal.Where(fmt.Sprintf("field_name = 'Sprint' AND (issue_id IN (%s) OR issue_id IN (%s))", boardIssues, sprintIssues), data.ScopeIds, sprintIds),
+		dal.Orderby("issue_id ASC, created_date ASC"),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to query sprint changelogs")
+	}
+	var links []*ticket.SprintIssue
+	if err = db.All(&links, dal.Where("sprint_id IN ?", sprintIds)); err != nil {
+		return errors.Default.Wrap(err, "failed to query sprint issues")
+	}
+
+	candidates := buildSprintCandidates(issues, changelogs, links)
+	inserter := helper.NewBatchSaveDivider(taskCtx, utils.BATCH_SIZE, "", "")
+	defer inserter.Close()
+	metricInserter, err := inserter.ForType(reflect.TypeOf(&models.SprintMetric{}))
+	if err != nil {
+		return err
+	}
+	issueInserter, err := inserter.ForType(reflect.TypeOf(&models.SprintIssueMetric{}))
+	if err != nil {
+		return err
+	}
+	burndownInserter, err := inserter.ForType(reflect.TypeOf(&models.SprintBurndown{}))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, sprint := range sprints {
+		if err = utils.CheckCancel(taskCtx); err != nil {
+			return err
+		}
+		metric, issueMetrics, burndowns := analyzeSprint(sprint, candidates[sprint.Id], now)
+		if err = metricInserter.Add(metric); err != nil {
+			return err
+		}
+		for _, m := range issueMetrics {
+			if err = issueInserter.Add(m); err != nil {
+				return err
+			}
+		}
+		for _, b := range burndowns {
+			if err = burndownInserter.Add(b); err != nil {
+				return err
+			}
+		}
+	}
+	logger.Info("metrics of %d sprints calculated", len(sprints))
+	return nil
+}
+
+// buildSprintCandidates groups the issues by the sprints they are linked to now or were moved in or out of
+func buildSprintCandidates(issues []*SprintIssueRow, changelogs []*SprintChangelogRow, links []*ticket.SprintIssue) map[string][]*sprintCandidate {
+	issueById := make(map[string]*SprintIssueRow, len(issues))
+	for _, issue := range issues {
+		issueById[issue.Id] = issue
+	}
+	changes := make(map[string][]*SprintChangelogRow)
+	for _, c := range changelogs {
+		changes[c.IssueId] = append(changes[c.IssueId], c)
+	}
+	bySprint := make(map[string]map[string]*sprintCandidate)
+	add := func(sprintId, issueId string) *sprintCandidate {
+		issue := issueById[issueId]
+		if issue == nil {
+			return nil
+		}
+		if bySprint[sprintId] == nil {
+			bySprint[sprintId] = make(map[string]*sprintCandidate)
+		}
+		c := bySprint[sprintId][issueId]
+		if c == nil {
+			c = &sprintCandidate{issue: issue, changes: changes[issueId]}
+			bySprint[sprintId][issueId] = c
+		}
+		return c
+	}
+	for _, link := range links {
+		if c := add(link.SprintId, link.IssueId); c != nil {
+			c.inSprint = true
+		}
+	}
+	for _, c := range changelogs {
+		for _, sprintId := range splitSprintIds(c.OriginalFromValue + "," + c.OriginalToValue) {
+			add(sprintId, c.IssueId)
+		}
+	}
+	result := make(map[string][]*sprintCandidate, len(bySprint))
+	for sprintId, candidates := range bySprint {
+		for _, c := range candidates {
+			result[sprintId] = append(result[sprintId], c)
+		}
+		sort.Slice(result[sprintId], func(i, j int) bool {
+			return result[sprintId][i].issue.Id < result[sprintId][j].issue.Id
+		})
+	}
+	return result
+}
+
+func splitSprintIds(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func containsSprint(value, sprintId string) bool {
+	for _, id := range splitSprintIds(value) {
+		if id == sprintId {
+			return true
+		}
+	}
+	return false
+}
+
+// memberAt tells if the issue was in the sprint at the time, changes made exactly at that time are ignored
+// with beforeOnly, as closing a sprint moves its unfinished issues out at the same moment
+func (c *sprintCandidate) memberAt(sprintId string, t time.Time, beforeOnly bool) bool {
+	if c.issue.CreatedDate.After(t) {
+		return false
+	}
+	var relevant []*SprintChangelogRow
+	for _, change := range c.changes {
+		if containsSprint(change.OriginalFromValue, sprintId) || containsSprint(change.OriginalToValue, sprintId) {
+			relevant = append(relevant, change)
+		}
+	}
+	// without changelogs about the sprint, the issue is taken to have been in it since created
+	if len(relevant) == 0 {
+		return c.inSprint
+	}
+	member := containsSprint(relevant[0].OriginalFromValue, sprintId)
+	for _, change := range relevant {
+		if change.CreatedDate.After(t) || beforeOnly && change.CreatedDate.Equal(t) {
+			break
+		}
+		member = containsSprint(change.OriginalToValue, sprintId)
+	}
+	return member
+}
+
+// resolvedBy tells if the issue was resolved at the time, issues done without a resolution date count at the sprint end
+func (c *sprintCandidate) resolvedBy(t, sprintEnd time.Time) bool {
+	if c.issue.ResolutionDate != nil {
+		return !c.issue.ResolutionDate.After(t)
+	}
+	return c.issue.Status == ticket.DONE && !t.Before(sprintEnd)
+}
+
+func (c *sprintCandidate) storyPoint() float64 {
+	if c.issue.StoryPoint == nil {
+		return 0
+	}
+	return *c.issue.StoryPoint
+}
+
+func analyzeSprint(sprint *SprintRow, candidates []*sprintCandidate, now time.Time) (*models.SprintMetric, []*models.SprintIssueMetric, []*models.SprintBurndown) {
+	start := *sprint.StartedDate
+	closed := sprint.CompletedDate != nil || sprint.Status == SPRINT_CLOSED
+	end := now
+	if sprint.CompletedDate != nil {
+		end = *sprint.CompletedDate
+	} else if closed && sprint.EndedDate != nil {
+		end = *sprint.EndedDate
+	}
+	if end.After(now) {
+		end = now
+	}
+	metric := &models.SprintMetric{
+		SprintId:    sprint.Id,
+		StartedDate: sprint.StartedDate,
+		EndedDate:   sprint.EndedDate,
+		IsClosed:    closed,
+	}
+	if closed {
+		metric.EndedDate = &end
+	}
+
+	var issueMetrics []*models.SprintIssueMetric
+	for _, c := range candidates {
+		m := &models.SprintIssueMetric{
+			SprintId:    sprint.Id,
+			IssueId:     c.issue.Id,
+			StoryPoint:  c.storyPoint(),
+			IsCommitted: c.memberAt(sprint.Id, start, false),
+		}
+		// walk through the moments the issue may have joined or left the sprint
+		var moments []time.Time
+		for _, change := range c.changes {
+			moments = append(moments, change.CreatedDate)
+		}
+		moments = append(moments, c.issue.CreatedDate)
+		sort.Slice(moments, func(i, j int) bool { return moments[i].Before(moments[j]) })
+		was := m.IsCommitted
+		for _, t := range moments {
+			if !t.After(start) || !t.Before(end) {
+				continue
+			}
+			is := c.memberAt(sprint.Id, t, false)
+			if is && !was && !m.IsCommitted && m.AddedDate == nil {
+				added := t
+				m.AddedDate = &added
+			}
+			if !is && was {
+				removed := t
+				m.RemovedDate = &removed
+			}
+			was = is
+		}
+		if !m.IsCommitted && m.AddedDate == nil {
+			// only passed through the sprint before it started or after it ended
+			continue
+		}
+		atEnd := c.memberAt(sprint.Id, end, closed)
+		if atEnd {
+			m.RemovedDate = nil
+			m.IsCompleted = c.resolvedBy(end, end)
+			m.IsCarriedOver = closed && !m.IsCompleted
+		}
+		issueMetrics = append(issueMetrics, m)
+
+		if m.IsCommitted {
+			metric.CommittedIssues++
+			metric.CommittedStoryPoints += m.StoryPoint
+		}
+		if m.AddedDate != nil {
+			metric.AddedIssues++
+			metric.AddedStoryPoints += m.StoryPoint
+		}
+		if m.RemovedDate != nil {
+			metric.RemovedIssues++
+			metric.RemovedStoryPoints += m.StoryPoint
+		}
+		if m.IsCompleted {
+			metric.CompletedIssues++
+			metric.CompletedStoryPoints += m.StoryPoint
+		}
+		if m.IsCarriedOver {
+			metric.CarriedOverIssues++
+			metric.CarriedOverStoryPoints += m.StoryPoint
+		}
+	}
+
+	var burndowns []*models.SprintBurndown
+	for day := start.UTC().Truncate(24 * time.Hour); day.Before(end); day = day.AddDate(0, 0, 1) {
+		at, beforeOnly := day.AddDate(0, 0, 1), false
+		if !at.Before(end) {
+			at, beforeOnly = end, closed
+		}
+		b := &models.SprintBurndown{SprintId: sprint.Id, Date: day}
+		for _, c := range candidates {
+			if !c.memberAt(sprint.Id, at, beforeOnly) {
+				continue
+			}
+			b.ScopeIssues++
+			b.ScopeStoryPoints += c.storyPoint()
+			if !c.resolvedBy(at, end) {
+				b.RemainingIssues++
+				b.RemainingStoryPoints += c.storyPoint()
+			}
+		}
+		burndowns = append(burndowns, b)
+	}
+	return metric, issueMetrics, burndowns
+}
@@ -0,0 +1,111 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/stretchr/testify/assert"
+)
+
+func date(s string) *time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return &t
+}
+
+func sprintChange(issueId, from, to, created string) *SprintChangelogRow {
+	return &SprintChangelogRow{IssueId: issueId, OriginalFromValue: from, OriginalToValue: to, CreatedDate: *date(created)}
+}
+
+func TestAnalyzeSprint(t *testing.T) {
+	points := func(p float64) *float64 { return &p }
+	issues := []*SprintIssueRow{
+		{Id: "a", CreatedDate: *date("2025-07-01T00:00:00Z"), StoryPoint: points(3), ResolutionDate: date("2025-08-03T10:00:00Z"), Status: ticket.DONE},
+		{Id: "b", CreatedDate: *date("2025-07-01T00:00:00Z"), StoryPoint: points(5), Status: ticket.IN_PROGRESS},
+		{Id: "c", CreatedDate: *date("2025-07-01T00:00:00Z"), StoryPoint: points(2), ResolutionDate: date("2025-08-04T10:00:00Z"), Status: ticket.DONE},
+		{Id: "d", CreatedDate: *date("2025-07-01T00:00:00Z"), StoryPoint: points(1), Status: ticket.TODO},
+		{Id: "e", CreatedDate: *date("2025-07-01T00:00:00Z"), Status: ticket.TODO},
+		{Id: "f", CreatedDate: *date("2025-07-01T00:00:00Z"), Status: ticket.DONE},
+	}
+	changelogs := []*SprintChangelogRow{
+		sprintChange("a", "", "s1", "2025-07-30T00:00:00Z"),
+		sprintChange("b", "", "s1", "2025-07-30T00:00:00Z"),
+		// moved to the next sprint when s1 is completed
+		sprintChange("b", "s1", "s2", "2025-08-05T00:00:00Z"),
+		sprintChange("c", "", "s1", "2025-08-02T12:00:00Z"),
+		sprintChange("d", "", "s1", "2025-07-30T00:00:00Z"),
+		sprintChange("d", "s1", "", "2025-08-03T09:00:00Z"),
+		sprintChange("e", "s1", "", "2025-07-31T00:00:00Z"),
+	}
+	links := []*ticket.SprintIssue{{SprintId: "s1", IssueId: "a"}, {SprintId: "s1", IssueId: "c"}, {SprintId: "s1", IssueId: "f"}, {SprintId: "s2", IssueId: "b"}}
+	candidates := buildSprintCandidates(issues, changelogs, links)
+	assert.Len(t, candidates["s1"], 6)
+	assert.Len(t, candidates["s2"], 1)
+
+	sprint := &SprintRow{Id: "s1", Status: SPRINT_CLOSED, StartedDate: date("2025-08-01T00:00:00Z"), CompletedDate: date("2025-08-05T00:00:00Z")}
+	metric, issueMetrics, burndowns := analyzeSprint(sprint, candidates["s1"], *date("2025-08-10T00:00:00Z"))
+	assert.True(t, metric.IsClosed)
+	// f has been in the sprint since created as no changelog tells otherwise
+	assert.Equal(t, 4, metric.CommittedIssues)
+	assert.Equal(t, 9.0, metric.CommittedStoryPoints)
+	assert.Equal(t, 1, metric.AddedIssues)
+	assert.Equal(t, 2.0, metric.AddedStoryPoints)
+	assert.Equal(t, 1, metric.RemovedIssues)
+	assert.Equal(t, 1.0, metric.RemovedStoryPoints)
+	assert.Equal(t, 3, metric.CompletedIssues)
+	assert.Equal(t, 5.0, metric.CompletedStoryPoints)
+	assert.Equal(t, 1, metric.CarriedOverIssues)
+	assert.Equal(t, 5.0, metric.CarriedOverStoryPoints)
+
+	byIssue := make(map[string]bool)
+	for _, m := range issueMetrics {
+		byIssue[m.IssueId] = true
+	}
+	assert.Equal(t, map[string]bool{"a": true, "b": true, "c": true, "d": true, "f": true}, byIssue)
+
+	var series [][4]float64
+	for _, b := range burndowns {
+		series = append(series, [4]float64{float64(b.ScopeIssues), b.ScopeStoryPoints, float64(b.RemainingIssues), b.RemainingStoryPoints})
+	}
+	assert.Equal(t, [][4]float64{
+		{4, 9, 4, 9},
+		{5, 11, 5, 11},
+		{4, 10, 3, 7},
+		{4, 10, 1, 5},
+	}, series)
+	assert.Equal(t, "2025-08-04", burndowns[3].Date.Format("2006-01-02"))
+}
+
+func TestAnalyzeActiveSprint(t *testing.T) {
+	issues := []*SprintIssueRow{{Id: "a", CreatedDate: *date("2025-08-02T10:00:00Z"), Status: ticket.TODO}}
+	candidates := buildSprintCandidates(issues, nil, []*ticket.SprintIssue{{SprintId: "s1", IssueId: "a"}})
+	sprint := &SprintRow{Id: "s1", StartedDate: date("2025-08-01T00:00:00Z"), EndedDate: date("2025-08-15T00:00:00Z")}
+	metric, issueMetrics, burndowns := analyzeSprint(sprint, candidates["s1"], *date("2025-08-03T12:00:00Z"))
+	assert.False(t, metric.IsClosed)
+	assert.Equal(t, "2025-08-15", metric.EndedDate.Format("2006-01-02"))
+	// created in the sprint after it started
+	assert.Equal(t, 0, metric.CommittedIssues)
+	assert.Equal(t, 1, metric.AddedIssues)
+	assert.Equal(t, 0, metric.CarriedOverIssues)
+	assert.False(t, issueMetrics[0].IsCarriedOver)
+	assert.Len(t, burndowns, 3)
+	assert.Equal(t, 0, burndowns[0].ScopeIssues)
+	assert.Equal(t, 1, burndowns[2].RemainingIssues)
+}
@@ -22,6 +22,10 @@ type Options struct {
 	Plugin      string   `json:"plugin"`   // jira
 	ScopeIds    []string `json:"scopeIds"` // 68
 	ProjectName string   `json:"projectName"`
+	// WaitStatuses are the original statuses an issue in progress is waiting in, e.g. Blocked or In Review
+	WaitStatuses []string `json:"waitStatuses"`
+	// FlowDays is how many days of WIP snapshots and cumulative flow to calculate, 90 by default
+	FlowDays int `json:"flowDays"`
 }
 
 // TaskData converted parameter
@@ -85,6 +85,8 @@ func ExtractIssueChangelogs(subtaskCtx plugin.SubTaskContext) errors.Error {
 			}
 			return result, nil
 		},
+		// changelogs are the largest raw table of jira, Extract only reads the userFieldMap so it is safe to run in parallel
+		Workers: 4,
 	})
 
 	if err != nil {
@@ -63,6 +63,8 @@ func CollectIssueField(taskCtx plugin.SubTaskContext) errors.Error {
 			return data, nil
 		},
 		AfterResponse: ignoreHTTPStatus400,
+		// fields rarely change, reuse the previous response if the server supports conditional requests
+		ConditionalRequests: true,
 	})
 
 	if err != nil {
@@ -51,6 +51,8 @@ func CollectStatus(taskCtx plugin.SubTaskContext) errors.Error {
 		ApiClient:     data.ApiClient,
 		UrlTemplate:   "api/2/status",
 		GetTotalPages: GetTotalPagesFromResponse,
+		// statuses rarely change, reuse the previous response if the server supports conditional requests
+		ConditionalRequests: true,
 		ResponseParser: func(res *http.Response) ([]json.RawMessage, errors.Error) {
 			var data []json.RawMessage
 			err := api.UnmarshalResponse(res, &data)
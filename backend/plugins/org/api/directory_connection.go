@@ -0,0 +1,171 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/org/directory"
+	"github.com/apache/incubator-devlake/plugins/org/models"
+)
+
+type directoryTestResult struct {
+	Users  int `json:"users"`
+	Groups int `json:"groups"`
+}
+
+// PostDirectoryConnection creates a directory connection
+// @Summary      Create a directory connection
+// @Description  create an LDAP or SCIM 2.0 directory connection, run the org plugin with its connectionId to sync it
+// @Tags 		 plugins/org
+// @Accept       application/json
+// @Param        body body models.DirectoryConnection true "json body"
+// @Produce      json
+// @Success      200  {object} models.DirectoryConnection
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/directory-connections [post]
+func (h *Handlers) PostDirectoryConnection(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.DirectoryConnection{}
+	err := h.connectionHelper.Create(connection, input)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: connection.Sanitize(), Status: http.StatusOK}, nil
+}
+
+// ListDirectoryConnections lists all directory connections
+// @Summary      List directory connections
+// @Description  list directory connections, secrets are not returned
+// @Tags 		 plugins/org
+// @Produce      json
+// @Success      200  {object} []models.DirectoryConnection
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/directory-connections [get]
+func (h *Handlers) ListDirectoryConnections(_ *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	var connections []models.DirectoryConnection
+	err := h.connectionHelper.List(&connections)
+	if err != nil {
+		return nil, err
+	}
+	for i := range connections {
+		connections[i] = connections[i].Sanitize()
+	}
+	return &plugin.ApiResourceOutput{Body: connections, Status: http.StatusOK}, nil
+}
+
+// GetDirectoryConnection returns a directory connection
+// @Summary      Get a directory connection
+// @Description  get a directory connection, secrets are not returned
+// @Tags 		 plugins/org
+// @Param        connectionId path int true "connection id"
+// @Produce      json
+// @Success      200  {object} models.DirectoryConnection
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 404  {object} shared.ApiBody "Not Found"
+// @Router       /plugins/org/directory-connections/{connectionId} [get]
+func (h *Handlers) GetDirectoryConnection(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.DirectoryConnection{}
+	err := h.connectionHelper.First(connection, input.Params)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: connection.Sanitize(), Status: http.StatusOK}, nil
+}
+
+// PatchDirectoryConnection updates a directory connection
+// @Summary      Patch a directory connection
+// @Description  update a directory connection
+// @Tags 		 plugins/org
+// @Accept       application/json
+// @Param        connectionId path int true "connection id"
+// @Param        body body models.DirectoryConnection true "json body"
+// @Produce      json
+// @Success      200  {object} models.DirectoryConnection
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 404  {object} shared.ApiBody "Not Found"
+// @Router       /plugins/org/directory-connections/{connectionId} [patch]
+func (h *Handlers) PatchDirectoryConnection(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.DirectoryConnection{}
+	err := h.connectionHelper.Patch(connection, input)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: connection.Sanitize(), Status: http.StatusOK}, nil
+}
+
+// DeleteDirectoryConnection deletes a directory connection, the users and teams synced from it are kept
+// @Summary      Delete a directory connection
+// @Description  delete a directory connection, the users and teams synced from it are kept
+// @Tags 		 plugins/org
+// @Param        connectionId path int true "connection id"
+// @Produce      json
+// @Success      200  {object} models.DirectoryConnection
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 404  {object} shared.ApiBody "Not Found"
+// @Router       /plugins/org/directory-connections/{connectionId} [delete]
+func (h *Handlers) DeleteDirectoryConnection(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.DirectoryConnection{}
+	err := h.connectionHelper.First(connection, input.Params)
+	if err != nil {
+		return nil, err
+	}
+	err = h.store.deleteDirectoryConnection(connection)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: connection.Sanitize(), Status: http.StatusOK}, nil
+}
+
+// TestDirectoryConnection reads the directory without saving anything
+// @Summary      Test a directory connection
+// @Description  read users and groups from the directory and return how many were found
+// @Tags 		 plugins/org
+// @Param        connectionId path int true "connection id"
+// @Produce      json
+// @Success      200  {object} directoryTestResult
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 401  {object} shared.ApiBody "Unauthorized"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/directory-connections/{connectionId}/test [post]
+func (h *Handlers) TestDirectoryConnection(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.DirectoryConnection{}
+	err := h.connectionHelper.First(connection, input.Params)
+	if err != nil {
+		return nil, err
+	}
+	d, err := directory.NewDirectory(connection)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if input.Request != nil {
+		ctx = input.Request.Context()
+	}
+	snapshot, err := d.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{
+		Body:   directoryTestResult{Users: len(snapshot.Users), Groups: len(snapshot.Groups)},
+		Status: http.StatusOK,
+	}, nil
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/csv"
 	"github.com/apache/incubator-devlake/core/context"
 	"github.com/apache/incubator-devlake/core/errors"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
 	"github.com/gocarina/gocsv"
 	"net/http"
 )
@@ -28,11 +29,15 @@ import (
 const maxMemory = 32 << 20 // 32 MB
 
 type Handlers struct {
-	store store
+	store            store
+	connectionHelper *helper.ConnectionApiHelper
 }
 
 func NewHandlers(basicRes context.BasicRes) *Handlers {
-	return &Handlers{store: NewDbStore(basicRes.GetDal(), basicRes)}
+	return &Handlers{
+		store:            NewDbStore(basicRes.GetDal(), basicRes),
+		connectionHelper: helper.NewConnectionHelper(basicRes, nil, "org"),
+	}
 }
 
 func (h *Handlers) unmarshal(r *http.Request, items interface{}) errors.Error {
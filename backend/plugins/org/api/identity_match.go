@@ -0,0 +1,111 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/org/models"
+)
+
+type identityMatch struct {
+	models.IdentityMatch
+	AccountEmail    string `json:"accountEmail"`
+	AccountFullName string `json:"accountFullName"`
+	AccountUserName string `json:"accountUserName"`
+	UserName        string `json:"userName"`
+	UserEmail       string `json:"userEmail"`
+}
+
+type identityMatchesOutput struct {
+	Count   int64           `json:"count"`
+	Matches []identityMatch `json:"matches"`
+}
+
+type identityMatchReview struct {
+	Status string `json:"status" mapstructure:"status"`
+}
+
+// GetIdentityMatches returns the candidates found by connectUserAccountsFuzzy, pending ones by default
+// @Summary      List identity matches
+// @Description  list account/user candidates waiting for review or already reviewed
+// @Tags 		 plugins/org
+// @Param        status query string false "PENDING (default), AUTO_LINKED, CONFIRMED, REJECTED or ALL"
+// @Param        accountId query string false "account id"
+// @Param        page query int false "page number, default 1"
+// @Param        pageSize query int false "page size, default 50"
+// @Produce      json
+// @Success      200  {object} identityMatchesOutput
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/identity-matches [get]
+func (h *Handlers) GetIdentityMatches(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	status := input.Query.Get("status")
+	switch status {
+	case "":
+		status = models.IDENTITY_MATCH_PENDING
+	case "ALL":
+		status = ""
+	case models.IDENTITY_MATCH_PENDING, models.IDENTITY_MATCH_AUTO, models.IDENTITY_MATCH_CONFIRMED, models.IDENTITY_MATCH_REJECTED:
+	default:
+		return nil, errors.BadInput.New("invalid status " + status)
+	}
+	limit, offset := helper.GetLimitOffset(input.Query, "pageSize", "page")
+	matches, count, err := h.store.findIdentityMatches(status, input.Query.Get("accountId"), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Body: identityMatchesOutput{Count: count, Matches: matches}, Status: http.StatusOK}, nil
+}
+
+// ReviewIdentityMatch confirms or rejects an identity match, confirming links the account to the user
+// @Summary      Review an identity match
+// @Description  confirm or reject an account/user candidate
+// @Tags 		 plugins/org
+// @Accept       application/json
+// @Param        accountId path string true "account id"
+// @Param        userId path string true "user id"
+// @Param        body body identityMatchReview true "CONFIRMED or REJECTED"
+// @Produce      json
+// @Success      200
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 404  {object} shared.ApiBody "Not Found"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/identity-matches/{accountId}/{userId} [patch]
+func (h *Handlers) ReviewIdentityMatch(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	var review identityMatchReview
+	err := helper.Decode(input.Body, &review, nil)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "could not decode review")
+	}
+	if review.Status != models.IDENTITY_MATCH_CONFIRMED && review.Status != models.IDENTITY_MATCH_REJECTED {
+		return nil, errors.BadInput.New("status must be CONFIRMED or REJECTED")
+	}
+	reviewer := ""
+	if input.User != nil {
+		reviewer = input.User.Name
+	}
+	err = h.store.reviewIdentityMatch(input.Params["accountId"], input.Params["userId"], review.Status, reviewer)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ApiResourceOutput{Status: http.StatusOK}, nil
+}
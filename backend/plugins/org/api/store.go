@@ -23,7 +23,9 @@ import (
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
 	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/org/models"
 	"reflect"
+	"time"
 )
 
 type store interface {
@@ -34,6 +36,13 @@ type store interface {
 	findAllProjectMapping() ([]projectMapping, errors.Error)
 	deleteAll(i interface{}) errors.Error
 	save(items []interface{}) errors.Error
+	findIdentityMatches(status, accountId string, limit, offset int) ([]identityMatch, int64, errors.Error)
+	reviewIdentityMatch(accountId, userId, status, reviewer string) errors.Error
+	findTeam(id string) (*crossdomain.Team, errors.Error)
+	findSubTeams(id string) ([]crossdomain.Team, errors.Error)
+	countTeamEntities(teamId string, query teamMetricQuery, from, to *time.Time) (int64, errors.Error)
+	pluckTeamEntityValues(teamId string, query teamValueQuery, from, to *time.Time) ([]float64, errors.Error)
+	deleteDirectoryConnection(connection *models.DirectoryConnection) errors.Error
 }
 
 type dbStore struct {
@@ -122,3 +131,160 @@ func (d *dbStore) save(items []interface{}) errors.Error {
 	d.driver.Close()
 	return nil
 }
+
+func (d *dbStore) findIdentityMatches(status, accountId string, limit, offset int) ([]identityMatch, int64, errors.Error) {
+	clauses := []dal.Clause{
+		dal.From("_tool_org_identity_matches m"),
+		dal.Join("LEFT JOIN accounts a ON a.id = m.account_id"),
+		dal.Join("LEFT JOIN users u ON u.id = m.user_id"),
+	}
+	if status != "" {
+		clauses = append(clauses, dal.Where("m.status = ?", status))
+	}
+	if accountId != "" {
+		clauses = append(clauses, dal.Where("m.account_id = ?", accountId))
+	}
+	count, err := d.db.Count(clauses...)
+	if err != nil {
+		return nil, 0, err
+	}
+	clauses = append(clauses,
+		dal.Select("m.*, a.email AS account_email, a.full_name AS account_full_name, a.user_name AS account_user_name, u.name AS user_name, u.email AS user_email"),
+		dal.Orderby("m.account_id, m.score DESC"),
+		dal.Limit(limit),
+		dal.Offset(offset),
+	)
+	var matches []identityMatch
+	err = d.db.All(&matches, clauses...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return matches, count, nil
+}
+
+func (d *dbStore) reviewIdentityMatch(accountId, userId, status, reviewer string) (err errors.Error) {
+	match := &models.IdentityMatch{}
+	err = d.db.First(match, dal.Where("account_id = ? AND user_id = ?", accountId, userId))
+	if d.db.IsErrorNotFound(err) {
+		return errors.NotFound.New("identity match not found")
+	}
+	if err != nil {
+		return err
+	}
+	tx := d.db.Begin()
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+	now := time.Now()
+	if status == models.IDENTITY_MATCH_CONFIRMED {
+		// an account belongs to a single user, so confirming a match rejects the other candidates
+		err = tx.UpdateColumns(&models.IdentityMatch{}, []dal.DalSet{
+			{ColumnName: "status", Value: models.IDENTITY_MATCH_REJECTED},
+			{ColumnName: "reviewed_by", Value: reviewer},
+			{ColumnName: "reviewed_at", Value: now},
+		}, dal.Where("account_id = ? AND user_id != ? AND status IN ?", accountId, userId,
+			[]string{models.IDENTITY_MATCH_PENDING, models.IDENTITY_MATCH_AUTO}))
+		if err != nil {
+			return err
+		}
+		err = tx.CreateOrUpdate(&crossdomain.UserAccount{UserId: userId, AccountId: accountId})
+	} else {
+		err = tx.Delete(&crossdomain.UserAccount{}, dal.Where("account_id = ? AND user_id = ?", accountId, userId))
+	}
+	if err != nil {
+		return err
+	}
+	match.Status = status
+	match.ReviewedBy = reviewer
+	match.ReviewedAt = &now
+	return tx.Update(match)
+}
+
+func (d *dbStore) findTeam(id string) (*crossdomain.Team, errors.Error) {
+	team := &crossdomain.Team{}
+	err := d.db.First(team, dal.Where("id = ?", id))
+	if d.db.IsErrorNotFound(err) {
+		return nil, errors.NotFound.New("team not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+func (d *dbStore) findSubTeams(id string) ([]crossdomain.Team, errors.Error) {
+	var teams []crossdomain.Team
+	err := d.db.All(&teams, dal.Where("parent_id = ?", id), dal.Orderby("sorting_index, name"))
+	if err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+func (d *dbStore) countTeamEntities(teamId string, query teamMetricQuery, from, to *time.Time) (int64, errors.Error) {
+	return d.db.Count(teamEntityClauses(teamId, query, query.entityType, "id", from, to)...)
+}
+
+func (d *dbStore) pluckTeamEntityValues(teamId string, query teamValueQuery, from, to *time.Time) ([]float64, errors.Error) {
+	table := query.table
+	if table == "" {
+		table = query.entityType
+	}
+	clauses := teamEntityClauses(teamId, query.teamMetricQuery, table, query.idColumn, from, to)
+	clauses = append(clauses, dal.Where(query.valueColumn+" IS NOT NULL"))
+	var values []float64
+	err := d.db.Pluck(query.valueColumn, &values, clauses...)
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// teamEntityClauses selects the rows of the table related to the entities attributed to the team or its sub-teams,
+// idColumn of the table refers to the attributed entity
+func teamEntityClauses(teamId string, query teamMetricQuery, table, idColumn string, from, to *time.Time) []dal.Clause {
+	clauses := []dal.Clause{
+		dal.From(table),
+		dal.Where(idColumn+` IN (
+			SELECT ta.entity_id FROM team_attributions ta
+			JOIN team_closures tc ON tc.descendant_id = ta.team_id
+			WHERE tc.ancestor_id = ? AND ta.entity_type = ?
+		)`, teamId, query.entityType),
+		dal.Where(query.dateColumn + " IS NOT NULL"),
+	}
+	if from != nil {
+		clauses = append(clauses, dal.Where(query.dateColumn+" >= ?", from))
+	}
+	if to != nil {
+		clauses = append(clauses, dal.Where(query.dateColumn+" < ?", to))
+	}
+	if query.condition != "" {
+		clauses = append(clauses, dal.Where(query.condition))
+	}
+	return clauses
+}
+
+// deleteDirectoryConnection removes the connection and its sync bookkeeping, synced users and teams are kept
+func (d *dbStore) deleteDirectoryConnection(connection *models.DirectoryConnection) (err errors.Error) {
+	tx := d.db.Begin()
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+	err = tx.Delete(&models.DirectoryUser{}, dal.Where("connection_id = ?", connection.ID))
+	if err != nil {
+		return err
+	}
+	err = tx.Delete(&models.DirectoryGroup{}, dal.Where("connection_id = ?", connection.ID))
+	if err != nil {
+		return err
+	}
+	return tx.Delete(connection)
+}
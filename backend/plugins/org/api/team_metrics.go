@@ -0,0 +1,298 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+// issueFlowMetricsTable is maintained by the issue_trace plugin, it is referred by name since plugins don't import each other
+const issueFlowMetricsTable = "issue_flow_metrics"
+
+// teamMetricQuery counts the entities of a type attributed to a team, dateColumn decides which of them fall in the period
+type teamMetricQuery struct {
+	entityType string
+	dateColumn string
+	condition  string
+}
+
+// teamValueQuery plucks a column of the rows related to the entities attributed to a team
+type teamValueQuery struct {
+	teamMetricQuery
+	// table defaults to the entity table, idColumn of the table refers to the attributed entity
+	table       string
+	idColumn    string
+	valueColumn string
+}
+
+type teamMetrics struct {
+	TeamId                string         `json:"teamId"`
+	TeamName              string         `json:"teamName"`
+	PullRequestsOpened    int64          `json:"pullRequestsOpened"`
+	PullRequestsMerged    int64          `json:"pullRequestsMerged"`
+	Deployments           int64          `json:"deployments"`
+	SuccessfulDeployments int64          `json:"successfulDeployments"`
+	IssuesCreated         int64          `json:"issuesCreated"`
+	IssuesResolved        int64          `json:"issuesResolved"`
+	IncidentsCreated      int64          `json:"incidentsCreated"`
+	IncidentsResolved     int64          `json:"incidentsResolved"`
+	SubTeams              []*teamMetrics `json:"subTeams,omitempty"`
+}
+
+// GetTeamMetrics returns the metrics of a team rolled up over all its sub-teams, along with the rollups of its direct sub-teams
+// @Summary      Get team metrics
+// @Description  count pull requests, deployments, issues and incidents attributed to a team and its sub-teams,
+// @Description  requires the buildTeamClosure and attributeToTeams subtasks to have run
+// @Tags 		 plugins/org
+// @Param        teamId path string true "team id"
+// @Param        from query string false "start date (inclusive), e.g. 2025-01-01"
+// @Param        to query string false "end date (exclusive), e.g. 2025-04-01"
+// @Produce      json
+// @Success      200  {object} teamMetrics
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 404  {object} shared.ApiBody "Not Found"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/teams/{teamId}/metrics [get]
+func (h *Handlers) GetTeamMetrics(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	team, from, to, err := h.parseTeamQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := h.teamMetrics(team.Id, team.Name, from, to)
+	if err != nil {
+		return nil, err
+	}
+	subTeams, err := h.store.findSubTeams(team.Id)
+	if err != nil {
+		return nil, err
+	}
+	for _, subTeam := range subTeams {
+		subMetrics, err := h.teamMetrics(subTeam.Id, subTeam.Name, from, to)
+		if err != nil {
+			return nil, err
+		}
+		metrics.SubTeams = append(metrics.SubTeams, subMetrics)
+	}
+	return &plugin.ApiResourceOutput{Body: metrics, Status: http.StatusOK}, nil
+}
+
+func (h *Handlers) teamMetrics(teamId, teamName string, from, to *time.Time) (*teamMetrics, errors.Error) {
+	metrics := &teamMetrics{TeamId: teamId, TeamName: teamName}
+	for counter, query := range map[*int64]teamMetricQuery{
+		&metrics.PullRequestsOpened:    {code.PullRequest{}.TableName(), "created_date", ""},
+		&metrics.PullRequestsMerged:    {code.PullRequest{}.TableName(), "merged_date", ""},
+		&metrics.Deployments:           {devops.CICDDeployment{}.TableName(), "finished_date", ""},
+		&metrics.SuccessfulDeployments: {devops.CICDDeployment{}.TableName(), "finished_date", "result = '" + devops.RESULT_SUCCESS + "'"},
+		&metrics.IssuesCreated:         {ticket.Issue{}.TableName(), "created_date", ""},
+		&metrics.IssuesResolved:        {ticket.Issue{}.TableName(), "resolution_date", ""},
+		&metrics.IncidentsCreated:      {ticket.Incident{}.TableName(), "created_date", ""},
+		&metrics.IncidentsResolved:     {ticket.Incident{}.TableName(), "resolution_date", ""},
+	} {
+		count, err := h.store.countTeamEntities(teamId, query, from, to)
+		if err != nil {
+			return nil, err
+		}
+		*counter = count
+	}
+	return metrics, nil
+}
+
+type teamDoraMetrics struct {
+	TeamId   string `json:"teamId"`
+	TeamName string `json:"teamName"`
+	// Deployments are the successful deployments, FailedDeployments are those of them caused incidents
+	Deployments                 int64    `json:"deployments"`
+	FailedDeployments           int64    `json:"failedDeployments"`
+	ChangeFailureRate           *float64 `json:"changeFailureRate"`
+	MedianChangeLeadTimeMinutes *float64 `json:"medianChangeLeadTimeMinutes"`
+	MedianTimeToRestoreMinutes  *float64 `json:"medianTimeToRestoreMinutes"`
+}
+
+// GetTeamDoraMetrics returns the dora metrics of a team rolled up over all its sub-teams
+// @Summary      Get team dora metrics
+// @Description  dora metrics of the deployments, pull requests and incidents attributed to a team and its sub-teams,
+// @Description  requires the dora plugin and the buildTeamClosure and attributeToTeams subtasks to have run
+// @Tags 		 plugins/org
+// @Param        teamId path string true "team id"
+// @Param        from query string false "start date (inclusive), e.g. 2025-01-01"
+// @Param        to query string false "end date (exclusive), e.g. 2025-04-01"
+// @Produce      json
+// @Success      200  {object} teamDoraMetrics
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 404  {object} shared.ApiBody "Not Found"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/teams/{teamId}/dora [get]
+func (h *Handlers) GetTeamDoraMetrics(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	team, from, to, err := h.parseTeamQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	metrics := &teamDoraMetrics{TeamId: team.Id, TeamName: team.Name}
+	deployment := teamMetricQuery{devops.CICDDeployment{}.TableName(), "finished_date", "result = '" + devops.RESULT_SUCCESS + "'"}
+	metrics.Deployments, err = h.store.countTeamEntities(team.Id, deployment, from, to)
+	if err != nil {
+		return nil, err
+	}
+	deployment.condition += " AND id IN (SELECT deployment_id FROM " + crossdomain.ProjectIncidentDeploymentRelationship{}.TableName() + ")"
+	metrics.FailedDeployments, err = h.store.countTeamEntities(team.Id, deployment, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if metrics.Deployments > 0 {
+		rate := float64(metrics.FailedDeployments) / float64(metrics.Deployments)
+		metrics.ChangeFailureRate = &rate
+	}
+	leadTimes, err := h.store.pluckTeamEntityValues(team.Id, teamValueQuery{
+		teamMetricQuery: teamMetricQuery{entityType: code.PullRequest{}.TableName(), dateColumn: "pr_deployed_date"},
+		table:           crossdomain.ProjectPrMetric{}.TableName(),
+		idColumn:        "id",
+		valueColumn:     "pr_cycle_time",
+	}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	metrics.MedianChangeLeadTimeMinutes = median(leadTimes)
+	restoreTimes, err := h.store.pluckTeamEntityValues(team.Id, teamValueQuery{
+		teamMetricQuery: teamMetricQuery{entityType: ticket.Incident{}.TableName(), dateColumn: "resolution_date"},
+		idColumn:        "id",
+		valueColumn:     "lead_time_minutes",
+	}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	metrics.MedianTimeToRestoreMinutes = median(restoreTimes)
+	return &plugin.ApiResourceOutput{Body: metrics, Status: http.StatusOK}, nil
+}
+
+type teamIssueTraceMetrics struct {
+	TeamId                 string   `json:"teamId"`
+	TeamName               string   `json:"teamName"`
+	IssuesDone             int      `json:"issuesDone"`
+	MedianCycleTimeMinutes *float64 `json:"medianCycleTimeMinutes"`
+	AverageFlowEfficiency  *float64 `json:"averageFlowEfficiency"`
+}
+
+// GetTeamIssueTraceMetrics returns the issue flow metrics of a team rolled up over all its sub-teams
+// @Summary      Get team issue flow metrics
+// @Description  cycle time and flow efficiency of the issues attributed to a team and its sub-teams and done in the period,
+// @Description  requires the issue_trace plugin and the buildTeamClosure and attributeToTeams subtasks to have run
+// @Tags 		 plugins/org
+// @Param        teamId path string true "team id"
+// @Param        from query string false "start date (inclusive), e.g. 2025-01-01"
+// @Param        to query string false "end date (exclusive), e.g. 2025-04-01"
+// @Produce      json
+// @Success      200  {object} teamIssueTraceMetrics
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 404  {object} shared.ApiBody "Not Found"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router       /plugins/org/teams/{teamId}/issue_trace [get]
+func (h *Handlers) GetTeamIssueTraceMetrics(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	team, from, to, err := h.parseTeamQuery(input)
+	if err != nil {
+		return nil, err
+	}
+	flow := teamMetricQuery{entityType: ticket.Issue{}.TableName(), dateColumn: "done_date"}
+	cycleTimes, err := h.store.pluckTeamEntityValues(team.Id, teamValueQuery{
+		teamMetricQuery: flow,
+		table:           issueFlowMetricsTable,
+		idColumn:        "issue_id",
+		valueColumn:     "cycle_time_minutes",
+	}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	efficiencies, err := h.store.pluckTeamEntityValues(team.Id, teamValueQuery{
+		teamMetricQuery: flow,
+		table:           issueFlowMetricsTable,
+		idColumn:        "issue_id",
+		valueColumn:     "flow_efficiency",
+	}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	metrics := &teamIssueTraceMetrics{
+		TeamId:                 team.Id,
+		TeamName:               team.Name,
+		IssuesDone:             len(cycleTimes),
+		MedianCycleTimeMinutes: median(cycleTimes),
+		AverageFlowEfficiency:  average(efficiencies),
+	}
+	return &plugin.ApiResourceOutput{Body: metrics, Status: http.StatusOK}, nil
+}
+
+// parseTeamQuery loads the team of the path and the period of the query
+func (h *Handlers) parseTeamQuery(input *plugin.ApiResourceInput) (*crossdomain.Team, *time.Time, *time.Time, errors.Error) {
+	from, err := parseDate(input.Query.Get("from"))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	to, err := parseDate(input.Query.Get("to"))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	team, err := h.store.findTeam(input.Params["teamId"])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return team, from, to, nil
+}
+
+func median(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	m := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		m = (sorted[len(sorted)/2-1] + m) / 2
+	}
+	return &m
+}
+
+func average(values []float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	avg := sum / float64(len(values))
+	return &avg
+}
+
+func parseDate(s string) (*time.Time, errors.Error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(TimeFormat, s)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid date "+s)
+	}
+	return &t, nil
+}
@@ -0,0 +1,166 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/stretchr/testify/assert"
+)
+
+// teamMetricsStore serves the team metrics from memory, counts and values are keyed by team id and the table queried
+type teamMetricsStore struct {
+	store
+	teams  []crossdomain.Team
+	counts map[string]int64
+	values map[string][]float64
+	from   *time.Time
+	to     *time.Time
+}
+
+func (s *teamMetricsStore) findTeam(id string) (*crossdomain.Team, errors.Error) {
+	for i := range s.teams {
+		if s.teams[i].Id == id {
+			return &s.teams[i], nil
+		}
+	}
+	return nil, errors.NotFound.New("team not found")
+}
+
+func (s *teamMetricsStore) findSubTeams(id string) ([]crossdomain.Team, errors.Error) {
+	var teams []crossdomain.Team
+	for _, team := range s.teams {
+		if team.ParentId == id {
+			teams = append(teams, team)
+		}
+	}
+	return teams, nil
+}
+
+func (s *teamMetricsStore) countTeamEntities(teamId string, query teamMetricQuery, from, to *time.Time) (int64, errors.Error) {
+	s.from, s.to = from, to
+	return s.counts[teamId+" "+query.entityType+" "+query.dateColumn+" "+query.condition], nil
+}
+
+func (s *teamMetricsStore) pluckTeamEntityValues(teamId string, query teamValueQuery, from, to *time.Time) ([]float64, errors.Error) {
+	s.from, s.to = from, to
+	return s.values[teamId+" "+query.table+" "+query.valueColumn], nil
+}
+
+func newTeamMetricsStore() *teamMetricsStore {
+	team := func(id, name, parentId string) crossdomain.Team {
+		return crossdomain.Team{DomainEntity: domainlayer.DomainEntity{Id: id}, Name: name, ParentId: parentId}
+	}
+	return &teamMetricsStore{
+		teams: []crossdomain.Team{
+			team("org", "Org", ""),
+			team("platform", "Platform", "org"),
+			team("infra", "Infra", "platform"),
+		},
+		counts: make(map[string]int64),
+		values: make(map[string][]float64),
+	}
+}
+
+func TestGetTeamMetrics(t *testing.T) {
+	s := newTeamMetricsStore()
+	prs := code.PullRequest{}.TableName()
+	deployments := devops.CICDDeployment{}.TableName()
+	s.counts["org "+prs+" created_date "] = 10
+	s.counts["org "+prs+" merged_date "] = 8
+	s.counts["org "+deployments+" finished_date result = 'SUCCESS'"] = 3
+	s.counts["platform "+prs+" created_date "] = 4
+	h := &Handlers{store: s}
+
+	output, err := h.GetTeamMetrics(&plugin.ApiResourceInput{
+		Params: map[string]string{"teamId": "org"},
+		Query:  url.Values{"from": {"2025-01-01"}, "to": {"2025-04-01"}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, output.Status)
+	metrics := output.Body.(*teamMetrics)
+	assert.Equal(t, "Org", metrics.TeamName)
+	assert.Equal(t, int64(10), metrics.PullRequestsOpened)
+	assert.Equal(t, int64(8), metrics.PullRequestsMerged)
+	assert.Equal(t, int64(3), metrics.SuccessfulDeployments)
+	assert.Equal(t, int64(0), metrics.IssuesCreated)
+	// only the direct sub-teams are listed, their metrics are rolled up as well
+	assert.Len(t, metrics.SubTeams, 1)
+	assert.Equal(t, "platform", metrics.SubTeams[0].TeamId)
+	assert.Equal(t, int64(4), metrics.SubTeams[0].PullRequestsOpened)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), *s.from)
+	assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), *s.to)
+
+	_, err = h.GetTeamMetrics(&plugin.ApiResourceInput{Params: map[string]string{"teamId": "missing"}})
+	assert.Equal(t, errors.NotFound, err.GetType())
+	_, err = h.GetTeamMetrics(&plugin.ApiResourceInput{
+		Params: map[string]string{"teamId": "org"},
+		Query:  url.Values{"from": {"01/01/2025"}},
+	})
+	assert.Equal(t, errors.BadInput, err.GetType())
+}
+
+func TestGetTeamDoraMetrics(t *testing.T) {
+	s := newTeamMetricsStore()
+	deployments := devops.CICDDeployment{}.TableName()
+	s.counts["platform "+deployments+" finished_date result = 'SUCCESS'"] = 4
+	s.counts["platform "+deployments+" finished_date result = 'SUCCESS' AND id IN (SELECT deployment_id FROM project_incident_deployment_relationships)"] = 1
+	s.values["platform "+crossdomain.ProjectPrMetric{}.TableName()+" pr_cycle_time"] = []float64{120, 30, 60, 600}
+	h := &Handlers{store: s}
+
+	output, err := h.GetTeamDoraMetrics(&plugin.ApiResourceInput{Params: map[string]string{"teamId": "platform"}})
+	assert.Nil(t, err)
+	metrics := output.Body.(*teamDoraMetrics)
+	assert.Equal(t, int64(4), metrics.Deployments)
+	assert.Equal(t, int64(1), metrics.FailedDeployments)
+	assert.Equal(t, 0.25, *metrics.ChangeFailureRate)
+	assert.Equal(t, float64(90), *metrics.MedianChangeLeadTimeMinutes)
+	assert.Nil(t, metrics.MedianTimeToRestoreMinutes)
+	assert.Nil(t, s.from)
+	assert.Nil(t, s.to)
+}
+
+func TestGetTeamIssueTraceMetrics(t *testing.T) {
+	s := newTeamMetricsStore()
+	s.values["infra issue_flow_metrics cycle_time_minutes"] = []float64{300, 100, 200}
+	s.values["infra issue_flow_metrics flow_efficiency"] = []float64{0.5, 0.25}
+	h := &Handlers{store: s}
+
+	output, err := h.GetTeamIssueTraceMetrics(&plugin.ApiResourceInput{Params: map[string]string{"teamId": "infra"}})
+	assert.Nil(t, err)
+	metrics := output.Body.(*teamIssueTraceMetrics)
+	assert.Equal(t, 3, metrics.IssuesDone)
+	assert.Equal(t, float64(200), *metrics.MedianCycleTimeMinutes)
+	assert.Equal(t, 0.375, *metrics.AverageFlowEfficiency)
+
+	output, err = h.GetTeamIssueTraceMetrics(&plugin.ApiResourceInput{Params: map[string]string{"teamId": "org"}})
+	assert.Nil(t, err)
+	metrics = output.Body.(*teamIssueTraceMetrics)
+	assert.Equal(t, 0, metrics.IssuesDone)
+	assert.Nil(t, metrics.MedianCycleTimeMinutes)
+	assert.Nil(t, metrics.AverageFlowEfficiency)
+}
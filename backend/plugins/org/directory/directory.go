@@ -0,0 +1,209 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package directory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/plugins/org/models"
+)
+
+const (
+	defaultTimeout     = 30 * time.Second
+	defaultPageSize    = 500
+	defaultUserFilter  = "(|(objectClass=inetOrgPerson)(objectClass=person)(objectClass=user))"
+	defaultGroupFilter = "(|(objectClass=groupOfNames)(objectClass=groupOfUniqueNames)(objectClass=group))"
+)
+
+// User is a person as seen by the directory
+type User struct {
+	ExternalId string
+	UserName   string
+	Name       string
+	Email      string
+	Active     bool
+}
+
+// Group is a team as seen by the directory, members are external ids of users and nested groups
+type Group struct {
+	ExternalId     string
+	Name           string
+	MemberUserIds  []string
+	MemberGroupIds []string
+}
+
+// Snapshot is the full content of a directory at a point in time
+type Snapshot struct {
+	Users  []User
+	Groups []Group
+}
+
+// Directory reads users and groups from an identity source
+type Directory interface {
+	Fetch(ctx context.Context) (*Snapshot, errors.Error)
+}
+
+// NewDirectory returns the Directory matching the connection type
+func NewDirectory(connection *models.DirectoryConnection) (Directory, errors.Error) {
+	timeout := defaultTimeout
+	if connection.Timeout > 0 {
+		timeout = time.Duration(connection.Timeout) * time.Second
+	}
+	switch connection.Type {
+	case models.DIRECTORY_LDAP:
+		if connection.BaseDn == "" {
+			return nil, errors.BadInput.New("baseDn is required for LDAP directories")
+		}
+		return &ldapDirectory{connection: connection, timeout: timeout}, nil
+	case models.DIRECTORY_SCIM:
+		return newScimDirectory(connection, timeout), nil
+	}
+	return nil, errors.BadInput.New("unsupported directory type " + connection.Type)
+}
+
+type ldapDirectory struct {
+	connection *models.DirectoryConnection
+	timeout    time.Duration
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// Fetch binds with the configured account and reads users and groups, every user returned by the user filter
+// is active, so disabled accounts can be excluded with the filter
+func (d *ldapDirectory) Fetch(ctx context.Context) (*Snapshot, errors.Error) {
+	c := d.connection
+	idAttr := c.UserIdAttribute
+	userNameAttr := orDefault(c.UserNameAttribute, "uid")
+	nameAttr := orDefault(c.NameAttribute, "cn")
+	emailAttr := orDefault(c.EmailAttribute, "mail")
+	groupNameAttr := orDefault(c.GroupNameAttribute, "cn")
+	memberAttrs := []string{"member", "uniqueMember"}
+	if c.MemberAttribute != "" {
+		memberAttrs = []string{c.MemberAttribute}
+	}
+
+	client, err := dialLdap(ctx, c.Endpoint, c.InsecureSkipVerify, d.timeout)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to connect to the LDAP server")
+	}
+	defer client.close()
+	if c.Username != "" {
+		if err = client.startTls(); err != nil {
+			return nil, errors.Default.Wrap(err, "failed to start TLS, credentials are never sent over plain ldap://")
+		}
+		if err = client.bind(c.Username, c.Password); err != nil {
+			return nil, errors.Unauthorized.Wrap(err, "failed to bind to the LDAP server")
+		}
+	}
+
+	userAttrs := []string{userNameAttr, nameAttr, emailAttr}
+	if idAttr != "" {
+		userAttrs = append(userAttrs, idAttr)
+	}
+	userEntries, err := client.search(ctx, c.BaseDn, orDefault(c.UserFilter, defaultUserFilter), userAttrs, defaultPageSize)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to search LDAP users")
+	}
+	groupEntries, err := client.search(ctx, c.BaseDn, orDefault(c.GroupFilter, defaultGroupFilter), append([]string{groupNameAttr}, memberAttrs...), defaultPageSize)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to search LDAP groups")
+	}
+
+	snapshot := &Snapshot{}
+	// members are referenced by DN, which is also the default external id
+	userIds := make(map[string]string, len(userEntries))
+	for _, entry := range userEntries {
+		externalId := entry.dn
+		if idAttr != "" && entry.get(idAttr) != "" {
+			externalId = entry.get(idAttr)
+		}
+		userIds[normalizeDn(entry.dn)] = externalId
+		snapshot.Users = append(snapshot.Users, User{
+			ExternalId: externalId,
+			UserName:   entry.get(userNameAttr),
+			Name:       entry.get(nameAttr),
+			Email:      entry.get(emailAttr),
+			Active:     true,
+		})
+	}
+	groupDns := make(map[string]string, len(groupEntries))
+	for _, entry := range groupEntries {
+		groupDns[normalizeDn(entry.dn)] = entry.dn
+	}
+	for _, entry := range groupEntries {
+		group := Group{ExternalId: entry.dn, Name: orDefault(entry.get(groupNameAttr), entry.dn)}
+		for _, attr := range memberAttrs {
+			for _, member := range entry.getAll(attr) {
+				dn := normalizeDn(member)
+				if userId, ok := userIds[dn]; ok {
+					group.MemberUserIds = append(group.MemberUserIds, userId)
+				} else if groupDn, ok := groupDns[dn]; ok {
+					group.MemberGroupIds = append(group.MemberGroupIds, groupDn)
+				}
+			}
+		}
+		snapshot.Groups = append(snapshot.Groups, group)
+	}
+	return snapshot, nil
+}
+
+// normalizeDn makes DNs comparable, attribute names and values are case-insensitive for the usual schemas
+func normalizeDn(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, part := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(part))
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParentIds resolves the parent of every group from the nested memberships, a group nested in several
+// groups gets the first one by name since a team has a single parent
+func ParentIds(groups []Group) map[string]string {
+	names := make(map[string]string, len(groups))
+	for _, g := range groups {
+		names[g.ExternalId] = g.Name
+	}
+	candidates := make(map[string][]string)
+	for _, g := range groups {
+		for _, child := range g.MemberGroupIds {
+			if child != g.ExternalId {
+				candidates[child] = append(candidates[child], g.ExternalId)
+			}
+		}
+	}
+	parents := make(map[string]string, len(candidates))
+	for child, ps := range candidates {
+		sort.Slice(ps, func(i, j int) bool {
+			if names[ps[i]] != names[ps[j]] {
+				return names[ps[i]] < names[ps[j]]
+			}
+			return ps[i] < ps[j]
+		})
+		parents[child] = ps[0]
+	}
+	return parents
+}
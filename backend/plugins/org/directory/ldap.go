@@ -0,0 +1,135 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package directory
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ldapClient wraps a go-ldap connection with the few operations the directory sync needs
+type ldapClient struct {
+	conn      *ldap.Conn
+	tlsConfig *tls.Config
+	isTls     bool
+	stop      chan struct{}
+}
+
+type ldapEntry struct {
+	dn         string
+	attributes map[string][]string
+}
+
+// get returns the first value of the attribute, attribute names are case-insensitive
+func (e *ldapEntry) get(attr string) string {
+	if values := e.attributes[strings.ToLower(attr)]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (e *ldapEntry) getAll(attr string) []string {
+	return e.attributes[strings.ToLower(attr)]
+}
+
+func dialLdap(ctx context.Context, endpoint string, insecureSkipVerify bool, timeout time.Duration) (*ldapClient, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ldap" && u.Scheme != "ldaps" {
+		return nil, fmt.Errorf("unsupported LDAP scheme %q, use ldap:// or ldaps://", u.Scheme)
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: insecureSkipVerify, // #nosec G402 opt-in for self-signed directories
+	}
+	conn, err := ldap.DialURL(endpoint, ldap.DialWithDialer(&net.Dialer{Timeout: timeout}), ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(timeout)
+	client := &ldapClient{conn: conn, tlsConfig: tlsConfig, isTls: u.Scheme == "ldaps", stop: make(chan struct{})}
+	// go-ldap has no context support, closing the connection aborts the pending request
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-client.stop:
+		}
+	}()
+	return client, nil
+}
+
+// startTls upgrades a plain ldap:// connection, it never falls back to cleartext
+func (c *ldapClient) startTls() error {
+	if c.isTls {
+		return nil
+	}
+	if err := c.conn.StartTLS(c.tlsConfig); err != nil {
+		return err
+	}
+	c.isTls = true
+	return nil
+}
+
+// bind refuses to send the password over a connection that is not encrypted
+func (c *ldapClient) bind(dn, password string) error {
+	if !c.isTls {
+		return fmt.Errorf("refusing to bind over an unencrypted connection")
+	}
+	return c.conn.Bind(dn, password)
+}
+
+// search runs a subtree search and follows the paged results control until the server has no more pages
+func (c *ldapClient) search(ctx context.Context, baseDn, filter string, attributes []string, pageSize int) ([]*ldapEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	request := ldap.NewSearchRequest(baseDn, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false, filter, attributes, nil)
+	result, err := c.conn.SearchWithPaging(request, uint32(pageSize))
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	entries := make([]*ldapEntry, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		entry := &ldapEntry{dn: e.DN, attributes: make(map[string][]string, len(e.Attributes))}
+		for _, attr := range e.Attributes {
+			name := strings.ToLower(attr.Name)
+			entry.attributes[name] = append(entry.attributes[name], attr.Values...)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (c *ldapClient) close() {
+	close(c.stop)
+	_ = c.conn.Unbind()
+	_ = c.conn.Close()
+}
@@ -0,0 +1,322 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package directory
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/plugins/org/models"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const startTlsOid = "1.3.6.1.4.1.1466.20037"
+
+// ldapStandIn is a tiny in-memory LDAP server answering StartTLS, simple binds and paged searches one entry per page
+type ldapStandIn struct {
+	listener  net.Listener
+	bindDn    string
+	password  string
+	entries   []*ldapEntry
+	tlsConfig *tls.Config // nil when the server does not support StartTLS
+
+	mu    sync.Mutex
+	binds []bool // whether each bind request arrived over TLS
+}
+
+func newLdapStandIn(t *testing.T, bindDn, password string, entries []*ldapEntry, startTls bool) *ldapStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &ldapStandIn{listener: listener, bindDn: bindDn, password: password, entries: entries}
+	if startTls {
+		s.tlsConfig = &tls.Config{Certificates: []tls.Certificate{selfSignedCert(t)}}
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func selfSignedCert(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func (s *ldapStandIn) endpoint() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *ldapStandIn) bindsOverTls() []bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.binds
+}
+
+func ldapResult(tag ber.Tag, code int64) *ber.Packet {
+	p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+	p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, ""))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	return p
+}
+
+func (s *ldapStandIn) serve(conn net.Conn) {
+	defer func() { conn.Close() }()
+	isTls := false
+	for {
+		message, err := ber.ReadPacket(conn)
+		if err != nil || len(message.Children) < 2 {
+			return
+		}
+		id, op := message.Children[0].Value.(int64), message.Children[1]
+		reply := func(p *ber.Packet, controls ...ldap.Control) {
+			m := ber.NewSequence("")
+			m.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+			m.AppendChild(p)
+			if len(controls) > 0 {
+				c := ber.Encode(ber.ClassContext, ber.TypeConstructed, 0, nil, "")
+				for _, control := range controls {
+					c.AppendChild(control.Encode())
+				}
+				m.AppendChild(c)
+			}
+			_, _ = conn.Write(m.Bytes())
+		}
+		switch op.Tag {
+		case ldap.ApplicationExtendedRequest:
+			if s.tlsConfig == nil || isTls || op.Children[0].Data.String() != startTlsOid {
+				reply(ldapResult(ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError))
+				continue
+			}
+			reply(ldapResult(ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess))
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if tlsConn.Handshake() != nil {
+				return
+			}
+			conn, isTls = tlsConn, true
+		case ldap.ApplicationBindRequest:
+			s.mu.Lock()
+			s.binds = append(s.binds, isTls)
+			s.mu.Unlock()
+			code := int64(ldap.LDAPResultSuccess)
+			if op.Children[1].Data.String() != s.bindDn || op.Children[2].Data.String() != s.password {
+				code = ldap.LDAPResultInvalidCredentials
+			}
+			reply(ldapResult(ldap.ApplicationBindResponse, code))
+		case ldap.ApplicationSearchRequest:
+			var matched []*ldapEntry
+			for _, e := range s.entries {
+				if strings.HasSuffix(normalizeDn(e.dn), normalizeDn(op.Children[0].Data.String())) && matchFilter(e, op.Children[6]) {
+					matched = append(matched, e)
+				}
+			}
+			offset := 0
+			if len(message.Children) > 2 {
+				for _, c := range message.Children[2].Children {
+					if control, err := ldap.DecodeControl(c); err == nil {
+						if paging, ok := control.(*ldap.ControlPaging); ok {
+							offset, _ = strconv.Atoi(string(paging.Cookie))
+						}
+					}
+				}
+			}
+			if offset < len(matched) {
+				e := matched[offset]
+				result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+				result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, ""))
+				attrs := ber.NewSequence("")
+				for name, values := range e.attributes {
+					attr := ber.NewSequence("")
+					attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, ""))
+					set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+					for _, v := range values {
+						set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, ""))
+					}
+					attr.AppendChild(set)
+					attrs.AppendChild(attr)
+				}
+				result.AppendChild(attrs)
+				reply(result)
+			}
+			paging := ldap.NewControlPaging(0)
+			if offset+1 < len(matched) {
+				paging.SetCookie([]byte(strconv.Itoa(offset + 1)))
+			}
+			reply(ldapResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess), paging)
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func matchFilter(e *ldapEntry, f *ber.Packet) bool {
+	switch f.Tag {
+	case ldap.FilterAnd:
+		for _, child := range f.Children {
+			if !matchFilter(e, child) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range f.Children {
+			if matchFilter(e, child) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return !matchFilter(e, f.Children[0])
+	case ldap.FilterPresent:
+		return len(e.getAll(f.Data.String())) > 0
+	case ldap.FilterEqualityMatch:
+		for _, v := range e.getAll(f.Children[0].Data.String()) {
+			if strings.EqualFold(v, f.Children[1].Data.String()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func entry(dn string, attrs map[string][]string) *ldapEntry {
+	e := &ldapEntry{dn: dn, attributes: make(map[string][]string)}
+	for k, v := range attrs {
+		e.attributes[strings.ToLower(k)] = v
+	}
+	return e
+}
+
+func TestLdapDirectory(t *testing.T) {
+	server := newLdapStandIn(t, "cn=admin,dc=example,dc=org", "secret", []*ldapEntry{
+		entry("uid=jdoe,ou=people,dc=example,dc=org", map[string][]string{
+			"objectClass": {"inetOrgPerson"}, "uid": {"jdoe"}, "cn": {"John Doe"}, "mail": {"jdoe@example.org"},
+		}),
+		entry("uid=asmith,ou=people,dc=example,dc=org", map[string][]string{
+			"objectClass": {"inetOrgPerson"}, "uid": {"asmith"}, "cn": {"Alice Smith"}, "mail": {"asmith@example.org"},
+		}),
+		entry("uid=gone,ou=people,dc=example,dc=org", map[string][]string{
+			"objectClass": {"inetOrgPerson"}, "uid": {"gone"}, "cn": {"Gone"}, "employeeType": {"disabled"},
+		}),
+		entry("cn=engineering,ou=groups,dc=example,dc=org", map[string][]string{
+			"objectClass": {"groupOfNames"}, "cn": {"Engineering"},
+			"member": {"UID=jdoe,ou=people,dc=example,dc=org", "cn=platform,ou=groups,dc=example,dc=org", "uid=gone,ou=people,dc=example,dc=org"},
+		}),
+		entry("cn=platform,ou=groups,dc=example,dc=org", map[string][]string{
+			"objectClass": {"groupOfNames"}, "cn": {"Platform"}, "member": {"uid=asmith,ou=people,dc=example,dc=org"},
+		}),
+	}, true)
+	connection := &models.DirectoryConnection{
+		Type:               models.DIRECTORY_LDAP,
+		Endpoint:           server.endpoint(),
+		Username:           "cn=admin,dc=example,dc=org",
+		Password:           "secret",
+		InsecureSkipVerify: true,
+		BaseDn:             "dc=example,dc=org",
+		UserFilter:         "(&(objectClass=inetOrgPerson)(!(employeeType=disabled)))",
+	}
+	d, err := NewDirectory(connection)
+	require.NoError(t, err)
+	snapshot, err := d.Fetch(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []User{
+		{ExternalId: "uid=jdoe,ou=people,dc=example,dc=org", UserName: "jdoe", Name: "John Doe", Email: "jdoe@example.org", Active: true},
+		{ExternalId: "uid=asmith,ou=people,dc=example,dc=org", UserName: "asmith", Name: "Alice Smith", Email: "asmith@example.org", Active: true},
+	}, snapshot.Users)
+	assert.Equal(t, []Group{
+		{
+			ExternalId:     "cn=engineering,ou=groups,dc=example,dc=org",
+			Name:           "Engineering",
+			MemberUserIds:  []string{"uid=jdoe,ou=people,dc=example,dc=org"},
+			MemberGroupIds: []string{"cn=platform,ou=groups,dc=example,dc=org"},
+		},
+		{
+			ExternalId:    "cn=platform,ou=groups,dc=example,dc=org",
+			Name:          "Platform",
+			MemberUserIds: []string{"uid=asmith,ou=people,dc=example,dc=org"},
+		},
+	}, snapshot.Groups)
+	assert.Equal(t, map[string]string{
+		"cn=platform,ou=groups,dc=example,dc=org": "cn=engineering,ou=groups,dc=example,dc=org",
+	}, ParentIds(snapshot.Groups))
+
+	connection.Password = "wrong"
+	_, err = d.Fetch(context.Background())
+	if assert.Error(t, err) {
+		assert.Equal(t, errors.Unauthorized, err.GetType())
+	}
+	// both binds happened after StartTLS
+	assert.Equal(t, []bool{true, true}, server.bindsOverTls())
+}
+
+func TestLdapDirectoryRefusesCleartextBind(t *testing.T) {
+	server := newLdapStandIn(t, "cn=admin,dc=example,dc=org", "secret", []*ldapEntry{
+		entry("uid=jdoe,ou=people,dc=example,dc=org", map[string][]string{"objectClass": {"inetOrgPerson"}, "uid": {"jdoe"}}),
+	}, false)
+	connection := &models.DirectoryConnection{
+		Type:     models.DIRECTORY_LDAP,
+		Endpoint: server.endpoint(),
+		Username: "cn=admin,dc=example,dc=org",
+		Password: "secret",
+		BaseDn:   "dc=example,dc=org",
+	}
+	d, err := NewDirectory(connection)
+	require.NoError(t, err)
+	_, err = d.Fetch(context.Background())
+	assert.Error(t, err)
+	assert.Empty(t, server.bindsOverTls())
+
+	// anonymous searches carry no credentials and may stay on plain ldap://
+	connection.Username, connection.Password = "", ""
+	snapshot, err := d.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, snapshot.Users, 1)
+	assert.Empty(t, server.bindsOverTls())
+}
@@ -0,0 +1,86 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package directory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/apache/incubator-devlake/plugins/org/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScimDirectory(t *testing.T) {
+	resources := map[string][]map[string]interface{}{
+		"/scim/v2/Users": {
+			{"id": "u1", "userName": "jdoe", "displayName": "John Doe", "emails": []map[string]interface{}{
+				{"value": "john@home.example"}, {"value": "jdoe@example.org", "primary": true},
+			}},
+			{"id": "u2", "userName": "asmith", "name": map[string]string{"givenName": "Alice", "familyName": "Smith"}, "active": false},
+		},
+		"/scim/v2/Groups": {
+			{"id": "g1", "displayName": "Engineering", "members": []map[string]string{{"value": "u1", "type": "User"}, {"value": "g2"}}},
+			{"id": "g2", "displayName": "Platform", "members": []map[string]string{{"value": "u2"}}},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		all, ok := resources[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// serve one resource per page regardless of count to exercise paging
+		start, _ := strconv.Atoi(r.URL.Query().Get("startIndex"))
+		page := all[start-1 : start]
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"schemas":      []string{"urn:ietf:params:scim:api:messages:2.0:ListResponse"},
+			"totalResults": len(all),
+			"startIndex":   start,
+			"itemsPerPage": len(page),
+			"Resources":    page,
+		})
+	}))
+	defer server.Close()
+
+	d, err := NewDirectory(&models.DirectoryConnection{Type: models.DIRECTORY_SCIM, Endpoint: server.URL + "/scim/v2/", Token: "token"})
+	require.NoError(t, err)
+	snapshot, err := d.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []User{
+		{ExternalId: "u1", UserName: "jdoe", Name: "John Doe", Email: "jdoe@example.org", Active: true},
+		{ExternalId: "u2", UserName: "asmith", Name: "Alice Smith", Active: false},
+	}, snapshot.Users)
+	assert.Equal(t, []Group{
+		{ExternalId: "g1", Name: "Engineering", MemberUserIds: []string{"u1"}, MemberGroupIds: []string{"g2"}},
+		{ExternalId: "g2", Name: "Platform", MemberUserIds: []string{"u2"}},
+	}, snapshot.Groups)
+
+	d, err = NewDirectory(&models.DirectoryConnection{Type: models.DIRECTORY_SCIM, Endpoint: server.URL + "/scim/v2", Token: "wrong"})
+	require.NoError(t, err)
+	_, err = d.Fetch(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/helpers/e2ehelper"
+	"github.com/apache/incubator-devlake/plugins/org/impl"
+	"github.com/apache/incubator-devlake/plugins/org/tasks"
+)
+
+func TestAttributeToTeamsDataFlow(t *testing.T) {
+	dataflowTester := e2ehelper.NewDataFlowTester(t, "org", impl.Org{})
+	taskData := &tasks.TaskData{
+		Options: &tasks.Options{},
+	}
+
+	dataflowTester.ImportCsvIntoTabler("./team_attribution/raw_tables/team_users.csv", &crossdomain.TeamUser{})
+	dataflowTester.ImportCsvIntoTabler("./team_attribution/raw_tables/accounts.csv", &crossdomain.Account{})
+	dataflowTester.ImportCsvIntoTabler("./team_attribution/raw_tables/user_accounts.csv", &crossdomain.UserAccount{})
+	dataflowTester.ImportCsvIntoTabler("./team_attribution/raw_tables/pull_requests.csv", &code.PullRequest{})
+	dataflowTester.ImportCsvIntoTabler("./team_attribution/raw_tables/commits.csv", &code.Commit{})
+	dataflowTester.ImportCsvIntoTabler("./team_attribution/raw_tables/cicd_deployment_commits.csv", &devops.CicdDeploymentCommit{})
+	dataflowTester.ImportCsvIntoTabler("./team_attribution/raw_tables/issues.csv", &ticket.Issue{})
+	dataflowTester.ImportCsvIntoTabler("./team_attribution/raw_tables/incidents.csv", &ticket.Incident{})
+
+	// entities are attributed to the direct teams of the users only, unmapped accounts are skipped
+	dataflowTester.FlushTabler(&crossdomain.TeamAttribution{})
+	dataflowTester.Subtask(tasks.AttributeToTeamsMeta, taskData)
+	dataflowTester.VerifyTableWithOptions(&crossdomain.TeamAttribution{}, e2ehelper.TableOptions{
+		CSVRelPath:  "./team_attribution/snapshot_tables/team_attributions.csv",
+		IgnoreTypes: []interface{}{common.NoPKModel{}},
+	})
+}
@@ -25,6 +25,8 @@ import (
 	"github.com/apache/incubator-devlake/core/plugin"
 	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
 	"github.com/apache/incubator-devlake/plugins/org/api"
+	"github.com/apache/incubator-devlake/plugins/org/models"
+	"github.com/apache/incubator-devlake/plugins/org/models/migrationscripts"
 	"github.com/apache/incubator-devlake/plugins/org/tasks"
 )
 
@@ -33,6 +35,7 @@ var _ interface {
 	plugin.PluginInit
 	plugin.PluginTask
 	plugin.PluginModel
+	plugin.PluginMigration
 	plugin.ProjectMapper
 } = (*Org)(nil)
 
@@ -46,7 +49,12 @@ func (p *Org) Init(basicRes context.BasicRes) errors.Error {
 }
 
 func (p Org) GetTablesInfo() []dal.Tabler {
-	return []dal.Tabler{}
+	return []dal.Tabler{
+		&models.IdentityMatch{},
+		&models.DirectoryConnection{},
+		&models.DirectoryUser{},
+		&models.DirectoryGroup{},
+	}
 }
 
 func (p Org) Description() string {
@@ -59,7 +67,11 @@ func (p Org) Name() string {
 
 func (p Org) SubTaskMetas() []plugin.SubTaskMeta {
 	return []plugin.SubTaskMeta{
+		tasks.SyncDirectoryMeta,
 		tasks.ConnectUserAccountsExactMeta,
+		tasks.ConnectUserAccountsFuzzyMeta,
+		tasks.BuildTeamClosureMeta,
+		tasks.AttributeToTeamsMeta,
 		tasks.SetProjectMappingMeta,
 	}
 }
@@ -94,9 +106,24 @@ func (p Org) PrepareTaskData(taskCtx plugin.TaskContext, options map[string]inte
 	taskData := &tasks.TaskData{
 		Options: &op,
 	}
+	// connectionId is also used without a directory behind it, in which case there is nothing to sync
+	if op.ConnectionId != 0 {
+		db := taskCtx.GetDal()
+		connection := &models.DirectoryConnection{}
+		err = db.First(connection, dal.Where("id = ?", op.ConnectionId))
+		if err == nil {
+			taskData.Connection = connection
+		} else if !db.IsErrorNotFound(err) {
+			return nil, errors.Default.Wrap(err, "could not get directory connection")
+		}
+	}
 	return taskData, nil
 }
 
+func (p Org) MigrationScripts() []plugin.MigrationScript {
+	return migrationscripts.All()
+}
+
 func (p Org) RootPkgPath() string {
 	return "github.com/apache/incubator-devlake/plugins/org"
 }
@@ -120,5 +147,32 @@ func (p Org) ApiResources() map[string]map[string]plugin.ApiResourceHandler {
 			"GET": p.handlers.GetProjectMapping,
 			"PUT": p.handlers.CreateProjectMapping,
 		},
+		"directory-connections": {
+			"POST": p.handlers.PostDirectoryConnection,
+			"GET":  p.handlers.ListDirectoryConnections,
+		},
+		"directory-connections/:connectionId": {
+			"GET":    p.handlers.GetDirectoryConnection,
+			"PATCH":  p.handlers.PatchDirectoryConnection,
+			"DELETE": p.handlers.DeleteDirectoryConnection,
+		},
+		"directory-connections/:connectionId/test": {
+			"POST": p.handlers.TestDirectoryConnection,
+		},
+		"teams/:teamId/metrics": {
+			"GET": p.handlers.GetTeamMetrics,
+		},
+		"teams/:teamId/dora": {
+			"GET": p.handlers.GetTeamDoraMetrics,
+		},
+		"teams/:teamId/issue_trace": {
+			"GET": p.handlers.GetTeamIssueTraceMetrics,
+		},
+		"identity-matches": {
+			"GET": p.handlers.GetIdentityMatches,
+		},
+		"identity-matches/:accountId/:userId": {
+			"PATCH": p.handlers.ReviewIdentityMatch,
+		},
 	}
 }
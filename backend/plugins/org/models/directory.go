@@ -0,0 +1,92 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+const (
+	DIRECTORY_LDAP = "ldap"
+	DIRECTORY_SCIM = "scim"
+)
+
+// DirectoryConnection is an LDAP directory or a SCIM 2.0 endpoint that users, teams and memberships are synced from.
+// The LDAP specific fields fall back to the usual inetOrgPerson/groupOfNames attributes when left empty.
+type DirectoryConnection struct {
+	helper.BaseConnection `mapstructure:",squash"`
+	Type                  string `gorm:"type:varchar(10)" mapstructure:"type" json:"type" validate:"required,oneof=ldap scim"`
+	// Endpoint is ldap(s)://host:port for LDAP and the base url of the SCIM api, e.g. https://idp.example.com/scim/v2.
+	// ldap:// connections with a Username are upgraded with StartTLS before binding.
+	Endpoint           string `mapstructure:"endpoint" json:"endpoint" validate:"required"`
+	Username           string `mapstructure:"username" json:"username"`
+	Password           string `gorm:"serializer:encdec" mapstructure:"password" json:"password"`
+	Token              string `gorm:"serializer:encdec" mapstructure:"token" json:"token"`
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify" json:"insecureSkipVerify"`
+	Timeout            int    `mapstructure:"timeout" json:"timeout"`
+	BaseDn             string `mapstructure:"baseDn" json:"baseDn"`
+	UserFilter         string `mapstructure:"userFilter" json:"userFilter"`
+	GroupFilter        string `mapstructure:"groupFilter" json:"groupFilter"`
+	UserIdAttribute    string `gorm:"type:varchar(100)" mapstructure:"userIdAttribute" json:"userIdAttribute"`
+	UserNameAttribute  string `gorm:"type:varchar(100)" mapstructure:"userNameAttribute" json:"userNameAttribute"`
+	NameAttribute      string `gorm:"type:varchar(100)" mapstructure:"nameAttribute" json:"nameAttribute"`
+	EmailAttribute     string `gorm:"type:varchar(100)" mapstructure:"emailAttribute" json:"emailAttribute"`
+	GroupNameAttribute string `gorm:"type:varchar(100)" mapstructure:"groupNameAttribute" json:"groupNameAttribute"`
+	MemberAttribute    string `gorm:"type:varchar(100)" mapstructure:"memberAttribute" json:"memberAttribute"`
+}
+
+func (DirectoryConnection) TableName() string {
+	return "_tool_org_directory_connections"
+}
+
+func (connection DirectoryConnection) Sanitize() DirectoryConnection {
+	connection.Password = ""
+	connection.Token = ""
+	return connection
+}
+
+// DirectoryUser tracks the users synced from a directory so that the ones leaving it can be deactivated
+type DirectoryUser struct {
+	ConnectionId  uint64 `gorm:"primaryKey"`
+	ExternalId    string `gorm:"primaryKey;type:varchar(255)"`
+	UserId        string `gorm:"type:varchar(255);index"`
+	UserName      string `gorm:"type:varchar(255)"`
+	Active        bool
+	DeactivatedAt *time.Time
+	common.NoPKModel
+}
+
+func (DirectoryUser) TableName() string {
+	return "_tool_org_directory_users"
+}
+
+// DirectoryGroup tracks the teams synced from a directory so that the ones removed from it can be deleted
+type DirectoryGroup struct {
+	ConnectionId uint64 `gorm:"primaryKey"`
+	ExternalId   string `gorm:"primaryKey;type:varchar(255)"`
+	TeamId       string `gorm:"type:varchar(255)"`
+	Name         string `gorm:"type:varchar(255)"`
+	common.NoPKModel
+}
+
+func (DirectoryGroup) TableName() string {
+	return "_tool_org_directory_groups"
+}
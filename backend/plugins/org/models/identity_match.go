@@ -0,0 +1,49 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+const (
+	IDENTITY_MATCH_PENDING   = "PENDING"
+	IDENTITY_MATCH_AUTO      = "AUTO_LINKED"
+	IDENTITY_MATCH_CONFIRMED = "CONFIRMED"
+	IDENTITY_MATCH_REJECTED  = "REJECTED"
+)
+
+// IdentityMatch is a candidate link between an account and a user found by the identity resolver.
+// Matches that are not confident enough to be linked automatically stay PENDING until someone
+// confirms or rejects them, rejected pairs are never proposed again.
+type IdentityMatch struct {
+	common.NoPKModel
+	AccountId  string     `gorm:"primaryKey;type:varchar(255)" json:"accountId"`
+	UserId     string     `gorm:"primaryKey;type:varchar(255)" json:"userId"`
+	Score      float64    `json:"score"`
+	Rules      string     `gorm:"type:varchar(255)" json:"rules"`
+	Status     string     `gorm:"type:varchar(20);index" json:"status"`
+	ReviewedBy string     `gorm:"type:varchar(255)" json:"reviewedBy"`
+	ReviewedAt *time.Time `json:"reviewedAt"`
+}
+
+func (IdentityMatch) TableName() string {
+	return "_tool_org_identity_matches"
+}
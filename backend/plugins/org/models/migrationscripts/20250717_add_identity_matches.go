@@ -0,0 +1,56 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+type addIdentityMatches struct{}
+
+type identityMatch20250717 struct {
+	archived.NoPKModel
+	AccountId  string `gorm:"primaryKey;type:varchar(255)"`
+	UserId     string `gorm:"primaryKey;type:varchar(255)"`
+	Score      float64
+	Rules      string `gorm:"type:varchar(255)"`
+	Status     string `gorm:"type:varchar(20);index"`
+	ReviewedBy string `gorm:"type:varchar(255)"`
+	ReviewedAt *time.Time
+}
+
+func (identityMatch20250717) TableName() string {
+	return "_tool_org_identity_matches"
+}
+
+func (*addIdentityMatches) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(basicRes, &identityMatch20250717{})
+}
+
+func (*addIdentityMatches) Version() uint64 {
+	return 20250717100000
+}
+
+func (*addIdentityMatches) Name() string {
+	return "add _tool_org_identity_matches"
+}
@@ -0,0 +1,96 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrationscripts
+
+import (
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/migrationscripts/archived"
+	"github.com/apache/incubator-devlake/helpers/migrationhelper"
+)
+
+type addDirectorySync struct{}
+
+type directoryConnection20250731 struct {
+	archived.BaseConnection
+	Type               string `gorm:"type:varchar(10)"`
+	Endpoint           string
+	Username           string
+	Password           string `gorm:"serializer:encdec"`
+	Token              string `gorm:"serializer:encdec"`
+	InsecureSkipVerify bool
+	Timeout            int
+	BaseDn             string
+	UserFilter         string
+	GroupFilter        string
+	UserIdAttribute    string `gorm:"type:varchar(100)"`
+	UserNameAttribute  string `gorm:"type:varchar(100)"`
+	NameAttribute      string `gorm:"type:varchar(100)"`
+	EmailAttribute     string `gorm:"type:varchar(100)"`
+	GroupNameAttribute string `gorm:"type:varchar(100)"`
+	MemberAttribute    string `gorm:"type:varchar(100)"`
+}
+
+func (directoryConnection20250731) TableName() string {
+	return "_tool_org_directory_connections"
+}
+
+type directoryUser20250731 struct {
+	ConnectionId  uint64 `gorm:"primaryKey"`
+	ExternalId    string `gorm:"primaryKey;type:varchar(255)"`
+	UserId        string `gorm:"type:varchar(255);index"`
+	UserName      string `gorm:"type:varchar(255)"`
+	Active        bool
+	DeactivatedAt *time.Time
+	archived.NoPKModel
+}
+
+func (directoryUser20250731) TableName() string {
+	return "_tool_org_directory_users"
+}
+
+type directoryGroup20250731 struct {
+	ConnectionId uint64 `gorm:"primaryKey"`
+	ExternalId   string `gorm:"primaryKey;type:varchar(255)"`
+	TeamId       string `gorm:"type:varchar(255)"`
+	Name         string `gorm:"type:varchar(255)"`
+	archived.NoPKModel
+}
+
+func (directoryGroup20250731) TableName() string {
+	return "_tool_org_directory_groups"
+}
+
+func (*addDirectorySync) Up(basicRes context.BasicRes) errors.Error {
+	return migrationhelper.AutoMigrateTables(
+		basicRes,
+		&directoryConnection20250731{},
+		&directoryUser20250731{},
+		&directoryGroup20250731{},
+	)
+}
+
+func (*addDirectorySync) Version() uint64 {
+	return 20250731100000
+}
+
+func (*addDirectorySync) Name() string {
+	return "add directory connections, users and groups"
+}
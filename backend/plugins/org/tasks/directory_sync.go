@@ -0,0 +1,261 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/didgen"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/org/directory"
+	"github.com/apache/incubator-devlake/plugins/org/models"
+)
+
+const syncBatchSize = 500
+
+var SyncDirectoryMeta = plugin.SubTaskMeta{
+	Name:             "syncDirectory",
+	EntryPoint:       SyncDirectory,
+	EnabledByDefault: true,
+	Description:      "sync users, teams and memberships from the LDAP/SCIM directory of connectionId, deactivate users who left it",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CROSS},
+}
+
+// directorySync is what a sync changes, memberships of the synced teams and deactivated users are replaced as a whole
+type directorySync struct {
+	users              []*crossdomain.User
+	directoryUsers     []*models.DirectoryUser
+	deactivatedUserIds []string
+	teams              []*crossdomain.Team
+	directoryGroups    []*models.DirectoryGroup
+	removedGroups      []*models.DirectoryGroup
+	teamUsers          []*crossdomain.TeamUser
+}
+
+func SyncDirectory(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*TaskData)
+	if data.Connection == nil {
+		return nil
+	}
+	db := taskCtx.GetDal()
+	logger := taskCtx.GetLogger()
+	connectionId := data.Connection.ID
+
+	d, err := directory.NewDirectory(data.Connection)
+	if err != nil {
+		return err
+	}
+	snapshot, err := d.Fetch(taskCtx.GetContext())
+	if err != nil {
+		return err
+	}
+	var directoryUsers []*models.DirectoryUser
+	err = db.All(&directoryUsers, dal.Where("connection_id = ?", connectionId))
+	if err != nil {
+		return err
+	}
+	var directoryGroups []*models.DirectoryGroup
+	err = db.All(&directoryGroups, dal.Where("connection_id = ?", connectionId))
+	if err != nil {
+		return err
+	}
+	var users []crossdomain.User
+	err = db.All(&users)
+	if err != nil {
+		return err
+	}
+
+	userIdGen := didgen.NewDomainIdGenerator(&models.DirectoryUser{})
+	teamIdGen := didgen.NewDomainIdGenerator(&models.DirectoryGroup{})
+	sync := planDirectorySync(connectionId, snapshot, directoryUsers, directoryGroups, users, time.Now(),
+		func(externalId string) string { return userIdGen.Generate(connectionId, externalId) },
+		func(externalId string) string { return teamIdGen.Generate(connectionId, externalId) },
+	)
+
+	err = applyDirectorySync(db, sync)
+	if err != nil {
+		return err
+	}
+	logger.Info("synced %d users and %d teams from directory %s, deactivated %d users, removed %d teams",
+		len(sync.users), len(sync.teams), data.Connection.Name, len(sync.deactivatedUserIds), len(sync.removedGroups))
+	return nil
+}
+
+// applyDirectorySync writes the changes in a single transaction, so a failed sync never leaves teams without
+// their memberships
+func applyDirectorySync(db dal.Dal, sync *directorySync) (err errors.Error) {
+	tx := db.Begin()
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+	// memberships of the synced teams are replaced, removed teams and deactivated users lose theirs
+	var teamIds []string
+	for _, team := range sync.teams {
+		teamIds = append(teamIds, team.Id)
+	}
+	for _, group := range sync.removedGroups {
+		teamIds = append(teamIds, group.TeamId)
+		err = tx.Delete(&crossdomain.Team{}, dal.Where("id = ?", group.TeamId))
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(group)
+		if err != nil {
+			return err
+		}
+	}
+	if len(teamIds) > 0 {
+		err = tx.Delete(&crossdomain.TeamUser{}, dal.Where("team_id IN ?", teamIds))
+		if err != nil {
+			return err
+		}
+	}
+	if len(sync.deactivatedUserIds) > 0 {
+		err = tx.Delete(&crossdomain.TeamUser{}, dal.Where("user_id IN ?", sync.deactivatedUserIds))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, items := range []interface{}{sync.users, sync.directoryUsers, sync.teams, sync.directoryGroups, sync.teamUsers} {
+		v := reflect.ValueOf(items)
+		for i := 0; i < v.Len(); i += syncBatchSize {
+			end := i + syncBatchSize
+			if end > v.Len() {
+				end = v.Len()
+			}
+			err = tx.CreateOrUpdate(v.Slice(i, end).Interface())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// planDirectorySync works out the changes a snapshot brings. Users already known by email, e.g. imported
+// from users.csv, are adopted instead of duplicated.
+func planDirectorySync(
+	connectionId uint64,
+	snapshot *directory.Snapshot,
+	directoryUsers []*models.DirectoryUser,
+	directoryGroups []*models.DirectoryGroup,
+	users []crossdomain.User,
+	now time.Time,
+	userId func(externalId string) string,
+	teamId func(externalId string) string,
+) *directorySync {
+	sync := &directorySync{}
+	known := make(map[string]*models.DirectoryUser, len(directoryUsers))
+	for _, u := range directoryUsers {
+		known[u.ExternalId] = u
+	}
+	byEmail := make(map[string]string, len(users))
+	for _, u := range users {
+		if u.Email != "" {
+			byEmail[strings.ToLower(u.Email)] = u.Id
+		}
+	}
+
+	activeUserIds := make(map[string]string)
+	seen := make(map[string]bool, len(snapshot.Users))
+	for _, u := range snapshot.Users {
+		seen[u.ExternalId] = true
+		record := known[u.ExternalId]
+		if record == nil {
+			record = &models.DirectoryUser{ConnectionId: connectionId, ExternalId: u.ExternalId}
+			if id, ok := byEmail[strings.ToLower(u.Email)]; ok && u.Email != "" {
+				record.UserId = id
+			} else {
+				record.UserId = userId(u.ExternalId)
+			}
+		}
+		record.UserName = u.UserName
+		if u.Active {
+			activeUserIds[u.ExternalId] = record.UserId
+			record.Active, record.DeactivatedAt = true, nil
+		} else if record.Active || record.DeactivatedAt == nil {
+			deactivate(sync, record, now)
+		}
+		sync.directoryUsers = append(sync.directoryUsers, record)
+		name := u.Name
+		if name == "" {
+			name = u.UserName
+		}
+		sync.users = append(sync.users, &crossdomain.User{
+			DomainEntity: domainlayer.DomainEntity{Id: record.UserId},
+			Name:         name,
+			Email:        u.Email,
+		})
+	}
+	for _, record := range directoryUsers {
+		if !seen[record.ExternalId] && record.Active {
+			deactivate(sync, record, now)
+			sync.directoryUsers = append(sync.directoryUsers, record)
+		}
+	}
+
+	parents := directory.ParentIds(snapshot.Groups)
+	seen = make(map[string]bool, len(snapshot.Groups))
+	for _, g := range snapshot.Groups {
+		seen[g.ExternalId] = true
+		id := teamId(g.ExternalId)
+		team := &crossdomain.Team{DomainEntity: domainlayer.DomainEntity{Id: id}, Name: g.Name}
+		if parent, ok := parents[g.ExternalId]; ok {
+			team.ParentId = teamId(parent)
+		}
+		sync.teams = append(sync.teams, team)
+		sync.directoryGroups = append(sync.directoryGroups, &models.DirectoryGroup{
+			ConnectionId: connectionId,
+			ExternalId:   g.ExternalId,
+			TeamId:       id,
+			Name:         g.Name,
+		})
+		members := make(map[string]bool)
+		for _, member := range g.MemberUserIds {
+			if uid, ok := activeUserIds[member]; ok && !members[uid] {
+				members[uid] = true
+				sync.teamUsers = append(sync.teamUsers, &crossdomain.TeamUser{TeamId: id, UserId: uid})
+			}
+		}
+	}
+	for _, group := range directoryGroups {
+		if !seen[group.ExternalId] {
+			sync.removedGroups = append(sync.removedGroups, group)
+		}
+	}
+	return sync
+}
+
+// deactivate keeps the user and its accounts for historical metrics but drops its team memberships
+func deactivate(sync *directorySync, record *models.DirectoryUser, now time.Time) {
+	deactivatedAt := now
+	record.Active = false
+	record.DeactivatedAt = &deactivatedAt
+	sync.deactivatedUserIds = append(sync.deactivatedUserIds, record.UserId)
+}
@@ -0,0 +1,83 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/plugins/org/directory"
+	"github.com/apache/incubator-devlake/plugins/org/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanDirectorySync(t *testing.T) {
+	now := time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC)
+	yesterday := now.AddDate(0, 0, -1)
+	snapshot := &directory.Snapshot{
+		Users: []directory.User{
+			{ExternalId: "u1", UserName: "jdoe", Name: "John Doe", Email: "JDoe@example.org", Active: true},
+			{ExternalId: "u2", UserName: "asmith", Email: "asmith@example.org", Active: true},
+			{ExternalId: "u3", UserName: "bsuspended", Email: "b@example.org", Active: false},
+		},
+		Groups: []directory.Group{
+			{ExternalId: "g1", Name: "Engineering", MemberUserIds: []string{"u1", "u3"}, MemberGroupIds: []string{"g2"}},
+			{ExternalId: "g2", Name: "Platform", MemberUserIds: []string{"u2", "u2"}},
+		},
+	}
+	known := []*models.DirectoryUser{
+		{ConnectionId: 1, ExternalId: "u2", UserId: "org:DirectoryUser:1:u2", Active: true},
+		{ConnectionId: 1, ExternalId: "u4", UserId: "org:DirectoryUser:1:u4", Active: true},
+		{ConnectionId: 1, ExternalId: "u5", UserId: "org:DirectoryUser:1:u5", Active: false, DeactivatedAt: &yesterday},
+	}
+	groups := []*models.DirectoryGroup{
+		{ConnectionId: 1, ExternalId: "g1", TeamId: "org:DirectoryGroup:1:g1"},
+		{ConnectionId: 1, ExternalId: "old", TeamId: "org:DirectoryGroup:1:old"},
+	}
+	users := []crossdomain.User{
+		{DomainEntity: domainlayer.DomainEntity{Id: "csv-1"}, Email: "jdoe@example.org"},
+	}
+	sync := planDirectorySync(1, snapshot, known, groups, users, now,
+		func(id string) string { return "org:DirectoryUser:1:" + id },
+		func(id string) string { return "org:DirectoryGroup:1:" + id },
+	)
+
+	// the user imported from csv is adopted by email, a user without name falls back to its login
+	assert.Equal(t, []*crossdomain.User{
+		{DomainEntity: domainlayer.DomainEntity{Id: "csv-1"}, Name: "John Doe", Email: "JDoe@example.org"},
+		{DomainEntity: domainlayer.DomainEntity{Id: "org:DirectoryUser:1:u2"}, Name: "asmith", Email: "asmith@example.org"},
+		{DomainEntity: domainlayer.DomainEntity{Id: "org:DirectoryUser:1:u3"}, Name: "bsuspended", Email: "b@example.org"},
+	}, sync.users)
+	// suspended in the directory and gone from it are deactivated, already deactivated ones are left alone
+	assert.Equal(t, []string{"org:DirectoryUser:1:u3", "org:DirectoryUser:1:u4"}, sync.deactivatedUserIds)
+	for _, u := range sync.directoryUsers {
+		assert.Equal(t, u.ExternalId == "u1" || u.ExternalId == "u2", u.Active, u.ExternalId)
+		assert.NotEqual(t, "u5", u.ExternalId)
+	}
+	assert.Equal(t, []*crossdomain.Team{
+		{DomainEntity: domainlayer.DomainEntity{Id: "org:DirectoryGroup:1:g1"}, Name: "Engineering"},
+		{DomainEntity: domainlayer.DomainEntity{Id: "org:DirectoryGroup:1:g2"}, Name: "Platform", ParentId: "org:DirectoryGroup:1:g1"},
+	}, sync.teams)
+	assert.Equal(t, []*crossdomain.TeamUser{
+		{TeamId: "org:DirectoryGroup:1:g1", UserId: "csv-1"},
+		{TeamId: "org:DirectoryGroup:1:g2", UserId: "org:DirectoryUser:1:u2"},
+	}, sync.teamUsers)
+	assert.Equal(t, []*models.DirectoryGroup{groups[1]}, sync.removedGroups)
+}
@@ -0,0 +1,276 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	defaultAutoLinkThreshold = 0.9
+	defaultReviewThreshold   = 0.6
+	// a match is ambiguous when the runner-up scores within this margin of the best one
+	ambiguityMargin = 0.1
+	// fuzzy name similarity is discounted so that it never links on its own
+	similarityWeight    = 0.8
+	minSimilarity       = 0.85
+	githubNoreplyDomain = "users.noreply.github.com"
+	gitlabNoreplyDomain = "users.noreply.gitlab.com"
+)
+
+const (
+	RULE_EMAIL           = "email"
+	RULE_NOREPLY_LOGIN   = "noreply_login"
+	RULE_NAME            = "name"
+	RULE_USERNAME        = "username"
+	RULE_EMAIL_LOCALPART = "email_localpart"
+	RULE_NAME_SIMILARITY = "name_similarity"
+)
+
+var ruleScores = map[string]float64{
+	RULE_EMAIL:           1.0,
+	RULE_NOREPLY_LOGIN:   0.9,
+	RULE_NAME:            0.9,
+	RULE_USERNAME:        0.85,
+	RULE_EMAIL_LOCALPART: 0.7,
+}
+
+var githubNoreplyPattern = regexp.MustCompile(`^\d+\+`)
+var gitlabNoreplyPattern = regexp.MustCompile(`^\d+-`)
+
+// letters that do not decompose into a base letter plus a combining mark
+var foldReplacer = strings.NewReplacer(
+	"ß", "ss", "æ", "ae", "œ", "oe", "ø", "o", "ł", "l", "đ", "d", "ı", "i",
+)
+
+// IdentityCandidate is a user the resolver considers to be the owner of an account
+type IdentityCandidate struct {
+	UserId string
+	Score  float64
+	Rules  []string
+}
+
+// IdentityResolver scores accounts against users with normalisation rules and name similarity
+type IdentityResolver struct {
+	opts  IdentityResolution
+	users []identity
+}
+
+// identity holds the normalised forms of a user or an account,
+// login is the squashed user name for users and the login hidden in a noreply email for accounts
+type identity struct {
+	id        string
+	email     string
+	localPart string
+	login     string
+	name      string
+}
+
+// NewIdentityResolver creates an IdentityResolver for the given users, missing thresholds fall back to defaults
+func NewIdentityResolver(users []crossdomain.User, opts *IdentityResolution) *IdentityResolver {
+	r := &IdentityResolver{}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.AutoLinkThreshold <= 0 {
+		r.opts.AutoLinkThreshold = defaultAutoLinkThreshold
+	}
+	if r.opts.ReviewThreshold <= 0 {
+		r.opts.ReviewThreshold = defaultReviewThreshold
+	}
+	aliases := make(map[string]string, len(r.opts.DomainAliases))
+	for from, to := range r.opts.DomainAliases {
+		aliases[strings.ToLower(strings.TrimSpace(from))] = strings.ToLower(strings.TrimSpace(to))
+	}
+	r.opts.DomainAliases = aliases
+	for _, user := range users {
+		u := identity{id: user.Id, name: NormalizeName(user.Name), login: NormalizeLogin(user.Name)}
+		u.email, u.localPart, _ = NormalizeEmail(user.Email, aliases)
+		r.users = append(r.users, u)
+	}
+	return r
+}
+
+// Resolve returns the user the account should be linked to if the match is confident and unambiguous,
+// otherwise all candidates worth a human review, best first. Users in rejected were refused by a reviewer
+// and are never candidates of the account.
+func (r *IdentityResolver) Resolve(account *crossdomain.Account, rejected map[string]bool) (*IdentityCandidate, []IdentityCandidate) {
+	a := identity{id: account.Id, name: NormalizeName(account.FullName)}
+	a.email, a.localPart, a.login = NormalizeEmail(account.Email, r.opts.DomainAliases)
+	username := NormalizeLogin(account.UserName)
+	var candidates []IdentityCandidate
+	for _, u := range r.users {
+		if rejected[u.id] {
+			continue
+		}
+		candidate := IdentityCandidate{UserId: u.id}
+		matched := func(rule string, score float64) {
+			candidate.Rules = append(candidate.Rules, rule)
+			// combine independent evidences, two strong rules agreeing beat any single one
+			candidate.Score = 1 - (1-candidate.Score)*(1-score)
+		}
+		if a.email != "" && a.email == u.email {
+			matched(RULE_EMAIL, ruleScores[RULE_EMAIL])
+		} else if a.localPart != "" && a.localPart == u.localPart {
+			matched(RULE_EMAIL_LOCALPART, ruleScores[RULE_EMAIL_LOCALPART])
+		}
+		if a.login != "" && (a.login == u.localPart || a.login == u.login) {
+			matched(RULE_NOREPLY_LOGIN, ruleScores[RULE_NOREPLY_LOGIN])
+		}
+		if username != "" && (username == u.localPart || username == u.login) {
+			matched(RULE_USERNAME, ruleScores[RULE_USERNAME])
+		}
+		if a.name != "" && a.name == u.name {
+			matched(RULE_NAME, ruleScores[RULE_NAME])
+		} else if a.name != "" && u.name != "" {
+			if similarity := JaroWinkler(a.name, u.name); similarity >= minSimilarity {
+				matched(RULE_NAME_SIMILARITY, similarity*similarityWeight)
+			}
+		}
+		candidate.Score = math.Round(candidate.Score*1000) / 1000
+		if candidate.Score >= r.opts.ReviewThreshold {
+			candidates = append(candidates, candidate)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if len(candidates) > 0 && candidates[0].Score >= r.opts.AutoLinkThreshold &&
+		(len(candidates) == 1 || candidates[0].Score-candidates[1].Score >= ambiguityMargin) {
+		return &candidates[0], nil
+	}
+	return nil, candidates
+}
+
+// NormalizeText lowercases the text, strips diacritics and turns punctuation into single spaces
+func NormalizeText(s string) string {
+	s = foldReplacer.Replace(strings.ToLower(norm.NFD.String(s)))
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteRune(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// NormalizeName normalises a person name so that "Doe, Jöhn" and "john doe" are equal
+func NormalizeName(name string) string {
+	tokens := strings.Fields(NormalizeText(name))
+	sort.Strings(tokens)
+	return strings.Join(tokens, " ")
+}
+
+// NormalizeLogin normalises a login so that "John.Doe", "john_doe" and "johndoe" are equal
+func NormalizeLogin(login string) string {
+	return strings.ReplaceAll(NormalizeText(login), " ", "")
+}
+
+// NormalizeEmail returns the canonical address with the +alias removed and the domain de-aliased,
+// its normalised local part, and for GitHub/GitLab noreply addresses the login they hide
+func NormalizeEmail(email string, domainAliases map[string]string) (address string, localPart string, login string) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 {
+		return "", "", ""
+	}
+	local, domain := email[:at], email[at+1:]
+	switch domain {
+	case githubNoreplyDomain:
+		return "", "", NormalizeLogin(githubNoreplyPattern.ReplaceAllString(local, ""))
+	case gitlabNoreplyDomain:
+		return "", "", NormalizeLogin(gitlabNoreplyPattern.ReplaceAllString(local, ""))
+	}
+	if plus := strings.Index(local, "+"); plus > 0 {
+		local = local[:plus]
+	}
+	if alias, ok := domainAliases[domain]; ok && alias != "" {
+		domain = alias
+	}
+	return local + "@" + domain, NormalizeLogin(local), ""
+}
+
+// JaroWinkler returns the Jaro-Winkler similarity of the two strings between 0 and 1
+func JaroWinkler(s1, s2 string) float64 {
+	a, b := []rune(s1), []rune(s2)
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	window := int(math.Max(float64(len(a)), float64(len(b))))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	aMatched := make([]bool, len(a))
+	bMatched := make([]bool, len(b))
+	matches := 0
+	for i := range a {
+		lo, hi := i-window, i+window+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(b) {
+			hi = len(b)
+		}
+		for j := lo; j < hi; j++ {
+			if !bMatched[j] && a[i] == b[j] {
+				aMatched[i], bMatched[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+	transpositions, j := 0, 0
+	for i := range a {
+		if !aMatched[i] {
+			continue
+		}
+		for !bMatched[j] {
+			j++
+		}
+		if a[i] != b[j] {
+			transpositions++
+		}
+		j++
+	}
+	m := float64(matches)
+	jaro := (m/float64(len(a)) + m/float64(len(b)) + (m-float64(transpositions)/2)/m) / 3
+	prefix := 0
+	for prefix < 4 && prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
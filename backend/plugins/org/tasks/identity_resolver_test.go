@@ -0,0 +1,114 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	aliases := map[string]string{"corp.example.com": "example.com"}
+	for _, tc := range []struct {
+		email, address, localPart, login string
+	}{
+		{"John.Doe@Example.com", "john.doe@example.com", "johndoe", ""},
+		{"john.doe+jira@example.com", "john.doe@example.com", "johndoe", ""},
+		{"john.doe@corp.example.com", "john.doe@example.com", "johndoe", ""},
+		{"12345+JDoe@users.noreply.github.com", "", "", "jdoe"},
+		{"jdoe@users.noreply.github.com", "", "", "jdoe"},
+		{"42-j.doe@users.noreply.gitlab.com", "", "", "jdoe"},
+		{"not-an-email", "", "", ""},
+	} {
+		address, localPart, login := NormalizeEmail(tc.email, aliases)
+		assert.Equal(t, tc.address, address, tc.email)
+		assert.Equal(t, tc.localPart, localPart, tc.email)
+		assert.Equal(t, tc.login, login, tc.email)
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	assert.Equal(t, "doe jose", NormalizeName("José Doe"))
+	assert.Equal(t, "doe jose", NormalizeName("Doe,  JOSÉ"))
+	assert.Equal(t, "jorgen sorensen", NormalizeName("Jørgen Sørensen"))
+	assert.Equal(t, "johndoe", NormalizeLogin("John.Doe"))
+	assert.Equal(t, "johndoe", NormalizeLogin("john_doe"))
+}
+
+func TestJaroWinkler(t *testing.T) {
+	assert.InDelta(t, 0.961, JaroWinkler("martha", "marhta"), 0.001)
+	assert.InDelta(t, 0.813, JaroWinkler("dixon", "dicksonx"), 0.001)
+	assert.Equal(t, 1.0, JaroWinkler("john doe", "john doe"))
+	assert.Equal(t, 0.0, JaroWinkler("", "john doe"))
+}
+
+func TestIdentityResolver(t *testing.T) {
+	users := []crossdomain.User{
+		{DomainEntity: domainlayer.DomainEntity{Id: "1"}, Name: "John Doe", Email: "john.doe@example.com"},
+		{DomainEntity: domainlayer.DomainEntity{Id: "2"}, Name: "Jane Roe", Email: "jane@example.com"},
+		{DomainEntity: domainlayer.DomainEntity{Id: "3"}, Name: "Jon Doe", Email: "jon@example.com"},
+		{DomainEntity: domainlayer.DomainEntity{Id: "4"}, Name: "Jane Roe", Email: "jane.roe@example.org"},
+	}
+	resolver := NewIdentityResolver(users, &IdentityResolution{DomainAliases: map[string]string{"corp.example.com": "example.com"}})
+
+	// alias email on an aliased domain links directly
+	best, candidates := resolver.Resolve(&crossdomain.Account{Email: "john.doe+gitlab@corp.example.com"}, nil)
+	if assert.NotNil(t, best) {
+		assert.Equal(t, "1", best.UserId)
+		assert.Equal(t, []string{RULE_EMAIL}, best.Rules)
+	}
+	assert.Empty(t, candidates)
+
+	// github noreply email carries the login
+	best, _ = resolver.Resolve(&crossdomain.Account{Email: "1+John.Doe@users.noreply.github.com"}, nil)
+	if assert.NotNil(t, best) {
+		assert.Equal(t, "1", best.UserId)
+	}
+
+	// two users with the same name are ambiguous
+	best, candidates = resolver.Resolve(&crossdomain.Account{FullName: "Jane Roe"}, nil)
+	assert.Nil(t, best)
+	assert.Len(t, candidates, 2)
+
+	// a similar name alone only goes to review
+	best, candidates = resolver.Resolve(&crossdomain.Account{FullName: "Jonh Doe"}, nil)
+	assert.Nil(t, best)
+	if assert.NotEmpty(t, candidates) {
+		assert.Equal(t, []string{RULE_NAME_SIMILARITY}, candidates[0].Rules)
+	}
+
+	// users refused by a reviewer are skipped, leaving the others to review
+	account := &crossdomain.Account{Email: "john.doe@example.com", FullName: "Jonh Doe"}
+	best, _ = resolver.Resolve(account, nil)
+	if assert.NotNil(t, best) {
+		assert.Equal(t, "1", best.UserId)
+	}
+	best, candidates = resolver.Resolve(account, map[string]bool{"1": true})
+	assert.Nil(t, best)
+	if assert.Len(t, candidates, 1) {
+		assert.Equal(t, "3", candidates[0].UserId)
+	}
+
+	// nothing in common
+	best, candidates = resolver.Resolve(&crossdomain.Account{FullName: "Richard Roe", UserName: "rroe"}, nil)
+	assert.Nil(t, best)
+	assert.Empty(t, candidates)
+}
@@ -17,11 +17,25 @@ limitations under the License.
 
 package tasks
 
-import "github.com/apache/incubator-devlake/core/plugin"
+import (
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/org/models"
+)
 
 type Options struct {
-	ConnectionId    uint64           `json:"connectionId"`
-	ProjectMappings []ProjectMapping `json:"projectMappings"`
+	ConnectionId       uint64              `json:"connectionId"`
+	ProjectMappings    []ProjectMapping    `json:"projectMappings"`
+	IdentityResolution *IdentityResolution `json:"identityResolution"`
+}
+
+// IdentityResolution configures how connectUserAccountsFuzzy matches accounts to users
+type IdentityResolution struct {
+	// DomainAliases maps an email domain to its canonical domain, e.g. {"corp.example.com": "example.com"}
+	DomainAliases map[string]string `json:"domainAliases"`
+	// AutoLinkThreshold is the minimal score for an unambiguous match to be linked without review
+	AutoLinkThreshold float64 `json:"autoLinkThreshold"`
+	// ReviewThreshold is the minimal score for a match to be put into the review queue
+	ReviewThreshold float64 `json:"reviewThreshold"`
 }
 
 // ProjectMapping represents the relations between project and scopes
@@ -52,7 +66,8 @@ func NewProjectMapping(projectName string, pluginScopes []plugin.Scope) ProjectM
 }
 
 type TaskData struct {
-	Options *Options
+	Options    *Options
+	Connection *models.DirectoryConnection
 }
 type Params struct {
 	ConnectionId uint64
@@ -0,0 +1,107 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+var AttributeToTeamsMeta = plugin.SubTaskMeta{
+	Name:             "attributeToTeams",
+	EntryPoint:       AttributeToTeams,
+	EnabledByDefault: true,
+	Description:      "attribute pull requests, deployments, issues and incidents to the teams of their users",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CROSS},
+}
+
+// teamAttributionSources select team_id, entity_id and user_id of the entities of a type,
+// entities are attributed to the direct teams only, rollups go through team_closures
+var teamAttributionSources = []struct {
+	entityType string
+	query      string
+}{
+	{
+		// the author owns a pull request
+		entityType: code.PullRequest{}.TableName(),
+		query: `
+			SELECT tu.team_id, pr.id AS entity_id, MIN(ua.user_id) AS user_id
+			FROM pull_requests pr
+			JOIN user_accounts ua ON ua.account_id = pr.author_id
+			JOIN team_users tu ON tu.user_id = ua.user_id
+			GROUP BY tu.team_id, pr.id`,
+	},
+	{
+		// a deployment belongs to the authors of the deployed commits, commits.author_id is the author email
+		entityType: devops.CICDDeployment{}.TableName(),
+		query: `
+			SELECT tu.team_id, cdc.cicd_deployment_id AS entity_id, MIN(ua.user_id) AS user_id
+			FROM cicd_deployment_commits cdc
+			JOIN commits c ON c.sha = cdc.commit_sha
+			JOIN accounts a ON a.email = c.author_id
+			JOIN user_accounts ua ON ua.account_id = a.id
+			JOIN team_users tu ON tu.user_id = ua.user_id
+			WHERE cdc.cicd_deployment_id != ''
+			GROUP BY tu.team_id, cdc.cicd_deployment_id`,
+	},
+	{
+		// issues and incidents belong to the assignee, or the creator when unassigned
+		entityType: ticket.Issue{}.TableName(),
+		query: `
+			SELECT tu.team_id, i.id AS entity_id, MIN(ua.user_id) AS user_id
+			FROM issues i
+			JOIN user_accounts ua ON ua.account_id = COALESCE(NULLIF(i.assignee_id, ''), i.creator_id)
+			JOIN team_users tu ON tu.user_id = ua.user_id
+			GROUP BY tu.team_id, i.id`,
+	},
+	{
+		entityType: ticket.Incident{}.TableName(),
+		query: `
+			SELECT tu.team_id, i.id AS entity_id, MIN(ua.user_id) AS user_id
+			FROM incidents i
+			JOIN user_accounts ua ON ua.account_id = COALESCE(NULLIF(i.assignee_id, ''), i.creator_id)
+			JOIN team_users tu ON tu.user_id = ua.user_id
+			GROUP BY tu.team_id, i.id`,
+	},
+}
+
+func AttributeToTeams(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	err := db.Delete(&crossdomain.TeamAttribution{}, dal.Where("1=1"))
+	if err != nil {
+		return err
+	}
+	taskCtx.SetProgress(0, len(teamAttributionSources))
+	for _, source := range teamAttributionSources {
+		err = db.Exec(`
+			INSERT INTO team_attributions (team_id, entity_type, entity_id, user_id, created_at, updated_at)
+			SELECT s.team_id, '` + source.entityType + `', s.entity_id, s.user_id, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+			FROM (` + source.query + `) s`,
+		)
+		if err != nil {
+			return errors.Default.Wrap(err, "failed to attribute "+source.entityType+" to teams")
+		}
+		taskCtx.IncProgress(1)
+	}
+	return nil
+}
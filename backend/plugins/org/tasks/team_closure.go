@@ -0,0 +1,92 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/log"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+var BuildTeamClosureMeta = plugin.SubTaskMeta{
+	Name:             "buildTeamClosure",
+	EntryPoint:       BuildTeamClosure,
+	EnabledByDefault: true,
+	Description:      "materialize the ancestor/descendant pairs of the team hierarchy into team_closures",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CROSS},
+}
+
+func BuildTeamClosure(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	var teams []crossdomain.Team
+	err := db.All(&teams)
+	if err != nil {
+		return err
+	}
+	closures := ComputeTeamClosure(teams, taskCtx.GetLogger())
+	err = db.Delete(&crossdomain.TeamClosure{}, dal.Where("1=1"))
+	if err != nil {
+		return err
+	}
+	divider := api.NewBatchSaveDivider(taskCtx, 1000, "", "")
+	defer divider.Close()
+	batch, err := divider.ForType(reflect.TypeOf(&crossdomain.TeamClosure{}))
+	if err != nil {
+		return err
+	}
+	for _, closure := range closures {
+		err = batch.Add(closure)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ComputeTeamClosure walks up the parent chain of every team. A parent that does not exist ends the chain,
+// a cycle is reported and cut where it is detected so that one bad row does not block the whole hierarchy.
+func ComputeTeamClosure(teams []crossdomain.Team, logger log.Logger) []*crossdomain.TeamClosure {
+	parents := make(map[string]string, len(teams))
+	for _, team := range teams {
+		parents[team.Id] = team.ParentId
+	}
+	var closures []*crossdomain.TeamClosure
+	for _, team := range teams {
+		visited := map[string]bool{team.Id: true}
+		closures = append(closures, &crossdomain.TeamClosure{AncestorId: team.Id, DescendantId: team.Id})
+		for depth, ancestor := 1, team.ParentId; ancestor != ""; depth, ancestor = depth+1, parents[ancestor] {
+			if _, ok := parents[ancestor]; !ok {
+				break
+			}
+			if visited[ancestor] {
+				if logger != nil {
+					logger.Warn(nil, "team %s is part of a parent_id cycle through %s", team.Id, ancestor)
+				}
+				break
+			}
+			visited[ancestor] = true
+			closures = append(closures, &crossdomain.TeamClosure{AncestorId: ancestor, DescendantId: team.Id, Depth: depth})
+		}
+	}
+	return closures
+}
@@ -0,0 +1,57 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeTeamClosure(t *testing.T) {
+	team := func(id, parentId string) crossdomain.Team {
+		return crossdomain.Team{DomainEntity: domainlayer.DomainEntity{Id: id}, ParentId: parentId}
+	}
+	teams := []crossdomain.Team{
+		team("org", ""),
+		team("platform", "org"),
+		team("infra", "platform"),
+		team("orphan", "missing"),
+		team("a", "b"),
+		team("b", "a"),
+	}
+	pairs := make(map[[2]string]int)
+	for _, c := range ComputeTeamClosure(teams, nil) {
+		pairs[[2]string{c.AncestorId, c.DescendantId}] = c.Depth
+	}
+	assert.Equal(t, map[[2]string]int{
+		{"org", "org"}:           0,
+		{"platform", "platform"}: 0,
+		{"org", "platform"}:      1,
+		{"infra", "infra"}:       0,
+		{"platform", "infra"}:    1,
+		{"org", "infra"}:         2,
+		{"orphan", "orphan"}:     0,
+		{"a", "a"}:               0,
+		{"b", "a"}:               1,
+		{"b", "b"}:               0,
+		{"a", "b"}:               1,
+	}, pairs)
+}
@@ -0,0 +1,154 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/org/models"
+)
+
+var ConnectUserAccountsFuzzyMeta = plugin.SubTaskMeta{
+	Name:             "connectUserAccountsFuzzy",
+	EntryPoint:       ConnectUserAccountsFuzzy,
+	EnabledByDefault: false,
+	Description:      "associate users and accounts by normalised identities and name similarity, queue ambiguous matches for review",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CROSS},
+}
+
+func ConnectUserAccountsFuzzy(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*TaskData)
+	logger := taskCtx.GetLogger()
+	var users []crossdomain.User
+	err := db.All(&users)
+	if err != nil {
+		return err
+	}
+	resolver := NewIdentityResolver(users, data.Options.IdentityResolution)
+
+	// candidates of accounts linked since the last run are no longer relevant
+	err = db.Delete(
+		&models.IdentityMatch{},
+		dal.Where("status = ? AND account_id IN (SELECT account_id FROM user_accounts)", models.IDENTITY_MATCH_PENDING),
+	)
+	if err != nil {
+		return err
+	}
+	// human decisions always win over the resolver
+	var reviewed []models.IdentityMatch
+	err = db.All(&reviewed, dal.Where("status IN ?", []string{models.IDENTITY_MATCH_CONFIRMED, models.IDENTITY_MATCH_REJECTED}))
+	if err != nil {
+		return err
+	}
+	rejectedUsers := make(map[string]map[string]bool)
+	confirmedUsers := make(map[string]string)
+	for _, m := range reviewed {
+		if m.Status == models.IDENTITY_MATCH_CONFIRMED {
+			confirmedUsers[m.AccountId] = m.UserId
+			continue
+		}
+		if rejectedUsers[m.AccountId] == nil {
+			rejectedUsers[m.AccountId] = make(map[string]bool)
+		}
+		rejectedUsers[m.AccountId][m.UserId] = true
+	}
+
+	count, err := db.Count(
+		dal.From(&crossdomain.Account{}),
+		dal.Where("id NOT IN (SELECT account_id FROM user_accounts)"),
+	)
+	if err != nil {
+		return err
+	}
+	cursor, err := db.Cursor(
+		dal.Select("*"),
+		dal.From(&crossdomain.Account{}),
+		dal.Where("id NOT IN (SELECT account_id FROM user_accounts)"),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	divider := api.NewBatchSaveDivider(taskCtx, 500, "", "")
+	defer divider.Close()
+	userAccounts, err := divider.ForType(reflect.TypeOf(&crossdomain.UserAccount{}))
+	if err != nil {
+		return err
+	}
+	matches, err := divider.ForType(reflect.TypeOf(&models.IdentityMatch{}))
+	if err != nil {
+		return err
+	}
+	taskCtx.SetProgress(0, int(count))
+	linked, queued := 0, 0
+	for cursor.Next() {
+		account := &crossdomain.Account{}
+		err = db.Fetch(cursor, account)
+		if err != nil {
+			return err
+		}
+		taskCtx.IncProgress(1)
+		if userId, ok := confirmedUsers[account.Id]; ok {
+			err = userAccounts.Add(&crossdomain.UserAccount{UserId: userId, AccountId: account.Id})
+			if err != nil {
+				return err
+			}
+			linked++
+			continue
+		}
+		best, candidates := resolver.Resolve(account, rejectedUsers[account.Id])
+		if best != nil {
+			err = userAccounts.Add(&crossdomain.UserAccount{UserId: best.UserId, AccountId: account.Id})
+			if err != nil {
+				return err
+			}
+			err = matches.Add(newIdentityMatch(account.Id, best, models.IDENTITY_MATCH_AUTO))
+			if err != nil {
+				return err
+			}
+			linked++
+		}
+		for i := range candidates {
+			err = matches.Add(newIdentityMatch(account.Id, &candidates[i], models.IDENTITY_MATCH_PENDING))
+			if err != nil {
+				return err
+			}
+			queued++
+		}
+	}
+	logger.Info("linked %d accounts, queued %d candidates for review", linked, queued)
+	return nil
+}
+
+func newIdentityMatch(accountId string, candidate *IdentityCandidate, status string) *models.IdentityMatch {
+	return &models.IdentityMatch{
+		AccountId: accountId,
+		UserId:    candidate.UserId,
+		Score:     candidate.Score,
+		Rules:     strings.Join(candidate.Rules, ","),
+		Status:    status,
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/qa"
+	"github.com/apache/incubator-devlake/core/plugin"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+type FlakyTest struct {
+	QaTestCaseId    string     `json:"qaTestCaseId"`
+	Name            string     `json:"name"`
+	QaProjectId     string     `json:"qaProjectId"`
+	TotalRuns       int        `json:"totalRuns"`
+	FailedRuns      int        `json:"failedRuns"`
+	FlakyGroups     int        `json:"flakyGroups"`
+	Flips           int        `json:"flips"`
+	FlakinessScore  float64    `json:"flakinessScore"`
+	RerunDurationMs int64      `json:"rerunDurationMs"`
+	FirstFlakyDate  *time.Time `json:"firstFlakyDate"`
+	LastFlakyDate   *time.Time `json:"lastFlakyDate"`
+	IsQuarantined   bool       `json:"isQuarantined"`
+}
+
+type FlakyTestSummary struct {
+	Count                int          `json:"count"`
+	QuarantinedCount     int          `json:"quarantinedCount"`
+	TotalRerunDurationMs int64        `json:"totalRerunDurationMs"`
+	Tests                []*FlakyTest `json:"tests"`
+}
+
+// GetFlakyTests returns the flaky tests of a project, the most expensive to re-run first
+// @Summary get the flaky tests of a project
+// @Description the flaky tests of a project ordered by the time spent re-running them
+// @Tags plugins/qa_trace
+// @Param projectName path string true "project name"
+// @Param quarantined query bool false "only the quarantined tests"
+// @Param pageSize query int false "page size, default 50"
+// @Param page query int false "page number, default 1"
+// @Success 200  {object} FlakyTestSummary
+// @Failure 400  {object} shared.ApiBody "Bad Request"
+// @Failure 500  {object} shared.ApiBody "Internal Error"
+// @Router /plugins/qa_trace/projects/{projectName}/flaky-tests [GET]
+func GetFlakyTests(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	projectName := input.Params["projectName"]
+	if projectName == "" {
+		return nil, errors.BadInput.New("projectName is required")
+	}
+	db := BasicRes.GetDal()
+	clauses := []dal.Clause{
+		dal.From("qa_test_case_flakiness f"),
+		dal.Where("f.project_name = ?", projectName),
+	}
+	if input.Query.Get("quarantined") == "true" {
+		clauses = append(clauses, dal.Where("f.is_quarantined = ?", true))
+	}
+
+	summary := &FlakyTestSummary{}
+	var totals struct {
+		Count                int
+		QuarantinedCount     int
+		TotalRerunDurationMs int64
+	}
+	err := db.First(&totals, append(clauses, dal.Select(`COUNT(*) AS count,
+		COALESCE(SUM(CASE WHEN f.is_quarantined THEN 1 ELSE 0 END), 0) AS quarantined_count,
+		COALESCE(SUM(f.rerun_duration_ms), 0) AS total_rerun_duration_ms`))...)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to summarize "+qa.QaTestCaseFlakiness{}.TableName())
+	}
+	summary.Count = totals.Count
+	summary.QuarantinedCount = totals.QuarantinedCount
+	summary.TotalRerunDurationMs = totals.TotalRerunDurationMs
+
+	limit, offset := helper.GetLimitOffset(input.Query, "pageSize", "page")
+	summary.Tests = make([]*FlakyTest, 0)
+	err = db.All(&summary.Tests, append(clauses,
+		dal.Select("f.*, tc.name"),
+		dal.Join("LEFT JOIN qa_test_cases tc ON tc.id = f.qa_test_case_id"),
+		dal.Orderby("f.rerun_duration_ms DESC, f.flakiness_score DESC, f.qa_test_case_id ASC"),
+		dal.Limit(limit),
+		dal.Offset(offset),
+	)...)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "failed to query "+qa.QaTestCaseFlakiness{}.TableName())
+	}
+	return &plugin.ApiResourceOutput{Body: summary, Status: http.StatusOK}, nil
+}
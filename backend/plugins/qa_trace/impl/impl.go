@@ -0,0 +1,139 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package impl
+
+import (
+	"encoding/json"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	coreModels "github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/qa_trace/api"
+	"github.com/apache/incubator-devlake/plugins/qa_trace/models/migrationscripts"
+	"github.com/apache/incubator-devlake/plugins/qa_trace/tasks"
+)
+
+// make sure interface is implemented
+var _ interface {
+	plugin.PluginMeta
+	plugin.PluginInit
+	plugin.PluginTask
+	plugin.PluginModel
+	plugin.PluginMetric
+	plugin.PluginMigration
+	plugin.PluginApi
+	plugin.MetricPluginBlueprintV200
+} = (*QaTrace)(nil)
+
+type QaTrace struct{}
+
+func (p QaTrace) Description() string {
+	return "To enrich data from qa domain"
+}
+
+func (p QaTrace) Init(basicRes context.BasicRes) errors.Error {
+	api.Init(basicRes)
+	return nil
+}
+
+// RequiredDataEntities hasn't been used so far
+func (p QaTrace) RequiredDataEntities() (data []map[string]interface{}, err errors.Error) {
+	return []map[string]interface{}{}, nil
+}
+
+// GetTablesInfo returns nothing since the products are domain layer tables
+func (p QaTrace) GetTablesInfo() []dal.Tabler {
+	return []dal.Tabler{}
+}
+
+func (p QaTrace) Name() string {
+	return "qa_trace"
+}
+
+func (p QaTrace) IsProjectMetric() bool {
+	return true
+}
+
+func (p QaTrace) RunAfter() ([]string, errors.Error) {
+	return []string{}, nil
+}
+
+func (p QaTrace) Settings() interface{} {
+	return nil
+}
+
+func (p QaTrace) SubTaskMetas() []plugin.SubTaskMeta {
+	return []plugin.SubTaskMeta{
+		tasks.DetectFlakyTestsMeta,
+	}
+}
+
+func (p QaTrace) PrepareTaskData(taskCtx plugin.TaskContext, options map[string]interface{}) (interface{}, errors.Error) {
+	op, err := tasks.DecodeAndValidateTaskOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	return &tasks.QaTraceTaskData{
+		Options: op,
+	}, nil
+}
+
+// RootPkgPath information lost when compiled as plugin(.so)
+func (p QaTrace) RootPkgPath() string {
+	return "github.com/apache/incubator-devlake/plugins/qa_trace"
+}
+
+func (p QaTrace) MigrationScripts() []plugin.MigrationScript {
+	return migrationscripts.All()
+}
+
+func (p QaTrace) ApiResources() map[string]map[string]plugin.ApiResourceHandler {
+	return map[string]map[string]plugin.ApiResourceHandler{
+		"projects/:projectName/flaky-tests": {
+			"GET": api.GetFlakyTests,
+		},
+	}
+}
+
+func (p QaTrace) MakeMetricPluginPipelinePlanV200(projectName string, options json.RawMessage) (coreModels.PipelinePlan, errors.Error) {
+	op := &tasks.QaTraceOptions{}
+	if options != nil && string(options) != "\"\"" {
+		err := json.Unmarshal(options, op)
+		if err != nil {
+			return nil, errors.Default.WrapRaw(err)
+		}
+	}
+	plan := coreModels.PipelinePlan{
+		{
+			{
+				Plugin: "qa_trace",
+				Options: map[string]interface{}{
+					"projectName":           projectName,
+					"quarantineScore":       op.QuarantineScore,
+					"quarantineFlakyGroups": op.QuarantineFlakyGroups,
+				},
+				Subtasks: []string{
+					"DetectFlakyTests",
+				},
+			},
+		},
+	}
+	return plan, nil
+}
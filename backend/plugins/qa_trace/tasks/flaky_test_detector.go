@@ -0,0 +1,187 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/qa"
+	"github.com/apache/incubator-devlake/core/plugin"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+const (
+	EXECUTION_SUCCESS = "SUCCESS"
+	EXECUTION_FAILED  = "FAILED"
+	BATCH_SIZE        = 1000
+)
+
+// ExecutionRow is a finished execution of a test case, with the commit its pipeline built if any
+type ExecutionRow struct {
+	QaTestCaseId   string
+	QaProjectId    string
+	CicdPipelineId string
+	CommitSha      string
+	Status         string
+	StartTime      time.Time
+	DurationMs     *int64
+}
+
+var DetectFlakyTestsMeta = plugin.SubTaskMeta{
+	Name:             "DetectFlakyTests",
+	EntryPoint:       DetectFlakyTests,
+	EnabledByDefault: true,
+	Description:      "Score the flakiness of test cases by their pass/fail flips on the same commit or pipeline",
+	DependencyTables: []string{qa.QaTestCaseExecution{}.TableName(), devops.CiCDPipelineCommit{}.TableName()},
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_CROSS},
+	ProductTables:    []string{qa.QaTestCaseFlakiness{}.TableName()},
+}
+
+func DetectFlakyTests(taskCtx plugin.SubTaskContext) errors.Error {
+	db := taskCtx.GetDal()
+	data := taskCtx.GetData().(*QaTraceTaskData)
+	projectName := data.Options.ProjectName
+
+	err := db.Delete(&qa.QaTestCaseFlakiness{}, dal.Where("project_name = ?", projectName))
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to delete qa_test_case_flakiness")
+	}
+
+	// executions belong to the project through their qa project or the cicd scope of their pipeline
+	cursor, err := db.Cursor(
+		dal.Select(`e.qa_test_case_id, e.qa_project_id, e.cicd_pipeline_id, e.status, e.start_time, e.duration_ms,
+			(SELECT MIN(pc.commit_sha) FROM cicd_pipeline_commits pc WHERE pc.pipeline_id = e.cicd_pipeline_id) AS commit_sha`),
+		dal.From("qa_test_case_executions e"),
+		dal.Join("LEFT JOIN cicd_pipelines p ON p.id = e.cicd_pipeline_id"),
+		dal.Where(`e.status IN ? AND (
+			e.qa_project_id IN (SELECT pm.row_id FROM project_mapping pm WHERE pm.project_name = ? AND pm.table = 'qa_projects')
+			OR p.cicd_scope_id IN (SELECT pm.row_id FROM project_mapping pm WHERE pm.project_name = ? AND pm.table = 'cicd_scopes'))`,
+			[]string{EXECUTION_SUCCESS, EXECUTION_FAILED}, projectName, projectName),
+		dal.Orderby("e.qa_test_case_id ASC, e.start_time ASC"),
+	)
+	if err != nil {
+		return errors.Default.Wrap(err, "failed to query qa_test_case_executions")
+	}
+	defer cursor.Close()
+
+	divider := helper.NewBatchSaveDivider(taskCtx, BATCH_SIZE, "", "")
+	defer divider.Close()
+	saver, err := divider.ForType(reflect.TypeOf(&qa.QaTestCaseFlakiness{}))
+	if err != nil {
+		return err
+	}
+	flush := func(runs []*ExecutionRow) errors.Error {
+		flakiness := analyzeExecutions(runs, data.Options)
+		if flakiness == nil {
+			return nil
+		}
+		flakiness.ProjectName = projectName
+		return saver.Add(flakiness)
+	}
+
+	var runs []*ExecutionRow
+	for cursor.Next() {
+		row := &ExecutionRow{}
+		if err = db.Fetch(cursor, row); err != nil {
+			return errors.Default.Wrap(err, "failed to fetch qa_test_case_executions")
+		}
+		if len(runs) > 0 && runs[0].QaTestCaseId != row.QaTestCaseId {
+			if err = flush(runs); err != nil {
+				return err
+			}
+			runs = nil
+		}
+		runs = append(runs, row)
+	}
+	if len(runs) > 0 {
+		return flush(runs)
+	}
+	return nil
+}
+
+// analyzeExecutions scores the executions of a single test case ordered by start time,
+// executions are grouped by the commit their pipeline built, or by the pipeline itself.
+// It returns nil if the test case never flipped.
+func analyzeExecutions(runs []*ExecutionRow, options *QaTraceOptions) *qa.QaTestCaseFlakiness {
+	flakiness := &qa.QaTestCaseFlakiness{
+		QaTestCaseId: runs[0].QaTestCaseId,
+		QaProjectId:  runs[0].QaProjectId,
+	}
+	var groupKeys []string
+	groups := make(map[string][]*ExecutionRow)
+	for _, run := range runs {
+		flakiness.TotalRuns++
+		if run.Status == EXECUTION_FAILED {
+			flakiness.FailedRuns++
+		}
+		key := run.CommitSha
+		if key == "" {
+			key = run.CicdPipelineId
+		}
+		// executions out of any pipeline can't be told apart from a fix landing between them
+		if key == "" {
+			continue
+		}
+		if _, ok := groups[key]; !ok {
+			groupKeys = append(groupKeys, key)
+		}
+		groups[key] = append(groups[key], run)
+	}
+
+	pairs := 0
+	for _, key := range groupKeys {
+		group := groups[key]
+		pairs += len(group) - 1
+		flips := 0
+		for i := 1; i < len(group); i++ {
+			if group[i].Status == group[i-1].Status {
+				continue
+			}
+			flips++
+			flipDate := group[i].StartTime
+			if flakiness.FirstFlakyDate == nil || flipDate.Before(*flakiness.FirstFlakyDate) {
+				flakiness.FirstFlakyDate = &flipDate
+			}
+			if flakiness.LastFlakyDate == nil || flipDate.After(*flakiness.LastFlakyDate) {
+				flakiness.LastFlakyDate = &flipDate
+			}
+		}
+		if flips == 0 {
+			continue
+		}
+		flakiness.Flips += flips
+		flakiness.FlakyGroups++
+		// every execution after the first one on a flaky commit or pipeline is a re-run
+		for _, run := range group[1:] {
+			if run.DurationMs != nil {
+				flakiness.RerunDurationMs += *run.DurationMs
+			}
+		}
+	}
+	if flakiness.Flips == 0 {
+		return nil
+	}
+	flakiness.FlakinessScore = float64(flakiness.Flips) / float64(pairs)
+	flakiness.IsQuarantined = flakiness.FlakyGroups >= options.QuarantineFlakyGroups &&
+		flakiness.FlakinessScore >= options.QuarantineScore
+	return flakiness
+}
@@ -0,0 +1,95 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeExecutions(t *testing.T) {
+	options := &QaTraceOptions{QuarantineScore: DEFAULT_QUARANTINE_SCORE, QuarantineFlakyGroups: DEFAULT_QUARANTINE_FLAKY_GROUPS}
+	base := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	ms := func(v int64) *int64 { return &v }
+	run := func(pipeline, sha, status string, hours int, duration int64) *ExecutionRow {
+		return &ExecutionRow{
+			QaTestCaseId:   "tc1",
+			QaProjectId:    "qa1",
+			CicdPipelineId: pipeline,
+			CommitSha:      sha,
+			Status:         status,
+			StartTime:      base.Add(time.Duration(hours) * time.Hour),
+			DurationMs:     ms(duration),
+		}
+	}
+
+	t.Run("stable test", func(t *testing.T) {
+		assert.Nil(t, analyzeExecutions([]*ExecutionRow{
+			run("p1", "c1", EXECUTION_SUCCESS, 0, 100),
+			run("p2", "c1", EXECUTION_SUCCESS, 1, 100),
+			run("p3", "c2", EXECUTION_FAILED, 2, 100),
+			run("p4", "c3", EXECUTION_SUCCESS, 3, 100),
+		}, options))
+	})
+
+	t.Run("executions out of pipelines are not compared", func(t *testing.T) {
+		assert.Nil(t, analyzeExecutions([]*ExecutionRow{
+			run("", "", EXECUTION_FAILED, 0, 100),
+			run("", "", EXECUTION_SUCCESS, 1, 100),
+		}, options))
+	})
+
+	t.Run("flips on the same commit and pipeline", func(t *testing.T) {
+		flakiness := analyzeExecutions([]*ExecutionRow{
+			// fails then passes when the pipeline of c1 is re-run
+			run("p1", "c1", EXECUTION_FAILED, 0, 100),
+			run("p2", "c1", EXECUTION_SUCCESS, 1, 200),
+			// stable on c2
+			run("p3", "c2", EXECUTION_SUCCESS, 2, 100),
+			run("p4", "c2", EXECUTION_SUCCESS, 3, 100),
+			// retried within a pipeline without commit
+			run("p5", "", EXECUTION_SUCCESS, 4, 100),
+			run("p5", "", EXECUTION_FAILED, 5, 300),
+			run("p5", "", EXECUTION_SUCCESS, 6, 400),
+		}, options)
+		assert.NotNil(t, flakiness)
+		assert.Equal(t, "tc1", flakiness.QaTestCaseId)
+		assert.Equal(t, "qa1", flakiness.QaProjectId)
+		assert.Equal(t, 7, flakiness.TotalRuns)
+		assert.Equal(t, 2, flakiness.FailedRuns)
+		assert.Equal(t, 2, flakiness.FlakyGroups)
+		assert.Equal(t, 3, flakiness.Flips)
+		assert.InDelta(t, 3.0/4.0, flakiness.FlakinessScore, 0.0001)
+		assert.Equal(t, int64(200+300+400), flakiness.RerunDurationMs)
+		assert.Equal(t, base.Add(time.Hour), *flakiness.FirstFlakyDate)
+		assert.Equal(t, base.Add(6*time.Hour), *flakiness.LastFlakyDate)
+		assert.True(t, flakiness.IsQuarantined)
+	})
+
+	t.Run("a single flaky commit is not quarantined", func(t *testing.T) {
+		flakiness := analyzeExecutions([]*ExecutionRow{
+			run("p1", "c1", EXECUTION_FAILED, 0, 100),
+			run("p2", "c1", EXECUTION_SUCCESS, 1, 100),
+		}, options)
+		assert.NotNil(t, flakiness)
+		assert.Equal(t, 1.0, flakiness.FlakinessScore)
+		assert.False(t, flakiness.IsQuarantined)
+	})
+}
@@ -0,0 +1,58 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"github.com/apache/incubator-devlake/core/errors"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+const (
+	DEFAULT_QUARANTINE_SCORE        = 0.1
+	DEFAULT_QUARANTINE_FLAKY_GROUPS = 2
+)
+
+type QaTraceOptions struct {
+	ProjectName string `json:"projectName"`
+	// QuarantineScore is the flakiness score from which a test is quarantined, 0.1 by default
+	QuarantineScore float64 `json:"quarantineScore"`
+	// QuarantineFlakyGroups is how many commits or pipelines a test must have flipped on before being quarantined, 2 by default
+	QuarantineFlakyGroups int `json:"quarantineFlakyGroups"`
+}
+
+type QaTraceTaskData struct {
+	Options *QaTraceOptions
+}
+
+func DecodeAndValidateTaskOptions(options map[string]interface{}) (*QaTraceOptions, errors.Error) {
+	var op QaTraceOptions
+	err := helper.Decode(options, &op, nil)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error decoding qa_trace task options")
+	}
+	if op.ProjectName == "" {
+		return nil, errors.BadInput.New("projectName is required")
+	}
+	if op.QuarantineScore <= 0 {
+		op.QuarantineScore = DEFAULT_QUARANTINE_SCORE
+	}
+	if op.QuarantineFlakyGroups <= 0 {
+		op.QuarantineFlakyGroups = DEFAULT_QUARANTINE_FLAKY_GROUPS
+	}
+	return &op, nil
+}
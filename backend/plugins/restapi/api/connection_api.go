@@ -0,0 +1,198 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/restapi/models"
+	"github.com/apache/incubator-devlake/server/api/shared"
+)
+
+type RestapiTestConnResponse struct {
+	shared.ApiBody
+	Connection *models.RestapiConn
+}
+
+func testConnection(ctx context.Context, connection models.RestapiConn) (*RestapiTestConnResponse, errors.Error) {
+	// validate
+	if vld != nil {
+		if err := connection.ValidateConnection(&connection, vld); err != nil {
+			return nil, err
+		}
+	}
+	spec, err := models.ParseSpec(connection.Spec)
+	if err != nil {
+		return nil, err
+	}
+	// test connection, only the spec would be validated if testPath was omitted
+	if spec.TestPath != "" {
+		apiClient, err := api.NewApiClientFromConnection(ctx, basicRes, &connection)
+		if err != nil {
+			return nil, err
+		}
+		res, err := apiClient.Get(spec.TestPath, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+			return nil, errors.HttpStatus(res.StatusCode).New("Please check your credential")
+		}
+		if res.StatusCode < 200 || res.StatusCode >= 300 {
+			return nil, errors.HttpStatus(res.StatusCode).New(fmt.Sprintf("unexpected status code: %d", res.StatusCode))
+		}
+	}
+	connection = connection.Sanitize()
+	body := RestapiTestConnResponse{}
+	body.Success = true
+	body.Message = "success"
+	body.Connection = &connection
+	return &body, nil
+}
+
+// validateSpec rejects the request if the spec in the body is invalid
+func validateSpec(body map[string]interface{}) errors.Error {
+	value, ok := body["spec"]
+	if !ok {
+		return nil
+	}
+	spec, ok := value.(string)
+	if !ok {
+		return errors.BadInput.New("spec must be a string in YAML or JSON")
+	}
+	_, err := models.ParseSpec(spec)
+	return err
+}
+
+// TestConnection test restapi connection
+// @Summary test restapi connection
+// @Description Test restapi Connection
+// @Tags plugins/restapi
+// @Param body body models.RestapiConnection true "json body"
+// @Success 200  {object} RestapiTestConnResponse "Success"
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/restapi/test [POST]
+func TestConnection(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	// process input
+	var connection models.RestapiConn
+	if err := api.Decode(input.Body, &connection, nil); err != nil {
+		return nil, err
+	}
+	// test connection
+	result, err := testConnection(context.TODO(), connection)
+	if err != nil {
+		return nil, plugin.WrapTestConnectionErrResp(basicRes, err)
+	}
+	return &plugin.ApiResourceOutput{Body: result, Status: http.StatusOK}, nil
+}
+
+// TestExistingConnection test restapi connection
+// @Summary test restapi connection
+// @Description Test restapi Connection
+// @Tags plugins/restapi
+// @Param connectionId path int true "connection ID"
+// @Success 200  {object} RestapiTestConnResponse "Success"
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/restapi/connections/{connectionId}/test [POST]
+func TestExistingConnection(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection, err := dsHelper.ConnApi.GetMergedConnection(input)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "find connection from db")
+	}
+	// test connection
+	result, err := testConnection(context.TODO(), connection.RestapiConn)
+	if err != nil {
+		return nil, plugin.WrapTestConnectionErrResp(basicRes, err)
+	}
+	return &plugin.ApiResourceOutput{Body: result, Status: http.StatusOK}, nil
+}
+
+// PostConnections create restapi connection
+// @Summary create restapi connection
+// @Description Create restapi connection, the spec would be validated
+// @Tags plugins/restapi
+// @Param body body models.RestapiConnection true "json body"
+// @Success 200  {object} models.RestapiConnection
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/restapi/connections [POST]
+func PostConnections(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	if err := validateSpec(input.Body); err != nil {
+		return nil, err
+	}
+	return dsHelper.ConnApi.Post(input)
+}
+
+// PatchConnection patch restapi connection
+// @Summary patch restapi connection
+// @Description Patch restapi connection, the spec would be validated
+// @Tags plugins/restapi
+// @Param body body models.RestapiConnection true "json body"
+// @Success 200  {object} models.RestapiConnection
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/restapi/connections/{connectionId} [PATCH]
+func PatchConnection(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	if err := validateSpec(input.Body); err != nil {
+		return nil, err
+	}
+	return dsHelper.ConnApi.Patch(input)
+}
+
+// DeleteConnection delete a restapi connection
+// @Summary delete a restapi connection
+// @Description Delete a restapi connection
+// @Tags plugins/restapi
+// @Success 200  {object} models.RestapiConnection
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/restapi/connections/{connectionId} [DELETE]
+func DeleteConnection(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	return dsHelper.ConnApi.Delete(input)
+}
+
+// ListConnections get all restapi connections
+// @Summary get all restapi connections
+// @Description Get all restapi connections
+// @Tags plugins/restapi
+// @Success 200  {object} []models.RestapiConnection
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/restapi/connections [GET]
+func ListConnections(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	return dsHelper.ConnApi.GetAll(input)
+}
+
+// GetConnection get restapi connection detail
+// @Summary get restapi connection detail
+// @Description Get restapi connection detail
+// @Tags plugins/restapi
+// @Success 200  {object} models.RestapiConnection
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/restapi/connections/{connectionId} [GET]
+func GetConnection(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	return dsHelper.ConnApi.GetDetail(input)
+}
@@ -0,0 +1,221 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package impl
+
+import (
+	"fmt"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	coreModels "github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/utils"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/restapi/api"
+	"github.com/apache/incubator-devlake/plugins/restapi/models"
+	"github.com/apache/incubator-devlake/plugins/restapi/models/migrationscripts"
+	"github.com/apache/incubator-devlake/plugins/restapi/tasks"
+)
+
+// make sure interface is implemented
+var _ plugin.PluginMeta = (*Restapi)(nil)
+var _ plugin.PluginInit = (*Restapi)(nil)
+var _ plugin.PluginTask = (*Restapi)(nil)
+var _ plugin.PluginApi = (*Restapi)(nil)
+var _ plugin.PluginModel = (*Restapi)(nil)
+var _ plugin.PluginSource = (*Restapi)(nil)
+var _ plugin.DataSourcePluginBlueprintV200 = (*Restapi)(nil)
+var _ plugin.CloseablePluginTask = (*Restapi)(nil)
+
+// Restapi collects data from any JSON REST API by the spec of its connection, without writing a plugin
+type Restapi struct{}
+
+// Name implements plugin.PluginMeta.
+func (Restapi) Name() string {
+	return "restapi"
+}
+
+func (p Restapi) Description() string {
+	return "collect data from JSON REST APIs declared by a spec"
+}
+
+func (p Restapi) Init(br context.BasicRes) errors.Error {
+	api.Init(br, &p)
+	return nil
+}
+
+func (p Restapi) Connection() dal.Tabler {
+	return &models.RestapiConnection{}
+}
+
+func (p Restapi) Scope() plugin.ToolLayerScope {
+	return &models.RestapiScope{}
+}
+
+func (p Restapi) ScopeConfig() dal.Tabler {
+	return &models.RestapiScopeConfig{}
+}
+
+func (p Restapi) GetTablesInfo() []dal.Tabler {
+	return []dal.Tabler{
+		&models.RestapiConnection{},
+		&models.RestapiScope{},
+		&models.RestapiScopeConfig{},
+		&models.RestapiRecord{},
+	}
+}
+
+func (p Restapi) SubTaskMetas() []plugin.SubTaskMeta {
+	return []plugin.SubTaskMeta{
+		tasks.CollectRecordsMeta,
+		tasks.ExtractRecordsMeta,
+		tasks.ConvertScopeMeta,
+		tasks.ConvertRecordsMeta,
+	}
+}
+
+func (p Restapi) MakeDataSourcePipelinePlanV200(
+	connectionId uint64,
+	scopes []*coreModels.BlueprintScope,
+) (pp coreModels.PipelinePlan, sc []plugin.Scope, err errors.Error) {
+	return api.MakeDataSourcePipelinePlanV200(p.SubTaskMetas(), connectionId, scopes)
+}
+
+func (p Restapi) PrepareTaskData(taskCtx plugin.TaskContext, options map[string]interface{}) (interface{}, errors.Error) {
+	op, err := tasks.DecodeAndValidateTaskOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	connectionHelper := helper.NewConnectionHelper(
+		taskCtx,
+		nil,
+		p.Name(),
+	)
+	connection := &models.RestapiConnection{}
+	err = connectionHelper.FirstById(connection, op.ConnectionId)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "unable to get restapi connection by the given connection ID")
+	}
+	spec, err := models.ParseSpec(connection.Spec)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, "invalid spec of the connection")
+	}
+
+	db := taskCtx.GetDal()
+	scope := &models.RestapiScope{}
+	err = db.First(scope, dal.Where("connection_id = ? AND id = ?", op.ConnectionId, op.ScopeId))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "scope not found")
+	}
+	// fallback to scope config of the scope
+	if op.ScopeConfig == nil && op.ScopeConfigId == 0 {
+		op.ScopeConfigId = scope.ScopeConfigId
+	}
+	if op.ScopeConfig == nil && op.ScopeConfigId != 0 {
+		var scopeConfig models.RestapiScopeConfig
+		err = db.First(&scopeConfig, dal.Where("id = ?", op.ScopeConfigId))
+		if err != nil && !db.IsErrorNotFound(err) {
+			return nil, errors.BadInput.Wrap(err, "fail to load scopeConfig")
+		}
+		op.ScopeConfig = &scopeConfig
+	}
+	if op.ScopeConfig == nil {
+		op.ScopeConfig = new(models.RestapiScopeConfig)
+	}
+
+	apiClient, err := tasks.NewRestapiApiClient(taskCtx, connection)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "unable to get restapi API client instance")
+	}
+	taskData := &tasks.RestapiTaskData{
+		Options:   op,
+		ApiClient: apiClient,
+		Scope:     scope,
+	}
+	// process streams of the selected entities only, all of them if no entity was selected
+	for _, stream := range spec.Streams {
+		entities := op.ScopeConfig.Entities
+		if len(entities) == 0 || utils.StringsContains(entities, stream.DomainType()) {
+			taskData.Streams = append(taskData.Streams, stream)
+		}
+	}
+	return taskData, nil
+}
+
+// RootPkgPath PkgPath information lost when compiled as plugin(.so)
+func (p Restapi) RootPkgPath() string {
+	return "github.com/apache/incubator-devlake/plugins/restapi"
+}
+
+func (p Restapi) MigrationScripts() []plugin.MigrationScript {
+	return migrationscripts.All()
+}
+
+func (p Restapi) ApiResources() map[string]map[string]plugin.ApiResourceHandler {
+	return map[string]map[string]plugin.ApiResourceHandler{
+		"test": {
+			"POST": api.TestConnection,
+		},
+		"connections": {
+			"POST": api.PostConnections,
+			"GET":  api.ListConnections,
+		},
+		"connections/:connectionId": {
+			"GET":    api.GetConnection,
+			"PATCH":  api.PatchConnection,
+			"DELETE": api.DeleteConnection,
+		},
+		"connections/:connectionId/test": {
+			"POST": api.TestExistingConnection,
+		},
+		"connections/:connectionId/scopes/:scopeId": {
+			"GET":    api.GetScope,
+			"PATCH":  api.PatchScope,
+			"DELETE": api.DeleteScope,
+		},
+		"connections/:connectionId/scopes/:scopeId/latest-sync-state": {
+			"GET": api.GetScopeLatestSyncState,
+		},
+		"connections/:connectionId/scopes": {
+			"GET": api.GetScopes,
+			"PUT": api.PutScopes,
+		},
+		"connections/:connectionId/scope-configs": {
+			"POST": api.PostScopeConfig,
+			"GET":  api.GetScopeConfigList,
+		},
+		"connections/:connectionId/scope-configs/:scopeConfigId": {
+			"PATCH":  api.PatchScopeConfig,
+			"GET":    api.GetScopeConfig,
+			"DELETE": api.DeleteScopeConfig,
+		},
+		"scope-config/:scopeConfigId/projects": {
+			"GET": api.GetProjectsByScopeConfig,
+		},
+	}
+}
+
+func (p Restapi) Close(taskCtx plugin.TaskContext) errors.Error {
+	data, ok := taskCtx.GetData().(*tasks.RestapiTaskData)
+	if !ok {
+		return errors.Default.New(fmt.Sprintf("GetData failed when try to close %+v", taskCtx))
+	}
+	data.ApiClient.Release()
+	return nil
+}
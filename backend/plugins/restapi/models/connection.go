@@ -0,0 +1,105 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/utils"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+)
+
+// RestapiAppKey sends the SecretKey as the value of the header named by AppId, i.e. `X-Api-Key`
+type RestapiAppKey helper.AppKey
+
+// SetupAuthentication sets up the HTTP Request Authentication
+func (ak *RestapiAppKey) SetupAuthentication(req *http.Request) errors.Error {
+	req.Header.Set(ak.AppId, ak.SecretKey)
+	return nil
+}
+
+// GetAppKeyAuthenticator returns the authenticator for the AppKey authentication method
+func (ak *RestapiAppKey) GetAppKeyAuthenticator() plugin.ApiAuthenticator {
+	return ak
+}
+
+// RestapiConn holds the essential information to connect to the API described by the Spec
+type RestapiConn struct {
+	helper.RestConnection `mapstructure:",squash"`
+	helper.MultiAuth      `mapstructure:",squash"`
+	helper.BasicAuth      `mapstructure:",squash"`
+	helper.AccessToken    `mapstructure:",squash"`
+	RestapiAppKey         `mapstructure:",squash" authMethod:"AppKey"`
+	// Spec declares what to collect from the API and how to convert it in YAML or JSON, check RestapiSpec for details
+	Spec string `mapstructure:"spec" json:"spec" validate:"required" gorm:"type:text"`
+}
+
+func (rc *RestapiConn) Sanitize() RestapiConn {
+	rc.Password = ""
+	rc.AccessToken.Token = utils.SanitizeString(rc.AccessToken.Token)
+	rc.RestapiAppKey.SecretKey = utils.SanitizeString(rc.RestapiAppKey.SecretKey)
+	return *rc
+}
+
+// SetupAuthentication implements the `IAuthentication` interface by delegating
+// the actual logic to the `MultiAuth` struct
+func (rc *RestapiConn) SetupAuthentication(req *http.Request) errors.Error {
+	return rc.MultiAuth.SetupAuthenticationForConnection(rc, req)
+}
+
+// RestapiConnection holds RestapiConn plus ID/Name for database storage
+type RestapiConnection struct {
+	helper.BaseConnection `mapstructure:",squash"`
+	RestapiConn           `mapstructure:",squash"`
+}
+
+func (RestapiConnection) TableName() string {
+	return "_tool_restapi_connections"
+}
+
+func (connection *RestapiConnection) MergeFromRequest(target *RestapiConnection, body map[string]interface{}) error {
+	token := target.Token
+	password := target.Password
+	secretKey := target.SecretKey
+	authMethod := target.AuthMethod
+
+	if err := helper.DecodeMapStruct(body, target, true); err != nil {
+		return err
+	}
+
+	// keep the secrets unless they were changed along with the auth method
+	if authMethod == target.AuthMethod {
+		if target.Token == "" || target.Token == utils.SanitizeString(token) {
+			target.Token = token
+		}
+		if target.Password == "" || target.Password == utils.SanitizeString(password) {
+			target.Password = password
+		}
+		if target.SecretKey == "" || target.SecretKey == utils.SanitizeString(secretKey) {
+			target.SecretKey = secretKey
+		}
+	}
+	return nil
+}
+
+func (connection RestapiConnection) Sanitize() RestapiConnection {
+	connection.RestapiConn = connection.RestapiConn.Sanitize()
+	return connection
+}
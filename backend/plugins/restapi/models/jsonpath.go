@@ -0,0 +1,103 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/tidwall/gjson"
+)
+
+// JsonPath is a compiled JSONPath expression, only the subset below is supported:
+//
+//	$                  the root
+//	$.name / $['name'] a child by name
+//	$.items[0]         an element by index
+//	$.items[*].id      `id` of all elements, an array would be returned
+type JsonPath struct {
+	raw  string
+	path string
+}
+
+var gjsonEscaper = strings.NewReplacer(
+	`\`, `\\`, `.`, `\.`, `*`, `\*`, `?`, `\?`, `|`, `\|`, `#`, `\#`, `@`, `\@`,
+)
+
+// CompileJsonPath translates the JSONPath expression into a gjson path
+func CompileJsonPath(expr string) (*JsonPath, errors.Error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return nil, errors.BadInput.New(fmt.Sprintf("invalid JSONPath %q: must start with $", expr))
+	}
+	var parts []string
+	rest := expr[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, errors.BadInput.New(fmt.Sprintf("invalid JSONPath %q: empty name", expr))
+			}
+			if name == "*" {
+				parts = append(parts, "#")
+			} else {
+				parts = append(parts, gjsonEscaper.Replace(name))
+			}
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, errors.BadInput.New(fmt.Sprintf("invalid JSONPath %q: unclosed [", expr))
+			}
+			selector := rest[1:end]
+			switch {
+			case selector == "*":
+				parts = append(parts, "#")
+			case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+				parts = append(parts, gjsonEscaper.Replace(selector[1:len(selector)-1]))
+			case selector != "" && strings.Trim(selector, "0123456789") == "":
+				parts = append(parts, selector)
+			default:
+				return nil, errors.BadInput.New(fmt.Sprintf("invalid JSONPath %q: unsupported selector [%s]", expr, selector))
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, errors.BadInput.New(fmt.Sprintf("invalid JSONPath %q: unexpected %q", expr, rest[0]))
+		}
+	}
+	return &JsonPath{raw: expr, path: strings.Join(parts, ".")}, nil
+}
+
+// Get returns the value at the path of the JSON document
+func (p *JsonPath) Get(data []byte) gjson.Result {
+	if p.path == "" {
+		return gjson.ParseBytes(data)
+	}
+	return gjson.GetBytes(data, p.path)
+}
+
+func (p *JsonPath) String() string {
+	return p.raw
+}
@@ -0,0 +1,64 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+// RestapiScope is defined by users, its Id could be referred by the stream paths as `{{ .Params.ScopeId }}`
+type RestapiScope struct {
+	common.Scope `mapstructure:",squash"`
+	Id           string `gorm:"primaryKey;type:varchar(255)" json:"id" mapstructure:"id" validate:"required"`
+	Name         string `gorm:"type:varchar(255)" json:"name" mapstructure:"name" validate:"required"`
+}
+
+func (RestapiScope) TableName() string {
+	return "_tool_restapi_scopes"
+}
+
+var _ plugin.ToolLayerScope = (*RestapiScope)(nil)
+
+type RestapiApiParams struct {
+	ConnectionId uint64
+	ScopeId      string
+}
+
+// ScopeFullName implements plugin.ToolLayerScope.
+func (s RestapiScope) ScopeFullName() string {
+	return s.Name
+}
+
+// ScopeId implements plugin.ToolLayerScope.
+func (s RestapiScope) ScopeId() string {
+	return s.Id
+}
+
+// ScopeName implements plugin.ToolLayerScope.
+func (s RestapiScope) ScopeName() string {
+	return s.Name
+}
+
+// ScopeParams implements plugin.ToolLayerScope.
+func (s RestapiScope) ScopeParams() interface{} {
+	return &RestapiApiParams{
+		ConnectionId: s.ConnectionId,
+		ScopeId:      s.Id,
+	}
+}
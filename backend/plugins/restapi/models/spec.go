@@ -0,0 +1,428 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/tidwall/gjson"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	PAGINATION_NONE   = "none"
+	PAGINATION_PAGE   = "page"
+	PAGINATION_CURSOR = "cursor"
+	PAGINATION_LINK   = "link"
+)
+
+const (
+	TIME_FORMAT_UNIX       = "unix"
+	TIME_FORMAT_UNIX_MILLI = "unixMilli"
+)
+
+// RestapiTarget is a domain table the records of a stream could be converted into
+type RestapiTarget struct {
+	DomainType string
+	New        func() dal.Tabler
+}
+
+// RestapiTargets lists the supported domain tables by their table names
+var RestapiTargets = map[string]RestapiTarget{
+	"issues":         {plugin.DOMAIN_TYPE_TICKET, func() dal.Tabler { return &ticket.Issue{} }},
+	"incidents":      {plugin.DOMAIN_TYPE_TICKET, func() dal.Tabler { return &ticket.Incident{} }},
+	"cicd_pipelines": {plugin.DOMAIN_TYPE_CICD, func() dal.Tabler { return &devops.CICDPipeline{} }},
+	"cicd_tasks":     {plugin.DOMAIN_TYPE_CICD, func() dal.Tabler { return &devops.CICDTask{} }},
+	"pull_requests":  {plugin.DOMAIN_TYPE_CODE, func() dal.Tabler { return &code.PullRequest{} }},
+}
+
+// RestapiSpec declares what to collect from the API and how to convert it, i.e.
+//
+//	testPath: api/v1/me
+//	streams:
+//	  - name: deployments
+//	    path: api/v1/services/{{ .Params.ScopeId }}/deployments
+//	    query: {status: all}
+//	    dataPath: $.data
+//	    pagination: {type: cursor, pageSize: 50, sizeParam: limit, cursorParam: after, cursorPath: $.meta.next}
+//	    incremental: {field: $.updated_at, param: updated_since}
+//	    idField: $.id
+//	    target: cicd_pipelines
+//	    mappings:
+//	      name: $.name
+//	      result: $.outcome
+//	      createdDate: $.created_at
+//	      finishedDate: $.finished_at
+type RestapiSpec struct {
+	// TestPath would be requested to test the connection if specified
+	TestPath string           `yaml:"testPath"`
+	Streams  []*RestapiStream `yaml:"streams"`
+}
+
+// RestapiStream is a list endpoint of the API whose records would be converted into the Target table
+type RestapiStream struct {
+	// Name identifies the stream, it must be unique within the spec and is used as part of the raw table name
+	Name string `yaml:"name"`
+	// Path is a go template relative to the endpoint of the connection, i.e. `projects/{{ .Params.ScopeId }}/issues`
+	Path    string            `yaml:"path"`
+	Query   map[string]string `yaml:"query"`
+	Headers map[string]string `yaml:"headers"`
+	// DataPath points to the array of records within the response, the response itself by default
+	DataPath    string              `yaml:"dataPath"`
+	Pagination  RestapiPagination   `yaml:"pagination"`
+	Incremental *RestapiIncremental `yaml:"incremental"`
+	// IdField points to the unique id of a record
+	IdField string `yaml:"idField"`
+	Target  string `yaml:"target"`
+	// Mappings maps fields of the target table, in camelCase, to JSONPaths within a record
+	Mappings map[string]string `yaml:"mappings"`
+	// Refs marks the mapped fields that refer to records of other streams, i.e. `pipelineId: pipelines`,
+	// their values would be converted into ids of the referred records in the domain layer
+	Refs map[string]string `yaml:"refs"`
+
+	dataPath *JsonPath
+	idPath   *JsonPath
+	mappings map[string]*JsonPath
+}
+
+type RestapiPagination struct {
+	// Type is one of none, page, cursor and link (the `next` url in the Link header)
+	Type string `yaml:"type"`
+	// PageSize must match the number of records the API returns per page, collection stops on a shorter page
+	PageSize  int    `yaml:"pageSize"`
+	SizeParam string `yaml:"sizeParam"`
+	PageParam string `yaml:"pageParam"`
+	// StartPage is the number of the first page, 1 by default
+	StartPage   *int   `yaml:"startPage"`
+	CursorParam string `yaml:"cursorParam"`
+	// CursorPath points to the cursor of the next page within the response
+	CursorPath string `yaml:"cursorPath"`
+
+	cursorPath *JsonPath
+}
+
+// RestapiIncremental enables incremental collection, records updated since the last successful collection
+// would be filtered by the API with Param if specified, otherwise the API must return records in descending
+// order of Field so the collection could stop at the first record updated before that
+type RestapiIncremental struct {
+	Field string `yaml:"field"`
+	Param string `yaml:"param"`
+	// Format of the Param value, either a go time layout, `unix` or `unixMilli`, RFC3339 by default
+	Format string `yaml:"format"`
+
+	field *JsonPath
+}
+
+var streamNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// ParseSpec parses and validates the spec in YAML or JSON
+func ParseSpec(text string) (*RestapiSpec, errors.Error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.BadInput.New("spec is required")
+	}
+	spec := &RestapiSpec{}
+	decoder := yaml.NewDecoder(strings.NewReader(text))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(spec); err != nil {
+		return nil, errors.BadInput.Wrap(err, "failed to parse spec")
+	}
+	if len(spec.Streams) == 0 {
+		return nil, errors.BadInput.New("spec must declare at least one stream")
+	}
+	names := make(map[string]bool, len(spec.Streams))
+	for _, stream := range spec.Streams {
+		if stream == nil {
+			return nil, errors.BadInput.New("stream must not be empty")
+		}
+		if !streamNamePattern.MatchString(stream.Name) {
+			return nil, errors.BadInput.New(fmt.Sprintf("invalid stream name %q: must be lowercase letters, digits and underscores", stream.Name))
+		}
+		if stream.Name == "scopes" {
+			return nil, errors.BadInput.New(`stream name "scopes" is reserved for the raw table of the scopes`)
+		}
+		if names[stream.Name] {
+			return nil, errors.BadInput.New(fmt.Sprintf("duplicated stream %q", stream.Name))
+		}
+		names[stream.Name] = true
+	}
+	for _, stream := range spec.Streams {
+		if err := stream.compile(names); err != nil {
+			return nil, errors.BadInput.Wrap(err, fmt.Sprintf("invalid stream %q", stream.Name))
+		}
+	}
+	return spec, nil
+}
+
+// DomainTypes returns the domain types of all streams
+func (spec *RestapiSpec) DomainTypes() []string {
+	var domainTypes []string
+	for _, stream := range spec.Streams {
+		domainType := stream.DomainType()
+		found := false
+		for _, t := range domainTypes {
+			found = found || t == domainType
+		}
+		if !found {
+			domainTypes = append(domainTypes, domainType)
+		}
+	}
+	return domainTypes
+}
+
+func (s *RestapiStream) compile(streams map[string]bool) errors.Error {
+	var err errors.Error
+	if s.Path == "" {
+		return errors.BadInput.New("path is required")
+	}
+	if _, e := template.New(s.Name).Parse(s.Path); e != nil {
+		return errors.BadInput.Wrap(e, "invalid path")
+	}
+	dataPath := s.DataPath
+	if dataPath == "" {
+		dataPath = "$"
+	}
+	if s.dataPath, err = CompileJsonPath(dataPath); err != nil {
+		return err
+	}
+	if s.IdField == "" {
+		return errors.BadInput.New("idField is required")
+	}
+	if s.idPath, err = CompileJsonPath(s.IdField); err != nil {
+		return err
+	}
+	if err = s.Pagination.compile(); err != nil {
+		return err
+	}
+	if s.Incremental != nil {
+		if err = s.Incremental.compile(); err != nil {
+			return err
+		}
+	}
+	target, ok := RestapiTargets[s.Target]
+	if !ok {
+		return errors.BadInput.New(fmt.Sprintf("unsupported target %q", s.Target))
+	}
+	fields := map[string]bool{}
+	collectFieldNames(reflect.TypeOf(target.New()).Elem(), fields)
+	s.mappings = make(map[string]*JsonPath, len(s.Mappings))
+	for field, path := range s.Mappings {
+		if !fields[strings.ToLower(field)] {
+			return errors.BadInput.New(fmt.Sprintf("unknown field %q of %s", field, s.Target))
+		}
+		if s.mappings[field], err = CompileJsonPath(path); err != nil {
+			return err
+		}
+	}
+	for field, stream := range s.Refs {
+		if _, ok := s.Mappings[field]; !ok {
+			return errors.BadInput.New(fmt.Sprintf("ref %q is not mapped", field))
+		}
+		if !streams[stream] {
+			return errors.BadInput.New(fmt.Sprintf("ref %q refers to unknown stream %q", field, stream))
+		}
+	}
+	return nil
+}
+
+// collectFieldNames collects lowercased names of the fields which could be mapped, ids are generated by the plugin
+func collectFieldNames(t reflect.Type, fields map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			if field.Type != reflect.TypeOf(domainlayer.DomainEntity{}) && field.Type.Kind() == reflect.Struct {
+				collectFieldNames(field.Type, fields)
+			}
+			continue
+		}
+		if field.IsExported() {
+			fields[strings.ToLower(field.Name)] = true
+		}
+	}
+}
+
+// RawTable returns the name of the raw table of the stream, without the `_raw_` prefix
+func (s *RestapiStream) RawTable() string {
+	return "restapi_" + s.Name
+}
+
+// DomainType returns the domain type of the target table
+func (s *RestapiStream) DomainType() string {
+	return RestapiTargets[s.Target].DomainType
+}
+
+// Records picks the records out of the response body
+func (s *RestapiStream) Records(body []byte) ([]json.RawMessage, errors.Error) {
+	result := s.dataPath.Get(body)
+	if !result.Exists() || result.Type == gjson.Null {
+		return nil, nil
+	}
+	if !result.IsArray() {
+		return nil, errors.Default.New(fmt.Sprintf("%s of the response is not an array", s.dataPath))
+	}
+	items := result.Array()
+	records := make([]json.RawMessage, len(items))
+	for i, item := range items {
+		records[i] = json.RawMessage(item.Raw)
+	}
+	return records, nil
+}
+
+// RecordId returns the id of the record
+func (s *RestapiStream) RecordId(record []byte) string {
+	return s.idPath.Get(record).String()
+}
+
+// ExtractFields returns the mapped fields of the record, missing ones are omitted
+func (s *RestapiStream) ExtractFields(record []byte) map[string]interface{} {
+	fields := make(map[string]interface{}, len(s.mappings))
+	for field, path := range s.mappings {
+		result := path.Get(record)
+		if result.Exists() {
+			fields[field] = result.Value()
+		}
+	}
+	return fields
+}
+
+func (p *RestapiPagination) compile() errors.Error {
+	if p.Type == "" {
+		p.Type = PAGINATION_NONE
+	}
+	switch p.Type {
+	case PAGINATION_NONE:
+		return nil
+	case PAGINATION_PAGE:
+		if p.PageParam == "" {
+			p.PageParam = "page"
+		}
+		if p.StartPage == nil {
+			startPage := 1
+			p.StartPage = &startPage
+		}
+	case PAGINATION_CURSOR:
+		if p.CursorParam == "" {
+			p.CursorParam = "cursor"
+		}
+		if p.CursorPath == "" {
+			return errors.BadInput.New("pagination.cursorPath is required for cursor pagination")
+		}
+		var err errors.Error
+		if p.cursorPath, err = CompileJsonPath(p.CursorPath); err != nil {
+			return err
+		}
+	case PAGINATION_LINK:
+	default:
+		return errors.BadInput.New(fmt.Sprintf("unsupported pagination type %q", p.Type))
+	}
+	if p.PageSize < 0 {
+		return errors.BadInput.New("pagination.pageSize must be positive")
+	}
+	if p.PageSize == 0 {
+		p.PageSize = 100
+	}
+	return nil
+}
+
+// Page returns the value of the page param for the nth page starting from 1
+func (p *RestapiPagination) Page(nth int) string {
+	return strconv.Itoa(*p.StartPage + nth - 1)
+}
+
+// NextCursor returns the cursor of the next page within the response body, empty if it was the last page
+func (p *RestapiPagination) NextCursor(body []byte) string {
+	return p.cursorPath.Get(body).String()
+}
+
+func (inc *RestapiIncremental) compile() errors.Error {
+	if inc.Field == "" && inc.Param == "" {
+		return errors.BadInput.New("incremental.field or incremental.param is required")
+	}
+	if inc.Field != "" {
+		var err errors.Error
+		if inc.field, err = CompileJsonPath(inc.Field); err != nil {
+			return err
+		}
+	}
+	if inc.Format == "" {
+		inc.Format = time.RFC3339
+	}
+	return nil
+}
+
+// FormatTime formats the time as the value of Param
+func (inc *RestapiIncremental) FormatTime(t time.Time) string {
+	switch inc.Format {
+	case TIME_FORMAT_UNIX:
+		return strconv.FormatInt(t.Unix(), 10)
+	case TIME_FORMAT_UNIX_MILLI:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+	return t.Format(inc.Format)
+}
+
+// UpdatedAt returns the time the record was updated, nil if Field was not specified or not found
+func (inc *RestapiIncremental) UpdatedAt(record []byte) (*time.Time, errors.Error) {
+	if inc.field == nil {
+		return nil, nil
+	}
+	result := inc.field.Get(record)
+	if !result.Exists() || result.Type == gjson.Null {
+		return nil, nil
+	}
+	updatedAt, err := ParseTime(result.Value())
+	if err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to parse %s of the record", inc.field))
+	}
+	return updatedAt, nil
+}
+
+// ParseTime parses the time in string, or in epoch seconds or milliseconds
+func ParseTime(value interface{}) (*time.Time, errors.Error) {
+	var t time.Time
+	switch v := value.(type) {
+	case float64:
+		t = time.Unix(int64(v), 0)
+		// values beyond year 5138 in seconds are taken as milliseconds
+		if v > 1e11 {
+			t = time.UnixMilli(int64(v))
+		}
+	case string:
+		var err error
+		if t, err = common.ConvertStringToTime(v); err != nil {
+			return nil, errors.Convert(err)
+		}
+	default:
+		return nil, errors.Default.New(fmt.Sprintf("unsupported time %v", value))
+	}
+	return &t, nil
+}
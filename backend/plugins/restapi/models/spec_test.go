@@ -0,0 +1,163 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSpec = `
+testPath: api/v1/me
+streams:
+  - name: pipelines
+    path: api/v1/projects/{{ .Params.ScopeId }}/pipelines
+    dataPath: $.data
+    pagination: {type: cursor, pageSize: 50, sizeParam: limit, cursorPath: $.meta.next}
+    incremental: {field: $.updated_at, param: since}
+    idField: $.id
+    target: cicd_pipelines
+    mappings:
+      name: $.name
+      result: $.outcome
+      createdDate: $.created_at
+  - name: jobs
+    path: api/v1/projects/{{ .Params.ScopeId }}/jobs
+    pagination: {type: page, startPage: 0}
+    idField: $['id']
+    target: cicd_tasks
+    mappings:
+      name: $.name
+      pipelineId: $.pipeline.id
+    refs:
+      pipelineId: pipelines
+`
+
+func TestCompileJsonPath(t *testing.T) {
+	data := []byte(`{"data":{"items":[{"id":1,"a.b":"x"},{"id":2}]},"list":[3,4]}`)
+	cases := map[string]string{
+		"$.data.items[0].id":          "1",
+		"$['data']['items'][1]['id']": "2",
+		"$.data.items[*].id":          "[1,2]",
+		"$.data.items[0]['a.b']":      `"x"`,
+		"$.list[1]":                   "4",
+		"$.list":                      "[3,4]",
+	}
+	for expr, expected := range cases {
+		path, err := CompileJsonPath(expr)
+		if assert.Nil(t, err, expr) {
+			assert.Equal(t, expected, path.Get(data).Raw, expr)
+		}
+	}
+	root, err := CompileJsonPath("$")
+	assert.Nil(t, err)
+	assert.Equal(t, string(data), root.Get(data).Raw)
+
+	for _, expr := range []string{"data.items", "$.data[", "$..id", "$.data[?(@.id)]"} {
+		_, err := CompileJsonPath(expr)
+		assert.NotNil(t, err, expr)
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	spec, err := ParseSpec(testSpec)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "api/v1/me", spec.TestPath)
+	assert.Len(t, spec.Streams, 2)
+	assert.Equal(t, []string{plugin.DOMAIN_TYPE_CICD}, spec.DomainTypes())
+
+	pipelines := spec.Streams[0]
+	assert.Equal(t, "restapi_pipelines", pipelines.RawTable())
+	assert.Equal(t, "cursor", pipelines.Pagination.CursorParam)
+	assert.Equal(t, time.RFC3339, pipelines.Incremental.Format)
+	records, err := pipelines.Records([]byte(`{"data":[{"id":"a","name":"build","outcome":"ok"}],"meta":{"next":"abc"}}`))
+	assert.Nil(t, err)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "a", pipelines.RecordId(records[0]))
+		assert.Equal(t, map[string]interface{}{"name": "build", "result": "ok"}, pipelines.ExtractFields(records[0]))
+	}
+	assert.Equal(t, "abc", pipelines.Pagination.NextCursor([]byte(`{"meta":{"next":"abc"}}`)))
+	_, err = pipelines.Records([]byte(`{"data":{"id":"a"}}`))
+	assert.NotNil(t, err)
+
+	jobs := spec.Streams[1]
+	assert.Equal(t, "page", jobs.Pagination.PageParam)
+	assert.Equal(t, 100, jobs.Pagination.PageSize)
+	assert.Equal(t, "0", jobs.Pagination.Page(1))
+	assert.Equal(t, "2", jobs.Pagination.Page(3))
+}
+
+func TestParseSpecInvalid(t *testing.T) {
+	stream := "streams:\n  - {name: items, path: items, idField: $.id, target: issues%s}\n"
+	cases := map[string]string{
+		"empty":           "",
+		"no streams":      "testPath: me",
+		"unknown key":     "streams: []\nfoo: bar",
+		"bad name":        "streams:\n  - {name: My-Items, path: items, idField: $.id, target: issues}",
+		"no path":         "streams:\n  - {name: items, idField: $.id, target: issues}",
+		"bad template":    "streams:\n  - {name: items, path: '{{ .Params', idField: $.id, target: issues}",
+		"no id":           "streams:\n  - {name: items, path: items, target: issues}",
+		"bad target":      "streams:\n  - {name: items, path: items, idField: $.id, target: commits}",
+		"unknown field":   fmt.Sprintf(stream, ", mappings: {foo: $.foo}"),
+		"bad mapping":     fmt.Sprintf(stream, ", mappings: {title: title}"),
+		"bad pagination":  fmt.Sprintf(stream, ", pagination: {type: offset}"),
+		"no cursor path":  fmt.Sprintf(stream, ", pagination: {type: cursor}"),
+		"bad incremental": fmt.Sprintf(stream, ", incremental: {format: unix}"),
+		"unmapped ref":    fmt.Sprintf(stream, ", refs: {parentIssueId: items}"),
+		"unknown ref":     fmt.Sprintf(stream, ", mappings: {parentIssueId: $.parent}, refs: {parentIssueId: others}"),
+		"duplicated":      "streams:\n  - {name: items, path: items, idField: $.id, target: issues}\n  - {name: items, path: items, idField: $.id, target: issues}",
+	}
+	for name, text := range cases {
+		_, err := ParseSpec(text)
+		assert.NotNil(t, err, name)
+	}
+	_, err := ParseSpec(fmt.Sprintf(stream, ", mappings: {title: $.title, parentIssueId: $.parent}, refs: {parentIssueId: items}"))
+	assert.Nil(t, err)
+}
+
+func TestIncrementalUpdatedAt(t *testing.T) {
+	inc := &RestapiIncremental{Field: "$.updated", Format: TIME_FORMAT_UNIX}
+	assert.Nil(t, inc.compile())
+	expected := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, record := range []string{
+		`{"updated":"2024-01-02T03:04:05Z"}`,
+		`{"updated":1704164645}`,
+		`{"updated":1704164645000}`,
+	} {
+		updatedAt, err := inc.UpdatedAt([]byte(record))
+		if assert.Nil(t, err, record) && assert.NotNil(t, updatedAt, record) {
+			assert.True(t, expected.Equal(*updatedAt), record)
+		}
+	}
+	updatedAt, err := inc.UpdatedAt([]byte(`{}`))
+	assert.Nil(t, err)
+	assert.Nil(t, updatedAt)
+
+	assert.Equal(t, "1704164645", inc.FormatTime(expected))
+	inc.Format = TIME_FORMAT_UNIX_MILLI
+	assert.Equal(t, "1704164645000", inc.FormatTime(expected))
+	inc.Format = "2006-01-02"
+	assert.Equal(t, "2024-01-02", inc.FormatTime(expected))
+}
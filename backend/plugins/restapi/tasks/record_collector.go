@@ -0,0 +1,241 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/restapi/models"
+)
+
+var _ plugin.SubTaskEntryPoint = CollectRecords
+
+var CollectRecordsMeta = plugin.SubTaskMeta{
+	Name:             "collectRecords",
+	EntryPoint:       CollectRecords,
+	EnabledByDefault: true,
+	Description:      "collect records of all streams declared by the spec",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_TICKET, plugin.DOMAIN_TYPE_CICD, plugin.DOMAIN_TYPE_CODE},
+}
+
+func CollectRecords(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*RestapiTaskData)
+	for _, stream := range data.Streams {
+		taskCtx.GetLogger().Info("collect stream %s", stream.Name)
+		if err := collectStream(taskCtx, stream); err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("failed to collect stream %s", stream.Name))
+		}
+	}
+	return nil
+}
+
+func collectStream(taskCtx plugin.SubTaskContext, stream *models.RestapiStream) errors.Error {
+	rawDataSubTaskArgs, data := CreateRawDataSubTaskArgs(taskCtx, stream)
+	if stream.Incremental == nil {
+		collector, err := api.NewApiCollector(buildCollectorArgs(*rawDataSubTaskArgs, data.ApiClient, stream, nil))
+		if err != nil {
+			return err
+		}
+		return collector.Execute()
+	}
+	// collect records updated since the last successful collection of the stream
+	manager, err := api.NewStatefulApiCollector(*rawDataSubTaskArgs)
+	if err != nil {
+		return err
+	}
+	err = manager.InitCollector(buildCollectorArgs(*rawDataSubTaskArgs, data.ApiClient, stream, manager.GetSince()))
+	if err != nil {
+		return err
+	}
+	return manager.Execute()
+}
+
+func buildCollectorArgs(
+	rawDataSubTaskArgs api.RawDataSubTaskArgs,
+	apiClient *api.ApiAsyncClient,
+	stream *models.RestapiStream,
+	since *time.Time,
+) api.ApiCollectorArgs {
+	pagination := stream.Pagination
+	args := api.ApiCollectorArgs{
+		RawDataSubTaskArgs: rawDataSubTaskArgs,
+		ApiClient:          apiClient,
+		UrlTemplate:        stream.Path,
+		Query: func(reqData *api.RequestData) (url.Values, errors.Error) {
+			return buildQuery(stream, reqData, since)
+		},
+		Header: func(reqData *api.RequestData) (http.Header, errors.Error) {
+			header := http.Header{}
+			for k, v := range stream.Headers {
+				header.Set(k, v)
+			}
+			return header, nil
+		},
+		ResponseParser: func(res *http.Response) ([]json.RawMessage, errors.Error) {
+			body, err := io.ReadAll(res.Body)
+			res.Body.Close()
+			if err != nil {
+				return nil, errors.Convert(err)
+			}
+			records, e := stream.Records(body)
+			if e != nil {
+				return nil, e
+			}
+			return filterUpdatedSince(stream, records, since)
+		},
+	}
+	switch pagination.Type {
+	case models.PAGINATION_PAGE:
+		args.PageSize = pagination.PageSize
+		// pages are requested in order, the page number is tracked by the Pager
+		args.GetNextPageCustomData = func(prevReqData *api.RequestData, prevPageResponse *http.Response) (interface{}, errors.Error) {
+			return nil, nil
+		}
+	case models.PAGINATION_CURSOR:
+		args.PageSize = pagination.PageSize
+		args.GetNextPageCustomData = func(prevReqData *api.RequestData, prevPageResponse *http.Response) (interface{}, errors.Error) {
+			body, err := io.ReadAll(prevPageResponse.Body)
+			if err != nil {
+				return nil, errors.Convert(err)
+			}
+			cursor := pagination.NextCursor(body)
+			if cursor == "" {
+				return nil, api.ErrFinishCollect
+			}
+			return cursor, nil
+		}
+	case models.PAGINATION_LINK:
+		args.PageSize = pagination.PageSize
+		// the next link is requested as is, it may point to another path of the endpoint
+		args.UrlTemplate = "{{ with .CustomData }}{{ . }}{{ else }}" + stream.Path + "{{ end }}"
+		args.GetNextPageCustomData = func(prevReqData *api.RequestData, prevPageResponse *http.Response) (interface{}, errors.Error) {
+			next := nextLink(prevPageResponse.Header.Get("Link"))
+			if next == "" {
+				return nil, api.ErrFinishCollect
+			}
+			// the credentials of the connection must not be sent to any other host
+			if err := checkSameOrigin(apiClient.GetEndpoint(), next); err != nil {
+				return nil, err
+			}
+			return next, nil
+		}
+	}
+	return args
+}
+
+func buildQuery(stream *models.RestapiStream, reqData *api.RequestData, since *time.Time) (url.Values, errors.Error) {
+	pagination := stream.Pagination
+	if next, ok := reqData.CustomData.(string); ok && next != "" && pagination.Type == models.PAGINATION_LINK {
+		// the next link carries the whole query of the page, it must not be altered
+		return nil, nil
+	}
+	query := url.Values{}
+	for k, v := range stream.Query {
+		query.Set(k, v)
+	}
+	if pagination.Type != models.PAGINATION_NONE && pagination.SizeParam != "" {
+		query.Set(pagination.SizeParam, strconv.Itoa(pagination.PageSize))
+	}
+	switch pagination.Type {
+	case models.PAGINATION_PAGE:
+		query.Set(pagination.PageParam, pagination.Page(reqData.Pager.Page))
+	case models.PAGINATION_CURSOR:
+		if cursor, ok := reqData.CustomData.(string); ok && cursor != "" {
+			query.Set(pagination.CursorParam, cursor)
+		}
+	}
+	if since != nil && stream.Incremental.Param != "" {
+		query.Set(stream.Incremental.Param, stream.Incremental.FormatTime(*since))
+	}
+	return query, nil
+}
+
+// filterUpdatedSince stops the collection at the first record updated before the last successful collection
+// if the API couldn't filter records by itself, records are expected in descending order of the updated time
+func filterUpdatedSince(stream *models.RestapiStream, records []json.RawMessage, since *time.Time) ([]json.RawMessage, errors.Error) {
+	if since == nil || stream.Incremental.Param != "" {
+		return records, nil
+	}
+	filtered := make([]json.RawMessage, 0, len(records))
+	for _, record := range records {
+		updatedAt, err := stream.Incremental.UpdatedAt(record)
+		if err != nil {
+			return nil, err
+		}
+		if updatedAt != nil && updatedAt.Before(*since) {
+			return filtered, api.ErrFinishCollect
+		}
+		filtered = append(filtered, record)
+	}
+	return filtered, nil
+}
+
+// checkSameOrigin rejects the absolute link with a scheme or host other than those of the endpoint
+func checkSameOrigin(endpoint, link string) errors.Error {
+	linkUrl, err := url.Parse(link)
+	if err != nil {
+		return errors.BadInput.Wrap(err, fmt.Sprintf("invalid next link %s", link))
+	}
+	if !linkUrl.IsAbs() && linkUrl.Host == "" {
+		return nil
+	}
+	endpointUrl, err := url.Parse(endpoint)
+	if err != nil {
+		return errors.BadInput.Wrap(err, fmt.Sprintf("invalid endpoint %s", endpoint))
+	}
+	if !strings.EqualFold(linkUrl.Scheme, endpointUrl.Scheme) || !strings.EqualFold(linkUrl.Host, endpointUrl.Host) {
+		return errors.BadInput.New(fmt.Sprintf("next link %s is out of the endpoint %s", link, endpoint))
+	}
+	return nil
+}
+
+// nextLink returns the url of rel="next" in the Link header, i.e. `<https://host/items?page=2>; rel="next"`
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		segments := strings.Split(link, ";")
+		if len(segments) < 2 {
+			continue
+		}
+		target := strings.TrimSpace(segments[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range segments[1:] {
+			key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || strings.TrimSpace(key) != "rel" {
+				continue
+			}
+			for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+				if rel == "next" {
+					return target[1 : len(target)-1]
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/restapi/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseStream(t *testing.T, stream string) *models.RestapiStream {
+	spec, err := models.ParseSpec("streams:\n  - " + stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return spec.Streams[0]
+}
+
+func TestBuildQuery(t *testing.T) {
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	stream := parseStream(t, `{name: items, path: items, query: {state: all}, idField: $.id, target: issues,
+    pagination: {type: page, pageSize: 20, sizeParam: per_page}, incremental: {param: since}}`)
+	query, err := buildQuery(stream, &api.RequestData{Pager: &api.Pager{Page: 2}}, &since)
+	assert.Nil(t, err)
+	assert.Equal(t, "all", query.Get("state"))
+	assert.Equal(t, "2", query.Get("page"))
+	assert.Equal(t, "20", query.Get("per_page"))
+	assert.Equal(t, "2024-01-02T03:04:05Z", query.Get("since"))
+
+	stream = parseStream(t, `{name: items, path: items, idField: $.id, target: issues,
+    pagination: {type: cursor, cursorParam: after, cursorPath: $.next}}`)
+	query, err = buildQuery(stream, &api.RequestData{Pager: &api.Pager{Page: 1}}, nil)
+	assert.Nil(t, err)
+	assert.False(t, query.Has("after"))
+	query, err = buildQuery(stream, &api.RequestData{Pager: &api.Pager{Page: 2}, CustomData: "abc"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", query.Get("after"))
+
+	stream = parseStream(t, `{name: items, path: items, query: {state: all}, idField: $.id, target: issues,
+    pagination: {type: link, pageSize: 100, sizeParam: per_page}}`)
+	query, err = buildQuery(stream, &api.RequestData{Pager: &api.Pager{Page: 1}}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "all", query.Get("state"))
+	assert.Equal(t, "100", query.Get("per_page"))
+	// the next link is followed as is
+	query, err = buildQuery(stream, &api.RequestData{
+		Pager:      &api.Pager{Page: 2},
+		CustomData: "https://example.com/api/v2/items?cursor=abc&per_page=100",
+	}, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, query)
+	args := buildCollectorArgs(api.RawDataSubTaskArgs{}, nil, stream, nil)
+	tpl := template.Must(template.New("url").Parse(args.UrlTemplate))
+	var buf bytes.Buffer
+	assert.Nil(t, tpl.Execute(&buf, &api.RequestData{CustomData: "https://example.com/api/v2/items?cursor=abc&per_page=100"}))
+	assert.Equal(t, "https://example.com/api/v2/items?cursor=abc&per_page=100", buf.String())
+	buf.Reset()
+	assert.Nil(t, tpl.Execute(&buf, &api.RequestData{}))
+	assert.Equal(t, "items", buf.String())
+}
+
+func TestNextLink(t *testing.T) {
+	header := `<https://example.com/items?page=1>; rel="prev", <https://example.com/items?page=3>; rel="next", <https://example.com/items?page=9>; rel="last"`
+	assert.Equal(t, "https://example.com/items?page=3", nextLink(header))
+	assert.Equal(t, "https://example.com/items?page=2", nextLink(`<https://example.com/items?page=2>; rel="next last"`))
+	assert.Equal(t, "", nextLink(`<https://example.com/items?page=1>; rel="prev"`))
+	assert.Equal(t, "", nextLink(""))
+}
+
+func TestCheckSameOrigin(t *testing.T) {
+	endpoint := "https://example.com/api/v2/"
+	assert.Nil(t, checkSameOrigin(endpoint, "https://example.com/api/v2/items?page=2"))
+	assert.Nil(t, checkSameOrigin(endpoint, "HTTPS://Example.com/other/items?page=2"))
+	assert.Nil(t, checkSameOrigin(endpoint, "/api/v2/items?page=2"))
+	assert.Nil(t, checkSameOrigin(endpoint, "items?page=2"))
+	assert.NotNil(t, checkSameOrigin(endpoint, "https://attacker.example.org/items?page=2"))
+	assert.NotNil(t, checkSameOrigin(endpoint, "http://example.com/api/v2/items?page=2"))
+	assert.NotNil(t, checkSameOrigin(endpoint, "https://example.com:8443/api/v2/items?page=2"))
+	assert.NotNil(t, checkSameOrigin(endpoint, "//attacker.example.org/items?page=2"))
+	assert.NotNil(t, checkSameOrigin(endpoint, "http://169.254.169.254/latest/meta-data"))
+}
+
+func TestFilterUpdatedSince(t *testing.T) {
+	since := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	records := []json.RawMessage{
+		json.RawMessage(`{"id":3,"updated":"2024-01-03T00:00:00Z"}`),
+		json.RawMessage(`{"id":2,"updated":"2024-01-02T12:00:00Z"}`),
+		json.RawMessage(`{"id":1,"updated":"2024-01-01T00:00:00Z"}`),
+	}
+
+	stream := parseStream(t, `{name: items, path: items, idField: $.id, target: issues, incremental: {field: $.updated}}`)
+	filtered, err := filterUpdatedSince(stream, records, &since)
+	assert.Equal(t, api.ErrFinishCollect, err)
+	assert.Equal(t, records[:2], filtered)
+	filtered, err = filterUpdatedSince(stream, records, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, records, filtered)
+
+	// filtered by the api
+	stream = parseStream(t, `{name: items, path: items, idField: $.id, target: issues, incremental: {field: $.updated, param: since}}`)
+	filtered, err = filterUpdatedSince(stream, records, &since)
+	assert.Nil(t, err)
+	assert.Equal(t, records, filtered)
+}
@@ -0,0 +1,157 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/restapi/models"
+	"github.com/mitchellh/mapstructure"
+)
+
+var _ plugin.SubTaskEntryPoint = ConvertRecords
+
+var ConvertRecordsMeta = plugin.SubTaskMeta{
+	Name:             "convertRecords",
+	EntryPoint:       ConvertRecords,
+	EnabledByDefault: true,
+	Description:      "Convert tool layer table _tool_restapi_records into the target domain layer tables",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_TICKET, plugin.DOMAIN_TYPE_CICD, plugin.DOMAIN_TYPE_CODE},
+}
+
+func ConvertRecords(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*RestapiTaskData)
+	for _, stream := range data.Streams {
+		if err := convertStream(taskCtx, stream); err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("failed to convert stream %s", stream.Name))
+		}
+	}
+	return nil
+}
+
+func convertStream(taskCtx plugin.SubTaskContext, stream *models.RestapiStream) errors.Error {
+	rawDataSubTaskArgs, data := CreateRawDataSubTaskArgs(taskCtx, stream)
+	db := taskCtx.GetDal()
+	cursor, err := db.Cursor(
+		dal.From(&models.RestapiRecord{}),
+		dal.Where(
+			"connection_id = ? AND scope_id = ? AND stream = ?",
+			data.Options.ConnectionId, data.Options.ScopeId, stream.Name,
+		),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	converter, err := api.NewDataConverter(api.DataConverterArgs{
+		RawDataSubTaskArgs: *rawDataSubTaskArgs,
+		InputRowType:       reflect.TypeOf(models.RestapiRecord{}),
+		Input:              cursor,
+		Convert: func(inputRow interface{}) ([]interface{}, errors.Error) {
+			return convertRecord(stream, inputRow.(*models.RestapiRecord))
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return converter.Execute()
+}
+
+// convertRecord decodes the mapped fields into the target domain entity and links it to the scope
+func convertRecord(stream *models.RestapiStream, record *models.RestapiRecord) ([]interface{}, errors.Error) {
+	fields := make(map[string]interface{}, len(record.Fields))
+	for field, value := range record.Fields {
+		if refStream, ok := stream.Refs[field]; ok && value != nil {
+			value = getRecordIdGen().Generate(record.ConnectionId, record.ScopeId, refStream, fmt.Sprint(value))
+		}
+		fields[field] = value
+	}
+	entity := models.RestapiTargets[stream.Target].New()
+	if err := decodeFields(fields, entity); err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to decode record %s", record.RecordId))
+	}
+	id := getRecordIdGen().Generate(record.ConnectionId, record.ScopeId, stream.Name, record.RecordId)
+	scopeId := getScopeIdGen().Generate(record.ConnectionId, record.ScopeId)
+	results := []interface{}{entity}
+	switch e := entity.(type) {
+	case *ticket.Issue:
+		e.Id = id
+		results = append(results, &ticket.BoardIssue{BoardId: scopeId, IssueId: id})
+	case *ticket.Incident:
+		e.Id = id
+		e.ScopeId = scopeId
+		e.Table = ticket.Board{}.TableName()
+	case *devops.CICDPipeline:
+		e.Id = id
+		e.CicdScopeId = scopeId
+	case *devops.CICDTask:
+		e.Id = id
+		e.CicdScopeId = scopeId
+	case *code.PullRequest:
+		e.Id = id
+		if e.BaseRepoId == "" {
+			e.BaseRepoId = scopeId
+		}
+		if e.HeadRepoId == "" {
+			e.HeadRepoId = scopeId
+		}
+	}
+	return results, nil
+}
+
+// decodeHookTime converts strings and epoch numbers into time.Time
+func decodeHookTime(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+	if t != reflect.TypeOf(time.Time{}) && t != reflect.TypeOf(&time.Time{}) {
+		return data, nil
+	}
+	if f.Kind() != reflect.String && f.Kind() != reflect.Float64 {
+		return data, nil
+	}
+	parsed, err := models.ParseTime(data)
+	if err != nil {
+		return nil, err
+	}
+	if t.Kind() == reflect.Ptr {
+		return parsed, nil
+	}
+	return *parsed, nil
+}
+
+// decodeFields decodes fields into the entity, fields of embedded structs like TaskDatesInfo are accepted as well
+func decodeFields(fields map[string]interface{}, entity interface{}) errors.Error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       decodeHookTime,
+		Result:           entity,
+		Squash:           true,
+		WeaklyTypedInput: true,
+	})
+	if err != nil {
+		return errors.Convert(err)
+	}
+	return errors.Convert(decoder.Decode(fields))
+}
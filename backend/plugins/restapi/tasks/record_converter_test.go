@@ -0,0 +1,118 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/restapi/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// restapiMeta registers the plugin for domain id generators
+type restapiMeta struct{}
+
+func (restapiMeta) Name() string        { return "restapi" }
+func (restapiMeta) Description() string { return "" }
+func (restapiMeta) RootPkgPath() string { return "github.com/apache/incubator-devlake/plugins/restapi" }
+
+func TestConvertRecord(t *testing.T) {
+	assert.Nil(t, plugin.RegisterPlugin("restapi", restapiMeta{}))
+	spec, err := models.ParseSpec(`
+streams:
+  - name: pipelines
+    path: pipelines
+    idField: $.id
+    target: cicd_pipelines
+    mappings: {name: $.name, durationSec: $.duration, createdDate: $.created_at, finishedDate: $.finished_at}
+  - name: jobs
+    path: jobs
+    idField: $.id
+    target: cicd_tasks
+    mappings: {name: $.name, pipelineId: $.pipeline_id}
+    refs: {pipelineId: pipelines}
+  - name: tickets
+    path: tickets
+    idField: $.id
+    target: issues
+    mappings: {title: $.title, originalStatus: $.state}
+`)
+	if !assert.Nil(t, err) {
+		return
+	}
+	scopeId := getScopeIdGen().Generate(uint64(1), "proj")
+
+	pipelines := spec.Streams[0]
+	record, err := extractRecord(pipelines, []byte(`{"id":7,"name":"build","duration":"12.5","created_at":"2024-01-02T03:04:05Z","finished_at":1704164705000}`))
+	if !assert.Nil(t, err) {
+		return
+	}
+	record.ConnectionId = 1
+	record.ScopeId = "proj"
+	results, err := convertRecord(pipelines, record)
+	assert.Nil(t, err)
+	if assert.Len(t, results, 1) {
+		pipeline := results[0].(*devops.CICDPipeline)
+		assert.Equal(t, "restapi:RestapiRecord:1:proj:pipelines:7", pipeline.Id)
+		assert.Equal(t, scopeId, pipeline.CicdScopeId)
+		assert.Equal(t, "build", pipeline.Name)
+		assert.Equal(t, 12.5, pipeline.DurationSec)
+		assert.True(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Equal(pipeline.CreatedDate))
+		if assert.NotNil(t, pipeline.FinishedDate) {
+			assert.True(t, time.Date(2024, 1, 2, 3, 5, 5, 0, time.UTC).Equal(*pipeline.FinishedDate))
+		}
+	}
+
+	jobs := spec.Streams[1]
+	results, err = convertRecord(jobs, &models.RestapiRecord{
+		ConnectionId: 1,
+		ScopeId:      "proj",
+		Stream:       "jobs",
+		RecordId:     "8",
+		Fields:       map[string]interface{}{"name": "test", "pipelineId": float64(7)},
+	})
+	assert.Nil(t, err)
+	if assert.Len(t, results, 1) {
+		task := results[0].(*devops.CICDTask)
+		assert.Equal(t, "restapi:RestapiRecord:1:proj:jobs:8", task.Id)
+		assert.Equal(t, "restapi:RestapiRecord:1:proj:pipelines:7", task.PipelineId)
+	}
+
+	tickets := spec.Streams[2]
+	results, err = convertRecord(tickets, &models.RestapiRecord{
+		ConnectionId: 1,
+		ScopeId:      "proj",
+		Stream:       "tickets",
+		RecordId:     "9",
+		Fields:       map[string]interface{}{"title": "broken", "originalStatus": "open"},
+	})
+	assert.Nil(t, err)
+	if assert.Len(t, results, 2) {
+		issue := results[0].(*ticket.Issue)
+		assert.Equal(t, "broken", issue.Title)
+		assert.Equal(t, "open", issue.OriginalStatus)
+		assert.Equal(t, &ticket.BoardIssue{BoardId: scopeId, IssueId: issue.Id}, results[1])
+	}
+
+	_, err = extractRecord(tickets, []byte(`{"title":"no id"}`))
+	assert.NotNil(t, err)
+}
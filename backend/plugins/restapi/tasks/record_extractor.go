@@ -0,0 +1,79 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"fmt"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/restapi/models"
+)
+
+var _ plugin.SubTaskEntryPoint = ExtractRecords
+
+var ExtractRecordsMeta = plugin.SubTaskMeta{
+	Name:             "extractRecords",
+	EntryPoint:       ExtractRecords,
+	EnabledByDefault: true,
+	Description:      "Extract mapped fields of raw records into tool layer table _tool_restapi_records",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_TICKET, plugin.DOMAIN_TYPE_CICD, plugin.DOMAIN_TYPE_CODE},
+}
+
+func ExtractRecords(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*RestapiTaskData)
+	for _, stream := range data.Streams {
+		if err := extractStream(taskCtx, stream); err != nil {
+			return errors.Default.Wrap(err, fmt.Sprintf("failed to extract stream %s", stream.Name))
+		}
+	}
+	return nil
+}
+
+func extractStream(taskCtx plugin.SubTaskContext, stream *models.RestapiStream) errors.Error {
+	rawDataSubTaskArgs, data := CreateRawDataSubTaskArgs(taskCtx, stream)
+	extractor, err := api.NewApiExtractor(api.ApiExtractorArgs{
+		RawDataSubTaskArgs: *rawDataSubTaskArgs,
+		Extract: func(row *api.RawData) ([]interface{}, errors.Error) {
+			record, err := extractRecord(stream, row.Data)
+			if err != nil {
+				return nil, err
+			}
+			record.ConnectionId = data.Options.ConnectionId
+			record.ScopeId = data.Options.ScopeId
+			return []interface{}{record}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return extractor.Execute()
+}
+
+func extractRecord(stream *models.RestapiStream, data []byte) (*models.RestapiRecord, errors.Error) {
+	recordId := stream.RecordId(data)
+	if recordId == "" {
+		return nil, errors.Default.New(fmt.Sprintf("%s not found in record %s", stream.IdField, string(data)))
+	}
+	return &models.RestapiRecord{
+		Stream:   stream.Name,
+		RecordId: recordId,
+		Fields:   stream.ExtractFields(data),
+	}, nil
+}
@@ -0,0 +1,98 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/restapi/models"
+)
+
+var _ plugin.SubTaskEntryPoint = ConvertScope
+
+var ConvertScopeMeta = plugin.SubTaskMeta{
+	Name:             "convertScope",
+	EntryPoint:       ConvertScope,
+	EnabledByDefault: true,
+	Description:      "Convert the scope into boards, cicd_scopes and repos for the domain types of the streams",
+	DomainTypes:      []string{plugin.DOMAIN_TYPE_TICKET, plugin.DOMAIN_TYPE_CICD, plugin.DOMAIN_TYPE_CODE},
+}
+
+func ConvertScope(taskCtx plugin.SubTaskContext) errors.Error {
+	data := taskCtx.GetData().(*RestapiTaskData)
+	db := taskCtx.GetDal()
+	cursor, err := db.Cursor(
+		dal.From(&models.RestapiScope{}),
+		dal.Where("connection_id = ? AND id = ?", data.Options.ConnectionId, data.Options.ScopeId),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	converter, err := api.NewDataConverter(api.DataConverterArgs{
+		RawDataSubTaskArgs: api.RawDataSubTaskArgs{
+			Ctx: taskCtx,
+			Params: models.RestapiApiParams{
+				ConnectionId: data.Options.ConnectionId,
+				ScopeId:      data.Options.ScopeId,
+			},
+			Table: RAW_SCOPE_TABLE,
+		},
+		InputRowType: reflect.TypeOf(models.RestapiScope{}),
+		Input:        cursor,
+		Convert: func(inputRow interface{}) ([]interface{}, errors.Error) {
+			return convertScope(inputRow.(*models.RestapiScope), data.Streams), nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return converter.Execute()
+}
+
+// convertScope makes a domain scope for every domain type the streams produce, so the converted records
+// are linked to an existing board, cicd_scope or repo
+func convertScope(scope *models.RestapiScope, streams []*models.RestapiStream) []interface{} {
+	id := getScopeIdGen().Generate(scope.ConnectionId, scope.Id)
+	converted := make(map[string]bool)
+	var results []interface{}
+	for _, stream := range streams {
+		domainType := stream.DomainType()
+		if converted[domainType] {
+			continue
+		}
+		converted[domainType] = true
+		switch domainType {
+		case plugin.DOMAIN_TYPE_TICKET:
+			results = append(results, ticket.NewBoard(id, scope.Name))
+		case plugin.DOMAIN_TYPE_CICD:
+			results = append(results, devops.NewCicdScope(id, scope.Name))
+		case plugin.DOMAIN_TYPE_CODE:
+			results = append(results, code.NewRepo(id, scope.Name))
+		}
+	}
+	return results
+}
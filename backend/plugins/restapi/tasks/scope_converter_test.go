@@ -0,0 +1,59 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/plugins/restapi/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertScope(t *testing.T) {
+	assert.Nil(t, plugin.RegisterPlugin("restapi", restapiMeta{}))
+	spec, err := models.ParseSpec(`
+streams:
+  - {name: pipelines, path: pipelines, idField: $.id, target: cicd_pipelines}
+  - {name: jobs, path: jobs, idField: $.id, target: cicd_tasks}
+  - {name: tickets, path: tickets, idField: $.id, target: issues}
+`)
+	if !assert.Nil(t, err) {
+		return
+	}
+	scope := &models.RestapiScope{Id: "svc-1", Name: "Service 1"}
+	scope.ConnectionId = 1
+
+	results := convertScope(scope, spec.Streams)
+	if !assert.Len(t, results, 2) {
+		return
+	}
+	cicdScope := results[0].(*devops.CicdScope)
+	assert.Equal(t, "restapi:RestapiScope:1:svc-1", cicdScope.Id)
+	assert.Equal(t, "Service 1", cicdScope.Name)
+	board := results[1].(*ticket.Board)
+	assert.Equal(t, cicdScope.Id, board.Id)
+
+	// only the streams of the selected entities are processed
+	assert.Len(t, convertScope(scope, spec.Streams[2:]), 1)
+
+	_, err = models.ParseSpec("streams:\n  - {name: scopes, path: scopes, idField: $.id, target: issues}")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,60 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"github.com/apache/incubator-devlake/core/models/domainlayer/didgen"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/restapi/models"
+)
+
+// RAW_SCOPE_TABLE is the raw table the domain scopes are attributed to, it is the one of scopes saved by the api
+const RAW_SCOPE_TABLE = "restapi_scopes"
+
+var recordIdGen *didgen.DomainIdGenerator
+var scopeIdGen *didgen.DomainIdGenerator
+
+func getRecordIdGen() *didgen.DomainIdGenerator {
+	if recordIdGen == nil {
+		recordIdGen = didgen.NewDomainIdGenerator(&models.RestapiRecord{})
+	}
+	return recordIdGen
+}
+
+func getScopeIdGen() *didgen.DomainIdGenerator {
+	if scopeIdGen == nil {
+		scopeIdGen = didgen.NewDomainIdGenerator(&models.RestapiScope{})
+	}
+	return scopeIdGen
+}
+
+// CreateRawDataSubTaskArgs creates the args for the stream, each stream has its own raw table
+func CreateRawDataSubTaskArgs(taskCtx plugin.SubTaskContext, stream *models.RestapiStream) (*api.RawDataSubTaskArgs, *RestapiTaskData) {
+	data := taskCtx.GetData().(*RestapiTaskData)
+	params := models.RestapiApiParams{
+		ConnectionId: data.Options.ConnectionId,
+		ScopeId:      data.Options.ScopeId,
+	}
+	rawDataSubTaskArgs := &api.RawDataSubTaskArgs{
+		Ctx:    taskCtx,
+		Params: params,
+		Table:  stream.RawTable(),
+	}
+	return rawDataSubTaskArgs, data
+}
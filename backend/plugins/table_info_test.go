@@ -45,7 +45,9 @@ import (
 	org "github.com/apache/incubator-devlake/plugins/org/impl"
 	pagerduty "github.com/apache/incubator-devlake/plugins/pagerduty/impl"
 	q_dev "github.com/apache/incubator-devlake/plugins/q_dev/impl"
+	qaTrace "github.com/apache/incubator-devlake/plugins/qa_trace/impl"
 	refdiff "github.com/apache/incubator-devlake/plugins/refdiff/impl"
+	restapi "github.com/apache/incubator-devlake/plugins/restapi/impl"
 	slack "github.com/apache/incubator-devlake/plugins/slack/impl"
 	sonarqube "github.com/apache/incubator-devlake/plugins/sonarqube/impl"
 	starrocks "github.com/apache/incubator-devlake/plugins/starrocks/impl"
@@ -78,7 +80,7 @@ func Test_GetPluginTablesInfo(t *testing.T) {
 	checker.FeedIn("icla/models", icla.Icla{}.GetTablesInfo)
 	checker.FeedIn("jenkins/models", jenkins.Jenkins{}.GetTablesInfo)
 	checker.FeedIn("jira/models", jira.Jira{}.GetTablesInfo)
-	checker.FeedIn("org", org.Org{}.GetTablesInfo)
+	checker.FeedIn("org/models", org.Org{}.GetTablesInfo)
 	checker.FeedIn("pagerduty/models", pagerduty.PagerDuty{}.GetTablesInfo)
 	checker.FeedIn("refdiff/models", refdiff.RefDiff{}.GetTablesInfo)
 	checker.FeedIn("slack/models", slack.Slack{}.GetTablesInfo)
@@ -94,6 +96,8 @@ func Test_GetPluginTablesInfo(t *testing.T) {
 	checker.FeedIn("linker/models", linker.Linker{}.GetTablesInfo)
 	checker.FeedIn("issue_trace/models", issueTrace.IssueTrace{}.GetTablesInfo)
 	checker.FeedIn("q_dev/models", q_dev.QDev{}.GetTablesInfo)
+	checker.FeedIn("qa_trace/models", qaTrace.QaTrace{}.GetTablesInfo)
+	checker.FeedIn("restapi/models", restapi.Restapi{}.GetTablesInfo)
 	err := checker.Verify()
 	if err != nil {
 		t.Error(err)
@@ -0,0 +1,334 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/log"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/dbhelper"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/webhook/models"
+)
+
+const (
+	maxBatchItems           = 1000
+	maxIdempotencyKeyLen    = 255
+	idempotencyKeyField     = "idempotencyKey"
+	idempotencyKeyHeader    = "Idempotency-Key"
+	batchItemsField         = "items"
+	recordTypeIssue         = "issue"
+	recordTypeDeployment    = "deployment"
+	recordTypePullRequest   = "pull_request"
+	recordTypeIncident      = "incident"
+	recordTypePipeline      = "cicd_pipeline"
+	recordTypeSprint        = "sprint"
+	batchItemStatusCreated  = "CREATED"
+	batchItemStatusSkipped  = "SKIPPED"
+	batchItemStatusInvalid  = "INVALID"
+	batchItemStatusFailed   = "FAILED"
+	batchItemStatusReverted = "ROLLED_BACK"
+)
+
+// WebhookBatchItemResult is the outcome of a single item of a batch request
+type WebhookBatchItemResult struct {
+	Index          int    `json:"index"`
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	Status         string `json:"status"`
+	Error          string `json:"error,omitempty"`
+}
+
+// WebhookBatchResult is the response body of the batch endpoints.
+// The request body is a JSON array, a NDJSON stream (Content-Type: application/x-ndjson) or {"items": [...]},
+// every item has the same shape as the single-record endpoint.
+// An item may carry an "idempotencyKey" property, items whose key was already applied are skipped.
+// A key in the Idempotency-Key header applies to the whole batch.
+// With ?transactional=true all items are saved in one transaction and any failure rolls back the whole batch.
+type WebhookBatchResult struct {
+	Transactional bool                      `json:"transactional"`
+	Total         int                       `json:"total"`
+	Created       int                       `json:"created"`
+	Skipped       int                       `json:"skipped"`
+	Failed        int                       `json:"failed"`
+	Items         []*WebhookBatchItemResult `json:"items"`
+}
+
+func (r *WebhookBatchResult) count() {
+	r.Created, r.Skipped, r.Failed = 0, 0, 0
+	for _, item := range r.Items {
+		switch item.Status {
+		case batchItemStatusCreated:
+			r.Created++
+		case batchItemStatusSkipped:
+			r.Skipped++
+		default:
+			r.Failed++
+		}
+	}
+}
+
+// batchItem is a decoded and validated item waiting to be saved
+type batchItem[R any] struct {
+	result      *WebhookBatchItemResult
+	request     *R
+	payloadHash string
+}
+
+// batchItemSaver writes one decoded item through the same code path as the single-record endpoint
+type batchItemSaver[R any] func(connection *models.WebhookConnection, request *R, tx dal.Transaction, logger log.Logger) errors.Error
+
+// readBatchItems extracts the raw items of a batch request. The body may be a JSON array, a NDJSON stream
+// (one object per line) or a JSON object carrying the array in its `items` property.
+func readBatchItems(input *plugin.ApiResourceInput) ([]map[string]interface{}, errors.Error) {
+	if input.Body != nil {
+		rawItems, ok := input.Body[batchItemsField].([]interface{})
+		if !ok {
+			return nil, errors.BadInput.New("body must be a JSON array, NDJSON or an object with an `items` array")
+		}
+		items := make([]map[string]interface{}, len(rawItems))
+		for i, rawItem := range rawItems {
+			item, ok := rawItem.(map[string]interface{})
+			if !ok {
+				return nil, errors.BadInput.New(fmt.Sprintf("item %d is not a JSON object", i))
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	if input.Request == nil || input.Request.Body == nil {
+		return nil, errors.BadInput.New("empty body")
+	}
+	return decodeBatchItems(input.Request.Body)
+}
+
+// decodeBatchItems decodes either a JSON array of objects or a stream of concatenated/newline delimited objects
+func decodeBatchItems(reader io.Reader) ([]map[string]interface{}, errors.Error) {
+	decoder := json.NewDecoder(reader)
+	var items []map[string]interface{}
+	for i := 0; ; i++ {
+		var value interface{}
+		err := decoder.Decode(&value)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.BadInput.Wrap(err, fmt.Sprintf("failed to decode item %d", len(items)))
+		}
+		switch v := value.(type) {
+		case []interface{}:
+			if i > 0 || decoder.More() {
+				return nil, errors.BadInput.New("a JSON array body must contain exactly one array")
+			}
+			for _, rawItem := range v {
+				item, ok := rawItem.(map[string]interface{})
+				if !ok {
+					return nil, errors.BadInput.New(fmt.Sprintf("item %d is not a JSON object", len(items)))
+				}
+				items = append(items, item)
+			}
+		case map[string]interface{}:
+			items = append(items, v)
+		default:
+			return nil, errors.BadInput.New(fmt.Sprintf("item %d is not a JSON object", len(items)))
+		}
+		if len(items) > maxBatchItems {
+			return nil, errors.BadInput.New(fmt.Sprintf("a batch may contain at most %d items", maxBatchItems))
+		}
+	}
+	return items, nil
+}
+
+// prepareBatchItem pops the idempotency key out of the raw item, then decodes and validates it exactly like
+// the single-record endpoint does. A key sent in the Idempotency-Key header applies to the whole batch and is
+// combined with the item index.
+func prepareBatchItem[R any](index int, rawItem map[string]interface{}, batchKey string) *batchItem[R] {
+	item := &batchItem[R]{result: &WebhookBatchItemResult{Index: index}}
+	if key, ok := rawItem[idempotencyKeyField]; ok {
+		delete(rawItem, idempotencyKeyField)
+		item.result.IdempotencyKey = strings.TrimSpace(fmt.Sprintf("%v", key))
+	} else if batchKey != "" {
+		item.result.IdempotencyKey = fmt.Sprintf("%s:%d", batchKey, index)
+	}
+	if len(item.result.IdempotencyKey) > maxIdempotencyKeyLen {
+		item.result.Status = batchItemStatusInvalid
+		item.result.Error = fmt.Sprintf("idempotency key must not exceed %d characters", maxIdempotencyKeyLen)
+		return item
+	}
+	request := new(R)
+	if err := helper.DecodeMapStruct(rawItem, request, true); err != nil {
+		item.result.Status = batchItemStatusInvalid
+		item.result.Error = err.Error()
+		return item
+	}
+	if err := vld.Struct(request); err != nil {
+		item.result.Status = batchItemStatusInvalid
+		item.result.Error = err.Error()
+		return item
+	}
+	if item.result.IdempotencyKey != "" {
+		// json.Marshal sorts map keys, so the hash does not depend on the property order of the item
+		payload, err := json.Marshal(rawItem)
+		if err != nil {
+			item.result.Status = batchItemStatusInvalid
+			item.result.Error = err.Error()
+			return item
+		}
+		sum := sha256.Sum256(payload)
+		item.payloadHash = hex.EncodeToString(sum[:])
+	}
+	item.request = request
+	return item
+}
+
+// saveBatchItem saves the item into tx unless its idempotency key was applied before, in which case
+// it is reported as skipped. Reusing a key for a different payload is an error.
+func saveBatchItem[R any](connection *models.WebhookConnection, recordType string, item *batchItem[R], save batchItemSaver[R], tx dal.Transaction) errors.Error {
+	key := item.result.IdempotencyKey
+	if key != "" {
+		applied := &models.WebhookIdempotencyKey{}
+		err := tx.First(applied, dal.Where("connection_id = ? AND record_type = ? AND idempotency_key = ?", connection.ID, recordType, key))
+		if err == nil {
+			if applied.PayloadHash != item.payloadHash {
+				return errors.Conflict.New(fmt.Sprintf("idempotency key %s was already used for a different payload", key))
+			}
+			item.result.Status = batchItemStatusSkipped
+			return nil
+		}
+		if !tx.IsErrorNotFound(err) {
+			return err
+		}
+	}
+	if err := save(connection, item.request, tx, logger); err != nil {
+		return err
+	}
+	if key != "" {
+		err := tx.Create(&models.WebhookIdempotencyKey{
+			ConnectionId:   connection.ID,
+			RecordType:     recordType,
+			IdempotencyKey: key,
+			PayloadHash:    item.payloadHash,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	item.result.Status = batchItemStatusCreated
+	return nil
+}
+
+// saveBatchItemInOwnTx saves a single item in a transaction of its own, used by non-transactional batches
+func saveBatchItemInOwnTx[R any](connection *models.WebhookConnection, recordType string, item *batchItem[R], save batchItemSaver[R]) (err errors.Error) {
+	txHelper := dbhelper.NewTxHelper(basicRes, &err)
+	defer txHelper.End()
+	tx := txHelper.Begin()
+	err = saveBatchItem(connection, recordType, item, save, tx)
+	return
+}
+
+// postBatch handles the batch variant of the record endpoints, see WebhookBatchResult for the request body.
+// With `?transactional=true` the batch is all-or-nothing: any invalid or failing item rolls back every item.
+// Otherwise every item is committed on its own and failures are only reported in the per-item results.
+func postBatch[R any](input *plugin.ApiResourceInput, connection *models.WebhookConnection, err errors.Error, recordType string, save batchItemSaver[R]) (*plugin.ApiResourceOutput, errors.Error) {
+	if err != nil {
+		return nil, err
+	}
+	rawItems, err := readBatchItems(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(rawItems) == 0 {
+		return nil, errors.BadInput.New("batch contains no items")
+	}
+	if len(rawItems) > maxBatchItems {
+		return nil, errors.BadInput.New(fmt.Sprintf("a batch may contain at most %d items", maxBatchItems))
+	}
+	batchKey := ""
+	if input.Request != nil {
+		batchKey = strings.TrimSpace(input.Request.Header.Get(idempotencyKeyHeader))
+	}
+	result := &WebhookBatchResult{
+		Transactional: input.Query.Get("transactional") == "true",
+		Total:         len(rawItems),
+		Items:         make([]*WebhookBatchItemResult, len(rawItems)),
+	}
+	items := make([]*batchItem[R], len(rawItems))
+	invalid := 0
+	for i, rawItem := range rawItems {
+		items[i] = prepareBatchItem[R](i, rawItem, batchKey)
+		result.Items[i] = items[i].result
+		if items[i].request == nil {
+			invalid++
+		}
+	}
+
+	if !result.Transactional {
+		for _, item := range items {
+			if item.request == nil {
+				continue
+			}
+			if err := saveBatchItemInOwnTx(connection, recordType, item, save); err != nil {
+				logger.Error(err, "save %s batch item %d", recordType, item.result.Index)
+				item.result.Status = batchItemStatusFailed
+				item.result.Error = err.Error()
+			}
+		}
+		result.count()
+		return &plugin.ApiResourceOutput{Body: result, Status: http.StatusOK}, nil
+	}
+
+	if invalid > 0 {
+		result.count()
+		return &plugin.ApiResourceOutput{Body: result}, errors.BadInput.New(fmt.Sprintf("%d of %d items are invalid, nothing was saved", invalid, len(items)))
+	}
+	saveErr := saveBatchTransactionally(connection, recordType, items, save)
+	result.count()
+	if saveErr != nil {
+		return &plugin.ApiResourceOutput{Body: result}, saveErr
+	}
+	return &plugin.ApiResourceOutput{Body: result, Status: http.StatusOK}, nil
+}
+
+// saveBatchTransactionally saves all items in one transaction, stopping at the first failure
+func saveBatchTransactionally[R any](connection *models.WebhookConnection, recordType string, items []*batchItem[R], save batchItemSaver[R]) (err errors.Error) {
+	txHelper := dbhelper.NewTxHelper(basicRes, &err)
+	defer txHelper.End()
+	tx := txHelper.Begin()
+	for i, item := range items {
+		if err = saveBatchItem(connection, recordType, item, save, tx); err != nil {
+			logger.Error(err, "save %s batch item %d", recordType, item.result.Index)
+			item.result.Status = batchItemStatusFailed
+			item.result.Error = err.Error()
+			for j, other := range items {
+				if j != i && other.result.Status != batchItemStatusSkipped {
+					other.result.Status = batchItemStatusReverted
+				}
+			}
+			return err.GetType().Wrap(err, fmt.Sprintf("item %d failed, the whole batch was rolled back", item.result.Index))
+		}
+	}
+	return nil
+}
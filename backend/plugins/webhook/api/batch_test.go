@@ -0,0 +1,120 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeBatchItems(t *testing.T) {
+	items, err := decodeBatchItems(strings.NewReader(` [{"id": "a"}, {"id": "b"}]`))
+	assert.Nil(t, err)
+	assert.Equal(t, []map[string]interface{}{{"id": "a"}, {"id": "b"}}, items)
+
+	items, err = decodeBatchItems(strings.NewReader("{\"id\": \"a\"}\n\n{\"id\": \"b\"}\n{\"id\": \"c\"}\n"))
+	assert.Nil(t, err)
+	assert.Len(t, items, 3)
+	assert.Equal(t, "c", items[2]["id"])
+
+	_, err = decodeBatchItems(strings.NewReader("{\"id\": \"a\"}\n{\"id\": "))
+	assert.NotNil(t, err)
+	_, err = decodeBatchItems(strings.NewReader(`[{"id": "a"}] [{"id": "b"}]`))
+	assert.NotNil(t, err)
+	_, err = decodeBatchItems(strings.NewReader(`[1, 2]`))
+	assert.NotNil(t, err)
+	_, err = decodeBatchItems(strings.NewReader(strings.Repeat("{}\n", maxBatchItems+1)))
+	assert.NotNil(t, err)
+}
+
+func TestReadBatchItemsFromItemsProperty(t *testing.T) {
+	items, err := readBatchItems(&plugin.ApiResourceInput{Body: map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"id": "a"}},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, []map[string]interface{}{{"id": "a"}}, items)
+
+	_, err = readBatchItems(&plugin.ApiResourceInput{Body: map[string]interface{}{"id": "a"}})
+	assert.NotNil(t, err)
+}
+
+func TestPrepareBatchItem(t *testing.T) {
+	vld = validator.New()
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"id":             "pr1",
+			"displayTitle":   "Add feature",
+			"pullRequestKey": 1,
+			"createdDate":    "2025-02-20T16:17:36Z",
+			"status":         "MERGED",
+		}
+	}
+
+	item := prepareBatchItem[WebhookPullRequestReq](0, valid(), "")
+	assert.Empty(t, item.result.Status)
+	assert.NotNil(t, item.request)
+	assert.Equal(t, 1, item.request.PullRequestKey)
+	assert.Empty(t, item.result.IdempotencyKey)
+	assert.Empty(t, item.payloadHash)
+
+	// the batch key applies to items without their own key
+	item = prepareBatchItem[WebhookPullRequestReq](3, valid(), "backfill-1")
+	assert.Equal(t, "backfill-1:3", item.result.IdempotencyKey)
+	assert.NotEmpty(t, item.payloadHash)
+
+	// the key does not take part in the payload hash
+	raw := valid()
+	raw["idempotencyKey"] = "pr1-v1"
+	keyed := prepareBatchItem[WebhookPullRequestReq](0, raw, "backfill-1")
+	assert.Equal(t, "pr1-v1", keyed.result.IdempotencyKey)
+	assert.Equal(t, item.payloadHash, keyed.payloadHash)
+
+	raw = valid()
+	raw["status"] = "OPEN"
+	changed := prepareBatchItem[WebhookPullRequestReq](3, raw, "backfill-1")
+	assert.NotEqual(t, item.payloadHash, changed.payloadHash)
+
+	raw = valid()
+	delete(raw, "displayTitle")
+	invalid := prepareBatchItem[WebhookPullRequestReq](1, raw, "")
+	assert.Equal(t, batchItemStatusInvalid, invalid.result.Status)
+	assert.Nil(t, invalid.request)
+	assert.Contains(t, invalid.result.Error, "Title")
+
+	raw = valid()
+	raw["idempotencyKey"] = strings.Repeat("k", maxIdempotencyKeyLen+1)
+	invalid = prepareBatchItem[WebhookPullRequestReq](2, raw, "")
+	assert.Equal(t, batchItemStatusInvalid, invalid.result.Status)
+}
+
+func TestBatchResultCount(t *testing.T) {
+	result := &WebhookBatchResult{Items: []*WebhookBatchItemResult{
+		{Status: batchItemStatusCreated},
+		{Status: batchItemStatusSkipped},
+		{Status: batchItemStatusInvalid},
+		{Status: batchItemStatusCreated},
+	}}
+	result.count()
+	assert.Equal(t, 2, result.Created)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, 1, result.Failed)
+}
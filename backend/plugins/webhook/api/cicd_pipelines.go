@@ -0,0 +1,545 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/log"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/dbhelper"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/webhook/models"
+)
+
+type WebhookPipelineReq struct {
+	Id             string     `mapstructure:"id" validate:"required"`
+	Name           string     `mapstructure:"name"`
+	DisplayTitle   string     `mapstructure:"displayTitle"`
+	Url            string     `mapstructure:"url"`
+	Result         string     `mapstructure:"result" validate:"omitempty,oneof=SUCCESS FAILURE"`
+	Status         string     `mapstructure:"status" validate:"omitempty,oneof=IN_PROGRESS DONE OTHER"`
+	OriginalResult string     `mapstructure:"originalResult"`
+	OriginalStatus string     `mapstructure:"originalStatus"`
+	Type           string     `mapstructure:"type" validate:"omitempty,oneof=TEST LINT BUILD DEPLOYMENT"`
+	Environment    string     `mapstructure:"environment" validate:"omitempty,oneof=PRODUCTION STAGING TESTING DEVELOPMENT"`
+	CreatedDate    *time.Time `mapstructure:"createdDate"`
+	QueuedDate     *time.Time `mapstructure:"queuedDate"`
+	StartedDate    *time.Time `mapstructure:"startedDate" validate:"required"`
+	FinishedDate   *time.Time `mapstructure:"finishedDate"`
+	// Commits are the commits the pipeline ran against
+	Commits []WebhookPipelineCommitReq `mapstructure:"commits" validate:"omitempty,dive"`
+	// Stages group tasks, a stage without tasks is saved as a task of its own
+	Stages []WebhookStageReq `mapstructure:"stages" validate:"omitempty,dive"`
+	// Tasks are the tasks which do not belong to any stage
+	Tasks []WebhookTaskReq `mapstructure:"tasks" validate:"omitempty,dive"`
+}
+
+type WebhookPipelineCommitReq struct {
+	CommitSha    string `mapstructure:"commitSha" validate:"required"`
+	CommitMsg    string `mapstructure:"commitMsg"`
+	DisplayTitle string `mapstructure:"displayTitle"`
+	Url          string `mapstructure:"url"`
+	Branch       string `mapstructure:"branch"`
+	RepoId       string `mapstructure:"repoId"`
+	RepoUrl      string `mapstructure:"repoUrl" validate:"required"`
+}
+
+type WebhookStageReq struct {
+	Name           string           `mapstructure:"name" validate:"required"`
+	Result         string           `mapstructure:"result" validate:"omitempty,oneof=SUCCESS FAILURE"`
+	Status         string           `mapstructure:"status" validate:"omitempty,oneof=IN_PROGRESS DONE OTHER"`
+	OriginalResult string           `mapstructure:"originalResult"`
+	OriginalStatus string           `mapstructure:"originalStatus"`
+	Type           string           `mapstructure:"type" validate:"omitempty,oneof=TEST LINT BUILD DEPLOYMENT"`
+	Environment    string           `mapstructure:"environment" validate:"omitempty,oneof=PRODUCTION STAGING TESTING DEVELOPMENT"`
+	QueuedDate     *time.Time       `mapstructure:"queuedDate"`
+	StartedDate    *time.Time       `mapstructure:"startedDate"`
+	FinishedDate   *time.Time       `mapstructure:"finishedDate"`
+	Tasks          []WebhookTaskReq `mapstructure:"tasks" validate:"omitempty,dive"`
+}
+
+type WebhookTaskReq struct {
+	// PipelineId is only used by the cicd_tasks endpoint, tasks nested in a pipeline belong to it
+	PipelineId     string     `mapstructure:"pipelineId"`
+	Id             string     `mapstructure:"id"`
+	Name           string     `mapstructure:"name" validate:"required"`
+	Result         string     `mapstructure:"result" validate:"omitempty,oneof=SUCCESS FAILURE"`
+	Status         string     `mapstructure:"status" validate:"omitempty,oneof=IN_PROGRESS DONE OTHER"`
+	OriginalResult string     `mapstructure:"originalResult"`
+	OriginalStatus string     `mapstructure:"originalStatus"`
+	Type           string     `mapstructure:"type" validate:"omitempty,oneof=TEST LINT BUILD DEPLOYMENT"`
+	Environment    string     `mapstructure:"environment" validate:"omitempty,oneof=PRODUCTION STAGING TESTING DEVELOPMENT"`
+	QueuedDate     *time.Time `mapstructure:"queuedDate"`
+	StartedDate    *time.Time `mapstructure:"startedDate"`
+	FinishedDate   *time.Time `mapstructure:"finishedDate"`
+}
+
+// PostCicdPipelines
+// @Summary create a cicd pipeline with its tasks by webhook
+// @Description Create or update a whole cicd pipeline, its commits and its tasks, optionally grouped into stages.<br/>
+// @Description example: {"id":"build-1024","name":"build","result":"SUCCESS","startedDate":"2020-01-01T12:00:00+00:00","finishedDate":"2020-01-01T12:10:00+00:00","commits":[{"commitSha":"015e3d3b480e417aede5a1293bd61de9b0fd051d","repoUrl":"https://github.com/apache/incubator-devlake","branch":"main"}],"stages":[{"name":"test","tasks":[{"name":"unit","result":"SUCCESS","startedDate":"2020-01-01T12:00:00+00:00","finishedDate":"2020-01-01T12:05:00+00:00"}]},{"name":"deploy","type":"DEPLOYMENT","environment":"PRODUCTION","result":"SUCCESS","startedDate":"2020-01-01T12:05:00+00:00","finishedDate":"2020-01-01T12:10:00+00:00"}]}<br/>
+// @Description Tasks of a stage are named "<stage>/<task>". A pipeline is DONE when finishedDate is set, its result defaults to FAILURE if any task failed.
+// @Tags plugins/webhook
+// @Param body body WebhookPipelineReq true "json body"
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/:connectionId/cicd_pipelines [POST]
+func PostCicdPipelines(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.First(connection, input.Params)
+	return postCicdPipelines(input, connection, err)
+}
+
+// PostCicdPipelinesByName
+// @Summary create a cicd pipeline with its tasks by webhook name
+// @Description Create or update a whole cicd pipeline, its commits and its tasks, optionally grouped into stages.<br/>
+// @Description Tasks of a stage are named "<stage>/<task>". A pipeline is DONE when finishedDate is set, its result defaults to FAILURE if any task failed.
+// @Tags plugins/webhook
+// @Param body body WebhookPipelineReq true "json body"
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/by-name/:connectionName/cicd_pipelines [POST]
+func PostCicdPipelinesByName(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.FirstByName(connection, input.Params)
+	return postCicdPipelines(input, connection, err)
+}
+
+// PostCicdPipelinesBatch
+// @Summary create cicd pipelines by webhook in batch
+// @Description Create up to 1000 cicd pipelines in one call, the body format, idempotency keys and ?transactional=true are described in WebhookBatchResult.
+// @Tags plugins/webhook
+// @Param body body []WebhookPipelineReq true "json array"
+// @Param transactional query bool false "all-or-nothing"
+// @Success 200  {object} WebhookBatchResult
+// @Failure 400  {object} WebhookBatchResult "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/:connectionId/cicd_pipelines/batch [POST]
+func PostCicdPipelinesBatch(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.First(connection, input.Params)
+	return postBatch(input, connection, err, recordTypePipeline, CreateCicdPipeline)
+}
+
+// PostCicdPipelinesBatchByName
+// @Summary create cicd pipelines by webhook name in batch
+// @Description Create up to 1000 cicd pipelines in one call, the body format, idempotency keys and ?transactional=true are described in WebhookBatchResult.
+// @Tags plugins/webhook
+// @Param body body []WebhookPipelineReq true "json array"
+// @Param transactional query bool false "all-or-nothing"
+// @Success 200  {object} WebhookBatchResult
+// @Failure 400  {object} WebhookBatchResult "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/by-name/:connectionName/cicd_pipelines/batch [POST]
+func PostCicdPipelinesBatchByName(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.FirstByName(connection, input.Params)
+	return postBatch(input, connection, err, recordTypePipeline, CreateCicdPipeline)
+}
+
+func postCicdPipelines(input *plugin.ApiResourceInput, connection *models.WebhookConnection, err errors.Error) (*plugin.ApiResourceOutput, errors.Error) {
+	if err != nil {
+		return nil, err
+	}
+	// get request
+	request := &WebhookPipelineReq{}
+	err = helper.DecodeMapStruct(input.Body, request, true)
+	if err != nil {
+		return &plugin.ApiResourceOutput{Body: err.Error(), Status: http.StatusBadRequest}, nil
+	}
+	// validate
+	err = errors.Convert(vld.Struct(request))
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, `input json error`)
+	}
+	txHelper := dbhelper.NewTxHelper(basicRes, &err)
+	defer txHelper.End()
+	tx := txHelper.Begin()
+	if err = CreateCicdPipeline(connection, request, tx, logger); err != nil {
+		logger.Error(err, "create cicd pipeline")
+		return nil, err
+	}
+
+	return &plugin.ApiResourceOutput{Body: nil, Status: http.StatusOK}, nil
+}
+
+// CreateCicdPipeline saves the pipeline, its commits and tasks under the cicd scope of the connection.
+// Tasks are replaced, so the payload always describes the full pipeline.
+func CreateCicdPipeline(connection *models.WebhookConnection, request *WebhookPipelineReq, tx dal.Transaction, logger log.Logger) errors.Error {
+	if request == nil {
+		return errors.BadInput.New("request body is nil")
+	}
+	pipelineId := generatePipelineId(connection.ID, request.Id)
+	scopeId := fmt.Sprintf("%s:%d", "webhook", connection.ID)
+
+	tasks := make([]*devops.CICDTask, 0, len(request.Tasks))
+	for i := range request.Tasks {
+		tasks = append(tasks, toCicdTask(connection.ID, request.Id, "", &request.Tasks[i], scopeId))
+	}
+	for _, stage := range request.Stages {
+		if len(stage.Tasks) == 0 {
+			tasks = append(tasks, toCicdTask(connection.ID, request.Id, "", &WebhookTaskReq{
+				Name:           stage.Name,
+				Result:         stage.Result,
+				Status:         stage.Status,
+				OriginalResult: stage.OriginalResult,
+				OriginalStatus: stage.OriginalStatus,
+				Type:           stage.Type,
+				Environment:    stage.Environment,
+				QueuedDate:     stage.QueuedDate,
+				StartedDate:    stage.StartedDate,
+				FinishedDate:   stage.FinishedDate,
+			}, scopeId))
+			continue
+		}
+		for i := range stage.Tasks {
+			task := stage.Tasks[i]
+			if task.Type == "" {
+				task.Type = stage.Type
+			}
+			if task.Environment == "" {
+				task.Environment = stage.Environment
+			}
+			tasks = append(tasks, toCicdTask(connection.ID, request.Id, stage.Name, &task, scopeId))
+		}
+	}
+
+	pipeline := &devops.CICDPipeline{
+		DomainEntity: domainlayer.DomainEntity{
+			Id: pipelineId,
+		},
+		Name:           request.Name,
+		DisplayTitle:   request.DisplayTitle,
+		Url:            request.Url,
+		Result:         request.Result,
+		Status:         request.Status,
+		OriginalResult: request.OriginalResult,
+		OriginalStatus: request.OriginalStatus,
+		Type:           request.Type,
+		Environment:    request.Environment,
+		TaskDatesInfo: devops.TaskDatesInfo{
+			QueuedDate:   request.QueuedDate,
+			StartedDate:  request.StartedDate,
+			FinishedDate: request.FinishedDate,
+		},
+		CicdScopeId: scopeId,
+	}
+	if pipeline.Name == "" {
+		pipeline.Name = request.Id
+	}
+	if request.CreatedDate != nil {
+		pipeline.CreatedDate = *request.CreatedDate
+	} else {
+		pipeline.CreatedDate = *request.StartedDate
+	}
+	finishPipeline(pipeline, tasks)
+
+	if err := tx.CreateOrUpdate(pipeline); err != nil {
+		logger.Error(err, "failed to save cicd pipeline")
+		return err
+	}
+	if len(request.Commits) > 0 {
+		pipelineCommits := make([]*devops.CiCDPipelineCommit, len(request.Commits))
+		for i, commit := range request.Commits {
+			pipelineCommits[i] = &devops.CiCDPipelineCommit{
+				PipelineId:   pipelineId,
+				CommitSha:    commit.CommitSha,
+				CommitMsg:    commit.CommitMsg,
+				DisplayTitle: commit.DisplayTitle,
+				Url:          commit.Url,
+				Branch:       commit.Branch,
+				RepoId:       commit.RepoId,
+				RepoUrl:      commit.RepoUrl,
+			}
+		}
+		if err := tx.CreateOrUpdate(pipelineCommits); err != nil {
+			logger.Error(err, "failed to save cicd pipeline commits")
+			return err
+		}
+	}
+	if err := tx.Delete(&devops.CICDTask{}, dal.Where("pipeline_id = ?", pipelineId)); err != nil {
+		logger.Error(err, "failed to delete previous cicd tasks")
+		return err
+	}
+	if len(tasks) > 0 {
+		if err := tx.CreateOrUpdate(tasks); err != nil {
+			logger.Error(err, "failed to save cicd tasks")
+			return err
+		}
+	}
+	return nil
+}
+
+// PostCicdTasks
+// @Summary create a cicd task by webhook
+// @Description Create or update a single task of the pipeline given by "pipelineId", the pipeline is created IN_PROGRESS if it does not exist yet.<br/>
+// @Description example: {"pipelineId":"build-1024","name":"unit","type":"TEST","result":"SUCCESS","startedDate":"2020-01-01T12:00:00+00:00","finishedDate":"2020-01-01T12:05:00+00:00"}<br/>
+// @Description Call cicd_pipeline/:pipelineName/finish once all tasks are reported.
+// @Tags plugins/webhook
+// @Param body body WebhookTaskReq true "json body"
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/:connectionId/cicd_tasks [POST]
+func PostCicdTasks(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.First(connection, input.Params)
+	return postCicdTasks(input, connection, err)
+}
+
+// PostCicdTasksByName
+// @Summary create a cicd task by webhook name
+// @Description Create or update a single task of the pipeline given by "pipelineId", the pipeline is created IN_PROGRESS if it does not exist yet.<br/>
+// @Description Call cicd_pipeline/:pipelineName/finish once all tasks are reported.
+// @Tags plugins/webhook
+// @Param body body WebhookTaskReq true "json body"
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/by-name/:connectionName/cicd_tasks [POST]
+func PostCicdTasksByName(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.FirstByName(connection, input.Params)
+	return postCicdTasks(input, connection, err)
+}
+
+func postCicdTasks(input *plugin.ApiResourceInput, connection *models.WebhookConnection, err errors.Error) (*plugin.ApiResourceOutput, errors.Error) {
+	if err != nil {
+		return nil, err
+	}
+	// get request
+	request := &WebhookTaskReq{}
+	err = helper.DecodeMapStruct(input.Body, request, true)
+	if err != nil {
+		return &plugin.ApiResourceOutput{Body: err.Error(), Status: http.StatusBadRequest}, nil
+	}
+	// validate
+	err = errors.Convert(vld.Struct(request))
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, `input json error`)
+	}
+	if request.PipelineId == "" {
+		return nil, errors.BadInput.New("pipelineId is required")
+	}
+	txHelper := dbhelper.NewTxHelper(basicRes, &err)
+	defer txHelper.End()
+	tx := txHelper.Begin()
+	if err = CreateCicdTask(connection, request, tx, logger); err != nil {
+		logger.Error(err, "create cicd task")
+		return nil, err
+	}
+
+	return &plugin.ApiResourceOutput{Body: nil, Status: http.StatusOK}, nil
+}
+
+// CreateCicdTask saves a single task, creating its pipeline in progress when it was not reported yet
+func CreateCicdTask(connection *models.WebhookConnection, request *WebhookTaskReq, tx dal.Transaction, logger log.Logger) errors.Error {
+	scopeId := fmt.Sprintf("%s:%d", "webhook", connection.ID)
+	pipelineId := generatePipelineId(connection.ID, request.PipelineId)
+	task := toCicdTask(connection.ID, request.PipelineId, "", request, scopeId)
+
+	count, err := tx.Count(dal.From(&devops.CICDPipeline{}), dal.Where("id = ?", pipelineId))
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		pipeline := &devops.CICDPipeline{
+			DomainEntity: domainlayer.DomainEntity{
+				Id: pipelineId,
+			},
+			Name:   request.PipelineId,
+			Status: devops.STATUS_IN_PROGRESS,
+			TaskDatesInfo: devops.TaskDatesInfo{
+				CreatedDate: task.CreatedDate,
+				StartedDate: task.StartedDate,
+			},
+			CicdScopeId: scopeId,
+		}
+		if err := tx.Create(pipeline); err != nil {
+			logger.Error(err, "failed to save cicd pipeline")
+			return err
+		}
+	}
+	if err := tx.CreateOrUpdate(task); err != nil {
+		logger.Error(err, "failed to save cicd task")
+		return err
+	}
+	return nil
+}
+
+// FinishCicdPipeline
+// @Summary set a cicd pipeline to DONE
+// @Description Set the pipeline reported with id :pipelineName to DONE, its result is FAILURE if any of its tasks failed and SUCCESS otherwise
+// @Tags plugins/webhook
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 404  {string} errcode.Error "Not Found"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/:connectionId/cicd_pipeline/:pipelineName/finish [POST]
+func FinishCicdPipeline(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.First(connection, input.Params)
+	return finishCicdPipeline(input, connection, err)
+}
+
+// FinishCicdPipelineByName
+// @Summary set a cicd pipeline to DONE
+// @Description Set the pipeline reported with id :pipelineName to DONE, its result is FAILURE if any of its tasks failed and SUCCESS otherwise
+// @Tags plugins/webhook
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 404  {string} errcode.Error "Not Found"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/by-name/:connectionName/cicd_pipeline/:pipelineName/finish [POST]
+func FinishCicdPipelineByName(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.FirstByName(connection, input.Params)
+	return finishCicdPipeline(input, connection, err)
+}
+
+func finishCicdPipeline(input *plugin.ApiResourceInput, connection *models.WebhookConnection, err errors.Error) (*plugin.ApiResourceOutput, errors.Error) {
+	if err != nil {
+		return nil, err
+	}
+	txHelper := dbhelper.NewTxHelper(basicRes, &err)
+	defer txHelper.End()
+	tx := txHelper.Begin()
+
+	pipeline := &devops.CICDPipeline{}
+	err = tx.First(pipeline, dal.Where("id = ?", generatePipelineId(connection.ID, input.Params["pipelineName"])))
+	if err != nil {
+		if tx.IsErrorNotFound(err) {
+			return nil, errors.NotFound.Wrap(err, `pipeline not found`)
+		}
+		return nil, err
+	}
+	var tasks []*devops.CICDTask
+	err = tx.All(&tasks, dal.Where("pipeline_id = ?", pipeline.Id))
+	if err != nil {
+		return nil, err
+	}
+	if pipeline.FinishedDate == nil {
+		now := time.Now()
+		pipeline.FinishedDate = &now
+	}
+	pipeline.Status = devops.STATUS_DONE
+	pipeline.Result = ""
+	finishPipeline(pipeline, tasks)
+	err = tx.Update(pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plugin.ApiResourceOutput{Body: nil, Status: http.StatusOK}, nil
+}
+
+func generatePipelineId(connectionId uint64, pipelineId string) string {
+	return fmt.Sprintf("%s:%d:%s", "webhook", connectionId, pipelineId)
+}
+
+func toCicdTask(connectionId uint64, pipelineId string, stage string, request *WebhookTaskReq, scopeId string) *devops.CICDTask {
+	name := request.Name
+	if stage != "" {
+		name = fmt.Sprintf("%s/%s", stage, request.Name)
+	}
+	taskKey := request.Id
+	if taskKey == "" {
+		taskKey = name
+	}
+	task := &devops.CICDTask{
+		DomainEntity: domainlayer.DomainEntity{
+			Id: fmt.Sprintf("%s:%s", generatePipelineId(connectionId, pipelineId), taskKey),
+		},
+		Name:           name,
+		PipelineId:     generatePipelineId(connectionId, pipelineId),
+		Result:         request.Result,
+		Status:         request.Status,
+		OriginalResult: request.OriginalResult,
+		OriginalStatus: request.OriginalStatus,
+		Type:           request.Type,
+		Environment:    request.Environment,
+		TaskDatesInfo: devops.TaskDatesInfo{
+			QueuedDate:   request.QueuedDate,
+			StartedDate:  request.StartedDate,
+			FinishedDate: request.FinishedDate,
+		},
+		CicdScopeId: scopeId,
+	}
+	if task.Status == "" {
+		if task.FinishedDate != nil {
+			task.Status = devops.STATUS_DONE
+		} else {
+			task.Status = devops.STATUS_IN_PROGRESS
+		}
+	}
+	if task.Status == devops.STATUS_DONE && task.Result == "" {
+		task.Result = devops.RESULT_SUCCESS
+	}
+	if task.Environment == "" && task.Type == devops.DEPLOYMENT {
+		task.Environment = devops.PRODUCTION
+	}
+	switch {
+	case task.QueuedDate != nil:
+		task.CreatedDate = *task.QueuedDate
+	case task.StartedDate != nil:
+		task.CreatedDate = *task.StartedDate
+	default:
+		task.CreatedDate = time.Now()
+	}
+	if task.StartedDate != nil && task.FinishedDate != nil {
+		task.DurationSec = float64(task.FinishedDate.Sub(*task.StartedDate).Milliseconds() / 1e3)
+	}
+	task.QueuedDurationSec = task.CalculateQueueDuration()
+	return task
+}
+
+// finishPipeline fills the status, result and durations of the pipeline which were not given explicitly
+func finishPipeline(pipeline *devops.CICDPipeline, tasks []*devops.CICDTask) {
+	if pipeline.Status == "" {
+		if pipeline.FinishedDate != nil {
+			pipeline.Status = devops.STATUS_DONE
+		} else {
+			pipeline.Status = devops.STATUS_IN_PROGRESS
+		}
+	}
+	if pipeline.Status == devops.STATUS_DONE && pipeline.Result == "" {
+		pipeline.Result = devops.RESULT_SUCCESS
+		for _, task := range tasks {
+			if task.Result == devops.RESULT_FAILURE {
+				pipeline.Result = devops.RESULT_FAILURE
+				break
+			}
+		}
+	}
+	if pipeline.Environment == "" && pipeline.Type == devops.DEPLOYMENT {
+		pipeline.Environment = devops.PRODUCTION
+	}
+	if pipeline.StartedDate != nil && pipeline.FinishedDate != nil {
+		pipeline.DurationSec = float64(pipeline.FinishedDate.Sub(*pipeline.StartedDate).Milliseconds() / 1e3)
+	}
+	pipeline.QueuedDurationSec = pipeline.CalculateQueueDuration()
+}
@@ -0,0 +1,75 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToCicdTask(t *testing.T) {
+	started := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	finished := started.Add(5 * time.Minute)
+
+	task := toCicdTask(1, "build-1", "test", &WebhookTaskReq{
+		Name:         "unit",
+		StartedDate:  &started,
+		FinishedDate: &finished,
+	}, "webhook:1")
+	assert.Equal(t, "webhook:1:build-1:test/unit", task.Id)
+	assert.Equal(t, "webhook:1:build-1", task.PipelineId)
+	assert.Equal(t, "test/unit", task.Name)
+	assert.Equal(t, devops.STATUS_DONE, task.Status)
+	assert.Equal(t, devops.RESULT_SUCCESS, task.Result)
+	assert.Equal(t, started, task.CreatedDate)
+	assert.Equal(t, float64(300), task.DurationSec)
+
+	task = toCicdTask(1, "build-1", "", &WebhookTaskReq{
+		Id:          "42",
+		Name:        "deploy",
+		Type:        devops.DEPLOYMENT,
+		StartedDate: &started,
+	}, "webhook:1")
+	assert.Equal(t, "webhook:1:build-1:42", task.Id)
+	assert.Equal(t, devops.STATUS_IN_PROGRESS, task.Status)
+	assert.Empty(t, task.Result)
+	assert.Equal(t, devops.PRODUCTION, task.Environment)
+}
+
+func TestFinishPipeline(t *testing.T) {
+	started := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	finished := started.Add(10 * time.Minute)
+
+	pipeline := &devops.CICDPipeline{TaskDatesInfo: devops.TaskDatesInfo{StartedDate: &started}}
+	finishPipeline(pipeline, nil)
+	assert.Equal(t, devops.STATUS_IN_PROGRESS, pipeline.Status)
+	assert.Empty(t, pipeline.Result)
+
+	pipeline = &devops.CICDPipeline{TaskDatesInfo: devops.TaskDatesInfo{StartedDate: &started, FinishedDate: &finished}}
+	finishPipeline(pipeline, []*devops.CICDTask{{Result: devops.RESULT_SUCCESS}, {Result: devops.RESULT_FAILURE}})
+	assert.Equal(t, devops.STATUS_DONE, pipeline.Status)
+	assert.Equal(t, devops.RESULT_FAILURE, pipeline.Result)
+	assert.Equal(t, float64(600), pipeline.DurationSec)
+
+	pipeline = &devops.CICDPipeline{Result: devops.RESULT_SUCCESS, TaskDatesInfo: devops.TaskDatesInfo{StartedDate: &started, FinishedDate: &finished}}
+	finishPipeline(pipeline, []*devops.CICDTask{{Result: devops.RESULT_FAILURE}})
+	assert.Equal(t, devops.RESULT_SUCCESS, pipeline.Result)
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/apache/incubator-devlake/core/errors"
 	coreModels "github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/utils"
 	"github.com/apache/incubator-devlake/plugins/webhook/models"
 )
 
@@ -42,6 +43,9 @@ import (
 func PostConnections(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
 	// update from request and save to database
 	connection := &models.WebhookConnection{}
+	if err := validateVerificationFields(input.Body); err != nil {
+		return nil, err
+	}
 	tx := basicRes.GetDal().Begin()
 	err := connectionHelper.CreateWithTx(tx, connection, input)
 	if err != nil {
@@ -90,11 +94,11 @@ func PostConnections(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput,
 // @Router /plugins/webhook/connections/{connectionId} [PATCH]
 func PatchConnection(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
 	connection := &models.WebhookConnection{}
-	err := connectionHelper.Patch(connection, input)
+	err := connectionHelper.First(connection, input.Params)
 	if err != nil {
 		return nil, err
 	}
-	return &plugin.ApiResourceOutput{Body: connection}, nil
+	return patchConnection(input, connection, connectionHelper.Patch)
 }
 
 // PatchConnectionByName
@@ -108,10 +112,28 @@ func PatchConnection(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput,
 // @Router /plugins/webhook/connections/by-name/{connectionName} [PATCH]
 func PatchConnectionByName(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
 	connection := &models.WebhookConnection{}
-	err := connectionHelper.PatchByName(connection, input)
+	err := connectionHelper.FirstByName(connection, input.Params)
+	if err != nil {
+		return nil, err
+	}
+	return patchConnection(input, connection, connectionHelper.PatchByName)
+}
+
+func patchConnection(
+	input *plugin.ApiResourceInput,
+	connection *models.WebhookConnection,
+	patch func(interface{}, *plugin.ApiResourceInput) errors.Error,
+) (*plugin.ApiResourceOutput, errors.Error) {
+	err := validateVerificationFields(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	keepSigningSecret(input.Body, connection)
+	err = patch(connection, input)
 	if err != nil {
 		return nil, err
 	}
+	connection.SigningSecret = utils.SanitizeString(connection.SigningSecret)
 	return &plugin.ApiResourceOutput{Body: connection}, nil
 }
 
@@ -188,6 +210,9 @@ type WebhookConnectionResponse struct {
 	PostPipelineTaskEndpoint       string             `json:"postPipelineTaskEndpoint"`
 	PostPipelineDeployTaskEndpoint string             `json:"postPipelineDeployTaskEndpoint"`
 	ClosePipelineEndpoint          string             `json:"closePipelineEndpoint"`
+	PostPipelinesEndpoint          string             `json:"postPipelinesEndpoint"`
+	PostIncidentsEndpoint          string             `json:"postIncidentsEndpoint"`
+	PostSprintsEndpoint            string             `json:"postSprintsEndpoint"`
 	ApiKey                         *coreModels.ApiKey `json:"apiKey,omitempty"`
 }
 
@@ -255,12 +280,16 @@ func getConnection(err errors.Error, connection *models.WebhookConnection) (*plu
 
 func formatConnection(connection *models.WebhookConnection, withApiKeyInfo bool) (*WebhookConnectionResponse, errors.Error) {
 	response := &WebhookConnectionResponse{WebhookConnection: *connection}
+	response.SigningSecret = utils.SanitizeString(connection.SigningSecret)
 	response.PostIssuesEndpoint = fmt.Sprintf(`/rest/plugins/webhook/connections/%d/issues`, connection.ID)
 	response.CloseIssuesEndpoint = fmt.Sprintf(`/rest/plugins/webhook/connections/%d/issue/:issueKey/close`, connection.ID)
 	response.PostPullRequestsEndpoint = fmt.Sprintf(`/rest/plugins/webhook/connections/%d/pull_requests`, connection.ID)
 	response.PostPipelineTaskEndpoint = fmt.Sprintf(`/rest/plugins/webhook/connections/%d/cicd_tasks`, connection.ID)
 	response.PostPipelineDeployTaskEndpoint = fmt.Sprintf(`/rest/plugins/webhook/connections/%d/deployments`, connection.ID)
 	response.ClosePipelineEndpoint = fmt.Sprintf(`/rest/plugins/webhook/connections/%d/cicd_pipeline/:pipelineName/finish`, connection.ID)
+	response.PostPipelinesEndpoint = fmt.Sprintf(`/rest/plugins/webhook/connections/%d/cicd_pipelines`, connection.ID)
+	response.PostIncidentsEndpoint = fmt.Sprintf(`/rest/plugins/webhook/connections/%d/incidents`, connection.ID)
+	response.PostSprintsEndpoint = fmt.Sprintf(`/rest/plugins/webhook/connections/%d/sprints`, connection.ID)
 	if withApiKeyInfo {
 		db := basicRes.GetDal()
 		apiKeyName := apiKeyHelper.GenApiKeyNameForPlugin(pluginName, connection.ID)
@@ -108,6 +108,38 @@ func PostDeploymentsByName(input *plugin.ApiResourceInput) (*plugin.ApiResourceO
 	return postDeployments(input, connection, err)
 }
 
+// PostDeploymentsBatch
+// @Summary create deployments by webhook in batch
+// @Description Create up to 1000 deployments in one call, the body format, idempotency keys and ?transactional=true are described in WebhookBatchResult.
+// @Tags plugins/webhook
+// @Param body body []WebhookDeploymentReq true "json array"
+// @Param transactional query bool false "all-or-nothing"
+// @Success 200  {object} WebhookBatchResult
+// @Failure 400  {object} WebhookBatchResult "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/:connectionId/deployments/batch [POST]
+func PostDeploymentsBatch(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.First(connection, input.Params)
+	return postBatch(input, connection, err, recordTypeDeployment, CreateDeploymentAndDeploymentCommits)
+}
+
+// PostDeploymentsBatchByName
+// @Summary create deployments by webhook name in batch
+// @Description Create up to 1000 deployments in one call, the body format, idempotency keys and ?transactional=true are described in WebhookBatchResult.
+// @Tags plugins/webhook
+// @Param body body []WebhookDeploymentReq true "json array"
+// @Param transactional query bool false "all-or-nothing"
+// @Success 200  {object} WebhookBatchResult
+// @Failure 400  {object} WebhookBatchResult "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/by-name/:connectionName/deployments/batch [POST]
+func PostDeploymentsBatchByName(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.FirstByName(connection, input.Params)
+	return postBatch(input, connection, err, recordTypeDeployment, CreateDeploymentAndDeploymentCommits)
+}
+
 func postDeployments(input *plugin.ApiResourceInput, connection *models.WebhookConnection, err errors.Error) (*plugin.ApiResourceOutput, errors.Error) {
 	if err != nil {
 		return nil, err
@@ -0,0 +1,221 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/log"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/dbhelper"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/webhook/models"
+)
+
+type WebhookIncidentReq struct {
+	IncidentKey             string     `mapstructure:"incidentKey" validate:"required"`
+	Url                     string     `mapstructure:"url"`
+	Title                   string     `mapstructure:"title" validate:"required"`
+	Description             string     `mapstructure:"description"`
+	Status                  string     `mapstructure:"status" validate:"oneof=TODO DONE IN_PROGRESS"`
+	OriginalStatus          string     `mapstructure:"originalStatus"`
+	ResolutionDate          *time.Time `mapstructure:"resolutionDate"`
+	CreatedDate             *time.Time `mapstructure:"createdDate" validate:"required"`
+	UpdatedDate             *time.Time `mapstructure:"updatedDate"`
+	LeadTimeMinutes         uint       `mapstructure:"leadTimeMinutes"`
+	OriginalEstimateMinutes int64      `mapstructure:"originalEstimateMinutes"`
+	TimeSpentMinutes        int64      `mapstructure:"timeSpentMinutes"`
+	TimeRemainingMinutes    int64      `mapstructure:"timeRemainingMinutes"`
+	ParentIncidentKey       string     `mapstructure:"parentIncidentKey"`
+	Priority                string     `mapstructure:"priority"`
+	Severity                string     `mapstructure:"severity"`
+	Urgency                 string     `mapstructure:"urgency"`
+	Component               string     `mapstructure:"component"`
+	OriginalProject         string     `mapstructure:"originalProject"`
+	CreatorId               string     `mapstructure:"creatorId"`
+	CreatorName             string     `mapstructure:"creatorName"`
+	AssigneeId              string     `mapstructure:"assigneeId"`
+	AssigneeName            string     `mapstructure:"assigneeName"`
+}
+
+// PostIncidents
+// @Summary create incidents by webhook
+// @Description Create or update an incident which does not come from an issue, e.g. one reported by an alerting bot.<br/>
+// @Description example: {"incidentKey":"INC-42","title":"checkout is down","status":"DONE","originalStatus":"resolved","createdDate":"2020-01-01T12:00:00+00:00","resolutionDate":"2020-01-01T12:30:00+00:00","severity":"SEV1","assigneeId":"user1","assigneeName":"Nick name 1"}<br/>
+// @Description The incident belongs to the board of the connection, so it is mapped to the projects the board is in.
+// @Tags plugins/webhook
+// @Param body body WebhookIncidentReq true "json body"
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/:connectionId/incidents [POST]
+func PostIncidents(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.First(connection, input.Params)
+	return postIncidents(input, connection, err)
+}
+
+// PostIncidentsByName
+// @Summary create incidents by webhook name
+// @Description Create or update an incident which does not come from an issue, e.g. one reported by an alerting bot.<br/>
+// @Description example: {"incidentKey":"INC-42","title":"checkout is down","status":"DONE","originalStatus":"resolved","createdDate":"2020-01-01T12:00:00+00:00","resolutionDate":"2020-01-01T12:30:00+00:00","severity":"SEV1","assigneeId":"user1","assigneeName":"Nick name 1"}<br/>
+// @Description The incident belongs to the board of the connection, so it is mapped to the projects the board is in.
+// @Tags plugins/webhook
+// @Param body body WebhookIncidentReq true "json body"
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/by-name/:connectionName/incidents [POST]
+func PostIncidentsByName(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.FirstByName(connection, input.Params)
+	return postIncidents(input, connection, err)
+}
+
+// PostIncidentsBatch
+// @Summary create incidents by webhook in batch
+// @Description Create up to 1000 incidents in one call, the body format, idempotency keys and ?transactional=true are described in WebhookBatchResult.
+// @Tags plugins/webhook
+// @Param body body []WebhookIncidentReq true "json array"
+// @Param transactional query bool false "all-or-nothing"
+// @Success 200  {object} WebhookBatchResult
+// @Failure 400  {object} WebhookBatchResult "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/:connectionId/incidents/batch [POST]
+func PostIncidentsBatch(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.First(connection, input.Params)
+	return postBatch(input, connection, err, recordTypeIncident, CreateIncident)
+}
+
+// PostIncidentsBatchByName
+// @Summary create incidents by webhook name in batch
+// @Description Create up to 1000 incidents in one call, the body format, idempotency keys and ?transactional=true are described in WebhookBatchResult.
+// @Tags plugins/webhook
+// @Param body body []WebhookIncidentReq true "json array"
+// @Param transactional query bool false "all-or-nothing"
+// @Success 200  {object} WebhookBatchResult
+// @Failure 400  {object} WebhookBatchResult "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/by-name/:connectionName/incidents/batch [POST]
+func PostIncidentsBatchByName(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.FirstByName(connection, input.Params)
+	return postBatch(input, connection, err, recordTypeIncident, CreateIncident)
+}
+
+func postIncidents(input *plugin.ApiResourceInput, connection *models.WebhookConnection, err errors.Error) (*plugin.ApiResourceOutput, errors.Error) {
+	if err != nil {
+		return nil, err
+	}
+	// get request
+	request := &WebhookIncidentReq{}
+	err = helper.DecodeMapStruct(input.Body, request, true)
+	if err != nil {
+		return &plugin.ApiResourceOutput{Body: err.Error(), Status: http.StatusBadRequest}, nil
+	}
+	// validate
+	err = errors.Convert(vld.Struct(request))
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, `input json error`)
+	}
+	txHelper := dbhelper.NewTxHelper(basicRes, &err)
+	defer txHelper.End()
+	tx := txHelper.Begin()
+	if err = CreateIncident(connection, request, tx, logger); err != nil {
+		logger.Error(err, "create incident")
+		return nil, err
+	}
+
+	return &plugin.ApiResourceOutput{Body: nil, Status: http.StatusOK}, nil
+}
+
+// CreateIncident saves the incident and its assignee under the board of the connection, the same scope
+// incidents derived from webhook issues live in
+func CreateIncident(connection *models.WebhookConnection, request *WebhookIncidentReq, tx dal.Transaction, logger log.Logger) errors.Error {
+	if request == nil {
+		return errors.BadInput.New("request body is nil")
+	}
+	domainBoardId := fmt.Sprintf("%s:%d", "webhook", connection.ID)
+	incident := &ticket.Incident{
+		DomainEntity: domainlayer.DomainEntity{
+			Id: fmt.Sprintf("%s:%d:%s", "webhook", connection.ID, request.IncidentKey),
+		},
+		Url:                     request.Url,
+		IncidentKey:             request.IncidentKey,
+		Title:                   request.Title,
+		Description:             request.Description,
+		Status:                  request.Status,
+		OriginalStatus:          request.OriginalStatus,
+		ResolutionDate:          request.ResolutionDate,
+		CreatedDate:             request.CreatedDate,
+		UpdatedDate:             request.UpdatedDate,
+		LeadTimeMinutes:         &request.LeadTimeMinutes,
+		OriginalEstimateMinutes: &request.OriginalEstimateMinutes,
+		TimeSpentMinutes:        &request.TimeSpentMinutes,
+		TimeRemainingMinutes:    &request.TimeRemainingMinutes,
+		CreatorName:             request.CreatorName,
+		Priority:                request.Priority,
+		Severity:                request.Severity,
+		Urgency:                 request.Urgency,
+		Component:               request.Component,
+		OriginalProject:         request.OriginalProject,
+		ScopeId:                 domainBoardId,
+		Table:                   ticket.Board{}.TableName(),
+		AssigneeName:            request.AssigneeName,
+	}
+	if *incident.LeadTimeMinutes == 0 && incident.ResolutionDate != nil && incident.CreatedDate != nil {
+		temp := uint(incident.ResolutionDate.Sub(*incident.CreatedDate).Minutes())
+		incident.LeadTimeMinutes = &temp
+	}
+	if request.CreatorId != "" {
+		incident.CreatorId = fmt.Sprintf("%s:%d:%s", "webhook", connection.ID, request.CreatorId)
+	}
+	if request.AssigneeId != "" {
+		incident.AssigneeId = fmt.Sprintf("%s:%d:%s", "webhook", connection.ID, request.AssigneeId)
+	}
+	if request.ParentIncidentKey != "" {
+		incident.ParentIncidentId = fmt.Sprintf("%s:%d:%s", "webhook", connection.ID, request.ParentIncidentKey)
+	}
+
+	if err := ensureBoard(tx, domainBoardId); err != nil {
+		return err
+	}
+	if err := tx.CreateOrUpdate(incident); err != nil {
+		logger.Error(err, "failed to save incident")
+		return err
+	}
+	if incident.AssigneeId != "" {
+		assignee := &ticket.IncidentAssignee{
+			IncidentId:   incident.Id,
+			AssigneeId:   incident.AssigneeId,
+			AssigneeName: incident.AssigneeName,
+		}
+		if err := tx.CreateOrUpdate(assignee); err != nil {
+			logger.Error(err, "failed to save incident assignee")
+			return err
+		}
+	}
+	return nil
+}
@@ -82,6 +82,26 @@ func saveIncidentRelatedRecordsFromIssue(db dal.Transaction, logger log.Logger,
 	return nil
 }
 
+// ensureBoard creates the board of the connection unless it exists
+func ensureBoard(tx dal.Transaction, domainBoardId string) errors.Error {
+	// check if board exists
+	count, err := tx.Count(dal.From(&ticket.Board{}), dal.Where("id = ?", domainBoardId))
+	if err != nil {
+		return err
+	}
+
+	// only create board with domainBoard non-existent
+	if count == 0 {
+		domainBoard := &ticket.Board{
+			DomainEntity: domainlayer.DomainEntity{
+				Id: domainBoardId,
+			},
+		}
+		return tx.Create(domainBoard)
+	}
+	return nil
+}
+
 // PostIssue
 // @Summary receive a record as defined and save it
 // @Description receive a record as follow and save it, example: {"url":"","issue_key":"DLK-1234","title":"a feature from DLK","description":"","epic_key":"","type":"BUG","status":"TODO","original_status":"created","story_point":0,"resolution_date":null,"created_date":"2020-01-01T12:00:00+00:00","updated_date":null,"lead_time_minutes":0,"parent_issue_key":"DLK-1200","priority":"","original_estimate_minutes":0,"time_spent_minutes":0,"time_remaining_minutes":0,"creator_id":"user1131","creator_name":"Nick name 1","assignee_id":"user1132","assignee_name":"Nick name 2","severity":"","component":""}
@@ -112,6 +132,38 @@ func PostIssueByName(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput,
 	return postIssue(input, err, connection)
 }
 
+// PostIssuesBatch
+// @Summary create issues by webhook in batch
+// @Description Create up to 1000 issues in one call, the body format, idempotency keys and ?transactional=true are described in WebhookBatchResult.
+// @Tags plugins/webhook
+// @Param body body []WebhookIssueRequest true "json array"
+// @Param transactional query bool false "all-or-nothing"
+// @Success 200  {object} WebhookBatchResult
+// @Failure 400  {object} WebhookBatchResult "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/:connectionId/issues/batch [POST]
+func PostIssuesBatch(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.First(connection, input.Params)
+	return postBatch(input, connection, err, recordTypeIssue, CreateIssue)
+}
+
+// PostIssuesBatchByName
+// @Summary create issues by webhook name in batch
+// @Description Create up to 1000 issues in one call, the body format, idempotency keys and ?transactional=true are described in WebhookBatchResult.
+// @Tags plugins/webhook
+// @Param body body []WebhookIssueRequest true "json array"
+// @Param transactional query bool false "all-or-nothing"
+// @Success 200  {object} WebhookBatchResult
+// @Failure 400  {object} WebhookBatchResult "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/by-name/:connectionName/issues/batch [POST]
+func PostIssuesBatchByName(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.FirstByName(connection, input.Params)
+	return postBatch(input, connection, err, recordTypeIssue, CreateIssue)
+}
+
 func postIssue(input *plugin.ApiResourceInput, err errors.Error, connection *models.WebhookConnection) (*plugin.ApiResourceOutput, errors.Error) {
 	if err != nil {
 		return nil, err
@@ -131,6 +183,18 @@ func postIssue(input *plugin.ApiResourceInput, err errors.Error, connection *mod
 	txHelper := dbhelper.NewTxHelper(basicRes, &err)
 	defer txHelper.End()
 	tx := txHelper.Begin()
+	if err = CreateIssue(connection, request, tx, logger); err != nil {
+		return nil, err
+	}
+
+	return &plugin.ApiResourceOutput{Body: nil, Status: http.StatusOK}, nil
+}
+
+// CreateIssue saves the issue described by request, together with its board and incident records, into tx
+func CreateIssue(connection *models.WebhookConnection, request *WebhookIssueRequest, tx dal.Transaction, logger log.Logger) errors.Error {
+	if request == nil {
+		return errors.BadInput.New("request body is nil")
+	}
 	domainIssue := &ticket.Issue{
 		DomainEntity: domainlayer.DomainEntity{
 			Id: fmt.Sprintf("%s:%d:%s", "webhook", connection.ID, request.IssueKey),
@@ -181,43 +245,29 @@ func postIssue(input *plugin.ApiResourceInput, err errors.Error, connection *mod
 		IssueId: domainIssue.Id,
 	}
 
-	// check if board exists
-	count, err := tx.Count(dal.From(&ticket.Board{}), dal.Where("id = ?", domainBoardId))
+	err := ensureBoard(tx, domainBoardId)
 	if err != nil {
-		return nil, err
-	}
-
-	// only create board with domainBoard non-existent
-	if count == 0 {
-		domainBoard := &ticket.Board{
-			DomainEntity: domainlayer.DomainEntity{
-				Id: domainBoardId,
-			},
-		}
-		err = tx.Create(domainBoard)
-		if err != nil {
-			return nil, err
-		}
+		return err
 	}
 
 	// save
 	err = tx.CreateOrUpdate(domainIssue)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	err = tx.CreateOrUpdate(boardIssue)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if domainIssue.IsIncident() {
 		if err := saveIncidentRelatedRecordsFromIssue(tx, logger, domainBoardId, domainIssue); err != nil {
 			logger.Error(err, "failed to save incident related records")
-			return nil, errors.Convert(err)
+			return errors.Convert(err)
 		}
 	}
 
-	return &plugin.ApiResourceOutput{Body: nil, Status: http.StatusOK}, nil
+	return nil
 }
 
 // CloseIssue
@@ -104,6 +104,38 @@ func PostPullRequestsByName(input *plugin.ApiResourceInput) (*plugin.ApiResource
 	return postPullRequests(input, connection, err)
 }
 
+// PostPullRequestsBatch
+// @Summary create pull requests by webhook in batch
+// @Description Create up to 1000 pull requests in one call, the body format, idempotency keys and ?transactional=true are described in WebhookBatchResult.
+// @Tags plugins/webhook
+// @Param body body []WebhookPullRequestReq true "json array"
+// @Param transactional query bool false "all-or-nothing"
+// @Success 200  {object} WebhookBatchResult
+// @Failure 400  {object} WebhookBatchResult "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/:connectionId/pull_requests/batch [POST]
+func PostPullRequestsBatch(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.First(connection, input.Params)
+	return postBatch(input, connection, err, recordTypePullRequest, CreatePullRequest)
+}
+
+// PostPullRequestsBatchByName
+// @Summary create pull requests by webhook name in batch
+// @Description Create up to 1000 pull requests in one call, the body format, idempotency keys and ?transactional=true are described in WebhookBatchResult.
+// @Tags plugins/webhook
+// @Param body body []WebhookPullRequestReq true "json array"
+// @Param transactional query bool false "all-or-nothing"
+// @Success 200  {object} WebhookBatchResult
+// @Failure 400  {object} WebhookBatchResult "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/by-name/:connectionName/pull_requests/batch [POST]
+func PostPullRequestsBatchByName(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.FirstByName(connection, input.Params)
+	return postBatch(input, connection, err, recordTypePullRequest, CreatePullRequest)
+}
+
 func postPullRequests(input *plugin.ApiResourceInput, connection *models.WebhookConnection, err errors.Error) (*plugin.ApiResourceOutput, errors.Error) {
 	if err != nil {
 		return nil, err
@@ -0,0 +1,195 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/log"
+	"github.com/apache/incubator-devlake/core/models/domainlayer"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/helpers/dbhelper"
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/webhook/models"
+)
+
+type WebhookSprintReq struct {
+	Id            string     `mapstructure:"id" validate:"required"`
+	Name          string     `mapstructure:"name" validate:"required"`
+	Url           string     `mapstructure:"url"`
+	Status        string     `mapstructure:"status" validate:"omitempty,oneof=ACTIVE CLOSED FUTURE"`
+	StartedDate   *time.Time `mapstructure:"startedDate"`
+	EndedDate     *time.Time `mapstructure:"endedDate"`
+	CompletedDate *time.Time `mapstructure:"completedDate"`
+	// IssueKeys replaces the issues of the sprint when present, the issues are the ones posted to the issues endpoint
+	IssueKeys []string `mapstructure:"issueKeys"`
+}
+
+// PostSprints
+// @Summary create sprints by webhook
+// @Description Create or update a sprint of the connection's board together with its issues.<br/>
+// @Description example: {"id":"2020-W01","name":"Sprint 1","status":"CLOSED","startedDate":"2020-01-01T00:00:00+00:00","endedDate":"2020-01-14T00:00:00+00:00","completedDate":"2020-01-14T10:00:00+00:00","issueKeys":["DLK-1234","DLK-1235"]}<br/>
+// @Description "issueKeys" replaces the sprint_issues of the sprint, omit it to keep them.
+// @Tags plugins/webhook
+// @Param body body WebhookSprintReq true "json body"
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/:connectionId/sprints [POST]
+func PostSprints(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.First(connection, input.Params)
+	return postSprints(input, connection, err)
+}
+
+// PostSprintsByName
+// @Summary create sprints by webhook name
+// @Description Create or update a sprint of the connection's board together with its issues.<br/>
+// @Description "issueKeys" replaces the sprint_issues of the sprint, omit it to keep them.
+// @Tags plugins/webhook
+// @Param body body WebhookSprintReq true "json body"
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/by-name/:connectionName/sprints [POST]
+func PostSprintsByName(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.FirstByName(connection, input.Params)
+	return postSprints(input, connection, err)
+}
+
+// PostSprintsBatch
+// @Summary create sprints by webhook in batch
+// @Description Create up to 1000 sprints in one call, the body format, idempotency keys and ?transactional=true are described in WebhookBatchResult.
+// @Tags plugins/webhook
+// @Param body body []WebhookSprintReq true "json array"
+// @Param transactional query bool false "all-or-nothing"
+// @Success 200  {object} WebhookBatchResult
+// @Failure 400  {object} WebhookBatchResult "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/:connectionId/sprints/batch [POST]
+func PostSprintsBatch(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.First(connection, input.Params)
+	return postBatch(input, connection, err, recordTypeSprint, CreateSprint)
+}
+
+// PostSprintsBatchByName
+// @Summary create sprints by webhook name in batch
+// @Description Create up to 1000 sprints in one call, the body format, idempotency keys and ?transactional=true are described in WebhookBatchResult.
+// @Tags plugins/webhook
+// @Param body body []WebhookSprintReq true "json array"
+// @Param transactional query bool false "all-or-nothing"
+// @Success 200  {object} WebhookBatchResult
+// @Failure 400  {object} WebhookBatchResult "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /plugins/webhook/connections/by-name/:connectionName/sprints/batch [POST]
+func PostSprintsBatchByName(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+	connection := &models.WebhookConnection{}
+	err := connectionHelper.FirstByName(connection, input.Params)
+	return postBatch(input, connection, err, recordTypeSprint, CreateSprint)
+}
+
+func postSprints(input *plugin.ApiResourceInput, connection *models.WebhookConnection, err errors.Error) (*plugin.ApiResourceOutput, errors.Error) {
+	if err != nil {
+		return nil, err
+	}
+	// get request
+	request := &WebhookSprintReq{}
+	err = helper.DecodeMapStruct(input.Body, request, true)
+	if err != nil {
+		return &plugin.ApiResourceOutput{Body: err.Error(), Status: http.StatusBadRequest}, nil
+	}
+	// validate
+	err = errors.Convert(vld.Struct(request))
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, `input json error`)
+	}
+	txHelper := dbhelper.NewTxHelper(basicRes, &err)
+	defer txHelper.End()
+	tx := txHelper.Begin()
+	if err = CreateSprint(connection, request, tx, logger); err != nil {
+		logger.Error(err, "create sprint")
+		return nil, err
+	}
+
+	return &plugin.ApiResourceOutput{Body: nil, Status: http.StatusOK}, nil
+}
+
+// CreateSprint saves the sprint under the board of the connection and, when IssueKeys is given, replaces its issues
+func CreateSprint(connection *models.WebhookConnection, request *WebhookSprintReq, tx dal.Transaction, logger log.Logger) errors.Error {
+	if request == nil {
+		return errors.BadInput.New("request body is nil")
+	}
+	domainBoardId := fmt.Sprintf("%s:%d", "webhook", connection.ID)
+	sprint := &ticket.Sprint{
+		DomainEntity: domainlayer.DomainEntity{
+			Id: fmt.Sprintf("%s:%d:%s", "webhook", connection.ID, request.Id),
+		},
+		Name:            request.Name,
+		Url:             request.Url,
+		Status:          request.Status,
+		StartedDate:     request.StartedDate,
+		EndedDate:       request.EndedDate,
+		CompletedDate:   request.CompletedDate,
+		OriginalBoardID: domainBoardId,
+	}
+	if err := ensureBoard(tx, domainBoardId); err != nil {
+		return err
+	}
+	if err := tx.CreateOrUpdate(sprint); err != nil {
+		logger.Error(err, "failed to save sprint")
+		return err
+	}
+	if err := tx.CreateOrUpdate(&ticket.BoardSprint{BoardId: domainBoardId, SprintId: sprint.Id}); err != nil {
+		logger.Error(err, "failed to save board sprint")
+		return err
+	}
+	if request.IssueKeys == nil {
+		return nil
+	}
+	if err := tx.Delete(&ticket.SprintIssue{}, dal.Where("sprint_id = ?", sprint.Id)); err != nil {
+		logger.Error(err, "failed to delete previous sprint issues")
+		return err
+	}
+	sprintIssues := make([]*ticket.SprintIssue, 0, len(request.IssueKeys))
+	seen := make(map[string]bool, len(request.IssueKeys))
+	for _, issueKey := range request.IssueKeys {
+		if issueKey == "" || seen[issueKey] {
+			continue
+		}
+		seen[issueKey] = true
+		sprintIssues = append(sprintIssues, &ticket.SprintIssue{
+			SprintId: sprint.Id,
+			IssueId:  fmt.Sprintf("%s:%d:%s", "webhook", connection.ID, issueKey),
+		})
+	}
+	if len(sprintIssues) == 0 {
+		return nil
+	}
+	if err := tx.CreateOrUpdate(sprintIssues); err != nil {
+		logger.Error(err, "failed to save sprint issues")
+		return err
+	}
+	return nil
+}
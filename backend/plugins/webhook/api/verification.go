@@ -0,0 +1,249 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/core/utils"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/webhook/models"
+)
+
+const (
+	signatureHeader            = "X-Devlake-Signature"
+	nonceHeader                = "X-Devlake-Nonce"
+	signatureQuery             = "sign"
+	nonceQuery                 = "nonce"
+	defaultReplayWindowSeconds = 300
+	signingSecretField         = "signingSecret"
+	ipAllowlistField           = "ipAllowlist"
+)
+
+// usedNonces is kept in memory, so with several DevLake instances behind a load balancer a nonce is only
+// rejected by the instance which already saw it, put the instances behind sticky sessions to rule replays out
+var usedNonces = newNonceCache()
+
+// Verified guards a handler posting data into a connection with the checks configured on the connection,
+// the IP allowlist first and then the signature of the payload. The client address is the peer of the connection,
+// or the address forwarded by one of the TRUSTED_PROXIES when DevLake runs behind a reverse proxy.
+//
+// Signed requests carry a nonce made of the unix timestamp of the request, a dash and a random string,
+// e.g. `1700000000-f3k2Ls9x`, along with the hex encoded sha256 of the body, the signing secret and the nonce
+// concatenated, which is how pipeline notifications are signed. Both go into the X-Devlake-Nonce and
+// X-Devlake-Signature headers, or the `nonce` and `sign` query parameters. A request is rejected when its timestamp
+// is out of the replay window of the connection or its nonce was already used within the window.
+func Verified(handler plugin.ApiResourceHandler) plugin.ApiResourceHandler {
+	return func(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+		connection := &models.WebhookConnection{}
+		var err errors.Error
+		if input.Params["connectionName"] != "" {
+			err = connectionHelper.FirstByName(connection, input.Params)
+		} else {
+			err = connectionHelper.First(connection, input.Params)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err = verifyRequest(connection, input.ClientIp, input.Request, time.Now()); err != nil {
+			logger.Warn(err, "rejected request to webhook connection %d", connection.ID)
+			return nil, err
+		}
+		return handler(input)
+	}
+}
+
+func verifyRequest(connection *models.WebhookConnection, clientIp string, req *http.Request, now time.Time) errors.Error {
+	if connection.IpAllowlist != "" {
+		if clientIp == "" && req != nil {
+			clientIp = req.RemoteAddr
+		}
+		if clientIp == "" {
+			return errors.Forbidden.New("unable to tell the client address")
+		}
+		allowed, err := isIpAllowed(connection.IpAllowlist, clientIp)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return errors.Forbidden.New("client address is not in the ipAllowlist of the connection")
+		}
+	}
+	if connection.SigningSecret == "" {
+		return nil
+	}
+	if req == nil {
+		return errors.Unauthorized.New("missing signature")
+	}
+	nonce := req.Header.Get(nonceHeader)
+	signature := req.Header.Get(signatureHeader)
+	if nonce == "" {
+		nonce = req.URL.Query().Get(nonceQuery)
+	}
+	if signature == "" {
+		signature = req.URL.Query().Get(signatureQuery)
+	}
+	if nonce == "" || signature == "" {
+		return errors.Unauthorized.New(fmt.Sprintf("missing %s or %s", nonceHeader, signatureHeader))
+	}
+	timestamp, err := parseNonceTimestamp(nonce)
+	if err != nil {
+		return err
+	}
+	window := time.Duration(connection.ReplayWindowSeconds) * time.Second
+	if window <= 0 {
+		window = defaultReplayWindowSeconds * time.Second
+	}
+	if timestamp.Before(now.Add(-window)) || timestamp.After(now.Add(window)) {
+		return errors.Unauthorized.New("the request timestamp is out of the replay window")
+	}
+	var payload []byte
+	if req.Body != nil {
+		var readErr error
+		payload, readErr = io.ReadAll(req.Body)
+		if readErr != nil {
+			return errors.BadInput.Wrap(readErr, "failed to read the payload")
+		}
+		req.Body = io.NopCloser(bytes.NewReader(payload))
+	}
+	if !api.VerifyNonceSignature(payload, connection.SigningSecret, nonce, signature) {
+		return errors.Unauthorized.New("invalid signature")
+	}
+	// only record the nonce of genuine requests, forged ones must not be able to burn nonces
+	if !usedNonces.add(fmt.Sprintf("%d:%s", connection.ID, nonce), timestamp.Add(window), now) {
+		return errors.Unauthorized.New("the nonce was already used")
+	}
+	return nil
+}
+
+func parseNonceTimestamp(nonce string) (time.Time, errors.Error) {
+	seconds, _, found := strings.Cut(nonce, "-")
+	if !found {
+		return time.Time{}, errors.Unauthorized.New("the nonce must be formatted as <unix timestamp>-<random string>")
+	}
+	unix, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.Unauthorized.New("the nonce must start with a unix timestamp")
+	}
+	return time.Unix(unix, 0), nil
+}
+
+// isIpAllowed tells if clientIp, with or without a port, matches one of the IPs or CIDRs of allowlist
+func isIpAllowed(allowlist string, clientIp string) (bool, errors.Error) {
+	host, _, err := net.SplitHostPort(clientIp)
+	if err != nil {
+		host = clientIp
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false, nil
+	}
+	networks, parseErr := parseIpAllowlist(allowlist)
+	if parseErr != nil {
+		return false, parseErr
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func parseIpAllowlist(allowlist string) ([]*net.IPNet, errors.Error) {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(allowlist, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil {
+				bits := 8 * net.IPv6len
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 8*net.IPv4len
+				}
+				networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, errors.BadInput.New(fmt.Sprintf("invalid ipAllowlist entry %s", entry))
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// validateVerificationFields rejects malformed verification settings before a connection gets saved
+func validateVerificationFields(body map[string]interface{}) errors.Error {
+	if allowlist, ok := body[ipAllowlistField].(string); ok {
+		if _, err := parseIpAllowlist(allowlist); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keepSigningSecret drops the signing secret from a patch body when it is the sanitized value returned by the api
+func keepSigningSecret(body map[string]interface{}, existing *models.WebhookConnection) {
+	if secret, ok := body[signingSecretField].(string); ok && secret != "" && secret == utils.SanitizeString(existing.SigningSecret) {
+		delete(body, signingSecretField)
+	}
+}
+
+// nonceCache remembers the nonces used within their replay window, in the memory of the current process
+type nonceCache struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	nextPrune time.Time
+}
+
+func newNonceCache() *nonceCache {
+	return &nonceCache{nonces: make(map[string]time.Time)}
+}
+
+// add records key until expiresAt, false is returned when it is already recorded
+func (c *nonceCache) add(key string, expiresAt time.Time, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if now.After(c.nextPrune) {
+		for k, expiry := range c.nonces {
+			if now.After(expiry) {
+				delete(c.nonces, k)
+			}
+		}
+		c.nextPrune = now.Add(time.Minute)
+	}
+	if expiry, ok := c.nonces[key]; ok && !now.After(expiry) {
+		return false
+	}
+	c.nonces[key] = expiresAt
+	return true
+}
@@ -0,0 +1,108 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
+	"github.com/apache/incubator-devlake/plugins/webhook/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSignedRequest(body string, secret string, nonce string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/plugins/webhook/connections/1/deployments", strings.NewReader(body))
+	req.Header.Set(nonceHeader, nonce)
+	req.Header.Set(signatureHeader, helper.SignWithNonce([]byte(body), secret, nonce))
+	return req
+}
+
+func TestVerifyRequestSignature(t *testing.T) {
+	connection := &models.WebhookConnection{SigningSecret: "s3cret"}
+	connection.ID = 1
+	now := time.Unix(1700000000, 0)
+	body := `{"commitSha": "abc"}`
+
+	req := newSignedRequest(body, "s3cret", "1700000000-first")
+	assert.Nil(t, verifyRequest(connection, "", req, now))
+	// the body is still there for the handler
+	restored, _ := io.ReadAll(req.Body)
+	assert.Equal(t, body, string(restored))
+
+	// replayed
+	assert.NotNil(t, verifyRequest(connection, "", newSignedRequest(body, "s3cret", "1700000000-first"), now))
+	// wrong secret
+	assert.NotNil(t, verifyRequest(connection, "", newSignedRequest(body, "other", "1700000000-second"), now))
+	// tampered body
+	req = newSignedRequest(body, "s3cret", "1700000000-third")
+	req.Body = io.NopCloser(strings.NewReader(`{"commitSha": "def"}`))
+	assert.NotNil(t, verifyRequest(connection, "", req, now))
+	// out of the default window
+	assert.NotNil(t, verifyRequest(connection, "", newSignedRequest(body, "s3cret", "1699999000-fourth"), now))
+	connection.ReplayWindowSeconds = 3600
+	assert.Nil(t, verifyRequest(connection, "", newSignedRequest(body, "s3cret", "1699999000-fourth"), now))
+	// malformed nonce and missing signature
+	assert.NotNil(t, verifyRequest(connection, "", newSignedRequest(body, "s3cret", "fifth"), now))
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	assert.NotNil(t, verifyRequest(connection, "", req, now))
+
+	// query parameters work as well
+	req = httptest.NewRequest(http.MethodPost, "/?nonce=1700000000-sixth&sign="+helper.SignWithNonce([]byte(body), "s3cret", "1700000000-sixth"), strings.NewReader(body))
+	assert.Nil(t, verifyRequest(connection, "", req, now))
+}
+
+func TestVerifyRequestIpAllowlist(t *testing.T) {
+	connection := &models.WebhookConnection{IpAllowlist: "10.0.0.0/8, 192.168.1.7, ::1"}
+	now := time.Now()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+
+	req.RemoteAddr = "10.1.2.3:51234"
+	assert.Nil(t, verifyRequest(connection, "", req, now))
+	req.RemoteAddr = "192.168.1.7:51234"
+	assert.Nil(t, verifyRequest(connection, "", req, now))
+	req.RemoteAddr = "[::1]:51234"
+	assert.Nil(t, verifyRequest(connection, "", req, now))
+	req.RemoteAddr = "192.168.1.8:51234"
+	assert.NotNil(t, verifyRequest(connection, "", req, now))
+
+	// the address forwarded by a trusted proxy wins over the peer
+	req.RemoteAddr = "172.16.0.1:51234"
+	assert.Nil(t, verifyRequest(connection, "10.1.2.3", req, now))
+	assert.NotNil(t, verifyRequest(connection, "192.168.1.8", req, now))
+
+	connection.IpAllowlist = "10.0.0.0/33"
+	assert.NotNil(t, verifyRequest(connection, "", req, now))
+	assert.NotNil(t, validateVerificationFields(map[string]interface{}{"ipAllowlist": "not-an-ip"}))
+	assert.Nil(t, validateVerificationFields(map[string]interface{}{"ipAllowlist": "10.0.0.1, 2001:db8::/32"}))
+}
+
+func TestKeepSigningSecret(t *testing.T) {
+	existing := &models.WebhookConnection{SigningSecret: "s3cret"}
+	body := map[string]interface{}{"signingSecret": "s3**et"}
+	keepSigningSecret(body, existing)
+	assert.NotContains(t, body, "signingSecret")
+
+	body = map[string]interface{}{"signingSecret": "n3w"}
+	keepSigningSecret(body, existing)
+	assert.Equal(t, "n3w", body["signingSecret"])
+}
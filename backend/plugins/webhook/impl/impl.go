@@ -57,6 +57,7 @@ func (p Webhook) Init(basicRes context.BasicRes) errors.Error {
 func (p Webhook) GetTablesInfo() []dal.Tabler {
 	return []dal.Tabler{
 		&models.WebhookConnection{},
+		&models.WebhookIdempotencyKey{},
 	}
 }
 
@@ -88,28 +89,73 @@ func (p Webhook) ApiResources() map[string]map[string]plugin.ApiResourceHandler
 			"DELETE": api.DeleteConnection,
 		},
 		"connections/:connectionId/deployments": {
-			"POST": api.PostDeployments,
+			"POST": api.Verified(api.PostDeployments),
 		},
 		"connections/:connectionId/pull_requests": {
-			"POST": api.PostPullRequests,
+			"POST": api.Verified(api.PostPullRequests),
 		},
 		"connections/:connectionId/issues": {
-			"POST": api.PostIssue,
+			"POST": api.Verified(api.PostIssue),
+		},
+		"connections/:connectionId/deployments/batch": {
+			"POST": api.Verified(api.PostDeploymentsBatch),
+		},
+		"connections/:connectionId/pull_requests/batch": {
+			"POST": api.Verified(api.PostPullRequestsBatch),
+		},
+		"connections/:connectionId/issues/batch": {
+			"POST": api.Verified(api.PostIssuesBatch),
 		},
 		"connections/:connectionId/issue/:issueKey/close": {
-			"POST": api.CloseIssue,
+			"POST": api.Verified(api.CloseIssue),
+		},
+		"connections/:connectionId/incidents": {
+			"POST": api.Verified(api.PostIncidents),
+		},
+		"connections/:connectionId/incidents/batch": {
+			"POST": api.Verified(api.PostIncidentsBatch),
+		},
+		"connections/:connectionId/cicd_pipelines": {
+			"POST": api.Verified(api.PostCicdPipelines),
+		},
+		"connections/:connectionId/cicd_pipelines/batch": {
+			"POST": api.Verified(api.PostCicdPipelinesBatch),
+		},
+		"connections/:connectionId/cicd_tasks": {
+			"POST": api.Verified(api.PostCicdTasks),
+		},
+		"connections/:connectionId/cicd_pipeline/:pipelineName/finish": {
+			"POST": api.Verified(api.FinishCicdPipeline),
+		},
+		"connections/:connectionId/sprints": {
+			"POST": api.Verified(api.PostSprints),
+		},
+		"connections/:connectionId/sprints/batch": {
+			"POST": api.Verified(api.PostSprintsBatch),
 		},
 		":connectionId/deployments": {
-			"POST": api.PostDeployments,
+			"POST": api.Verified(api.PostDeployments),
 		},
 		":connectionId/pull_requests": {
-			"POST": api.PostPullRequests,
+			"POST": api.Verified(api.PostPullRequests),
 		},
 		":connectionId/issues": {
-			"POST": api.PostIssue,
+			"POST": api.Verified(api.PostIssue),
 		},
 		":connectionId/issue/:issueKey/close": {
-			"POST": api.CloseIssue,
+			"POST": api.Verified(api.CloseIssue),
+		},
+		":connectionId/incidents": {
+			"POST": api.Verified(api.PostIncidents),
+		},
+		":connectionId/cicd_pipelines": {
+			"POST": api.Verified(api.PostCicdPipelines),
+		},
+		":connectionId/cicd_tasks": {
+			"POST": api.Verified(api.PostCicdTasks),
+		},
+		":connectionId/sprints": {
+			"POST": api.Verified(api.PostSprints),
 		},
 		"connections/by-name/:connectionName": {
 			"GET":    api.GetConnectionByName,
@@ -117,16 +163,49 @@ func (p Webhook) ApiResources() map[string]map[string]plugin.ApiResourceHandler
 			"DELETE": api.DeleteConnectionByName,
 		},
 		"connections/by-name/:connectionName/deployments": {
-			"POST": api.PostDeploymentsByName,
+			"POST": api.Verified(api.PostDeploymentsByName),
 		},
 		"connections/by-name/:connectionName/pull_requests": {
-			"POST": api.PostPullRequestsByName,
+			"POST": api.Verified(api.PostPullRequestsByName),
 		},
 		"connections/by-name/:connectionName/issues": {
-			"POST": api.PostIssueByName,
+			"POST": api.Verified(api.PostIssueByName),
+		},
+		"connections/by-name/:connectionName/deployments/batch": {
+			"POST": api.Verified(api.PostDeploymentsBatchByName),
+		},
+		"connections/by-name/:connectionName/pull_requests/batch": {
+			"POST": api.Verified(api.PostPullRequestsBatchByName),
+		},
+		"connections/by-name/:connectionName/issues/batch": {
+			"POST": api.Verified(api.PostIssuesBatchByName),
 		},
 		"connections/by-name/:connectionName/issue/:issueKey/close": {
-			"POST": api.CloseIssueByName,
+			"POST": api.Verified(api.CloseIssueByName),
+		},
+		"connections/by-name/:connectionName/incidents": {
+			"POST": api.Verified(api.PostIncidentsByName),
+		},
+		"connections/by-name/:connectionName/incidents/batch": {
+			"POST": api.Verified(api.PostIncidentsBatchByName),
+		},
+		"connections/by-name/:connectionName/cicd_pipelines": {
+			"POST": api.Verified(api.PostCicdPipelinesByName),
+		},
+		"connections/by-name/:connectionName/cicd_pipelines/batch": {
+			"POST": api.Verified(api.PostCicdPipelinesBatchByName),
+		},
+		"connections/by-name/:connectionName/cicd_tasks": {
+			"POST": api.Verified(api.PostCicdTasksByName),
+		},
+		"connections/by-name/:connectionName/cicd_pipeline/:pipelineName/finish": {
+			"POST": api.Verified(api.FinishCicdPipelineByName),
+		},
+		"connections/by-name/:connectionName/sprints": {
+			"POST": api.Verified(api.PostSprintsByName),
+		},
+		"connections/by-name/:connectionName/sprints/batch": {
+			"POST": api.Verified(api.PostSprintsBatchByName),
 		},
 	}
 }
@@ -23,6 +23,12 @@ import (
 
 type WebhookConnection struct {
 	helper.BaseConnection `mapstructure:",squash"`
+	// SigningSecret makes the payloads posted to the connection require a signature when set
+	SigningSecret string `mapstructure:"signingSecret" json:"signingSecret" gorm:"serializer:encdec"`
+	// ReplayWindowSeconds is how far the timestamp of a signed request may be from now, 300 when left 0
+	ReplayWindowSeconds int `mapstructure:"replayWindowSeconds" json:"replayWindowSeconds"`
+	// IpAllowlist holds the comma separated IPs and CIDRs allowed to post to the connection, anyone when empty
+	IpAllowlist string `mapstructure:"ipAllowlist" json:"ipAllowlist" gorm:"type:text"`
 }
 
 func (WebhookConnection) TableName() string {
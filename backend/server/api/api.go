@@ -81,9 +81,10 @@ func CreateAndRunApiServer() {
 func CreateApiServer() *gin.Engine {
 	// Create router
 	router := gin.Default()
+	cfg := basicRes.GetConfigReader()
+	errors.Must(setTrustedProxies(router, cfg.GetString("TRUSTED_PROXIES")))
 
 	// Enable CORS
-	cfg := basicRes.GetConfigReader()
 	router.Use(cors.New(cors.Config{
 		// Allow all origins
 		AllowOrigins: cfg.GetStringSlice("CORS_ALLOW_ORIGIN"),
@@ -105,13 +106,38 @@ func CreateApiServer() *gin.Engine {
 	router.GET("/health", ping.Health)
 	router.GET("/version", version.Get)
 
+	// Native OIDC login, enabled by OIDC_ENABLED
+	oidcEnabled := registerOidcLogin(router, basicRes)
+
 	// Api keys
 	router.Use(RestAuthentication(router, basicRes))
-	router.Use(OAuth2ProxyAuthentication(basicRes))
+	if oidcEnabled {
+		router.Use(OidcAuthentication(basicRes))
+	} else {
+		router.Use(OAuth2ProxyAuthentication(basicRes))
+	}
+	router.GET("/auth/userinfo", GetUserInfo)
+	// Role based access control, enabled by RBAC_ENABLED
+	router.Use(RbacAuthorization(basicRes))
 
 	return router
 }
 
+// setTrustedProxies lets only the given comma separated IPs or CIDRs tell the client address with
+// X-Forwarded-For or X-Real-IP, the address of the peer is used for any other request
+func setTrustedProxies(router *gin.Engine, proxies string) errors.Error {
+	var trusted []string
+	for _, proxy := range strings.Split(proxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trusted = append(trusted, proxy)
+		}
+	}
+	if err := router.SetTrustedProxies(trusted); err != nil {
+		return errors.BadInput.Wrap(err, "invalid TRUSTED_PROXIES")
+	}
+	return nil
+}
+
 func SetupApiServer(router *gin.Engine) {
 	// Set gin mode
 	gin.SetMode(basicRes.GetConfig("MODE"))
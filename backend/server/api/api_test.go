@@ -0,0 +1,71 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/config"
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/plugin"
+	contextimpl "github.com/apache/incubator-devlake/impls/context"
+	"github.com/apache/incubator-devlake/impls/logruslog"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIpBehindTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("ENCRYPTION_SECRET", "test-secret")
+	basicRes := contextimpl.NewDefaultBasicRes(config.GetConfig(), logruslog.Global, struct{ dal.Dal }{})
+	var clientIp string
+	handler := func(input *plugin.ApiResourceInput) (*plugin.ApiResourceOutput, errors.Error) {
+		clientIp = input.ClientIp
+		return &plugin.ApiResourceOutput{Status: http.StatusNoContent}, nil
+	}
+	newRouter := func(proxies string) *gin.Engine {
+		router := gin.New()
+		assert.Nil(t, setTrustedProxies(router, proxies))
+		router.POST("/plugins/webhook/connections/:connectionId/deployments", handlePluginCall(basicRes, "webhook", "connections/:connectionId/deployments", handler))
+		return router
+	}
+	send := func(router *gin.Engine, remoteAddr string) {
+		req := httptest.NewRequest(http.MethodPost, "/plugins/webhook/connections/1/deployments", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	router := newRouter("10.0.0.0/8, 192.168.1.1")
+	// forwarded by a trusted proxy
+	send(router, "10.0.0.5:51234")
+	assert.Equal(t, "203.0.113.7", clientIp)
+	// anybody else can't spoof its address with the header
+	send(router, "172.16.0.1:51234")
+	assert.Equal(t, "172.16.0.1", clientIp)
+
+	// no proxy is trusted by default
+	router = newRouter("")
+	send(router, "10.0.0.5:51234")
+	assert.Equal(t, "10.0.0.5", clientIp)
+
+	assert.NotNil(t, setTrustedProxies(gin.New(), "not-an-ip"))
+}
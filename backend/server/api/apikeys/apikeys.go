@@ -77,7 +77,8 @@ func DeleteApiKey(c *gin.Context) {
 		shared.ApiOutputError(c, errors.BadInput.Wrap(err, "bad apiKeyId format supplied"))
 		return
 	}
-	err = services.DeleteApiKey(id)
+	user, _ := shared.GetUser(c)
+	err = services.DeleteApiKey(user, id)
 	if err != nil {
 		shared.ApiOutputError(c, errors.Default.Wrap(err, "error deleting api key"))
 		return
@@ -0,0 +1,68 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auditevents
+
+import (
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PaginatedAuditEvents struct {
+	AuditEvents []*models.AuditEvent `json:"auditEvents"`
+	Count       int64                `json:"count"`
+}
+
+// @Summary Get list of audit events
+// @Description GET /audit-events?actor=alice&action=update&targetType=connection&targetPlugin=github&targetId=1&since=2025-07-01T00:00:00Z&until=2025-08-01T00:00:00Z&page=1&pageSize=50
+// @Description latest events come first, diff holds the changed fields with sensitive values redacted
+// @Tags framework/audit-events
+// @Param actor query string false "actor"
+// @Param action query string false "create, update, delete or trigger"
+// @Param targetType query string false "connection, scope-config, blueprint, project, api-key, pipeline or role-binding"
+// @Param targetPlugin query string false "targetPlugin"
+// @Param targetId query string false "targetId"
+// @Param since query string false "RFC3339 time, inclusive"
+// @Param until query string false "RFC3339 time, exclusive"
+// @Param page query int false "query"
+// @Param pageSize query int false "query"
+// @Success 200  {object} PaginatedAuditEvents
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /audit-events [get]
+func GetAuditEvents(c *gin.Context) {
+	var query services.AuditEventQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	auditEvents, count, err := services.GetAuditEvents(&query)
+	if err != nil {
+		shared.ApiOutputAbort(c, errors.Default.Wrap(err, "error getting audit events"))
+		return
+	}
+	shared.ApiOutputSuccess(c, PaginatedAuditEvents{
+		AuditEvents: auditEvents,
+		Count:       count,
+	}, http.StatusOK)
+}
@@ -0,0 +1,254 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/helpers/oidchelper"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+var oidcProvider *oidchelper.OidcProvider
+var sessionManager *oidchelper.SessionManager
+var secureCookie bool
+
+type UserInfo struct {
+	Name   string   `json:"name"`
+	Email  string   `json:"email"`
+	Groups []string `json:"groups"`
+	// Admin and Projects are the roles granted by role bindings, only available when RBAC_ENABLED is on
+	Admin    bool              `json:"admin"`
+	Projects map[string]string `json:"projects,omitempty"`
+}
+
+// registerOidcLogin mounts the login endpoints when OIDC_ENABLED is on, it returns false when OIDC is off
+func registerOidcLogin(router *gin.Engine, basicRes context.BasicRes) bool {
+	cfg := basicRes.GetConfigReader()
+	provider, err := oidchelper.NewOidcProviderFromConfig(cfg)
+	if err != nil {
+		panic(err)
+	}
+	if provider == nil {
+		return false
+	}
+	oidcProvider = provider
+	sessionSecret := cfg.GetString("OIDC_SESSION_SECRET")
+	if sessionSecret == "" {
+		sessionSecret = cfg.GetString("ENCRYPTION_SECRET")
+	}
+	sessionManager = oidchelper.NewSessionManager(sessionSecret, cfg.GetDuration("OIDC_SESSION_TTL"))
+	secureCookie = !cfg.IsSet("OIDC_COOKIE_SECURE") || cfg.GetBool("OIDC_COOKIE_SECURE")
+
+	router.GET("/auth/login", OidcLogin)
+	router.GET("/auth/callback", OidcCallback)
+	router.POST("/auth/logout", OidcLogout)
+	return true
+}
+
+// OidcAuthentication authenticates requests by the session cookie or the bearer token issued by the OIDC provider,
+// the X-Forwarded-* headers of oauth2-proxy are not trusted in this mode
+func OidcAuthentication(basicRes context.BasicRes) gin.HandlerFunc {
+	logger := basicRes.GetLogger()
+	return func(c *gin.Context) {
+		restoreApiKeyUser(c)
+		if _, exist := c.Get(common.USER); !exist {
+			user, err := getOidcUser(c)
+			if err != nil {
+				logger.Debug("oidc authentication failed: %s", err.Error())
+				shared.ApiOutputError(c, err)
+				c.Abort()
+				return
+			}
+			if user != nil {
+				user.SourceIp = c.ClientIP()
+				c.Set(common.USER, user)
+			}
+		}
+		if _, exist := c.Get(common.USER); !exist && c.FullPath() != "" && !isPublicRoute(c.Request.Method, c.FullPath()) {
+			shared.ApiOutputError(c, errors.Unauthorized.New("authentication is required, please log in at /auth/login"))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func getOidcUser(c *gin.Context) (*common.User, errors.Error) {
+	authHeader := c.GetHeader("Authorization")
+	if token := strings.TrimPrefix(authHeader, "Bearer "); token != authHeader && token != "" {
+		return oidcProvider.VerifyBearerToken(token)
+	}
+	session, e := c.Cookie(oidchelper.SessionCookieName)
+	if e != nil || session == "" {
+		return nil, nil
+	}
+	user, err := sessionManager.DecodeSession(session)
+	if err != nil {
+		// expired sessions are treated as logged out
+		setCookie(c, oidchelper.SessionCookieName, "", -1)
+		return nil, nil
+	}
+	return user, nil
+}
+
+// @Summary Log in with the OpenID Connect provider
+// @Description Redirect to the OIDC provider, the user is redirected back to `redirect` after logging in
+// @Tags framework/auth
+// @Param redirect query string false "relative path to return to, default to /"
+// @Success 302
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /auth/login [get]
+func OidcLogin(c *gin.Context) {
+	state := &oidchelper.LoginState{Redirect: c.Query("redirect")}
+	if !isRelativePath(state.Redirect) {
+		state.Redirect = "/"
+	}
+	var err errors.Error
+	for _, value := range []*string{&state.State, &state.Nonce, &state.CodeVerifier} {
+		*value, err = oidchelper.RandomString()
+		if err != nil {
+			shared.ApiOutputError(c, err)
+			return
+		}
+	}
+	authUrl, err := oidcProvider.AuthCodeURL(state.State, state.Nonce, state.CodeVerifier)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error connecting to the OIDC provider"))
+		return
+	}
+	signedState, err := sessionManager.EncodeState(state)
+	if err != nil {
+		shared.ApiOutputError(c, err)
+		return
+	}
+	setCookie(c, oidchelper.StateCookieName, signedState, oidchelper.StateTtl)
+	c.Redirect(http.StatusFound, authUrl)
+}
+
+// @Summary Callback of the OpenID Connect provider
+// @Description Exchange the authorization code and start the session
+// @Tags framework/auth
+// @Param code query string true "authorization code"
+// @Param state query string true "state"
+// @Success 302
+// @Failure 401  {string} errcode.Error "Unauthorized"
+// @Router /auth/callback [get]
+func OidcCallback(c *gin.Context) {
+	if e := c.Query("error"); e != "" {
+		shared.ApiOutputError(c, errors.Unauthorized.New(fmt.Sprintf("login failed: %s %s", e, c.Query("error_description"))))
+		return
+	}
+	signedState, e := c.Cookie(oidchelper.StateCookieName)
+	if e != nil || signedState == "" {
+		shared.ApiOutputError(c, errors.Unauthorized.New("login state is missing, please log in again"))
+		return
+	}
+	setCookie(c, oidchelper.StateCookieName, "", -1)
+	state, err := sessionManager.DecodeState(signedState)
+	if err != nil {
+		shared.ApiOutputError(c, err)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(state.State), []byte(c.Query("state"))) != 1 {
+		shared.ApiOutputError(c, errors.Unauthorized.New("login state doesn't match, please log in again"))
+		return
+	}
+	user, err := oidcProvider.Exchange(c.Request.Context(), c.Query("code"), state.CodeVerifier, state.Nonce)
+	if err != nil {
+		shared.ApiOutputError(c, err)
+		return
+	}
+	session, err := sessionManager.EncodeSession(user)
+	if err != nil {
+		shared.ApiOutputError(c, err)
+		return
+	}
+	setCookie(c, oidchelper.SessionCookieName, session, sessionManager.Ttl())
+	c.Redirect(http.StatusFound, state.Redirect)
+}
+
+// @Summary Log out
+// @Description End the session started by OIDC login
+// @Tags framework/auth
+// @Success 200
+// @Router /auth/logout [post]
+func OidcLogout(c *gin.Context) {
+	setCookie(c, oidchelper.SessionCookieName, "", -1)
+	shared.ApiOutputSuccess(c, nil, http.StatusOK)
+}
+
+// @Summary Get the current user
+// @Description Get the authenticated user, with the roles when RBAC_ENABLED is on
+// @Tags framework/auth
+// @Success 200  {object} UserInfo
+// @Failure 401  {string} errcode.Error "Unauthorized"
+// @Router /auth/userinfo [get]
+func GetUserInfo(c *gin.Context) {
+	user, exist := shared.GetUser(c)
+	if !exist {
+		shared.ApiOutputError(c, errors.Unauthorized.New("not logged in"))
+		return
+	}
+	userInfo := &UserInfo{
+		Name:   user.Name,
+		Email:  user.Email,
+		Groups: user.Groups,
+	}
+	if services.IsRbacEnabled() {
+		roles, err := services.GetUserRoles(user)
+		if err != nil {
+			shared.ApiOutputError(c, err)
+			return
+		}
+		userInfo.Admin = roles.Admin
+		userInfo.Projects = roles.Projects
+	}
+	shared.ApiOutputSuccess(c, userInfo, http.StatusOK)
+}
+
+func setCookie(c *gin.Context, name, value string, ttl time.Duration) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if ttl < 0 {
+		cookie.MaxAge = -1
+	} else {
+		cookie.MaxAge = int(ttl.Seconds())
+	}
+	http.SetCookie(c.Writer, cookie)
+}
+
+// isRelativePath prevents open redirects to other sites
+func isRelativePath(path string) bool {
+	return strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "//") && !strings.Contains(path, "\\")
+}
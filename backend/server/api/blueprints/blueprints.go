@@ -50,7 +50,8 @@ func Post(c *gin.Context) {
 		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
 		return
 	}
-	err = services.CreateBlueprint(blueprint)
+	user, _ := shared.GetUser(c)
+	err = services.CreateBlueprint(user, blueprint)
 	if err != nil {
 		shared.ApiOutputError(c, errors.Default.Wrap(err, "error creating blueprint"))
 		return
@@ -77,6 +78,7 @@ func Index(c *gin.Context) {
 		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
 		return
 	}
+	query.ProjectNames = shared.GetAccessibleProjects(c)
 	blueprints, count, err := services.GetBlueprints(&query, true)
 	if err != nil {
 		shared.ApiOutputAbort(c, errors.Default.Wrap(err, "error getting blueprints"))
@@ -152,7 +154,8 @@ func Patch(c *gin.Context) {
 		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
 		return
 	}
-	blueprint, err := services.PatchBlueprint(id, body)
+	user, _ := shared.GetUser(c)
+	blueprint, err := services.PatchBlueprint(user, id, body)
 	if err != nil {
 		shared.ApiOutputError(c, errors.Default.Wrap(err, "error patching the blueprint"))
 		return
@@ -190,7 +193,8 @@ func Trigger(c *gin.Context) {
 			return
 		}
 	}
-	pipeline, err := services.TriggerBlueprint(id, triggerSyncPolicy, true)
+	user, _ := shared.GetUser(c)
+	pipeline, err := services.TriggerBlueprint(user, id, triggerSyncPolicy, true)
 	if err != nil {
 		shared.ApiOutputError(c, errors.Default.Wrap(err, "error triggering blueprint"))
 		return
@@ -243,7 +247,8 @@ func Delete(c *gin.Context) {
 		shared.ApiOutputError(c, errors.BadInput.Wrap(err, "bad blueprintId format supplied"))
 		return
 	}
-	err = services.DeleteBlueprint(id)
+	user, _ := shared.GetUser(c)
+	err = services.DeleteBlueprint(user, id)
 	if err != nil {
 		shared.ApiOutputError(c, errors.Default.Wrap(err, "error deleting blueprint"))
 		return
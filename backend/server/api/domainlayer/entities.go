@@ -0,0 +1,130 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package domainlayer
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+// query parameters with a meaning of their own, all the others are column filters
+var reservedQueryParams = map[string]bool{
+	"fields":    true,
+	"timeField": true,
+	"since":     true,
+	"until":     true,
+	"cursor":    true,
+	"pageSize":  true,
+}
+
+// @Summary Query the domain entities of a project
+// @Description Page through pull_requests, commits, cicd_deployments, cicd_pipelines, issues, incidents or sprints
+// @Description of a project, ordered by primary key. Any other query parameter is an equality filter on the column
+// @Description of the same name, comma separated values are OR'ed, e.g. ?status=MERGED,CLOSED
+// @Tags framework/domainlayer
+// @Param projectName path string true "project name"
+// @Param entity path string true "pull_requests, commits, cicd_deployments, cicd_pipelines, issues, incidents or sprints"
+// @Param fields query string false "comma separated columns to return, all columns by default"
+// @Param timeField query string false "column since/until apply to, e.g. created_date (default) or merged_date for pull_requests"
+// @Param since query string false "inclusive lower bound, RFC3339 or yyyy-mm-dd"
+// @Param until query string false "exclusive upper bound, RFC3339 or yyyy-mm-dd"
+// @Param cursor query string false "nextCursor of the previous page"
+// @Param pageSize query int false "page size, default 100, max 1000"
+// @Success 200  {object} services.DomainQueryResult
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 404  {string} errcode.Error "Not Found"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /domainlayer/projects/{projectName}/{entity} [get]
+func EntitiesIndex(c *gin.Context) {
+	query, err := parseDomainQuery(c)
+	if err != nil {
+		shared.ApiOutputError(c, err)
+		return
+	}
+	result, err := services.QueryDomainEntities(c.Param("projectName"), c.Param("entity"), query)
+	if err != nil {
+		shared.ApiOutputError(c, err)
+		return
+	}
+	shared.ApiOutputSuccess(c, result, http.StatusOK)
+}
+
+func parseDomainQuery(c *gin.Context) (*services.DomainQuery, errors.Error) {
+	query := &services.DomainQuery{
+		Fields:    splitValues(c.Query("fields")),
+		TimeField: c.Query("timeField"),
+		Cursor:    c.Query("cursor"),
+		Filters:   make(map[string][]string),
+	}
+	var err errors.Error
+	if query.Since, err = parseTime("since", c.Query("since")); err != nil {
+		return nil, err
+	}
+	if query.Until, err = parseTime("until", c.Query("until")); err != nil {
+		return nil, err
+	}
+	if pageSize := c.Query("pageSize"); pageSize != "" {
+		size, convErr := strconv.Atoi(pageSize)
+		if convErr != nil || size < 1 {
+			return nil, errors.BadInput.New("pageSize must be a positive integer")
+		}
+		query.PageSize = size
+	}
+	for key, values := range c.Request.URL.Query() {
+		if reservedQueryParams[key] {
+			continue
+		}
+		for _, v := range values {
+			query.Filters[key] = append(query.Filters[key], splitValues(v)...)
+		}
+		// ?status= would otherwise filter on an empty IN list
+		if len(query.Filters[key]) == 0 {
+			return nil, errors.BadInput.New("filter " + key + " needs at least one value")
+		}
+	}
+	return query, nil
+}
+
+func splitValues(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func parseTime(name, value string) (*time.Time, errors.Error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, errors.BadInput.New(name + " must be RFC3339 or yyyy-mm-dd")
+}
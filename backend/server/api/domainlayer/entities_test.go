@@ -0,0 +1,63 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package domainlayer
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func queryContext(rawQuery string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/domainlayer/projects/p1/pull_requests?"+rawQuery, nil)
+	return c
+}
+
+func TestParseDomainQuery(t *testing.T) {
+	query, err := parseDomainQuery(queryContext("fields=id,%20title&timeField=merged_date&since=2025-01-01" +
+		"&until=2025-02-01T00:00:00Z&cursor=abc&pageSize=50&status=MERGED,CLOSED&status=OPEN&type=bug"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id", "title"}, query.Fields)
+	assert.Equal(t, "merged_date", query.TimeField)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), *query.Since)
+	assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), *query.Until)
+	assert.Equal(t, "abc", query.Cursor)
+	assert.Equal(t, 50, query.PageSize)
+	assert.Equal(t, map[string][]string{"status": {"MERGED", "CLOSED", "OPEN"}, "type": {"bug"}}, query.Filters)
+}
+
+func TestParseDomainQueryValidation(t *testing.T) {
+	for _, rawQuery := range []string{
+		"status=",
+		"status=,%20",
+		"since=yesterday",
+		"until=2025-13-01",
+		"pageSize=0",
+		"pageSize=ten",
+	} {
+		_, err := parseDomainQuery(queryContext(rawQuery))
+		if assert.Error(t, err, rawQuery) {
+			assert.Equal(t, errors.BadInput, err.GetType(), rawQuery)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package domainlayer
+
+import (
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/apache/incubator-devlake/server/services/graphql"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Query the domain layer with GraphQL
+// @Description Traverse projects, their domain entities and the relations between them, e.g. the commits of
+// @Description a deployment, their pull requests and the linked issues. Only the accessible projects are visible,
+// @Description and queries deeper than GRAPHQL_MAX_DEPTH or resolving more than GRAPHQL_MAX_COMPLEXITY objects are rejected
+// @Tags framework/domainlayer
+// @Accept application/json
+// @Param body body graphql.Request true "query, operationName and variables"
+// @Success 200  {object} graphql.Response
+// @Failure 400  {object} graphql.Response
+// @Failure 403  {object} graphql.Response
+// @Failure 500  {object} graphql.Response
+// @Router /domainlayer/graphql [post]
+func PostGraphql(c *gin.Context) {
+	request := &graphql.Request{}
+	if err := c.ShouldBindJSON(request); err != nil {
+		outputGraphqlError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	data, err := services.ExecuteDomainGraphql(request, shared.GetAccessibleProjects(c))
+	if err != nil {
+		outputGraphqlError(c, err)
+		return
+	}
+	shared.ApiOutputSuccess(c, &graphql.Response{Data: data}, http.StatusOK)
+}
+
+// @Summary Get the GraphQL schema of the domain layer
+// @Description The schema in the schema definition language, the same schema is available through introspection
+// @Tags framework/domainlayer
+// @Produce plain
+// @Success 200  {string} string "schema document"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /domainlayer/graphql/schema [get]
+func GetGraphqlSchema(c *gin.Context) {
+	sdl, err := services.GetDomainGraphqlSchema()
+	if err != nil {
+		shared.ApiOutputError(c, err)
+		return
+	}
+	c.String(http.StatusOK, sdl)
+}
+
+func outputGraphqlError(c *gin.Context, err errors.Error) {
+	c.JSON(err.GetType().GetHttpCode(), &graphql.Response{
+		Errors: []*graphql.ResponseError{{Message: err.Messages().Format()}},
+	})
+}
@@ -0,0 +1,55 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package encryption
+
+import (
+	"net/http"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Rotate the ENCRYPTION_SECRET
+// @Description Re-encrypt all encrypted fields (connection credentials, pipeline plans, task options...) with the new secret.
+// @Description The running instance switches to the new secret immediately, ENCRYPTION_SECRET must be updated before the next restart.
+// @Description Tables are re-encrypted batch by batch with the progress recorded, an interrupted rotation could be resumed by posting the same new secret again.
+// @Description Api keys are hashed with the secret and can't be migrated, the rotation is refused until they are deleted, recreate them afterward.
+// @Tags framework/encryption
+// @Accept application/json
+// @Param rotation body services.EncryptionSecretRotation true "json"
+// @Success 200  {object} services.EncryptionSecretRotationResult
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 409  {string} errcode.Error "Pipelines are running, api keys exist or another rotation was interrupted"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /encryption-secret/rotate [post]
+func PostRotate(c *gin.Context) {
+	rotation := &services.EncryptionSecretRotation{}
+	err := c.ShouldBindJSON(rotation)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	result, err := services.RotateEncryptionSecret(rotation)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error rotating encryption secret"))
+		return
+	}
+	shared.ApiOutputSuccess(c, result, http.StatusOK)
+}
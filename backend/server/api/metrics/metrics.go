@@ -23,7 +23,10 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/utils"
 	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
 )
 
 // MetricPoint represents a single metric data point
@@ -98,6 +101,9 @@ type AlertInfo struct {
 
 // GetOverviewMetrics returns dashboard overview KPIs
 func GetOverviewMetrics(c *gin.Context) {
+	if !rejectTeamFilter(c) {
+		return
+	}
 	// Generate sample overview metrics
 	// In a real implementation, this would fetch from Grafana/Prometheus
 	overview := OverviewMetrics{}
@@ -131,6 +137,9 @@ func GetOverviewMetrics(c *gin.Context) {
 
 // GetToolMetrics returns tool-specific metrics
 func GetToolMetrics(c *gin.Context) {
+	if !rejectTeamFilter(c) {
+		return
+	}
 	tool := c.Param("tool")
 	
 	// Generate sample tool metrics
@@ -165,8 +174,12 @@ func GetToolMetrics(c *gin.Context) {
 	shared.ApiOutputSuccess(c, toolMetrics, http.StatusOK)
 }
 
-// GetAlerts returns alert history with filtering
+// GetAlerts returns alert history with filtering, alerts are filtered by the `team` label if teamId was specified
 func GetAlerts(c *gin.Context) {
+	teamIds, ok := parseTeamFilter(c)
+	if !ok {
+		return
+	}
 	// Parse query parameters
 	pageStr := c.DefaultQuery("page", "1")
 	limitStr := c.DefaultQuery("limit", "20")
@@ -179,7 +192,7 @@ func GetAlerts(c *gin.Context) {
 	
 	// Generate sample alerts
 	// In a real implementation, this would fetch from the database
-	alerts := generateSampleAlerts(page, limit, severity, status, source)
+	alerts := generateSampleAlerts(page, limit, severity, status, source, teamIds)
 	
 	response := map[string]interface{}{
 		"alerts": alerts,
@@ -195,6 +208,9 @@ func GetAlerts(c *gin.Context) {
 
 // ExportMetrics exports metrics data in the requested format
 func ExportMetrics(c *gin.Context) {
+	if !rejectTeamFilter(c) {
+		return
+	}
 	format := c.DefaultQuery("format", "json")
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
@@ -248,6 +264,31 @@ func ExportMetrics(c *gin.Context) {
 	shared.ApiOutputSuccess(c, exportData, http.StatusOK)
 }
 
+// parseTeamFilter resolves the teamId query to the ids of the team and its sub-teams through team_closures,
+// nil is returned if teamId was not specified, and false if the error was responded
+func parseTeamFilter(c *gin.Context) ([]string, bool) {
+	teamId := c.Query("teamId")
+	if teamId == "" {
+		return nil, true
+	}
+	teamIds, err := services.GetTeamSubtreeIds(teamId)
+	if err != nil {
+		shared.ApiOutputError(c, err)
+		return nil, false
+	}
+	return teamIds, true
+}
+
+// rejectTeamFilter responds an error if teamId was specified to a metric without the team dimension,
+// false is returned if the error was responded
+func rejectTeamFilter(c *gin.Context) bool {
+	if c.Query("teamId") != "" {
+		shared.ApiOutputError(c, errors.BadInput.New("teamId is only supported by /metrics/alerts"))
+		return false
+	}
+	return true
+}
+
 // generateSampleData generates sample time series data
 func generateSampleData(points int, min, max float64) []MetricPoint {
 	data := make([]MetricPoint, points)
@@ -263,7 +304,7 @@ func generateSampleData(points int, min, max float64) []MetricPoint {
 }
 
 // generateSampleAlerts generates sample alert data
-func generateSampleAlerts(page, limit int, severity, status, source string) []AlertInfo {
+func generateSampleAlerts(page, limit int, severity, status, source string, teamIds []string) []AlertInfo {
 	alerts := []AlertInfo{
 		{
 			ID:          "alert-001",
@@ -308,6 +349,9 @@ func generateSampleAlerts(page, limit int, severity, status, source string) []Al
 		if source != "" && alert.Source != source {
 			continue
 		}
+		if teamIds != nil && !utils.StringsContains(teamIds, alert.Labels["team"]) {
+			continue
+		}
 		filtered = append(filtered, alert)
 	}
 	
@@ -18,6 +18,7 @@ limitations under the License.
 package api
 
 import (
+	gocontext "context"
 	"encoding/base64"
 	"fmt"
 	"github.com/apache/incubator-devlake/core/log"
@@ -34,15 +35,33 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// apiKeyUserKey carries the user of the api key in the request context, since gin clears the keys of
+// the gin context when RestAuthentication dispatches the request again
+type apiKeyUserKey struct{}
+
+// restoreApiKeyUser sets the user authenticated by the api key back to the gin context
+func restoreApiKeyUser(c *gin.Context) {
+	if user, ok := c.Request.Context().Value(apiKeyUserKey{}).(*common.User); ok {
+		c.Set(common.USER, user)
+	}
+}
+
 func getOAuthUserInfo(c *gin.Context) (*common.User, error) {
 	if c == nil {
 		return nil, errors.Default.New("request is nil")
 	}
 	user := c.GetHeader("X-Forwarded-User")
 	email := c.GetHeader("X-Forwarded-Email")
+	var groups []string
+	for _, group := range strings.Split(c.GetHeader("X-Forwarded-Groups"), ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
 	return &common.User{
-		Name:  user,
-		Email: email,
+		Name:   user,
+		Email:  email,
+		Groups: groups,
 	}, nil
 }
 
@@ -75,6 +94,7 @@ func getBasicAuthUserInfo(c *gin.Context, basicRes context.BasicRes) (*common.Us
 func OAuth2ProxyAuthentication(basicRes context.BasicRes) gin.HandlerFunc {
 	logger := basicRes.GetLogger()
 	return func(c *gin.Context) {
+		restoreApiKeyUser(c)
 		_, exist := c.Get(common.USER)
 		if !exist {
 			user, err := getOAuthUserInfo(c)
@@ -89,6 +109,7 @@ func OAuth2ProxyAuthentication(basicRes context.BasicRes) gin.HandlerFunc {
 				}
 			}
 			if user != nil && user.Name != "" {
+				user.SourceIp = c.ClientIP()
 				c.Set(common.USER, user)
 			}
 		}
@@ -118,12 +139,22 @@ func RestAuthentication(router *gin.Engine, basicRes context.BasicRes) gin.Handl
 			return
 		}
 		path = strings.TrimPrefix(path, "/rest")
+		if isPublicPath(c.Request.Method, path) {
+			// GitHub and GitLab can't send an api key, the webhook event receivers verify the payload signature instead
+			c.Request.URL.Path = path
+			router.HandleContext(c)
+			c.Abort()
+			return
+		}
 		authHeader := c.GetHeader("Authorization")
 		ok := CheckAuthorizationHeader(c, logger, db, apiKeyHelper, authHeader, path)
 		if !ok {
 			c.Abort()
 			return
 		} else {
+			if user, exist := c.Get(common.USER); exist {
+				c.Request = c.Request.WithContext(gocontext.WithValue(c.Request.Context(), apiKeyUserKey{}, user))
+			}
 			router.HandleContext(c)
 			c.Abort()
 			return
@@ -208,8 +239,10 @@ func CheckAuthorizationHeader(c *gin.Context, logger log.Logger, db dal.Dal, api
 	logger.Info("redirect path: %s to: %s", c.Request.URL.Path, path)
 	c.Request.URL.Path = path
 	c.Set(common.USER, &common.User{
-		Name:  apiKey.Creator.Creator,
-		Email: apiKey.Creator.CreatorEmail,
+		Name:       apiKey.Creator.Creator,
+		Email:      apiKey.Creator.CreatorEmail,
+		ApiKeyName: apiKey.Name,
+		SourceIp:   c.ClientIP(),
 	})
 	return true
 }
@@ -0,0 +1,70 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/config"
+	"github.com/apache/incubator-devlake/core/dal"
+	contextimpl "github.com/apache/incubator-devlake/impls/context"
+	"github.com/apache/incubator-devlake/impls/logruslog"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestAuthenticationWebhookEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("ENCRYPTION_SECRET", "test-secret")
+	// the database is never reached when no api key is checked
+	basicRes := contextimpl.NewDefaultBasicRes(config.GetConfig(), logruslog.Global, struct{ dal.Dal }{})
+	router := gin.New()
+	router.Use(RestAuthentication(router, basicRes))
+	ok := func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	}
+	router.POST("/plugins/github/connections/:connectionId/webhook-events", ok)
+	router.POST("/plugins/github/connections/:connectionId/scopes", ok)
+	router.PUT("/store/:storeKey", ok)
+	router.DELETE("/projects/:projectName", ok)
+	router.GET("/plugins/github/connections/:connectionId/:resource", ok)
+
+	// the signature is the credential of the webhook events
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/rest/plugins/github/connections/1/webhook-events", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	// any other open api still requires an api key
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/rest/plugins/github/connections/1/scopes", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// route params named webhook-events are not exempted
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPut, "/rest/store/webhook-events", nil),
+		httptest.NewRequest(http.MethodDelete, "/rest/projects/webhook-events", nil),
+		httptest.NewRequest(http.MethodGet, "/rest/plugins/github/connections/1/webhook-events", nil),
+		httptest.NewRequest(http.MethodPost, "/rest/plugins/github/connections/by-name/webhook-events", nil),
+	} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, req.Method+" "+req.URL.Path)
+	}
+}
@@ -49,7 +49,8 @@ func Post(c *gin.Context) {
 		return
 	}
 
-	pipeline, err := services.CreatePipeline(newPipeline, true)
+	user, _ := shared.GetUser(c)
+	pipeline, err := services.CreatePipeline(user, newPipeline, true)
 	// Return all created tasks to the User
 	if err != nil {
 		shared.ApiOutputError(c, errors.Default.Wrap(err, "error creating pipeline"))
@@ -78,6 +79,7 @@ func Index(c *gin.Context) {
 		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
 		return
 	}
+	query.ProjectNames = shared.GetAccessibleProjects(c)
 	pipelines, count, err := services.GetPipelines(&query, true)
 	if err != nil {
 		shared.ApiOutputError(c, errors.Default.Wrap(err, "error getting pipelines"))
@@ -184,7 +186,8 @@ func PostRerun(c *gin.Context) {
 		shared.ApiOutputError(c, errors.BadInput.Wrap(err, "bad pipelineID format supplied"))
 		return
 	}
-	rerunTasks, err := services.RerunPipeline(id, nil)
+	user, _ := shared.GetUser(c)
+	rerunTasks, err := services.RerunPipeline(user, id, nil)
 	if err != nil {
 		shared.ApiOutputError(c, errors.Default.Wrap(err, "failed to rerun pipeline"))
 		return
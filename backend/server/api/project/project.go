@@ -92,6 +92,7 @@ func GetProjects(c *gin.Context) {
 		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
 		return
 	}
+	query.ProjectNames = shared.GetAccessibleProjects(c)
 	projects, count, err := services.GetProjects(&query)
 	if err != nil {
 		shared.ApiOutputAbort(c, errors.Default.Wrap(err, "error getting projects"))
@@ -125,7 +126,8 @@ func PostProject(c *gin.Context) {
 		return
 	}
 
-	projectOutput, err := services.CreateProject(projectInput)
+	user, _ := shared.GetUser(c)
+	projectOutput, err := services.CreateProject(user, projectInput)
 	if err != nil {
 		shared.ApiOutputError(c, errors.Default.Wrap(err, "error creating project"))
 		return
@@ -153,7 +155,8 @@ func PatchProject(c *gin.Context) {
 		return
 	}
 
-	projectOutput, err := services.PatchProject(projectName, body)
+	user, _ := shared.GetUser(c)
+	projectOutput, err := services.PatchProject(user, projectName, body)
 	if err != nil {
 		shared.ApiOutputError(c, errors.Default.Wrap(err, "error patch project"))
 		return
@@ -172,7 +175,8 @@ func PatchProject(c *gin.Context) {
 // @Router /projects/:projectName [delete]
 func DeleteProject(c *gin.Context) {
 	projectName := c.Param("projectName")
-	err := services.DeleteProject(projectName)
+	user, _ := shared.GetUser(c)
+	err := services.DeleteProject(user, projectName)
 	if err != nil {
 		shared.ApiOutputError(c, errors.Default.Wrap(err, "error deleting project"))
 		return
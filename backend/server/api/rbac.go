@@ -0,0 +1,290 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+// rbacRule describes the role required by a route
+type rbacRule struct {
+	role string
+	// projects resolves the projects the request works on, the role is required on all of them
+	projects func(c *gin.Context) ([]string, errors.Error)
+	// anyProject requires the role on at least one project, the handler narrows down the result
+	anyProject bool
+}
+
+var rbacRules = map[string]rbacRule{
+	"GET /pipelines":                            {role: models.ROLE_VIEWER, anyProject: true},
+	"POST /pipelines":                           {role: models.ROLE_ADMIN},
+	"GET /pipelines/:pipelineId":                {role: models.ROLE_VIEWER, projects: pipelineProjects},
+	"DELETE /pipelines/:pipelineId":             {role: models.ROLE_PROJECT_MAINTAINER, projects: pipelineProjects},
+	"GET /pipelines/:pipelineId/tasks":          {role: models.ROLE_VIEWER, projects: pipelineProjects},
+	"GET /pipelines/:pipelineId/subtasks":       {role: models.ROLE_VIEWER, projects: pipelineProjects},
+	"POST /pipelines/:pipelineId/rerun":         {role: models.ROLE_PROJECT_MAINTAINER, projects: pipelineProjects},
+	"GET /pipelines/:pipelineId/logging.tar.gz": {role: models.ROLE_VIEWER, projects: pipelineProjects},
+
+	"GET /blueprints":                        {role: models.ROLE_VIEWER, anyProject: true},
+	"POST /blueprints":                       {role: models.ROLE_PROJECT_MAINTAINER, projects: bodyProjects},
+	"GET /blueprints/:blueprintId":           {role: models.ROLE_VIEWER, projects: blueprintProjects},
+	"PATCH /blueprints/:blueprintId":         {role: models.ROLE_PROJECT_MAINTAINER, projects: blueprintProjects},
+	"DELETE /blueprints/:blueprintId":        {role: models.ROLE_PROJECT_MAINTAINER, projects: blueprintProjects},
+	"POST /blueprints/:blueprintId/trigger":  {role: models.ROLE_PROJECT_MAINTAINER, projects: blueprintProjects},
+	"GET /blueprints/:blueprintId/pipelines": {role: models.ROLE_VIEWER, projects: blueprintProjects},
+	"POST /tasks/:taskId/rerun":              {role: models.ROLE_PROJECT_MAINTAINER, projects: taskProjects},
+
+	"GET /projects":                    {role: models.ROLE_VIEWER, anyProject: true},
+	"POST /projects":                   {role: models.ROLE_ADMIN},
+	"GET /projects/:projectName":       {role: models.ROLE_VIEWER, projects: paramProjects},
+	"GET /projects/:projectName/check": {role: models.ROLE_VIEWER, projects: paramProjects},
+	"PATCH /projects/:projectName":     {role: models.ROLE_PROJECT_MAINTAINER, projects: paramProjects},
+	"DELETE /projects/:projectName":    {role: models.ROLE_ADMIN},
+
+	"GET /domainlayer/repos":   {role: models.ROLE_VIEWER, anyProject: true},
+	"GET /plugininfo":          {role: models.ROLE_VIEWER, anyProject: true},
+	"GET /plugins":             {role: models.ROLE_VIEWER, anyProject: true},
+	"GET /store/:storeKey":     {role: models.ROLE_VIEWER, anyProject: true},
+	"GET /metrics/overview":    {role: models.ROLE_VIEWER, anyProject: true},
+	"GET /metrics/tools/:tool": {role: models.ROLE_VIEWER, anyProject: true},
+	"GET /metrics/alerts":      {role: models.ROLE_VIEWER, anyProject: true},
+	"GET /metrics/export":      {role: models.ROLE_VIEWER, anyProject: true},
+
+	"GET /domainlayer/projects/:projectName/:entity": {role: models.ROLE_VIEWER, projects: paramProjects},
+	"POST /domainlayer/graphql":                      {role: models.ROLE_VIEWER, anyProject: true},
+	"GET /domainlayer/graphql/schema":                {role: models.ROLE_VIEWER, anyProject: true},
+}
+
+// RbacAuthorization rejects requests of users without the role required by the route when RBAC_ENABLED is on.
+// Framework routes without a rule and all role binding management are reserved for admins.
+func RbacAuthorization(basicRes context.BasicRes) gin.HandlerFunc {
+	logger := basicRes.GetLogger()
+	return func(c *gin.Context) {
+		fullPath := c.FullPath()
+		if !services.IsRbacEnabled() || fullPath == "" || isPublicRoute(c.Request.Method, fullPath) {
+			c.Next()
+			return
+		}
+		user, exist := shared.GetUser(c)
+		if !exist {
+			shared.ApiOutputError(c, errors.Unauthorized.New("authentication is required"))
+			c.Abort()
+			return
+		}
+		roles, err := services.GetUserRoles(user)
+		if err != nil {
+			shared.ApiOutputError(c, err)
+			c.Abort()
+			return
+		}
+		shared.SetAccessibleProjects(c, roles.AccessibleProjects())
+		rule := getRbacRule(c.Request.Method, fullPath)
+		allowed, err := checkRbacRule(c, roles, rule)
+		if err != nil {
+			shared.ApiOutputError(c, err)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			logger.Info("user %s is not allowed to %s %s", user.Name, c.Request.Method, c.Request.URL.Path)
+			shared.ApiOutputError(c, errors.Forbidden.New(fmt.Sprintf("role %s is required", rule.role)))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// webhookEventsRoutes receive the events of GitHub and GitLab, which authenticate the sender by verifying
+// the payload signature themselves
+var webhookEventsRoutes = map[string]bool{
+	"POST /plugins/github/connections/:connectionId/webhook-events": true,
+	"POST /plugins/gitlab/connections/:connectionId/webhook-events": true,
+}
+
+// webhookEventsPathPattern matches the request paths of the webhookEventsRoutes before they are routed
+var webhookEventsPathPattern = regexp.MustCompile(`^/plugins/(github|gitlab)/connections/\d+/webhook-events$`)
+
+// isPublicRoute tells if the route is open to everyone: the api documents and the webhook event receivers
+func isPublicRoute(method, fullPath string) bool {
+	return isApiDocPath(fullPath) || webhookEventsRoutes[method+" "+fullPath]
+}
+
+// isPublicPath is isPublicRoute for the request path, for the middlewares running before the request is routed
+func isPublicPath(method, path string) bool {
+	return isApiDocPath(path) || (method == http.MethodPost && webhookEventsPathPattern.MatchString(path))
+}
+
+// isApiDocPath tells if the path is under the catch-all routes of the api documents
+func isApiDocPath(path string) bool {
+	return strings.HasPrefix(path, "/swagger/") || strings.HasPrefix(path, "/plugins/swagger/")
+}
+
+func getRbacRule(method, fullPath string) rbacRule {
+	if rule, ok := rbacRules[method+" "+fullPath]; ok {
+		return rule
+	}
+	if strings.HasPrefix(fullPath, "/plugins/") {
+		// plugin resources are bound to projects through the connections used by blueprints
+		if strings.Contains(fullPath, "/:connectionId") || strings.Contains(fullPath, "/:connectionName") {
+			if method == http.MethodGet {
+				return rbacRule{role: models.ROLE_VIEWER, projects: connectionProjects, anyProject: true}
+			}
+			return rbacRule{role: models.ROLE_PROJECT_MAINTAINER, projects: connectionProjects}
+		}
+		if strings.Contains(fullPath, "/:projectName") {
+			if method == http.MethodGet {
+				return rbacRule{role: models.ROLE_VIEWER, projects: paramProjects}
+			}
+			return rbacRule{role: models.ROLE_PROJECT_MAINTAINER, projects: paramProjects}
+		}
+		if method == http.MethodGet {
+			return rbacRule{role: models.ROLE_VIEWER, anyProject: true}
+		}
+		// writes not bound to a project, e.g. creating connections or importing teams, affect all the projects
+		return rbacRule{role: models.ROLE_ADMIN}
+	}
+	return rbacRule{role: models.ROLE_ADMIN}
+}
+
+func checkRbacRule(c *gin.Context, roles *services.UserRoles, rule rbacRule) (bool, errors.Error) {
+	if roles.Admin {
+		return true, nil
+	}
+	if rule.role == models.ROLE_ADMIN {
+		return false, nil
+	}
+	if rule.projects == nil {
+		return roles.HasRoleInAnyProject(rule.role), nil
+	}
+	projectNames, err := rule.projects(c)
+	if err != nil {
+		return false, err
+	}
+	// resources not bound to any project yet are managed by the admins
+	if len(projectNames) == 0 {
+		return false, nil
+	}
+	for _, projectName := range projectNames {
+		hasRole := roles.HasRole(projectName, rule.role)
+		if rule.anyProject && hasRole {
+			return true, nil
+		}
+		if !rule.anyProject && !hasRole {
+			return false, nil
+		}
+	}
+	return !rule.anyProject, nil
+}
+
+func parseIdParam(c *gin.Context, name string) (uint64, errors.Error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		return 0, errors.BadInput.Wrap(err, fmt.Sprintf("bad %s format supplied", name))
+	}
+	return id, nil
+}
+
+func nonEmptyProjects(projectName string) []string {
+	if projectName == "" {
+		return nil
+	}
+	return []string{projectName}
+}
+
+func pipelineProjects(c *gin.Context) ([]string, errors.Error) {
+	id, err := parseIdParam(c, "pipelineId")
+	if err != nil {
+		return nil, err
+	}
+	projectName, err := services.GetPipelineProjectName(id)
+	return nonEmptyProjects(projectName), err
+}
+
+func blueprintProjects(c *gin.Context) ([]string, errors.Error) {
+	id, err := parseIdParam(c, "blueprintId")
+	if err != nil {
+		return nil, err
+	}
+	projectName, err := services.GetBlueprintProjectName(id)
+	return nonEmptyProjects(projectName), err
+}
+
+func taskProjects(c *gin.Context) ([]string, errors.Error) {
+	id, err := parseIdParam(c, "taskId")
+	if err != nil {
+		return nil, err
+	}
+	projectName, err := services.GetTaskProjectName(id)
+	return nonEmptyProjects(projectName), err
+}
+
+func paramProjects(c *gin.Context) ([]string, errors.Error) {
+	return nonEmptyProjects(c.Param("projectName")), nil
+}
+
+// bodyProjects peeks the projectName from the json body and restores the body for the handler
+func bodyProjects(c *gin.Context) ([]string, errors.Error) {
+	if c.Request.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, errors.BadInput.Wrap(err, shared.BadRequestBody)
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	payload := &struct {
+		ProjectName string `json:"projectName"`
+	}{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, payload); err != nil {
+			return nil, errors.BadInput.Wrap(err, shared.BadRequestBody)
+		}
+	}
+	return nonEmptyProjects(payload.ProjectName), nil
+}
+
+// connectionProjects resolves the projects of the connection referred by either its id or its name
+func connectionProjects(c *gin.Context) ([]string, errors.Error) {
+	pluginName := strings.SplitN(strings.TrimPrefix(c.FullPath(), "/plugins/"), "/", 2)[0]
+	var id uint64
+	var err errors.Error
+	if connectionName := c.Param("connectionName"); connectionName != "" {
+		id, err = services.GetConnectionIdByName(pluginName, connectionName)
+	} else {
+		id, err = parseIdParam(c, "connectionId")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return services.GetConnectionProjectNames(pluginName, id)
+}
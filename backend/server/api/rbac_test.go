@@ -0,0 +1,66 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRbacRule(t *testing.T) {
+	rule := getRbacRule(http.MethodGet, "/plugins/github/connections")
+	assert.Equal(t, models.ROLE_VIEWER, rule.role)
+	assert.True(t, rule.anyProject)
+
+	rule = getRbacRule(http.MethodPatch, "/plugins/github/connections/:connectionId")
+	assert.Equal(t, models.ROLE_PROJECT_MAINTAINER, rule.role)
+	assert.NotNil(t, rule.projects)
+	assert.False(t, rule.anyProject)
+
+	rule = getRbacRule(http.MethodPost, "/plugins/dora/projects/:projectName/metrics")
+	assert.Equal(t, models.ROLE_PROJECT_MAINTAINER, rule.role)
+	assert.NotNil(t, rule.projects)
+
+	// writes not bound to any project are reserved for admins
+	for _, route := range []string{
+		"POST /plugins/github/connections",
+		"POST /plugins/github/test",
+		"PUT /plugins/org/teams.csv",
+		"PUT /plugins/org/users.csv",
+		"POST /plugins/customize/csvfiles/issues.csv",
+	} {
+		method, fullPath, _ := strings.Cut(route, " ")
+		assert.Equal(t, models.ROLE_ADMIN, getRbacRule(method, fullPath).role, route)
+	}
+
+	rule = getRbacRule(http.MethodPost, "/pipelines")
+	assert.Equal(t, models.ROLE_ADMIN, rule.role)
+	rule = getRbacRule(http.MethodDelete, "/users/:userId")
+	assert.Equal(t, models.ROLE_ADMIN, rule.role)
+}
+
+func TestIsPublicRoute(t *testing.T) {
+	assert.True(t, isPublicRoute(http.MethodPost, "/plugins/github/connections/:connectionId/webhook-events"))
+	assert.True(t, isPublicRoute(http.MethodGet, "/swagger/*any"))
+	assert.False(t, isPublicRoute(http.MethodPut, "/store/:storeKey"))
+	assert.False(t, isPublicRoute(http.MethodGet, "/plugins/github/connections/:connectionId/webhook-events"))
+}
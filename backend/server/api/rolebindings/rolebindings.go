@@ -0,0 +1,116 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rolebindings
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PaginatedRoleBindings struct {
+	RoleBindings []*models.RoleBinding `json:"roleBindings"`
+	Count        int64                 `json:"count"`
+}
+
+// @Summary Get list of role bindings
+// @Description GET /role-bindings?subject=alice&projectName=xxx&page=1&pageSize=10
+// @Tags framework/role-bindings
+// @Param subject query string false "subject"
+// @Param projectName query string false "projectName"
+// @Param page query int false "query"
+// @Param pageSize query int false "query"
+// @Success 200  {object} PaginatedRoleBindings
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /role-bindings [get]
+func GetRoleBindings(c *gin.Context) {
+	var query services.RoleBindingQuery
+	err := c.ShouldBindQuery(&query)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	roleBindings, count, err := services.GetRoleBindings(&query)
+	if err != nil {
+		shared.ApiOutputAbort(c, errors.Default.Wrap(err, "error getting role bindings"))
+		return
+	}
+	shared.ApiOutputSuccess(c, PaginatedRoleBindings{
+		RoleBindings: roleBindings,
+		Count:        count,
+	}, http.StatusOK)
+}
+
+// @Summary Create a role binding
+// @Description Grant a role to a user or a group, admin is granted globally while project-maintainer and viewer require a projectName
+// @Tags framework/role-bindings
+// @Accept application/json
+// @Param roleBinding body models.RoleBinding true "json"
+// @Success 201  {object} models.RoleBinding
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /role-bindings [post]
+func PostRoleBinding(c *gin.Context) {
+	roleBinding := &models.RoleBinding{}
+	err := c.ShouldBindJSON(roleBinding)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, shared.BadRequestBody))
+		return
+	}
+	roleBinding.Creator = common.Creator{}
+	user, exist := shared.GetUser(c)
+	if exist {
+		roleBinding.Creator.Creator = user.Name
+		roleBinding.Creator.CreatorEmail = user.Email
+	}
+	err = services.CreateRoleBinding(user, roleBinding)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error creating role binding"))
+		return
+	}
+	shared.ApiOutputSuccess(c, roleBinding, http.StatusCreated)
+}
+
+// @Summary Delete a role binding
+// @Description Revoke a role binding
+// @Tags framework/role-bindings
+// @Success 200
+// @Failure 400  {string} errcode.Error "Bad Request"
+// @Failure 500  {string} errcode.Error "Internal Error"
+// @Router /role-bindings/{roleBindingId} [delete]
+func DeleteRoleBinding(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("roleBindingId"), 10, 64)
+	if err != nil {
+		shared.ApiOutputError(c, errors.BadInput.Wrap(err, "bad roleBindingId format supplied"))
+		return
+	}
+	user, _ := shared.GetUser(c)
+	err = services.DeleteRoleBinding(user, id)
+	if err != nil {
+		shared.ApiOutputError(c, errors.Default.Wrap(err, "error deleting role binding"))
+		return
+	}
+	shared.ApiOutputSuccess(c, nil, http.StatusOK)
+}
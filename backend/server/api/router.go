@@ -18,163 +18,260 @@ limitations under the License.
 package api
 
 import (
-        "fmt"
-        "net/http"
-        "strings"
-
-        "github.com/apache/incubator-devlake/core/context"
-        "github.com/apache/incubator-devlake/core/errors"
-        "github.com/apache/incubator-devlake/impls/logruslog"
-        "github.com/apache/incubator-devlake/server/api/apikeys"
-        "github.com/apache/incubator-devlake/server/api/store"
-
-        "github.com/apache/incubator-devlake/core/plugin"
-        "github.com/apache/incubator-devlake/server/api/blueprints"
-        "github.com/apache/incubator-devlake/server/api/domainlayer"
-        "github.com/apache/incubator-devlake/server/api/pipelines"
-        "github.com/apache/incubator-devlake/server/api/plugininfo"
-        "github.com/apache/incubator-devlake/server/api/project"
-        "github.com/apache/incubator-devlake/server/api/push"
-        "github.com/apache/incubator-devlake/server/api/shared"
-        "        "github.com/apache/incubator-devlake/server/api/task"
-        "github.com/apache/incubator-devlake/server/api/metrics""
-        "github.com/apache/incubator-devlake/server/services"
-
-        "github.com/gin-gonic/gin"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/apache/incubator-devlake/core/context"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/impls/logruslog"
+	"github.com/apache/incubator-devlake/server/api/apikeys"
+	"github.com/apache/incubator-devlake/server/api/auditevents"
+	"github.com/apache/incubator-devlake/server/api/store"
+
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/server/api/blueprints"
+	"github.com/apache/incubator-devlake/server/api/domainlayer"
+	"github.com/apache/incubator-devlake/server/api/encryption"
+	"github.com/apache/incubator-devlake/server/api/metrics"
+	"github.com/apache/incubator-devlake/server/api/pipelines"
+	"github.com/apache/incubator-devlake/server/api/plugininfo"
+	"github.com/apache/incubator-devlake/server/api/project"
+	"github.com/apache/incubator-devlake/server/api/push"
+	"github.com/apache/incubator-devlake/server/api/rolebindings"
+	"github.com/apache/incubator-devlake/server/api/shared"
+	"github.com/apache/incubator-devlake/server/api/task"
+	"github.com/apache/incubator-devlake/server/services"
+
+	"github.com/gin-gonic/gin"
 )
 
 func RegisterRouter(r *gin.Engine, basicRes context.BasicRes) {
-        r.GET("/pipelines", pipelines.Index)
-        r.POST("/pipelines", pipelines.Post)
-        r.GET("/pipelines/:pipelineId", pipelines.Get)
-        r.DELETE("/pipelines/:pipelineId", pipelines.Delete)
-        r.GET("/pipelines/:pipelineId/tasks", task.GetTaskByPipeline)
-        r.GET("/pipelines/:pipelineId/subtasks", task.GetSubtaskByPipeline)
-        r.POST("/pipelines/:pipelineId/rerun", pipelines.PostRerun)
-        r.GET("/pipelines/:pipelineId/logging.tar.gz", pipelines.DownloadLogs)
-
-        r.GET("/blueprints", blueprints.Index)
-        r.POST("/blueprints", blueprints.Post)
-        r.PATCH("/blueprints/:blueprintId", blueprints.Patch)
-        r.DELETE("/blueprints/:blueprintId", blueprints.Delete)
-        r.GET("/blueprints/:blueprintId", blueprints.Get)
-        r.POST("/blueprints/:blueprintId/trigger", blueprints.Trigger)
-        r.GET("/blueprints/:blueprintId/pipelines", blueprints.GetBlueprintPipelines)
-
-        r.POST("/tasks/:taskId/rerun", task.PostRerun)
-
-        r.POST("/push/:tableName", push.Post)
-        r.GET("/domainlayer/repos", domainlayer.ReposIndex)
-
-        // plugin api
-        r.GET("/plugininfo", plugininfo.Get)
-        r.GET("/plugins", plugininfo.GetPluginMetas)
-
-        // project api
-        r.GET("/projects/:projectName", project.GetProject)
-        r.GET("/projects/:projectName/check", project.GetProjectCheck)
-        r.PATCH("/projects/:projectName", project.PatchProject)
-        r.DELETE("/projects/:projectName", project.DeleteProject)
-        r.POST("/projects", project.PostProject)
-        r.GET("/projects", project.GetProjects)
-        // on board api
-        r.GET("/store/:storeKey", store.GetStore)
-        r.PUT("/store/:storeKey", store.PutStore)
-
-        // api keys api
-        r.GET("/api-keys", apikeys.GetApiKeys)
-        r.POST("/api-keys", apikeys.PostApiKey)
-        r.PUT("/api-keys/:apiKeyId", apikeys.PutApiKey)
-        r.DELETE("/api-keys/:apiKeyId", apikeys.DeleteApiKey)
-        // metrics api for custom dashboard
-        r.GET("/metrics/overview", metrics.GetOverviewMetrics)
-        r.GET("/metrics/tools/:tool", metrics.GetToolMetrics)
-        r.GET("/metrics/alerts", metrics.GetAlerts)
-        r.GET("/metrics/export", metrics.ExportMetrics)
-
-        // mount all api resources for all plugins
-        resources, err := services.GetPluginsApiResources()
-        if err != nil {
-                panic(err)
-        }
-        // mount all api resources for all plugins
-        for pluginName, apiResources := range resources {
-                registerPluginEndpoints(r, basicRes, pluginName, apiResources)
-        }
+	r.GET("/pipelines", pipelines.Index)
+	r.POST("/pipelines", pipelines.Post)
+	r.GET("/pipelines/:pipelineId", pipelines.Get)
+	r.DELETE("/pipelines/:pipelineId", pipelines.Delete)
+	r.GET("/pipelines/:pipelineId/tasks", task.GetTaskByPipeline)
+	r.GET("/pipelines/:pipelineId/subtasks", task.GetSubtaskByPipeline)
+	r.POST("/pipelines/:pipelineId/rerun", pipelines.PostRerun)
+	r.GET("/pipelines/:pipelineId/logging.tar.gz", pipelines.DownloadLogs)
+
+	r.GET("/blueprints", blueprints.Index)
+	r.POST("/blueprints", blueprints.Post)
+	r.PATCH("/blueprints/:blueprintId", blueprints.Patch)
+	r.DELETE("/blueprints/:blueprintId", blueprints.Delete)
+	r.GET("/blueprints/:blueprintId", blueprints.Get)
+	r.POST("/blueprints/:blueprintId/trigger", blueprints.Trigger)
+	r.GET("/blueprints/:blueprintId/pipelines", blueprints.GetBlueprintPipelines)
+
+	r.POST("/tasks/:taskId/rerun", task.PostRerun)
+
+	r.POST("/push/:tableName", push.Post)
+	r.GET("/domainlayer/repos", domainlayer.ReposIndex)
+	r.GET("/domainlayer/projects/:projectName/:entity", domainlayer.EntitiesIndex)
+	r.POST("/domainlayer/graphql", domainlayer.PostGraphql)
+	r.GET("/domainlayer/graphql/schema", domainlayer.GetGraphqlSchema)
+
+	// plugin api
+	r.GET("/plugininfo", plugininfo.Get)
+	r.GET("/plugins", plugininfo.GetPluginMetas)
+
+	// project api
+	r.GET("/projects/:projectName", project.GetProject)
+	r.GET("/projects/:projectName/check", project.GetProjectCheck)
+	r.PATCH("/projects/:projectName", project.PatchProject)
+	r.DELETE("/projects/:projectName", project.DeleteProject)
+	r.POST("/projects", project.PostProject)
+	r.GET("/projects", project.GetProjects)
+	// on board api
+	r.GET("/store/:storeKey", store.GetStore)
+	r.PUT("/store/:storeKey", store.PutStore)
+
+	// api keys api
+	r.GET("/api-keys", apikeys.GetApiKeys)
+	r.POST("/api-keys", apikeys.PostApiKey)
+	r.PUT("/api-keys/:apiKeyId", apikeys.PutApiKey)
+	r.DELETE("/api-keys/:apiKeyId", apikeys.DeleteApiKey)
+
+	// role bindings api
+	r.GET("/role-bindings", rolebindings.GetRoleBindings)
+	r.POST("/role-bindings", rolebindings.PostRoleBinding)
+	r.DELETE("/role-bindings/:roleBindingId", rolebindings.DeleteRoleBinding)
+
+	// audit events api
+	r.GET("/audit-events", auditevents.GetAuditEvents)
+
+	// encryption api
+	r.POST("/encryption-secret/rotate", encryption.PostRotate)
+
+	// metrics api for custom dashboard
+	r.GET("/metrics/overview", metrics.GetOverviewMetrics)
+	r.GET("/metrics/tools/:tool", metrics.GetToolMetrics)
+	r.GET("/metrics/alerts", metrics.GetAlerts)
+	r.GET("/metrics/export", metrics.ExportMetrics)
+
+	// mount all api resources for all plugins
+	resources, err := services.GetPluginsApiResources()
+	if err != nil {
+		panic(err)
+	}
+	// mount all api resources for all plugins
+	for pluginName, apiResources := range resources {
+		registerPluginEndpoints(r, basicRes, pluginName, apiResources)
+	}
 }
 
 func registerPluginEndpoints(r *gin.Engine, basicRes context.BasicRes, pluginName string, apiResources map[string]map[string]plugin.ApiResourceHandler) {
-        for resourcePath, resourceHandlers := range apiResources {
-                for method, h := range resourceHandlers {
-                        r.Handle(
-                                method,
-                                fmt.Sprintf("/plugins/%s/%s", pluginName, resourcePath),
-                                handlePluginCall(basicRes, pluginName, h),
-                        )
-                }
-        }
+	for resourcePath, resourceHandlers := range apiResources {
+		for method, h := range resourceHandlers {
+			r.Handle(
+				method,
+				fmt.Sprintf("/plugins/%s/%s", pluginName, resourcePath),
+				handlePluginCall(basicRes, pluginName, resourcePath, h),
+			)
+		}
+	}
+}
+
+func handlePluginCall(basicRes context.BasicRes, pluginName string, resourcePath string, handler plugin.ApiResourceHandler) func(c *gin.Context) {
+	return func(c *gin.Context) {
+		var err errors.Error
+		input := &plugin.ApiResourceInput{}
+		input.Params = make(map[string]string)
+		if len(c.Params) > 0 {
+			for _, param := range c.Params {
+				input.Params[param.Key] = param.Value
+			}
+		}
+		input.Params["plugin"] = pluginName
+		input.Query = c.Request.URL.Query()
+		user, exist := shared.GetUser(c)
+		if !exist {
+			basicRes.GetLogger().Debug("user doesn't exist")
+		} else {
+			input.User = user
+		}
+		input.Request = c.Request
+		input.ClientIp = c.ClientIP()
+		if c.Request.Body != nil {
+			// only the webhook batch endpoints accept bodies which can't be bound, other bodies aren't peeked at
+			isBatch := pluginName == "webhook" && strings.HasSuffix(resourcePath, "/batch")
+			if !strings.HasPrefix(c.Request.Header.Get("Content-Type"), "multipart/form-data;") && !(isBatch && isRawJsonBody(c.Request)) {
+				// keep the raw bytes around, handlers verifying payload signatures need them exactly as sent
+				rawBody, readErr := io.ReadAll(c.Request.Body)
+				if readErr != nil {
+					shared.ApiOutputError(c, errors.BadInput.Wrap(readErr, "failed to read request body"))
+					return
+				}
+				c.Request.Body = io.NopCloser(bytes.NewReader(rawBody))
+				shouldBindJSONErr := c.ShouldBindJSON(&input.Body)
+				if shouldBindJSONErr != nil && shouldBindJSONErr.Error() != "EOF" {
+					shared.ApiOutputError(c, shouldBindJSONErr)
+					return
+				}
+				c.Request.Body = io.NopCloser(bytes.NewReader(rawBody))
+			}
+		}
+		output, err := handler(input)
+		if err == nil && output != nil && c.Request.Method == http.MethodGet && resourcePath == "connections" {
+			err = filterAccessibleConnections(c, pluginName, output)
+		}
+		if err != nil {
+			if output != nil && output.Body != nil {
+				logruslog.Global.Error(err, "")
+				shared.ApiOutputSuccess(c, output.Body, err.GetType().GetHttpCode())
+			} else {
+				shared.ApiOutputError(c, err)
+			}
+		} else if output != nil {
+			status := output.Status
+			if status < http.StatusContinue {
+				status = http.StatusOK
+			}
+			if output.Header != nil {
+				for k, vs := range output.Header {
+					for _, v := range vs {
+						c.Header(k, v)
+					}
+				}
+			}
+			if output.File != nil {
+				c.Data(status, output.File.ContentType, output.File.Data)
+				return
+			}
+			if blob, ok := output.Body.([]byte); ok && output.ContentType != "" {
+				c.Data(status, output.ContentType, blob)
+			} else {
+				shared.ApiOutputSuccess(c, output.Body, status)
+			}
+		} else {
+			shared.ApiOutputSuccess(c, nil, http.StatusOK)
+		}
+	}
+}
+
+// isRawJsonBody reports whether the body is a NDJSON stream or a top level JSON array, neither of which can be
+// bound to input.Body, so the handler has to read input.Request.Body itself. The body is left unconsumed.
+func isRawJsonBody(req *http.Request) bool {
+	contentType := req.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "application/x-ndjson") || strings.HasPrefix(contentType, "application/jsonl") {
+		return true
+	}
+	reader := bufio.NewReader(req.Body)
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{reader, req.Body}
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return false
+		}
+		if unicode.IsSpace(rune(b)) {
+			continue
+		}
+		_ = reader.UnreadByte()
+		return b == '['
+	}
 }
 
-func handlePluginCall(basicRes context.BasicRes, pluginName string, handler plugin.ApiResourceHandler) func(c *gin.Context) {
-        return func(c *gin.Context) {
-                var err errors.Error
-                input := &plugin.ApiResourceInput{}
-                input.Params = make(map[string]string)
-                if len(c.Params) > 0 {
-                        for _, param := range c.Params {
-                                input.Params[param.Key] = param.Value
-                        }
-                }
-                input.Params["plugin"] = pluginName
-                input.Query = c.Request.URL.Query()
-                user, exist := shared.GetUser(c)
-                if !exist {
-                        basicRes.GetLogger().Debug("user doesn't exist")
-                } else {
-                        input.User = user
-                }
-                if c.Request.Body != nil {
-                        if strings.HasPrefix(c.Request.Header.Get("Content-Type"), "multipart/form-data;") {
-                                input.Request = c.Request
-                        } else {
-                                shouldBindJSONErr := c.ShouldBindJSON(&input.Body)
-                                if shouldBindJSONErr != nil && shouldBindJSONErr.Error() != "EOF" {
-                                        shared.ApiOutputError(c, shouldBindJSONErr)
-                                        return
-                                }
-                        }
-                }
-                output, err := handler(input)
-                if err != nil {
-                        if output != nil && output.Body != nil {
-                                logruslog.Global.Error(err, "")
-                                shared.ApiOutputSuccess(c, output.Body, err.GetType().GetHttpCode())
-                        } else {
-                                shared.ApiOutputError(c, err)
-                        }
-                } else if output != nil {
-                        status := output.Status
-                        if status < http.StatusContinue {
-                                status = http.StatusOK
-                        }
-                        if output.Header != nil {
-                                for k, vs := range output.Header {
-                                        for _, v := range vs {
-                                                c.Header(k, v)
-                                        }
-                                }
-                        }
-                        if output.File != nil {
-                                c.Data(status, output.File.ContentType, output.File.Data)
-                                return
-                        }
-                        if blob, ok := output.Body.([]byte); ok && output.ContentType != "" {
-                                c.Data(status, output.ContentType, blob)
-                        } else {
-                                shared.ApiOutputSuccess(c, output.Body, status)
-                        }
-                } else {
-                        shared.ApiOutputSuccess(c, nil, http.StatusOK)
-                }
-        }
+// filterAccessibleConnections removes connections not used by the projects accessible to the user
+func filterAccessibleConnections(c *gin.Context, pluginName string, output *plugin.ApiResourceOutput) errors.Error {
+	projectNames := shared.GetAccessibleProjects(c)
+	if projectNames == nil {
+		return nil
+	}
+	connections := reflect.ValueOf(output.Body)
+	if connections.Kind() != reflect.Slice {
+		return nil
+	}
+	connectionIds, err := services.GetProjectsConnectionIds(pluginName, projectNames)
+	if err != nil {
+		return err
+	}
+	accessible := make(map[uint64]bool, len(connectionIds))
+	for _, id := range connectionIds {
+		accessible[id] = true
+	}
+	filtered := reflect.MakeSlice(connections.Type(), 0, connections.Len())
+	for i := 0; i < connections.Len(); i++ {
+		connection := reflect.Indirect(connections.Index(i))
+		if connection.Kind() == reflect.Interface {
+			connection = reflect.Indirect(connection.Elem())
+		}
+		if connection.Kind() != reflect.Struct {
+			continue
+		}
+		id := connection.FieldByName("ID")
+		if id.IsValid() && id.CanUint() && accessible[id.Uint()] {
+			filtered = reflect.Append(filtered, connections.Index(i))
+		}
+	}
+	output.Body = filtered.Interface()
+	return nil
 }
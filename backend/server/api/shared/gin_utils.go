@@ -30,3 +30,19 @@ func GetUser(c *gin.Context) (*common.User, bool) {
 	user := userObj.(*common.User)
 	return user, true
 }
+
+const accessibleProjectsKey = "accessibleProjects"
+
+// SetAccessibleProjects records the projects accessible to the user, nil means no restriction
+func SetAccessibleProjects(c *gin.Context, projectNames []string) {
+	c.Set(accessibleProjectsKey, projectNames)
+}
+
+// GetAccessibleProjects returns the projects accessible to the user, nil means no restriction
+func GetAccessibleProjects(c *gin.Context) []string {
+	projectNames, exist := c.Get(accessibleProjectsKey)
+	if !exist {
+		return nil
+	}
+	return projectNames.([]string)
+}
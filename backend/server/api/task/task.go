@@ -111,7 +111,8 @@ func PostRerun(c *gin.Context) {
 		shared.ApiOutputError(c, errors.BadInput.Wrap(err, "bad taskId format supplied"))
 		return
 	}
-	task, err := services.RerunTask(id)
+	user, _ := shared.GetUser(c)
+	task, err := services.RerunTask(user, id)
 	if err != nil {
 		shared.ApiOutputError(c, err)
 		return
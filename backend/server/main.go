@@ -18,10 +18,15 @@ limitations under the License.
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+
 	"github.com/apache/incubator-devlake/core/config"
 	"github.com/apache/incubator-devlake/core/plugin"
 	_ "github.com/apache/incubator-devlake/core/version"
 	"github.com/apache/incubator-devlake/server/api"
+	"github.com/apache/incubator-devlake/server/services"
 )
 
 func main() {
@@ -30,5 +35,25 @@ func main() {
 	if encryptionSecret == "" {
 		panic("ENCRYPTION_SECRET must be set in environment variable or .env file")
 	}
+	if len(os.Args) > 1 && os.Args[1] == "rotate-encryption-secret" {
+		rotateEncryptionSecret(v.GetString("NEW_ENCRYPTION_SECRET"))
+		return
+	}
 	api.CreateAndRunApiServer()
 }
+
+// rotateEncryptionSecret re-encrypts all encrypted fields with NEW_ENCRYPTION_SECRET while the server is stopped,
+// i.e. `NEW_ENCRYPTION_SECRET=xxx lake rotate-encryption-secret`
+func rotateEncryptionSecret(newSecret string) {
+	if newSecret == "" {
+		panic("NEW_ENCRYPTION_SECRET must be set in environment variable or .env file")
+	}
+	services.Init()
+	result, err := services.RotateEncryptionSecret(&services.EncryptionSecretRotation{NewSecret: newSecret})
+	if err != nil {
+		panic(err)
+	}
+	output, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(output))
+	fmt.Println("Done, please replace ENCRYPTION_SECRET with NEW_ENCRYPTION_SECRET before starting the server.")
+}
@@ -18,6 +18,8 @@ limitations under the License.
 package services
 
 import (
+	"fmt"
+
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models"
@@ -64,18 +66,26 @@ func GetApiKeys(query *ApiKeysQuery) ([]*models.ApiKey, int64, errors.Error) {
 	return apiKeys, count, nil
 }
 
-func DeleteApiKey(id uint64) errors.Error {
+func DeleteApiKey(user *common.User, id uint64) errors.Error {
 	// verify input
 	if id == 0 {
 		return errors.BadInput.New("api key's id is missing")
 	}
 
 	apiKeyHelper := apikeyhelper.NewApiKeyHelper(basicRes, logger)
-	err := apiKeyHelper.Delete(id)
+	before, err := apiKeyHelper.GetApiKey(nil, dal.Where("id = ?", id))
+	if err != nil {
+		if db.IsErrorNotFound(err) {
+			return errors.NotFound.New(fmt.Sprintf("api key %d not found", id))
+		}
+		return err
+	}
+	err = apiKeyHelper.Delete(id)
 	if err != nil {
 		logger.Error(err, "api key helper delete: %d", id)
 		return err
 	}
+	recordAuditEvent(user, models.AUDIT_ACTION_DELETE, models.AUDIT_TARGET_API_KEY, id, before.Name, before, nil)
 	return nil
 }
 
@@ -85,11 +95,19 @@ func PutApiKey(user *common.User, id uint64) (*models.ApiOutputApiKey, errors.Er
 		return nil, errors.BadInput.New("api key's id is missing")
 	}
 	apiKeyHelper := apikeyhelper.NewApiKeyHelper(basicRes, logger)
+	before, err := apiKeyHelper.GetApiKey(nil, dal.Where("id = ?", id))
+	if err != nil {
+		if db.IsErrorNotFound(err) {
+			return nil, errors.NotFound.New(fmt.Sprintf("api key %d not found", id))
+		}
+		return nil, err
+	}
 	apiKey, err := apiKeyHelper.Put(user, id)
 	if err != nil {
 		logger.Error(err, "api key helper put: %d", id)
 		return nil, err
 	}
+	recordAuditEvent(user, models.AUDIT_ACTION_UPDATE, models.AUDIT_TARGET_API_KEY, id, apiKey.Name, before, apiKey)
 	return apiKey, nil
 }
 
@@ -114,5 +132,6 @@ func CreateApiKey(user *common.User, apiKeyInput *models.ApiInputApiKey) (*model
 	if err := tx.Commit(); err != nil {
 		logger.Info("transaction commit: %s", err)
 	}
+	recordAuditEvent(user, models.AUDIT_ACTION_CREATE, models.AUDIT_TARGET_API_KEY, apiKey.ID, apiKey.Name, nil, apiKey)
 	return apiKey, nil
 }
@@ -0,0 +1,91 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
+)
+
+// AuditEventQuery used to query audit events
+type AuditEventQuery struct {
+	Pagination
+	Actor        string     `form:"actor"`
+	Action       string     `form:"action"`
+	TargetType   string     `form:"targetType"`
+	TargetPlugin string     `form:"targetPlugin"`
+	TargetId     string     `form:"targetId"`
+	Since        *time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until        *time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// GetAuditEvents returns a paginated list of audit events based on `query`, latest first
+func GetAuditEvents(query *AuditEventQuery) ([]*models.AuditEvent, int64, errors.Error) {
+	clauses := []dal.Clause{dal.From(&models.AuditEvent{})}
+	if query.Actor != "" {
+		clauses = append(clauses, dal.Where("actor = ?", query.Actor))
+	}
+	if query.Action != "" {
+		clauses = append(clauses, dal.Where("action = ?", query.Action))
+	}
+	if query.TargetType != "" {
+		clauses = append(clauses, dal.Where("target_type = ?", query.TargetType))
+	}
+	if query.TargetPlugin != "" {
+		clauses = append(clauses, dal.Where("target_plugin = ?", query.TargetPlugin))
+	}
+	if query.TargetId != "" {
+		clauses = append(clauses, dal.Where("target_id = ?", query.TargetId))
+	}
+	if query.Since != nil {
+		clauses = append(clauses, dal.Where("created_at >= ?", query.Since))
+	}
+	if query.Until != nil {
+		clauses = append(clauses, dal.Where("created_at < ?", query.Until))
+	}
+	count, err := db.Count(clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error getting DB count of audit events")
+	}
+	clauses = append(clauses,
+		dal.Orderby("id DESC"),
+		dal.Offset(query.GetSkip()),
+		dal.Limit(query.GetPageSize()),
+	)
+	events := make([]*models.AuditEvent, 0)
+	err = db.All(&events, clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error finding DB audit events")
+	}
+	return events, count, nil
+}
+
+// recordAuditEvent appends the audit event of a change made through the framework api
+func recordAuditEvent(user *common.User, action string, targetType string, targetId interface{}, targetName string, before, after interface{}) {
+	auditHelper.Record(user, &models.AuditEvent{
+		Action:     action,
+		TargetType: targetType,
+		TargetId:   fmt.Sprintf("%v", targetId),
+		TargetName: targetName,
+	}, before, after)
+}
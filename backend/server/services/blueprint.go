@@ -28,6 +28,8 @@ import (
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/helpers/audithelper"
 	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
 	"github.com/apache/incubator-devlake/impls/logruslog"
 	"github.com/robfig/cron/v3"
@@ -46,6 +48,8 @@ type BlueprintQuery struct {
 	Label    string `form:"label"`
 	// isManual must be omitted or `null` for type to take effect
 	Type string `form:"type" enums:"ALL,MANUAL,DAILY,WEEKLY,MONTHLY,CUSTOM" validate:"oneof=ALL MANUAL DAILY WEEKLY MONTHLY CUSTOM"`
+	// ProjectNames limits blueprints to the projects accessible to the user, nil for no limitation
+	ProjectNames []string `form:"-" json:"-"`
 }
 
 type BlueprintJob struct {
@@ -67,20 +71,25 @@ func (bj BlueprintJob) Run() {
 }
 
 // CreateBlueprint accepts a Blueprint instance and insert it to database
-func CreateBlueprint(blueprint *models.Blueprint) errors.Error {
+func CreateBlueprint(user *common.User, blueprint *models.Blueprint) errors.Error {
 	_, err := saveBlueprint(blueprint)
-	return err
+	if err != nil {
+		return err
+	}
+	recordAuditEvent(user, models.AUDIT_ACTION_CREATE, models.AUDIT_TARGET_BLUEPRINT, blueprint.ID, blueprint.Name, nil, blueprint)
+	return nil
 }
 
 // GetBlueprints returns a paginated list of Blueprints based on `query`
 func GetBlueprints(query *BlueprintQuery, shouldSanitize bool) ([]*models.Blueprint, int64, errors.Error) {
 	blueprints, count, err := bpManager.GetDbBlueprints(&services.GetBlueprintQuery{
-		Enable:      query.Enable,
-		IsManual:    query.IsManual,
-		Label:       query.Label,
-		SkipRecords: query.GetSkip(),
-		PageSize:    query.GetPageSize(),
-		Type:        query.Type,
+		Enable:       query.Enable,
+		IsManual:     query.IsManual,
+		Label:        query.Label,
+		SkipRecords:  query.GetSkip(),
+		PageSize:     query.GetPageSize(),
+		Type:         query.Type,
+		ProjectNames: query.ProjectNames,
 	})
 	if err != nil {
 		return nil, 0, err
@@ -199,12 +208,13 @@ func saveBlueprint(blueprint *models.Blueprint) (*models.Blueprint, errors.Error
 }
 
 // PatchBlueprint FIXME ...
-func PatchBlueprint(id uint64, body map[string]interface{}) (*models.Blueprint, errors.Error) {
+func PatchBlueprint(user *common.User, id uint64, body map[string]interface{}) (*models.Blueprint, errors.Error) {
 	// load record from db
 	blueprint, err := GetBlueprint(id, false)
 	if err != nil {
 		return nil, err
 	}
+	before := audithelper.Snapshot(blueprint)
 
 	originMode := blueprint.Mode
 	err = helper.DecodeMapStruct(body, blueprint, true)
@@ -226,6 +236,7 @@ func PatchBlueprint(id uint64, body map[string]interface{}) (*models.Blueprint,
 	if err != nil {
 		return nil, err
 	}
+	recordAuditEvent(user, models.AUDIT_ACTION_UPDATE, models.AUDIT_TARGET_BLUEPRINT, blueprint.ID, blueprint.Name, before, blueprint)
 	if err := SanitizeBlueprint(blueprint); err != nil {
 		return nil, errors.Convert(err)
 	}
@@ -233,7 +244,7 @@ func PatchBlueprint(id uint64, body map[string]interface{}) (*models.Blueprint,
 }
 
 // DeleteBlueprint FIXME ...
-func DeleteBlueprint(id uint64) errors.Error {
+func DeleteBlueprint(user *common.User, id uint64) errors.Error {
 	bp, err := bpManager.GetDbBlueprint(id)
 	if err != nil {
 		return err
@@ -249,6 +260,7 @@ func DeleteBlueprint(id uint64) errors.Error {
 	if err != nil {
 		return errors.Default.Wrap(err, "Failed to delete the blueprint")
 	}
+	recordAuditEvent(user, models.AUDIT_ACTION_DELETE, models.AUDIT_TARGET_BLUEPRINT, bp.ID, bp.Name, bp, nil)
 	return nil
 }
 
@@ -342,7 +354,7 @@ func createPipelineByBlueprint(blueprint *models.Blueprint, syncPolicy *models.S
 	// if !shouldCreatePipeline {
 	// 	return nil, ErrEmptyPlan
 	// }
-	pipeline, err := CreatePipeline(&newPipeline, false)
+	pipeline, err := CreateDbPipeline(&newPipeline)
 	// Return all created tasks to the User
 	if err != nil {
 		blueprintLog.Error(err, fmt.Sprintf("%s on blueprint:[%d][%s]", failToCreateCronJob, blueprint.ID, blueprint.Name))
@@ -407,7 +419,7 @@ func SequentializePipelinePlans(plans ...models.PipelinePlan) models.PipelinePla
 }
 
 // TriggerBlueprint triggers blueprint immediately
-func TriggerBlueprint(id uint64, triggerSyncPolicy *models.TriggerSyncPolicy, shouldSanitize bool) (*models.Pipeline, errors.Error) {
+func TriggerBlueprint(user *common.User, id uint64, triggerSyncPolicy *models.TriggerSyncPolicy, shouldSanitize bool) (*models.Pipeline, errors.Error) {
 	// load record from db
 	blueprint, err := GetBlueprint(id, false)
 	if err != nil {
@@ -427,6 +439,7 @@ func TriggerBlueprint(id uint64, triggerSyncPolicy *models.TriggerSyncPolicy, sh
 	if err != nil {
 		return nil, err
 	}
+	recordAuditEvent(user, models.AUDIT_ACTION_TRIGGER, models.AUDIT_TARGET_BLUEPRINT, blueprint.ID, blueprint.Name, nil, triggerSyncPolicy)
 	if shouldSanitize {
 		if err := SanitizePipeline(pipeline); err != nil {
 			return nil, errors.Convert(err)
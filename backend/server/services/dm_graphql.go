@@ -0,0 +1,481 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/utils"
+	"github.com/apache/incubator-devlake/server/services/graphql"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	defaultGraphqlFirst         = 20
+	maxGraphqlFirst             = 100
+	defaultGraphqlMaxDepth      = 8
+	defaultGraphqlMaxComplexity = 50000
+	// graphqlBatchSize keeps the IN lists of the batched queries below the parameter limits of the databases
+	graphqlBatchSize = 1000
+	// rows are tagged with the project they were reached from, relations never leave it
+	graphqlProjectKey  = "__project"
+	graphqlProjectsKey = "__projects"
+)
+
+// domainGraphCollections are the domain entities listed under a project, in the order of the schema
+var domainGraphCollections = []string{
+	"pull_requests", "commits", "cicd_deployments", "cicd_pipelines", "issues", "incidents", "sprints",
+}
+
+// domainLink is a relation between two domain entities through a link table
+type domainLink struct {
+	from, to   string
+	field      string
+	table      string
+	fromColumn string
+	toColumn   string
+}
+
+var domainLinks = []domainLink{
+	{from: "pull_requests", to: "commits", field: "commits", table: "pull_request_commits", fromColumn: "pull_request_id", toColumn: "commit_sha"},
+	{from: "pull_requests", to: "issues", field: "issues", table: "pull_request_issues", fromColumn: "pull_request_id", toColumn: "issue_id"},
+	{from: "commits", to: "pull_requests", field: "pullRequests", table: "pull_request_commits", fromColumn: "commit_sha", toColumn: "pull_request_id"},
+	{from: "commits", to: "issues", field: "issues", table: "issue_commits", fromColumn: "commit_sha", toColumn: "issue_id"},
+	{from: "commits", to: "cicd_deployments", field: "cicdDeployments", table: "cicd_deployment_commits", fromColumn: "commit_sha", toColumn: "cicd_deployment_id"},
+	{from: "cicd_deployments", to: "commits", field: "commits", table: "cicd_deployment_commits", fromColumn: "cicd_deployment_id", toColumn: "commit_sha"},
+	{from: "cicd_pipelines", to: "commits", field: "commits", table: "cicd_pipeline_commits", fromColumn: "pipeline_id", toColumn: "commit_sha"},
+	{from: "issues", to: "pull_requests", field: "pullRequests", table: "pull_request_issues", fromColumn: "issue_id", toColumn: "pull_request_id"},
+	{from: "issues", to: "commits", field: "commits", table: "issue_commits", fromColumn: "issue_id", toColumn: "commit_sha"},
+	{from: "issues", to: "sprints", field: "sprints", table: "sprint_issues", fromColumn: "issue_id", toColumn: "sprint_id"},
+	{from: "sprints", to: "issues", field: "issues", table: "sprint_issues", fromColumn: "sprint_id", toColumn: "issue_id"},
+}
+
+var (
+	domainGraphSchema     *graphql.Schema
+	domainGraphSchemaErr  errors.Error
+	domainGraphSchemaOnce sync.Once
+)
+
+// GetDomainGraphqlSchema returns the schema document of the domain graph
+func GetDomainGraphqlSchema() (string, errors.Error) {
+	s, err := getDomainGraphSchema()
+	if err != nil {
+		return "", err
+	}
+	return s.SDL(), nil
+}
+
+// ExecuteDomainGraphql runs a query on the domain graph within the accessible projects, nil means no restriction
+func ExecuteDomainGraphql(request *graphql.Request, projectNames []string) (interface{}, errors.Error) {
+	s, err := getDomainGraphSchema()
+	if err != nil {
+		return nil, err
+	}
+	limits := graphql.Limits{
+		MaxDepth:      cfg.GetInt("GRAPHQL_MAX_DEPTH"),
+		MaxComplexity: cfg.GetInt("GRAPHQL_MAX_COMPLEXITY"),
+	}
+	if limits.MaxDepth <= 0 {
+		limits.MaxDepth = defaultGraphqlMaxDepth
+	}
+	if limits.MaxComplexity <= 0 {
+		limits.MaxComplexity = defaultGraphqlMaxComplexity
+	}
+	return graphql.Execute(s, request, graphql.Row{graphqlProjectsKey: projectNames}, limits)
+}
+
+func getDomainGraphSchema() (*graphql.Schema, errors.Error) {
+	domainGraphSchemaOnce.Do(func() {
+		domainGraphSchema, domainGraphSchemaErr = buildDomainGraphSchema()
+	})
+	return domainGraphSchema, domainGraphSchemaErr
+}
+
+// buildDomainGraphSchema generates the object types from the domain models, and wires them up by the link tables
+func buildDomainGraphSchema() (*graphql.Schema, errors.Error) {
+	objects := make(map[string]*graphql.Object)
+	for name, entity := range domainEntities {
+		object, err := newGraphqlObject(entity.model, "")
+		if err != nil {
+			return nil, err
+		}
+		objects[name] = object
+	}
+	for _, link := range domainLinks {
+		objects[link.from].AddField(&graphql.Field{
+			Name:     link.field,
+			Object:   objects[link.to],
+			List:     true,
+			Args:     []*graphql.Argument{firstArgument()},
+			Requires: []string{domainEntities[link.from].keyColumn},
+			Cost:     firstCost,
+			Resolve:  resolveDomainLink(link),
+		})
+	}
+
+	project, err := newGraphqlObject(&models.Project{}, "A project and the domain entities of its data scopes")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range domainGraphCollections {
+		object := objects[name]
+		args := []*graphql.Argument{
+			firstArgument(),
+			{Name: "after", Type: graphql.String, Description: "the key of the last item of the previous page"},
+			{Name: "since", Type: graphql.DateTime, Description: "inclusive lower bound of timeField"},
+			{Name: "until", Type: graphql.DateTime, Description: "exclusive upper bound of timeField"},
+			{Name: "timeField", Type: graphql.String, Description: "one of " + strings.Join(domainEntities[name].timeFields, ", ")},
+		}
+		// every text column can be filtered by a list of values
+		for _, f := range object.Fields {
+			if f.Type == graphql.String {
+				args = append(args, &graphql.Argument{Name: f.Name, Type: "[" + graphql.String + "]"})
+			}
+		}
+		project.AddField(&graphql.Field{
+			Name:        graphqlFieldName(name),
+			Description: fmt.Sprintf("%s of the project ordered by %s", name, domainEntities[name].keyColumn),
+			Object:      object,
+			List:        true,
+			Args:        args,
+			Requires:    []string{"name"},
+			Cost:        firstCost,
+			Resolve:     resolveProjectEntities(name, object),
+		})
+	}
+
+	query := graphql.NewObject("Query", "").
+		AddField(&graphql.Field{
+			Name:        "projects",
+			Description: "the accessible projects ordered by name",
+			Object:      project,
+			List:        true,
+			Args: []*graphql.Argument{
+				firstArgument(),
+				{Name: "after", Type: graphql.String, Description: "the name of the last project of the previous page"},
+			},
+			Cost:    firstCost,
+			Resolve: resolveProjects,
+		}).
+		AddField(&graphql.Field{
+			Name:    "project",
+			Object:  project,
+			Args:    []*graphql.Argument{{Name: "name", Type: graphql.String + "!"}},
+			Resolve: resolveProjects,
+		})
+	return &graphql.Schema{Query: query}, nil
+}
+
+// newGraphqlObject maps the columns of a model to scalar fields, the raw data origin is left out
+func newGraphqlObject(model dal.Tabler, description string) (*graphql.Object, errors.Error) {
+	s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to parse %s", model.TableName()))
+	}
+	object := graphql.NewObject(s.Name, description)
+	for _, field := range s.Fields {
+		if field.DBName == "" || strings.HasPrefix(field.DBName, "_raw_data") {
+			continue
+		}
+		typ := graphqlScalarType(field.IndirectFieldType)
+		if typ == "" {
+			continue
+		}
+		object.AddField(&graphql.Field{Name: graphqlFieldName(field.DBName), Type: typ, Column: field.DBName})
+	}
+	return object, nil
+}
+
+func graphqlScalarType(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Time{}) {
+		return graphql.DateTime
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return graphql.String
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return graphql.Int
+	case reflect.Float32, reflect.Float64:
+		return graphql.Float
+	case reflect.Bool:
+		return graphql.Boolean
+	}
+	return ""
+}
+
+// graphqlFieldName turns a column name into camel case, e.g. base_repo_id into baseRepoId
+func graphqlFieldName(column string) string {
+	parts := strings.Split(column, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func firstArgument() *graphql.Argument {
+	return &graphql.Argument{
+		Name:        "first",
+		Type:        graphql.Int,
+		Default:     defaultGraphqlFirst,
+		Description: fmt.Sprintf("at most %d", maxGraphqlFirst),
+	}
+}
+
+func firstCost(args map[string]interface{}) int {
+	return graphqlFirst(args)
+}
+
+func graphqlFirst(args map[string]interface{}) int {
+	first, _ := args["first"].(int)
+	if first <= 0 {
+		return defaultGraphqlFirst
+	}
+	if first > maxGraphqlFirst {
+		return maxGraphqlFirst
+	}
+	return first
+}
+
+func graphqlString(args map[string]interface{}, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+// resolveProjects serves both the projects and the project field of the query
+func resolveProjects(params *graphql.ResolveParams) ([][]graphql.Row, errors.Error) {
+	accessible, _ := params.Parents[0][graphqlProjectsKey].([]string)
+	clauses := []dal.Clause{
+		dal.Select(strings.Join(quoteIdentifiers(utils.StringsUniq(append([]string{"name"}, params.Columns...))), ", ")),
+		dal.From(&models.Project{}),
+	}
+	if accessible != nil {
+		clauses = append(clauses, dal.Where("name IN ?", accessible))
+	}
+	if name := graphqlString(params.Args, "name"); name != "" {
+		if accessible != nil && !utils.StringsContains(accessible, name) {
+			return nil, errors.Forbidden.New(fmt.Sprintf("no access to project %s", name))
+		}
+		clauses = append(clauses, dal.Where("name = ?", name))
+	} else {
+		if after := graphqlString(params.Args, "after"); after != "" {
+			clauses = append(clauses, dal.Where("name > ?", after))
+		}
+		clauses = append(clauses, dal.Orderby("name"), dal.Limit(graphqlFirst(params.Args)))
+	}
+	rows, err := queryGraphqlRows(clauses)
+	if err != nil {
+		return nil, err
+	}
+	return [][]graphql.Row{rows}, nil
+}
+
+func resolveProjectEntities(entityName string, object *graphql.Object) graphql.Resolver {
+	entity := domainEntities[entityName]
+	return func(params *graphql.ResolveParams) ([][]graphql.Row, errors.Error) {
+		columns, err := getDomainColumns(entity.model)
+		if err != nil {
+			return nil, err
+		}
+		query := &DomainQuery{
+			Fields:    params.Columns,
+			TimeField: graphqlString(params.Args, "timeField"),
+			Filters:   make(map[string][]string),
+		}
+		if query.Since, err = parseGraphqlTime(params.Args, "since"); err != nil {
+			return nil, err
+		}
+		if query.Until, err = parseGraphqlTime(params.Args, "until"); err != nil {
+			return nil, err
+		}
+		if after := graphqlString(params.Args, "after"); after != "" {
+			query.Cursor = encodeDomainCursor(after)
+		}
+		for _, f := range object.Fields {
+			if values, ok := params.Args[f.Name].([]interface{}); ok {
+				for _, v := range values {
+					if v != nil {
+						query.Filters[f.Column] = append(query.Filters[f.Column], v.(string))
+					}
+				}
+			}
+		}
+		result := make([][]graphql.Row, len(params.Parents))
+		for i, parent := range params.Parents {
+			projectName := fmt.Sprint(parent["name"])
+			clauses, err := buildDomainQuery(entity, columns, projectName, query)
+			if err != nil {
+				return nil, err
+			}
+			clauses = append(clauses, dal.Orderby(quoteIdentifier(entity.keyColumn)+" ASC"), dal.Limit(graphqlFirst(params.Args)))
+			if result[i], err = queryGraphqlRows(clauses); err != nil {
+				return nil, err
+			}
+			tagGraphqlRows(result[i], projectName)
+		}
+		return result, nil
+	}
+}
+
+// resolveDomainLink loads the linked entities of all the parents in a few queries, those out of the project are left out
+func resolveDomainLink(link domainLink) graphql.Resolver {
+	from, to := domainEntities[link.from], domainEntities[link.to]
+	return func(params *graphql.ResolveParams) ([][]graphql.Row, errors.Error) {
+		columns, err := getDomainColumns(to.model)
+		if err != nil {
+			return nil, err
+		}
+		first := graphqlFirst(params.Args)
+		result := make([][]graphql.Row, len(params.Parents))
+		for projectName, indexes := range groupGraphqlRowsByProject(params.Parents) {
+			keys := make([]string, 0, len(indexes))
+			for _, i := range indexes {
+				keys = append(keys, graphqlKey(params.Parents[i][from.keyColumn]))
+			}
+			pairs, err := queryGraphqlLinks(link, utils.StringsUniq(keys))
+			if err != nil {
+				return nil, err
+			}
+			var targetKeys []string
+			for _, linked := range pairs {
+				targetKeys = append(targetKeys, linked...)
+			}
+			targets := make(map[string]graphql.Row)
+			fields := append([]string{to.keyColumn}, params.Columns...)
+			for _, batch := range batchStrings(utils.StringsUniq(targetKeys), graphqlBatchSize) {
+				clauses, err := buildDomainQuery(to, columns, projectName, &DomainQuery{Fields: fields})
+				if err != nil {
+					return nil, err
+				}
+				rows, err := queryGraphqlRows(append(clauses, dal.Where(quoteIdentifier(to.keyColumn)+" IN ?", batch)))
+				if err != nil {
+					return nil, err
+				}
+				tagGraphqlRows(rows, projectName)
+				for _, row := range rows {
+					targets[graphqlKey(row[to.keyColumn])] = row
+				}
+			}
+			for _, i := range indexes {
+				for _, key := range pairs[graphqlKey(params.Parents[i][from.keyColumn])] {
+					if row, ok := targets[key]; ok && len(result[i]) < first {
+						result[i] = append(result[i], row)
+					}
+				}
+			}
+		}
+		return result, nil
+	}
+}
+
+// queryGraphqlLinks returns the keys linked to each of the given keys, in order
+func queryGraphqlLinks(link domainLink, keys []string) (map[string][]string, errors.Error) {
+	pairs := make(map[string][]string)
+	for _, batch := range batchStrings(keys, graphqlBatchSize) {
+		rows, err := queryGraphqlRows([]dal.Clause{
+			dal.Select(fmt.Sprintf("%s AS from_key, %s AS to_key", link.fromColumn, link.toColumn)),
+			dal.From(link.table),
+			dal.Where(link.fromColumn+" IN ?", batch),
+			dal.Orderby(link.toColumn),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			fromKey := graphqlKey(row["from_key"])
+			pairs[fromKey] = append(pairs[fromKey], graphqlKey(row["to_key"]))
+		}
+	}
+	return pairs, nil
+}
+
+func queryGraphqlRows(clauses []dal.Clause) ([]graphql.Row, errors.Error) {
+	var maps []map[string]interface{}
+	if err := db.All(&maps, clauses...); err != nil {
+		return nil, err
+	}
+	rows := make([]graphql.Row, len(maps))
+	for i, m := range maps {
+		rows[i] = m
+	}
+	return rows, nil
+}
+
+func tagGraphqlRows(rows []graphql.Row, projectName string) {
+	for _, row := range rows {
+		row[graphqlProjectKey] = projectName
+	}
+}
+
+func groupGraphqlRowsByProject(rows []graphql.Row) map[string][]int {
+	groups := make(map[string][]int)
+	for i, row := range rows {
+		projectName := fmt.Sprint(row[graphqlProjectKey])
+		groups[projectName] = append(groups[projectName], i)
+	}
+	return groups
+}
+
+func graphqlKey(v interface{}) string {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+func parseGraphqlTime(args map[string]interface{}, name string) (*time.Time, errors.Error) {
+	value := graphqlString(args, name)
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, errors.BadInput.New(name + " must be RFC3339 or yyyy-mm-dd")
+}
+
+func quoteIdentifiers(names []string) []string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, quoteIdentifier(name))
+	}
+	return quoted
+}
+
+func batchStrings(values []string, size int) [][]string {
+	var batches [][]string
+	for len(values) > size {
+		batches = append(batches, values[:size])
+		values = values[size:]
+	}
+	if len(values) > 0 {
+		batches = append(batches, values)
+	}
+	return batches
+}
@@ -0,0 +1,71 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/server/services/graphql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDomainGraphSchema(t *testing.T) {
+	s, err := buildDomainGraphSchema()
+	assert.Nil(t, err)
+	project := s.Query.Field("project").Object
+	deployments := project.Field("cicdDeployments")
+	assert.Equal(t, "[String]", argumentType(deployments.Args, "environment"))
+	commits := deployments.Object.Field("commits")
+	assert.Equal(t, "Commit", commits.Object.Name)
+	assert.Equal(t, []string{"id"}, commits.Requires)
+	assert.Equal(t, graphql.DateTime, commits.Object.Field("authoredDate").Type)
+	assert.Equal(t, "authored_date", commits.Object.Field("authoredDate").Column)
+	assert.Nil(t, commits.Object.Field("rawDataTable"))
+	issues := commits.Object.Field("pullRequests").Object.Field("issues")
+	assert.Equal(t, "Issue", issues.Object.Name)
+
+	// the generated types are valid GraphQL types
+	data, err := graphql.Execute(s, &graphql.Request{Query: `{ __type(name: "Project") { name } }`}, graphql.Row{}, graphql.Limits{})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"__type": map[string]interface{}{"name": "Project"}}, data)
+}
+
+func TestGraphqlFieldName(t *testing.T) {
+	assert.Equal(t, "id", graphqlFieldName("id"))
+	assert.Equal(t, "baseRepoId", graphqlFieldName("base_repo_id"))
+}
+
+func TestGraphqlFirst(t *testing.T) {
+	assert.Equal(t, defaultGraphqlFirst, graphqlFirst(map[string]interface{}{}))
+	assert.Equal(t, 5, graphqlFirst(map[string]interface{}{"first": 5}))
+	assert.Equal(t, maxGraphqlFirst, graphqlFirst(map[string]interface{}{"first": 1000}))
+}
+
+func TestBatchStrings(t *testing.T) {
+	assert.Nil(t, batchStrings(nil, 2))
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, batchStrings([]string{"a", "b", "c"}, 2))
+}
+
+func argumentType(args []*graphql.Argument, name string) string {
+	for _, a := range args {
+		if a.Name == name {
+			return a.Type
+		}
+	}
+	return ""
+}
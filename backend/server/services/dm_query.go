@@ -0,0 +1,265 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/code"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/devops"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/ticket"
+)
+
+const (
+	defaultDomainQueryPageSize = 100
+	maxDomainQueryPageSize     = 1000
+)
+
+// DomainQuery filters and pages the rows of a domain entity, Filters are equality filters on columns,
+// several values of a column are OR'ed
+type DomainQuery struct {
+	Fields    []string
+	TimeField string
+	Since     *time.Time
+	Until     *time.Time
+	Filters   map[string][]string
+	Cursor    string
+	PageSize  int
+}
+
+// DomainQueryResult is a page of rows keyed by column name, NextCursor is empty on the last page
+type DomainQueryResult struct {
+	Data       []map[string]interface{} `json:"data"`
+	NextCursor string                   `json:"nextCursor,omitempty"`
+}
+
+// domainEntity describes how a domain table is paged and scoped to a project
+type domainEntity struct {
+	model     dal.Tabler
+	keyColumn string
+	// timeFields can be used for since/until, the first one is the default
+	timeFields []string
+	// projectScope selects the rows of a project, every ? is bound to the project name
+	projectScope string
+}
+
+var domainEntities = map[string]domainEntity{
+	"pull_requests": {
+		model:        &code.PullRequest{},
+		keyColumn:    "id",
+		timeFields:   []string{"created_date", "merged_date", "closed_date", "updated_date"},
+		projectScope: "base_repo_id IN (SELECT pm.row_id FROM project_mapping pm WHERE pm.project_name = ? AND pm.table = 'repos')",
+	},
+	"commits": {
+		model:      &code.Commit{},
+		keyColumn:  "sha",
+		timeFields: []string{"authored_date", "committed_date"},
+		projectScope: `sha IN (SELECT rc.commit_sha FROM repo_commits rc JOIN project_mapping pm ON pm.row_id = rc.repo_id
+			WHERE pm.project_name = ? AND pm.table = 'repos')`,
+	},
+	"cicd_deployments": {
+		model:        &devops.CICDDeployment{},
+		keyColumn:    "id",
+		timeFields:   []string{"created_date", "started_date", "finished_date"},
+		projectScope: "cicd_scope_id IN (SELECT pm.row_id FROM project_mapping pm WHERE pm.project_name = ? AND pm.table = 'cicd_scopes')",
+	},
+	"cicd_pipelines": {
+		model:        &devops.CICDPipeline{},
+		keyColumn:    "id",
+		timeFields:   []string{"created_date", "started_date", "finished_date"},
+		projectScope: "cicd_scope_id IN (SELECT pm.row_id FROM project_mapping pm WHERE pm.project_name = ? AND pm.table = 'cicd_scopes')",
+	},
+	"issues": {
+		model:      &ticket.Issue{},
+		keyColumn:  "id",
+		timeFields: []string{"created_date", "updated_date", "resolution_date"},
+		projectScope: `id IN (SELECT bi.issue_id FROM board_issues bi JOIN project_mapping pm ON pm.row_id = bi.board_id
+			WHERE pm.project_name = ? AND pm.table = 'boards')`,
+	},
+	"incidents": {
+		model:        &ticket.Incident{},
+		keyColumn:    "id",
+		timeFields:   []string{"created_date", "updated_date", "resolution_date"},
+		projectScope: "scope_id IN (SELECT pm.row_id FROM project_mapping pm WHERE pm.project_name = ?)",
+	},
+	"sprints": {
+		model:      &ticket.Sprint{},
+		keyColumn:  "id",
+		timeFields: []string{"started_date", "ended_date", "completed_date"},
+		projectScope: `id IN (SELECT bs.sprint_id FROM board_sprints bs JOIN project_mapping pm ON pm.row_id = bs.board_id
+			WHERE pm.project_name = ? AND pm.table = 'boards')`,
+	},
+}
+
+// domainColumns caches the column names of the domain tables, they only change with migrations
+var domainColumns sync.Map
+
+// GetDomainEntityNames returns the entities QueryDomainEntities accepts
+func GetDomainEntityNames() []string {
+	names := make([]string, 0, len(domainEntities))
+	for name := range domainEntities {
+		names = append(names, name)
+	}
+	return names
+}
+
+// QueryDomainEntities returns a page of the rows of a domain entity belonging to the project, ordered by key
+func QueryDomainEntities(projectName, entityName string, query *DomainQuery) (*DomainQueryResult, errors.Error) {
+	entity, ok := domainEntities[entityName]
+	if !ok {
+		return nil, errors.NotFound.New(fmt.Sprintf("unknown domain entity %s", entityName))
+	}
+	if _, err := getProjectByName(db, projectName); err != nil {
+		return nil, err
+	}
+	columns, err := getDomainColumns(entity.model)
+	if err != nil {
+		return nil, err
+	}
+	clauses, err := buildDomainQuery(entity, columns, projectName, query)
+	if err != nil {
+		return nil, err
+	}
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultDomainQueryPageSize
+	}
+	if pageSize > maxDomainQueryPageSize {
+		pageSize = maxDomainQueryPageSize
+	}
+	// one extra row tells whether there is a next page
+	clauses = append(clauses, dal.Orderby(quoteIdentifier(entity.keyColumn)+" ASC"), dal.Limit(pageSize+1))
+	rows := make([]map[string]interface{}, 0, pageSize+1)
+	err = db.All(&rows, clauses...)
+	if err != nil {
+		return nil, err
+	}
+	return pageDomainRows(rows, pageSize, entity.keyColumn), nil
+}
+
+// pageDomainRows cuts the extra row fetched beyond pageSize and points the cursor at the last returned key
+func pageDomainRows(rows []map[string]interface{}, pageSize int, keyColumn string) *DomainQueryResult {
+	result := &DomainQueryResult{Data: rows}
+	if len(rows) > pageSize {
+		result.Data = rows[:pageSize]
+		result.NextCursor = encodeDomainCursor(fmt.Sprint(rows[pageSize-1][keyColumn]))
+	}
+	return result
+}
+
+func buildDomainQuery(entity domainEntity, columns map[string]bool, projectName string, query *DomainQuery) ([]dal.Clause, errors.Error) {
+	selected := []string{quoteIdentifier(entity.keyColumn)}
+	for _, field := range query.Fields {
+		if !columns[field] {
+			return nil, errors.BadInput.New(fmt.Sprintf("unknown field %s", field))
+		}
+		if field != entity.keyColumn {
+			selected = append(selected, quoteIdentifier(field))
+		}
+	}
+	if len(query.Fields) == 0 {
+		selected = []string{"*"}
+	}
+	projectParams := make([]interface{}, strings.Count(entity.projectScope, "?"))
+	for i := range projectParams {
+		projectParams[i] = projectName
+	}
+	clauses := []dal.Clause{
+		dal.Select(strings.Join(selected, ", ")),
+		dal.From(entity.model.TableName()),
+		dal.Where(entity.projectScope, projectParams...),
+	}
+	if query.Since != nil || query.Until != nil {
+		timeField := query.TimeField
+		if timeField == "" {
+			timeField = entity.timeFields[0]
+		}
+		valid := false
+		for _, f := range entity.timeFields {
+			valid = valid || f == timeField
+		}
+		if !valid {
+			return nil, errors.BadInput.New(fmt.Sprintf("timeField must be one of %s", strings.Join(entity.timeFields, ", ")))
+		}
+		if query.Since != nil {
+			clauses = append(clauses, dal.Where(quoteIdentifier(timeField)+" >= ?", *query.Since))
+		}
+		if query.Until != nil {
+			clauses = append(clauses, dal.Where(quoteIdentifier(timeField)+" < ?", *query.Until))
+		}
+	}
+	for column, values := range query.Filters {
+		if !columns[column] {
+			return nil, errors.BadInput.New(fmt.Sprintf("unknown filter %s", column))
+		}
+		if len(values) == 0 {
+			return nil, errors.BadInput.New(fmt.Sprintf("filter %s needs at least one value", column))
+		}
+		clauses = append(clauses, dal.Where(quoteIdentifier(column)+" IN ?", values))
+	}
+	if query.Cursor != "" {
+		key, err := decodeDomainCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, dal.Where(quoteIdentifier(entity.keyColumn)+" > ?", key))
+	}
+	return clauses, nil
+}
+
+func getDomainColumns(model dal.Tabler) (map[string]bool, errors.Error) {
+	if cached, ok := domainColumns.Load(model.TableName()); ok {
+		return cached.(map[string]bool), nil
+	}
+	names, err := dal.GetColumnNames(db, model, nil)
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]bool, len(names))
+	for _, name := range names {
+		columns[name] = true
+	}
+	domainColumns.Store(model.TableName(), columns)
+	return columns, nil
+}
+
+// quoteIdentifier quotes a column name already checked against the table, some of them like `table` are reserved words
+func quoteIdentifier(name string) string {
+	if db != nil && db.Dialect() == "postgres" {
+		return `"` + name + `"`
+	}
+	return "`" + name + "`"
+}
+
+func encodeDomainCursor(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeDomainCursor(cursor string) (string, errors.Error) {
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", errors.BadInput.New("invalid cursor")
+	}
+	return string(key), nil
+}
@@ -0,0 +1,97 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var pullRequestColumns = map[string]bool{"id": true, "status": true, "title": true, "created_date": true, "merged_date": true}
+
+func TestBuildDomainQuery(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clauses, err := buildDomainQuery(domainEntities["pull_requests"], pullRequestColumns, "p1", &DomainQuery{
+		Fields:    []string{"title", "id"},
+		TimeField: "merged_date",
+		Since:     &since,
+		Filters:   map[string][]string{"status": {"MERGED", "CLOSED"}},
+		Cursor:    encodeDomainCursor("pr:9"),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []dal.Clause{
+		dal.Select("`id`, `title`"),
+		dal.From("pull_requests"),
+		dal.Where(domainEntities["pull_requests"].projectScope, "p1"),
+		dal.Where("`merged_date` >= ?", since),
+		dal.Where("`status` IN ?", []string{"MERGED", "CLOSED"}),
+		dal.Where("`id` > ?", "pr:9"),
+	}, clauses)
+}
+
+func TestBuildDomainQueryProjectScope(t *testing.T) {
+	// every placeholder of the scope is bound to the project, so rows of other projects never match
+	for name, entity := range domainEntities {
+		clauses, err := buildDomainQuery(entity, map[string]bool{}, "p1", &DomainQuery{})
+		assert.Nil(t, err, name)
+		assert.Equal(t, dal.Select("*"), clauses[0], name)
+		scope := clauses[2].Data.(dal.DalClause)
+		assert.Equal(t, entity.projectScope, scope.Expr, name)
+		assert.NotEmpty(t, scope.Params, name)
+		for _, param := range scope.Params {
+			assert.Equal(t, "p1", param, name)
+		}
+	}
+}
+
+func TestBuildDomainQueryValidation(t *testing.T) {
+	since := time.Now()
+	for name, query := range map[string]*DomainQuery{
+		"unknown field":      {Fields: []string{"password"}},
+		"unknown filter":     {Filters: map[string][]string{"1=1 OR `id`": {"x"}}},
+		"empty filter":       {Filters: map[string][]string{"status": {}}},
+		"invalid time field": {TimeField: "title", Since: &since},
+		"invalid cursor":     {Cursor: "not base64!"},
+	} {
+		_, err := buildDomainQuery(domainEntities["pull_requests"], pullRequestColumns, "p1", query)
+		if assert.Error(t, err, name) {
+			assert.Equal(t, errors.BadInput, err.GetType(), name)
+		}
+	}
+}
+
+func TestPageDomainRows(t *testing.T) {
+	rows := []map[string]interface{}{{"sha": "a"}, {"sha": "b"}, {"sha": "c"}}
+	page := pageDomainRows(rows, 2, "sha")
+	assert.Equal(t, rows[:2], page.Data)
+	cursor, err := decodeDomainCursor(page.NextCursor)
+	assert.Nil(t, err)
+	assert.Equal(t, "b", cursor)
+
+	// the next page starts after the cursor and is the last one
+	clauses, err := buildDomainQuery(domainEntities["commits"], map[string]bool{"sha": true}, "p1", &DomainQuery{Cursor: page.NextCursor})
+	assert.Nil(t, err)
+	assert.Equal(t, dal.Where("`sha` > ?", "b"), clauses[len(clauses)-1])
+	page = pageDomainRows(rows[2:], 2, "sha")
+	assert.Equal(t, rows[2:], page.Data)
+	assert.Empty(t, page.NextCursor)
+}
@@ -0,0 +1,257 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/plugin"
+	"github.com/apache/incubator-devlake/impls/dalgorm"
+)
+
+const reEncryptBatchSize = 500
+
+// EncryptionSecretRotation holds the new ENCRYPTION_SECRET
+type EncryptionSecretRotation struct {
+	NewSecret string `json:"newSecret" validate:"required"`
+}
+
+// EncryptionSecretRotationResult tells how many values were re-encrypted for each table
+type EncryptionSecretRotationResult struct {
+	Tables map[string]int `json:"tables"`
+	Total  int            `json:"total"`
+}
+
+// RotateEncryptionSecret re-encrypts all `encdec` fields of the framework and plugins tables with the new secret,
+// and switches the running instance to the new secret afterward. ENCRYPTION_SECRET must be updated accordingly
+// before the next restart.
+// Tables are re-encrypted batch by batch, each batch is committed along with the progress of the table, so an
+// interrupted rotation could be resumed by running it again with the same new secret.
+// Api keys are hmac digests of the secret and can't be re-hashed since the keys themselves are not stored, so the
+// rotation is refused while api keys exist, they have to be deleted beforehand and recreated afterward.
+func RotateEncryptionSecret(rotation *EncryptionSecretRotation) (*EncryptionSecretRotationResult, errors.Error) {
+	if err := VerifyStruct(rotation); err != nil {
+		return nil, err
+	}
+	oldSecret := cfg.GetString(plugin.EncodeKeyEnvStr)
+	if rotation.NewSecret == oldSecret {
+		return nil, errors.BadInput.New("the new secret is the same as the current one")
+	}
+	// running pipelines would keep writing values encrypted by the old secret
+	count, err := db.Count(dal.From(&models.Pipeline{}), dal.Where("status IN ?", models.PendingTaskStatus))
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.Conflict.New(fmt.Sprintf("%d pipelines are pending or running, please wait for them to finish", count))
+	}
+	count, err = db.Count(dal.From(&models.ApiKey{}))
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.Conflict.New(fmt.Sprintf("%d api keys are hashed with the current secret and can't be migrated, please delete them before rotating and recreate them afterward", count))
+	}
+
+	secretDigest := fmt.Sprintf("%x", sha256.Sum256([]byte(rotation.NewSecret)))
+	progresses := make(map[string]*models.EncryptionRotationProgress)
+	var previous []*models.EncryptionRotationProgress
+	err = db.All(&previous)
+	if err != nil {
+		return nil, err
+	}
+	for _, progress := range previous {
+		if progress.SecretDigest != secretDigest {
+			return nil, errors.Conflict.New("a rotation to another secret was interrupted, please resume it with the same new secret")
+		}
+		progresses[progress.EncryptedTable] = progress
+	}
+
+	result := &EncryptionSecretRotationResult{Tables: make(map[string]int)}
+	for _, table := range getEncryptedTables() {
+		if !db.HasTable(table.TableName()) {
+			continue
+		}
+		progress := progresses[table.TableName()]
+		if progress == nil {
+			progress = &models.EncryptionRotationProgress{EncryptedTable: table.TableName(), SecretDigest: secretDigest}
+		}
+		if progress.Done {
+			continue
+		}
+		var n int
+		n, err = reEncryptTable(table, progress, oldSecret, rotation.NewSecret)
+		if err != nil {
+			return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to re-encrypt table %s", table.TableName()))
+		}
+		if n > 0 {
+			result.Tables[table.TableName()] = n
+			result.Total += n
+		}
+	}
+	err = db.Delete(&models.EncryptionRotationProgress{}, dal.Where("1=1"))
+	if err != nil {
+		return nil, err
+	}
+	dalgorm.SetEncryptionSecret(rotation.NewSecret)
+	if setter, ok := cfg.(interface{ Set(string, interface{}) }); ok {
+		setter.Set(plugin.EncodeKeyEnvStr, rotation.NewSecret)
+	}
+	logger.Info("ENCRYPTION_SECRET rotated, %d values re-encrypted", result.Total)
+	return result, nil
+}
+
+// getEncryptedTables returns the framework tables and all plugins tables that might contain `encdec` fields
+func getEncryptedTables() []dal.Tabler {
+	tables := []dal.Tabler{
+		&models.Blueprint{},
+		&models.Pipeline{},
+		&models.Task{},
+	}
+	seen := make(map[string]bool)
+	for _, table := range tables {
+		seen[table.TableName()] = true
+	}
+	for _, pluginInst := range plugin.AllPlugins() {
+		pluginModel, ok := pluginInst.(plugin.PluginModel)
+		if !ok {
+			continue
+		}
+		for _, table := range pluginModel.GetTablesInfo() {
+			if !seen[table.TableName()] {
+				seen[table.TableName()] = true
+				tables = append(tables, table)
+			}
+		}
+	}
+	return tables
+}
+
+// reEncryptTable decrypts the `encdec` columns of the table with the old secret and encrypts them with the new one,
+// starting from the rows recorded by the progress
+func reEncryptTable(table dal.Tabler, progress *models.EncryptionRotationProgress, oldSecret, newSecret string) (int, errors.Error) {
+	pkColumns, encDecColumns, err := dalgorm.GetEncDecColumns(table)
+	if err != nil {
+		return 0, err
+	}
+	if len(encDecColumns) == 0 {
+		return 0, nil
+	}
+	if len(pkColumns) == 0 {
+		return 0, errors.Default.New("table with encrypted columns must have primary key")
+	}
+	total := 0
+	for !progress.Done {
+		var n int
+		n, err = reEncryptBatch(table.TableName(), pkColumns, encDecColumns, progress, oldSecret, newSecret)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// reEncryptBatch re-encrypts the next batch of rows and saves the progress in the same transaction
+func reEncryptBatch(table string, pkColumns, encDecColumns []string, progress *models.EncryptionRotationProgress, oldSecret, newSecret string) (n int, err errors.Error) {
+	// values are collected before updating since the cursor and updates can't share the connection
+	updates, err := reEncryptRows(table, pkColumns, encDecColumns, progress.Rows, oldSecret, newSecret)
+	if err != nil {
+		return 0, err
+	}
+	tx := db.Begin()
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	where := strings.Join(pkColumns, " = ? AND ") + " = ?"
+	for _, update := range updates {
+		if len(update.set) == 0 {
+			continue
+		}
+		err = tx.UpdateColumns(table, update.set, dal.Where(where, update.pks...))
+		if err != nil {
+			return 0, err
+		}
+		n += len(update.set)
+	}
+	progress.Rows += len(updates)
+	progress.Done = len(updates) < reEncryptBatchSize
+	err = tx.CreateOrUpdate(progress)
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+type reEncryptedRow struct {
+	pks []interface{}
+	set []dal.DalSet
+}
+
+func reEncryptRows(table string, pkColumns, encDecColumns []string, offset int, oldSecret, newSecret string) ([]reEncryptedRow, errors.Error) {
+	cursor, err := db.Cursor(
+		dal.Select(strings.Join(append(append([]string{}, pkColumns...), encDecColumns...), ", ")),
+		dal.From(table),
+		dal.Orderby(strings.Join(pkColumns, ", ")),
+		dal.Offset(offset),
+		dal.Limit(reEncryptBatchSize),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close()
+	var rows []reEncryptedRow
+	for cursor.Next() {
+		pks := make([]interface{}, len(pkColumns))
+		values := make([]*string, len(encDecColumns))
+		dest := make([]interface{}, 0, len(pks)+len(values))
+		for i := range pks {
+			dest = append(dest, &pks[i])
+		}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if e := cursor.Scan(dest...); e != nil {
+			return nil, errors.Convert(e)
+		}
+		row := reEncryptedRow{pks: pks}
+		for i, value := range values {
+			if value == nil || *value == "" || plugin.IsSecretRef(*value) {
+				continue
+			}
+			decrypted, err := plugin.Decrypt(oldSecret, *value)
+			if err != nil {
+				return nil, errors.Default.Wrap(err, fmt.Sprintf("failed to decrypt %s of %v", encDecColumns[i], pks))
+			}
+			encrypted, err := plugin.Encrypt(newSecret, decrypted)
+			if err != nil {
+				return nil, err
+			}
+			row.set = append(row.set, dal.DalSet{ColumnName: encDecColumns[i], Value: encrypted})
+		}
+		rows = append(rows, row)
+	}
+	return rows, errors.Convert(cursor.Err())
+}
@@ -0,0 +1,518 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// maxNesting bounds the nesting of selection sets, lists and arguments in a document
+const maxNesting = 64
+
+// Request is the body of a GraphQL request
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Response is the body of a GraphQL response
+type Response struct {
+	Data   interface{}      `json:"data"`
+	Errors []*ResponseError `json:"errors,omitempty"`
+}
+
+// ResponseError is an error of a GraphQL response
+type ResponseError struct {
+	Message string `json:"message"`
+}
+
+// Limits rejects expensive queries before they are executed, zero means no limit
+type Limits struct {
+	// MaxDepth is the maximum nesting of selection sets
+	MaxDepth int
+	// MaxComplexity is the maximum number of objects a query may resolve, estimated by the Cost of the fields
+	MaxComplexity int
+}
+
+// Execute validates the query of the request against the schema and the limits, and resolves it.
+// The root row is the parent of the query fields, e.g. to tell the resolvers who is asking
+func Execute(schema *Schema, request *Request, root Row, limits Limits) (interface{}, errors.Error) {
+	compiled, err := schema.compile()
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseAndValidate(&compiled, request.Query)
+	if err != nil {
+		return nil, err
+	}
+	op, err := selectOperation(doc, request.OperationName)
+	if err != nil {
+		return nil, err
+	}
+	if op.Operation != ast.OperationTypeQuery {
+		return nil, errors.BadInput.New(fmt.Sprintf("%s is not supported, only queries are", op.Operation))
+	}
+	if err = checkLimits(schema, doc, op, request.Variables, limits); err != nil {
+		return nil, err
+	}
+	ctx := context.WithValue(context.Background(), batchesKey{}, make(map[string]*batch))
+	result := gql.Execute(gql.ExecuteParams{
+		Schema:        compiled,
+		Root:          root,
+		AST:           doc,
+		OperationName: request.OperationName,
+		Args:          request.Variables,
+		Context:       ctx,
+	})
+	if len(result.Errors) > 0 {
+		return nil, executionError(result.Errors[0])
+	}
+	return result.Data, nil
+}
+
+// parseAndValidate turns the few malformed documents graphql-go panics on, e.g. a variable without a type,
+// into bad requests
+func parseAndValidate(schema *gql.Schema, query string) (doc *ast.Document, err errors.Error) {
+	if err = checkNesting(query); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			doc, err = nil, errors.BadInput.New(fmt.Sprintf("invalid document: %v", r))
+		}
+	}()
+	doc, e := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(query)})})
+	if e != nil {
+		return nil, badRequest(gqlerrors.FormatErrors(e))
+	}
+	// the other rules recurse endlessly on fragment cycles, so they only run on documents without any
+	for _, rules := range [][]gql.ValidationRuleFn{{gql.NoFragmentCyclesRule}, gql.SpecifiedRules} {
+		if validation := gql.ValidateDocument(schema, doc, rules); !validation.IsValid {
+			return nil, badRequest(validation.Errors)
+		}
+	}
+	return doc, nil
+}
+
+// checkNesting rejects documents nested deeper than any sensible query before the recursive descent parser
+// runs out of stack on them
+func checkNesting(query string) errors.Error {
+	depth := 0
+	for i := 0; i < len(query); i++ {
+		switch query[i] {
+		case '{', '[', '(':
+			if depth++; depth > maxNesting {
+				return errors.BadInput.New(fmt.Sprintf("the document is nested deeper than %d levels", maxNesting))
+			}
+		case '}', ']', ')':
+			depth--
+		case '#':
+			for i < len(query) && query[i] != '\n' && query[i] != '\r' {
+				i++
+			}
+		case '"':
+			if strings.HasPrefix(query[i:], `"""`) {
+				end := strings.Index(query[i+3:], `"""`)
+				if end < 0 {
+					return nil
+				}
+				i += end + 5
+				continue
+			}
+			for i++; i < len(query) && query[i] != '"' && query[i] != '\n'; i++ {
+				if query[i] == '\\' {
+					i++
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func badRequest(formatted []gqlerrors.FormattedError) errors.Error {
+	messages := make([]string, 0, len(formatted))
+	for _, f := range formatted {
+		messages = append(messages, f.Message)
+	}
+	return errors.BadInput.New(strings.Join(messages, "\n"))
+}
+
+// executionError keeps the type of the errors returned by the resolvers, e.g. Forbidden
+func executionError(formatted gqlerrors.FormattedError) errors.Error {
+	path := make([]string, 0, len(formatted.Path))
+	for _, key := range formatted.Path {
+		path = append(path, fmt.Sprint(key))
+	}
+	// the executor wraps the errors of thunks twice
+	for original := formatted.OriginalError(); original != nil; {
+		switch e := original.(type) {
+		case errors.Error:
+			return e.GetType().Wrap(e, strings.Join(path, "."))
+		case *gqlerrors.Error:
+			original = e.OriginalError
+		case gqlerrors.FormattedError:
+			original = e.OriginalError()
+		default:
+			original = nil
+		}
+	}
+	return errors.Default.New(fmt.Sprintf("%s: %s", strings.Join(path, "."), formatted.Message))
+}
+
+func selectOperation(doc *ast.Document, operationName string) (*ast.OperationDefinition, errors.Error) {
+	var operations []*ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		if op, ok := definition.(*ast.OperationDefinition); ok {
+			if operationName == "" || (op.Name != nil && op.Name.Value == operationName) {
+				operations = append(operations, op)
+			}
+		}
+	}
+	switch {
+	case len(operations) == 0 && operationName != "":
+		return nil, errors.BadInput.New(fmt.Sprintf("unknown operation %s", operationName))
+	case len(operations) == 0:
+		return nil, errors.BadInput.New("the document contains no operation")
+	case len(operations) > 1:
+		return nil, errors.BadInput.New("operationName is required when the document contains several operations")
+	}
+	return operations[0], nil
+}
+
+// limitChecker walks a validated operation, fields of the same response key are merged like the executor does
+type limitChecker struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	maxDepth  int
+}
+
+func checkLimits(schema *Schema, doc *ast.Document, op *ast.OperationDefinition, variables map[string]interface{}, limits Limits) errors.Error {
+	c := &limitChecker{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: make(map[string]interface{}),
+		maxDepth:  limits.MaxDepth,
+	}
+	for _, definition := range doc.Definitions {
+		if f, ok := definition.(*ast.FragmentDefinition); ok {
+			c.fragments[f.Name.Value] = f
+		}
+	}
+	for _, v := range op.VariableDefinitions {
+		if value, ok := variables[v.Variable.Name.Value]; ok {
+			c.variables[v.Variable.Name.Value] = value
+		} else if v.DefaultValue != nil {
+			c.variables[v.Variable.Name.Value] = c.value(v.DefaultValue)
+		}
+	}
+	total, err := c.complexity(schema.Query, []*ast.SelectionSet{op.SelectionSet}, 1)
+	if err != nil {
+		return err
+	}
+	if limits.MaxComplexity > 0 && total > limits.MaxComplexity {
+		return errors.BadInput.New(fmt.Sprintf("query complexity %d exceeds the limit %d", total, limits.MaxComplexity))
+	}
+	return nil
+}
+
+// complexity estimates the number of objects the selections resolve
+func (c *limitChecker) complexity(object *Object, selectionSets []*ast.SelectionSet, depth int) (int, errors.Error) {
+	if c.maxDepth > 0 && depth > c.maxDepth {
+		return 0, errors.BadInput.New(fmt.Sprintf("query depth exceeds the limit %d", c.maxDepth))
+	}
+	total := 0
+	for _, fields := range c.collect(selectionSets) {
+		field := object.Field(fields[0].Name.Value)
+		// introspection fields are not in the schema and cost nothing
+		if field == nil || field.Object == nil {
+			continue
+		}
+		var children []*ast.SelectionSet
+		for _, f := range fields {
+			children = append(children, f.SelectionSet)
+		}
+		childComplexity, err := c.complexity(field.Object, children, depth+1)
+		if err != nil {
+			return 0, err
+		}
+		n := 1
+		if field.Cost != nil {
+			n = field.Cost(c.arguments(field, fields[0]))
+		}
+		total += n * (1 + childComplexity)
+		if total > math.MaxInt32 {
+			return math.MaxInt32, nil
+		}
+	}
+	return total, nil
+}
+
+// collect flattens the fragments and groups the fields by response key, in order
+func (c *limitChecker) collect(selectionSets []*ast.SelectionSet) [][]*ast.Field {
+	var keys []string
+	fields := make(map[string][]*ast.Field)
+	var walk func(set *ast.SelectionSet)
+	walk = func(set *ast.SelectionSet) {
+		if set == nil {
+			return
+		}
+		for _, selection := range set.Selections {
+			switch s := selection.(type) {
+			case *ast.Field:
+				key := s.Name.Value
+				if s.Alias != nil {
+					key = s.Alias.Value
+				}
+				if _, ok := fields[key]; !ok {
+					keys = append(keys, key)
+				}
+				fields[key] = append(fields[key], s)
+			case *ast.InlineFragment:
+				walk(s.SelectionSet)
+			case *ast.FragmentSpread:
+				if f, ok := c.fragments[s.Name.Value]; ok {
+					walk(f.SelectionSet)
+				}
+			}
+		}
+	}
+	for _, set := range selectionSets {
+		walk(set)
+	}
+	collected := make([][]*ast.Field, 0, len(keys))
+	for _, key := range keys {
+		collected = append(collected, fields[key])
+	}
+	return collected
+}
+
+// arguments returns the argument values of the field as the resolvers get them
+func (c *limitChecker) arguments(field *Field, f *ast.Field) map[string]interface{} {
+	args := make(map[string]interface{}, len(field.Args))
+	for _, a := range field.Args {
+		if a.Default != nil {
+			args[a.Name] = a.Default
+		}
+	}
+	for _, a := range f.Arguments {
+		if value := c.value(a.Value); value != nil {
+			args[a.Name.Value] = value
+		}
+	}
+	return args
+}
+
+func (c *limitChecker) value(v ast.Value) interface{} {
+	switch value := v.(type) {
+	case *ast.Variable:
+		// json numbers of the variables are float64
+		if f, ok := c.variables[value.Name.Value].(float64); ok && f == math.Trunc(f) {
+			return int(f)
+		}
+		return c.variables[value.Name.Value]
+	case *ast.IntValue:
+		i, _ := strconv.Atoi(value.Value)
+		return i
+	case *ast.FloatValue:
+		f, _ := strconv.ParseFloat(value.Value, 64)
+		return f
+	case *ast.StringValue:
+		return value.Value
+	case *ast.BooleanValue:
+		return value.Value
+	case *ast.ListValue:
+		list := make([]interface{}, 0, len(value.Values))
+		for _, item := range value.Values {
+			list = append(list, c.value(item))
+		}
+		return list
+	}
+	return nil
+}
+
+type batchesKey struct{}
+
+// batch collects the parents of an object field at one path of the response. The executor resolves the
+// thunks returned by resolveBatched breadth first, so all the parents are known when the first thunk runs
+type batch struct {
+	params  *ResolveParams
+	results [][]Row
+}
+
+// load resolves the parents without results so far in one call
+func (b *batch) load(field *Field) errors.Error {
+	pending := len(b.params.Parents) - len(b.results)
+	if pending == 0 {
+		return nil
+	}
+	params := *b.params
+	params.Parents = b.params.Parents[len(b.results):]
+	results, err := field.Resolve(&params)
+	if err != nil {
+		return err
+	}
+	if len(results) != pending {
+		return errors.Default.New(fmt.Sprintf("resolved %d results for %d parents", len(results), pending))
+	}
+	b.results = append(b.results, results...)
+	return nil
+}
+
+func resolveBatched(field *Field) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		batches := p.Context.Value(batchesKey{}).(map[string]*batch)
+		key := pathKey(p.Info.Path)
+		b, ok := batches[key]
+		if !ok {
+			b = &batch{params: &ResolveParams{Args: p.Args, Columns: requiredColumns(field.Object, p.Info)}}
+			batches[key] = b
+		}
+		parent, _ := p.Source.(Row)
+		index := len(b.params.Parents)
+		b.params.Parents = append(b.params.Parents, parent)
+		return func() (interface{}, error) {
+			if err := b.load(field); err != nil {
+				return nil, err
+			}
+			rows := b.results[index]
+			if field.List {
+				return rows, nil
+			}
+			if len(rows) == 0 {
+				return nil, nil
+			}
+			return rows[0], nil
+		}, nil
+	}
+}
+
+// pathKey identifies a field of the query by its response path without the list indexes
+func pathKey(path *gql.ResponsePath) string {
+	var keys []string
+	for ; path != nil; path = path.Prev {
+		if key, ok := path.Key.(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+	return strings.Join(keys, ".")
+}
+
+// requiredColumns lists the columns of the rows needed by the selection of the resolved field
+func requiredColumns(object *Object, info gql.ResolveInfo) []string {
+	var columns []string
+	seen := make(map[string]bool)
+	var walk func(set *ast.SelectionSet)
+	walk = func(set *ast.SelectionSet) {
+		if set == nil {
+			return
+		}
+		for _, selection := range set.Selections {
+			switch s := selection.(type) {
+			case *ast.Field:
+				field := object.Field(s.Name.Value)
+				if field == nil {
+					continue
+				}
+				required := field.Requires
+				if field.Object == nil {
+					required = []string{field.column()}
+				}
+				for _, column := range required {
+					if !seen[column] {
+						seen[column] = true
+						columns = append(columns, column)
+					}
+				}
+			case *ast.InlineFragment:
+				walk(s.SelectionSet)
+			case *ast.FragmentSpread:
+				if f, ok := info.Fragments[s.Name.Value].(*ast.FragmentDefinition); ok {
+					walk(f.SelectionSet)
+				}
+			}
+		}
+	}
+	for _, f := range info.FieldASTs {
+		walk(f.SelectionSet)
+	}
+	return columns
+}
+
+func resolveScalar(field *Field) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (interface{}, error) {
+		row, _ := p.Source.(Row)
+		value, err := scalarValue(field.Type, row[field.column()])
+		if err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+}
+
+// scalarValue converts a database value to the json value of the scalar type
+func scalarValue(typ string, value interface{}) (interface{}, errors.Error) {
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case *time.Time:
+		if v == nil {
+			return nil, nil
+		}
+		value = *v
+	}
+	switch typ {
+	case Int, Float:
+		if s, ok := value.(string); ok {
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, errors.Default.Wrap(err, fmt.Sprintf("%s is not a number", s))
+			}
+			return f, nil
+		}
+	case Boolean:
+		switch v := value.(type) {
+		case int64:
+			return v != 0, nil
+		case string:
+			return v == "1" || v == "true", nil
+		}
+	case String, ID:
+		if _, ok := value.(string); !ok {
+			return fmt.Sprint(value), nil
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,215 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graphql
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// testSchema serves authors and their books from memory
+func testSchema(calls *int) *Schema {
+	books := map[string][]Row{
+		"a1": {{"title": "Dune", "pages": int64(412)}, {"title": "Children of Dune", "pages": int64(444)}},
+		"a2": {{"title": "Solaris", "pages": []byte("204")}},
+	}
+	book := NewObject("Book", "").
+		AddField(&Field{Name: "title", Type: String}).
+		AddField(&Field{Name: "pages", Type: Int})
+	author := NewObject("Author", "a writer").
+		AddField(&Field{Name: "id", Type: ID}).
+		AddField(&Field{Name: "name", Type: String}).
+		AddField(&Field{
+			Name:     "books",
+			Object:   book,
+			List:     true,
+			Args:     []*Argument{{Name: "first", Type: Int, Default: 10}},
+			Requires: []string{"id"},
+			Cost:     func(args map[string]interface{}) int { return args["first"].(int) },
+			Resolve: func(params *ResolveParams) ([][]Row, errors.Error) {
+				*calls++
+				result := make([][]Row, len(params.Parents))
+				for i, p := range params.Parents {
+					rows := books[p["id"].(string)]
+					if first := params.Args["first"].(int); len(rows) > first {
+						rows = rows[:first]
+					}
+					result[i] = rows
+				}
+				return result, nil
+			},
+		})
+	query := NewObject("Query", "").
+		AddField(&Field{
+			Name:   "authors",
+			Object: author,
+			List:   true,
+			Cost:   func(args map[string]interface{}) int { return 10 },
+			Resolve: func(params *ResolveParams) ([][]Row, errors.Error) {
+				return [][]Row{{{"id": "a1", "name": "Frank Herbert"}, {"id": "a2", "name": "Stanisław Lem"}}}, nil
+			},
+		}).
+		AddField(&Field{
+			Name:   "author",
+			Object: author,
+			Args:   []*Argument{{Name: "id", Type: "ID!"}},
+			Resolve: func(params *ResolveParams) ([][]Row, errors.Error) {
+				if params.Args["id"] == "a2" {
+					return [][]Row{{{"id": "a2", "name": "Stanisław Lem"}}}, nil
+				}
+				return [][]Row{nil}, nil
+			},
+		})
+	return &Schema{Query: query}
+}
+
+func execute(t *testing.T, query string, variables map[string]interface{}, limits Limits) (string, errors.Error) {
+	calls := 0
+	data, err := Execute(testSchema(&calls), &Request{Query: query, Variables: variables}, Row{}, limits)
+	if err != nil {
+		return "", err
+	}
+	body, e := json.Marshal(data)
+	assert.Nil(t, e)
+	return string(body), nil
+}
+
+func TestExecute(t *testing.T) {
+	body, err := execute(t, `
+		# every author with the first book
+		query Books($n: Int = 1) {
+			authors {
+				...AuthorName
+				books(first: $n) { title pages }
+				__typename
+			}
+		}
+		fragment AuthorName on Author { name }`, nil, Limits{})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"authors":[`+
+		`{"__typename":"Author","books":[{"pages":412,"title":"Dune"}],"name":"Frank Herbert"},`+
+		`{"__typename":"Author","books":[{"pages":204,"title":"Solaris"}],"name":"Stanisław Lem"}]}`, body)
+
+	body, err = execute(t, `query ($id: ID!, $withBooks: Boolean!) {
+		lem: author(id: $id) { id ... on Author { books @include(if: $withBooks) { title } } }
+		nobody: author(id: "a3") { id }
+	}`, map[string]interface{}{"id": "a2", "withBooks": false}, Limits{})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"lem":{"id":"a2"},"nobody":null}`, body)
+}
+
+func TestExecuteBatchesResolvers(t *testing.T) {
+	calls := 0
+	_, err := Execute(testSchema(&calls), &Request{Query: `{ authors { books { title } } }`}, Row{}, Limits{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestExecuteLimits(t *testing.T) {
+	query := `{ authors { books(first: 5) { title } } }`
+	_, err := execute(t, query, nil, Limits{MaxDepth: 2})
+	assert.Contains(t, err.Error(), "depth")
+	// 10 authors with 5 books each
+	_, err = execute(t, query, nil, Limits{MaxDepth: 3, MaxComplexity: 59})
+	assert.Contains(t, err.Error(), "complexity 60")
+	_, err = execute(t, query, nil, Limits{MaxDepth: 3, MaxComplexity: 60})
+	assert.Nil(t, err)
+}
+
+func TestExecuteErrors(t *testing.T) {
+	for query, message := range map[string]string{
+		`{ authors { age } }`:                                        `Cannot query field "age" on type "Author"`,
+		`{ authors }`:                                                "must have a sub selection",
+		`{ authors { name { first } } }`:                             "must not have a sub selection",
+		`{ author { name } }`:                                        `argument "id" of type "ID!" is required`,
+		`{ author(id: "a1", name: "x") { name } }`:                   `Unknown argument "name"`,
+		`{ authors { books(first: "2") { title } } }`:                `Argument "first" has invalid value "2"`,
+		`query ($n: Int) { authors { books(first: $m) { title } } }`: `Variable "$m" is not defined`,
+		`{ authors { ...Missing } }`:                                 `Unknown fragment "Missing"`,
+		`{ authors { ...A } } fragment A on Author { ...A }`:         `Cannot spread fragment "A" within itself`,
+		`{ authors { name: id name } }`:                              "conflict",
+		`mutation { authors { name } }`:                              "mutation is not supported",
+		`{ authors { name }`:                                         "Expected Name, found EOF",
+		`{ authors(first: 1.) { name } }`:                            "Invalid number",
+		strings.Repeat("{ authors ", 100) + strings.Repeat("}", 100): "nested deeper than 64 levels",
+	} {
+		_, err := execute(t, query, nil, Limits{})
+		if assert.NotNil(t, err, query) {
+			assert.True(t, strings.Contains(err.Messages().Format(), message), "%s: %s", query, err.Messages().Format())
+			assert.Equal(t, errors.BadInput, err.GetType())
+		}
+	}
+}
+
+func TestExecuteResolverError(t *testing.T) {
+	calls := 0
+	s := testSchema(&calls)
+	s.Query.AddField(&Field{
+		Name:   "secret",
+		Object: s.Query.Field("author").Object,
+		Resolve: func(params *ResolveParams) ([][]Row, errors.Error) {
+			return nil, errors.Forbidden.New("no access")
+		},
+	})
+	_, err := Execute(s, &Request{Query: `{ secret { name } }`}, Row{}, Limits{})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, errors.Forbidden, err.GetType())
+		assert.Contains(t, err.Messages().Format(), "secret")
+	}
+}
+
+func TestExecuteIntrospection(t *testing.T) {
+	body, err := execute(t, `{ __type(name: "Author") { fields { name } } }`, nil, Limits{MaxDepth: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"__type":{"fields":[{"name":"books"},{"name":"id"},{"name":"name"}]}}`, body)
+}
+
+func TestCheckNesting(t *testing.T) {
+	assert.Nil(t, checkNesting(`{ a(s: "{{{{", b: """ [[[[ """) { b } # ((((`+"\n"+`}`))
+	assert.NotNil(t, checkNesting(strings.Repeat("[", 65)))
+}
+
+// FuzzExecute makes sure no document crashes the parser, the validation or the executor
+func FuzzExecute(f *testing.F) {
+	for _, seed := range []string{
+		`query Books($n: Int = 1) { authors { ...AuthorName books(first: $n) { title pages } __typename } } fragment AuthorName on Author { name }`,
+		`{ lem: author(id: "a2") { id ... on Author { books @include(if: false) { title } } } }`,
+		`{ authors { ...A } } fragment A on Author { ...B } fragment B on Author { ...A }`,
+		`{ __schema { types { name fields { name type { name ofType { name } } } } } }`,
+		`{ authors(first: [[[1]]]) { name } }`,
+		`{ authors { name } } query { authors { id } }`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, query string) {
+		calls := 0
+		_, _ = Execute(testSchema(&calls), &Request{Query: query}, Row{}, Limits{MaxDepth: 8, MaxComplexity: 1000})
+	})
+}
+
+func TestSchemaSDL(t *testing.T) {
+	calls := 0
+	sdl := testSchema(&calls).SDL()
+	assert.Contains(t, sdl, "schema {\n  query: Query\n}")
+	assert.Contains(t, sdl, "\"\"\"a writer\"\"\"\ntype Author {\n  id: ID\n  name: String\n  books(first: Int = 10): [Book]\n}")
+	assert.Less(t, strings.Index(sdl, "type Query"), strings.Index(sdl, "type Author"))
+}
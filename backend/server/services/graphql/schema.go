@@ -0,0 +1,318 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graphql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/incubator-devlake/core/errors"
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// scalar types of the fields and arguments
+const (
+	String   = "String"
+	Int      = "Int"
+	Float    = "Float"
+	Boolean  = "Boolean"
+	ID       = "ID"
+	DateTime = "DateTime"
+)
+
+// Row holds the columns of a resolved object, scalar fields read their values from it
+type Row map[string]interface{}
+
+// Schema is the object graph requests are executed against, only queries are supported
+type Schema struct {
+	Query *Object
+
+	compileOnce sync.Once
+	compiled    gql.Schema
+	compileErr  errors.Error
+}
+
+// Object is an object type of the schema
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+// Field is either a scalar field read from the row by Column or an object field loaded by Resolve
+type Field struct {
+	Name        string
+	Description string
+	// Type is the scalar type of scalar fields
+	Type string
+	// Column is the key of the value in the row, default to Name
+	Column string
+	Object *Object
+	List   bool
+	Args   []*Argument
+	// Requires lists the columns of the parent rows needed by Resolve
+	Requires []string
+	Resolve  Resolver
+	// Cost estimates how many objects the field resolves per parent, 1 if nil. It drives the complexity limit
+	Cost func(args map[string]interface{}) int
+}
+
+// Argument of a field, Type is written as in the query language, e.g. Int, String! or [String]
+type Argument struct {
+	Name        string
+	Type        string
+	Description string
+	Default     interface{}
+}
+
+// ResolveParams are the inputs of a Resolver, the parents are resolved in one batch to save round trips
+type ResolveParams struct {
+	Parents []Row
+	Args    map[string]interface{}
+	// Columns are the columns of the resolved rows needed by the selection
+	Columns []string
+}
+
+// Resolver returns the rows of an object field for each of the parents, in the same order
+type Resolver func(params *ResolveParams) ([][]Row, errors.Error)
+
+// NewObject creates an object type
+func NewObject(name, description string) *Object {
+	return &Object{Name: name, Description: description}
+}
+
+// AddField appends a field to the object type
+func (o *Object) AddField(field *Field) *Object {
+	o.Fields = append(o.Fields, field)
+	return o
+}
+
+// Field looks up a field by name
+func (o *Object) Field(name string) *Field {
+	for _, f := range o.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func (f *Field) column() string {
+	if f.Column != "" {
+		return f.Column
+	}
+	return f.Name
+}
+
+func (f *Field) typeName() string {
+	name := f.Type
+	if f.Object != nil {
+		name = f.Object.Name
+	}
+	if f.List {
+		return "[" + name + "]"
+	}
+	return name
+}
+
+// SDL prints the schema in the schema definition language, e.g. for code generators that do not introspect
+func (s *Schema) SDL() string {
+	var objects []*Object
+	seen := make(map[*Object]bool)
+	var walk func(o *Object)
+	walk = func(o *Object) {
+		if seen[o] {
+			return
+		}
+		seen[o] = true
+		objects = append(objects, o)
+		for _, f := range o.Fields {
+			if f.Object != nil {
+				walk(f.Object)
+			}
+		}
+	}
+	walk(s.Query)
+	sort.SliceStable(objects[1:], func(i, j int) bool { return objects[i+1].Name < objects[j+1].Name })
+
+	var sb strings.Builder
+	sb.WriteString("schema {\n  query: " + s.Query.Name + "\n}\n\n")
+	sb.WriteString("\"\"\"RFC3339 date time\"\"\"\nscalar " + DateTime + "\n")
+	for _, o := range objects {
+		sb.WriteString("\n")
+		writeDescription(&sb, "", o.Description)
+		sb.WriteString("type " + o.Name + " {\n")
+		for _, f := range o.Fields {
+			writeDescription(&sb, "  ", f.Description)
+			sb.WriteString("  " + f.Name)
+			if len(f.Args) > 0 {
+				args := make([]string, 0, len(f.Args))
+				for _, a := range f.Args {
+					arg := a.Name + ": " + a.Type
+					if a.Default != nil {
+						arg += fmt.Sprintf(" = %v", formatValue(a.Default))
+					}
+					args = append(args, arg)
+				}
+				sb.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			sb.WriteString(": " + f.typeName() + "\n")
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
+
+func writeDescription(sb *strings.Builder, indent, description string) {
+	if description != "" {
+		sb.WriteString(indent + "\"\"\"" + description + "\"\"\"\n")
+	}
+}
+
+func formatValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(v)
+}
+
+var dateTimeScalar = gql.NewScalar(gql.ScalarConfig{
+	Name:        DateTime,
+	Description: "RFC3339 date time",
+	Serialize: func(value interface{}) interface{} {
+		if t, ok := value.(time.Time); ok {
+			return t.Format(time.RFC3339Nano)
+		}
+		return value
+	},
+	ParseValue: func(value interface{}) interface{} {
+		if s, ok := value.(string); ok {
+			return s
+		}
+		return nil
+	},
+	ParseLiteral: func(value ast.Value) interface{} {
+		if s, ok := value.(*ast.StringValue); ok {
+			return s.Value
+		}
+		return nil
+	},
+})
+
+var scalarTypes = map[string]*gql.Scalar{
+	String:   gql.String,
+	Int:      gql.Int,
+	Float:    gql.Float,
+	Boolean:  gql.Boolean,
+	ID:       gql.ID,
+	DateTime: dateTimeScalar,
+}
+
+// compile translates the schema into a graphql-go schema once, object fields resolve through batches
+func (s *Schema) compile() (gql.Schema, errors.Error) {
+	s.compileOnce.Do(func() {
+		objects := make(map[*Object]*gql.Object)
+		var err errors.Error
+		var compileObject func(o *Object) *gql.Object
+		compileObject = func(o *Object) *gql.Object {
+			if compiled, ok := objects[o]; ok {
+				return compiled
+			}
+			// fields are a thunk as the object graph has cycles, e.g. commits and their pull requests
+			objects[o] = gql.NewObject(gql.ObjectConfig{
+				Name:        o.Name,
+				Description: o.Description,
+				Fields: gql.FieldsThunk(func() gql.Fields {
+					fields := make(gql.Fields, len(o.Fields))
+					for _, f := range o.Fields {
+						field, fieldErr := compileField(f, compileObject)
+						if fieldErr != nil {
+							err = fieldErr
+							continue
+						}
+						fields[f.Name] = field
+					}
+					return fields
+				}),
+			})
+			return objects[o]
+		}
+		compiled, e := gql.NewSchema(gql.SchemaConfig{Query: compileObject(s.Query)})
+		switch {
+		case err != nil:
+			s.compileErr = err
+		case e != nil:
+			s.compileErr = errors.Default.Wrap(e, "invalid GraphQL schema")
+		default:
+			s.compiled = compiled
+		}
+	})
+	return s.compiled, s.compileErr
+}
+
+func compileField(f *Field, compileObject func(o *Object) *gql.Object) (*gql.Field, errors.Error) {
+	field := &gql.Field{Name: f.Name, Description: f.Description, Args: gql.FieldConfigArgument{}}
+	for _, a := range f.Args {
+		typ, err := inputType(a.Type)
+		if err != nil {
+			return nil, errors.Default.Wrap(err, fmt.Sprintf("argument %s of field %s", a.Name, f.Name))
+		}
+		field.Args[a.Name] = &gql.ArgumentConfig{Type: typ, DefaultValue: a.Default, Description: a.Description}
+	}
+	if f.Object == nil {
+		scalar, ok := scalarTypes[f.Type]
+		if !ok {
+			return nil, errors.Default.New(fmt.Sprintf("unknown type %s of field %s", f.Type, f.Name))
+		}
+		field.Type = scalar
+		field.Resolve = resolveScalar(f)
+		return field, nil
+	}
+	field.Type = compileObject(f.Object)
+	if f.List {
+		field.Type = gql.NewList(field.Type)
+	}
+	field.Resolve = resolveBatched(f)
+	return field, nil
+}
+
+// inputType parses a type written as in the query language, e.g. Int, String! or [String]
+func inputType(typ string) (gql.Input, errors.Error) {
+	if strings.HasSuffix(typ, "!") {
+		inner, err := inputType(strings.TrimSuffix(typ, "!"))
+		if err != nil {
+			return nil, err
+		}
+		return gql.NewNonNull(inner), nil
+	}
+	if strings.HasPrefix(typ, "[") && strings.HasSuffix(typ, "]") {
+		inner, err := inputType(typ[1 : len(typ)-1])
+		if err != nil {
+			return nil, err
+		}
+		return gql.NewList(inner), nil
+	}
+	if scalar, ok := scalarTypes[typ]; ok {
+		return scalar, nil
+	}
+	return nil, errors.Default.New(fmt.Sprintf("unknown input type %s", typ))
+}
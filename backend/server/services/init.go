@@ -29,6 +29,7 @@ import (
 	"github.com/apache/incubator-devlake/core/models/migrationscripts"
 	"github.com/apache/incubator-devlake/core/plugin"
 	"github.com/apache/incubator-devlake/core/runner"
+	"github.com/apache/incubator-devlake/helpers/audithelper"
 	"github.com/apache/incubator-devlake/helpers/pluginhelper/services"
 	"github.com/go-playground/validator/v10"
 	"github.com/robfig/cron/v3"
@@ -39,6 +40,7 @@ var logger log.Logger
 var db dal.Dal
 
 var bpManager *services.BlueprintManager
+var auditHelper *audithelper.AuditHelper
 var basicRes context.BasicRes
 var migrator plugin.Migrator
 var cronManager *cron.Cron
@@ -65,6 +67,7 @@ func InitResources() {
 	logger = basicRes.GetLogger()
 	db = basicRes.GetDal()
 	bpManager = services.NewBlueprintManager(db)
+	auditHelper = audithelper.NewAuditHelper(basicRes)
 	// initialize db migrator
 	migrator, err = runner.InitMigrator(basicRes)
 	if err != nil {
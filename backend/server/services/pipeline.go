@@ -34,6 +34,7 @@ import (
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
 	"github.com/apache/incubator-devlake/core/plugin"
 	"github.com/apache/incubator-devlake/core/utils"
 	"github.com/apache/incubator-devlake/helpers/dbhelper"
@@ -76,6 +77,8 @@ type PipelineQuery struct {
 	Pending     int    `form:"pending"`
 	BlueprintId uint64 `uri:"blueprintId" form:"blueprint_id"`
 	Label       string `form:"label"`
+	// ProjectNames limits pipelines to the projects accessible to the user, nil for no limitation
+	ProjectNames []string `form:"-" json:"-"`
 }
 
 func pipelineServiceInit() {
@@ -131,11 +134,12 @@ func markInterruptedPipelineAs(status string) {
 }
 
 // CreatePipeline and return the model
-func CreatePipeline(newPipeline *models.NewPipeline, shouldSanitize bool) (*models.Pipeline, errors.Error) {
+func CreatePipeline(user *common.User, newPipeline *models.NewPipeline, shouldSanitize bool) (*models.Pipeline, errors.Error) {
 	pipeline, err := CreateDbPipeline(newPipeline)
 	if err != nil {
 		return nil, errors.Convert(err)
 	}
+	recordAuditEvent(user, models.AUDIT_ACTION_TRIGGER, models.AUDIT_TARGET_PIPELINE, pipeline.ID, pipeline.Name, nil, newPipeline)
 	if shouldSanitize {
 		if err := SanitizePipeline(pipeline); err != nil {
 			return nil, errors.Convert(err)
@@ -471,10 +475,22 @@ func getPipelineLogsPath(pipeline *models.Pipeline) (string, errors.Error) {
 }
 
 // RerunPipeline would rerun all failed tasks or specified task
-func RerunPipeline(pipelineId uint64, task *models.Task) (tasks []*models.Task, err errors.Error) {
+func RerunPipeline(user *common.User, pipelineId uint64, task *models.Task) (tasks []*models.Task, err errors.Error) {
 	// prevent pipeline executor from doing anything that might jeopardize the integrity
 	pipeline := &models.Pipeline{}
 	txHelper := dbhelper.NewTxHelper(basicRes, &err)
+	defer func() {
+		// runs after the transaction gets committed
+		if err == nil {
+			rerunTaskIds := make([]uint64, 0, len(tasks))
+			for _, t := range tasks {
+				rerunTaskIds = append(rerunTaskIds, t.ID)
+			}
+			recordAuditEvent(user, models.AUDIT_ACTION_TRIGGER, models.AUDIT_TARGET_PIPELINE, pipeline.ID, pipeline.Name, nil, map[string]interface{}{
+				"rerunTaskIds": rerunTaskIds,
+			})
+		}
+	}()
 	tx := txHelper.Begin()
 	defer txHelper.End()
 	err = txHelper.LockTablesTimeout(2*time.Second, dal.LockTables{
@@ -18,13 +18,12 @@ limitations under the License.
 package services
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models"
 	"github.com/apache/incubator-devlake/core/utils"
+	"github.com/apache/incubator-devlake/helpers/pluginhelper/api"
 	"io"
 	"net/http"
 	"strings"
@@ -88,6 +87,5 @@ func (n *DefaultPipelineNotificationService) sendNotification(notificationType m
 }
 
 func (n *DefaultPipelineNotificationService) signature(input, nouce string) string {
-	sum := sha256.Sum256([]byte(input + n.Secret + nouce))
-	return hex.EncodeToString(sum[:])
+	return api.SignWithNonce([]byte(input), n.Secret, nouce)
 }
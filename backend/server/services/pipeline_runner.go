@@ -109,18 +109,20 @@ func runPipeline(pipelineId uint64) errors.Error {
 // ComputePipelineStatus determines pipleline status by its latest(rerun included) tasks statuses
 // 1. TASK_COMPLETED: all tasks were executed sucessfully
 // 2. TASK_FAILED: SkipOnFail=false with failed task(s)
-// 3. TASK_PARTIAL: SkipOnFail=true with failed task(s)
+// 3. TASK_PARTIAL: SkipOnFail=true with failed task(s), or partially succeeded task(s) without failed ones
 func ComputePipelineStatus(pipeline *models.Pipeline, isCancelled bool) (string, errors.Error) {
 	tasks, err := GetLatestTasksOfPipeline(pipeline)
 	if err != nil {
 		return "", err
 	}
 
-	succeeded, failed, pending, running := 0, 0, 0, 0
+	succeeded, partial, failed, pending, running := 0, 0, 0, 0, 0
 
 	for _, task := range tasks {
 		if task.Status == models.TASK_COMPLETED {
 			succeeded += 1
+		} else if task.Status == models.TASK_PARTIAL {
+			partial += 1
 		} else if task.Status == models.TASK_FAILED || task.Status == models.TASK_CANCELLED {
 			failed += 1
 		} else if task.Status == models.TASK_RUNNING {
@@ -134,10 +136,10 @@ func ComputePipelineStatus(pipeline *models.Pipeline, isCancelled bool) (string,
 		return "", errors.Default.New("unexpected status, did you call computePipelineStatus at a wrong timing?")
 	}
 
-	if failed == 0 {
+	if failed == 0 && partial == 0 {
 		return models.TASK_COMPLETED, nil
 	}
-	if pipeline.SkipOnFail && succeeded > 0 {
+	if failed == 0 || (pipeline.SkipOnFail && succeeded+partial > 0) {
 		return models.TASK_PARTIAL, nil
 	}
 	return models.TASK_FAILED, nil
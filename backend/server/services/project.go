@@ -27,7 +27,9 @@ import (
 	"github.com/apache/incubator-devlake/core/dal"
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
 	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+	"github.com/apache/incubator-devlake/helpers/audithelper"
 	helper "github.com/apache/incubator-devlake/helpers/pluginhelper/api"
 )
 
@@ -41,6 +43,8 @@ var projectService ProjectService
 type ProjectQuery struct {
 	Pagination
 	Keyword *string `json:"keyword" form:"keyword"`
+	// ProjectNames limits projects to the ones accessible to the user, nil for no limitation
+	ProjectNames []string `json:"-" form:"-"`
 }
 
 func (query *ProjectQuery) GetKeyword() string {
@@ -62,6 +66,9 @@ func GetProjects(query *ProjectQuery) ([]*models.ApiOutputProject, int64, errors
 	if query.Keyword != nil {
 		clauses = append(clauses, dal.Where("LOWER(name) LIKE ?", "%"+query.GetKeyword()+"%"))
 	}
+	if query.ProjectNames != nil {
+		clauses = append(clauses, dal.Where("name IN ?", query.ProjectNames))
+	}
 
 	count, err := db.Count(clauses...)
 	if err != nil {
@@ -100,7 +107,7 @@ func GetProjects(query *ProjectQuery) ([]*models.ApiOutputProject, int64, errors
 }
 
 // CreateProject accepts a project instance and insert it to database
-func CreateProject(projectInput *models.ApiInputProject) (*models.ApiOutputProject, errors.Error) {
+func CreateProject(user *common.User, projectInput *models.ApiInputProject) (*models.ApiOutputProject, errors.Error) {
 	// verify input
 	if err := VerifyStruct(projectInput); err != nil {
 		return nil, err
@@ -173,6 +180,7 @@ func CreateProject(projectInput *models.ApiInputProject) (*models.ApiOutputProje
 	if err != nil {
 		return nil, err
 	}
+	recordAuditEvent(user, models.AUDIT_ACTION_CREATE, models.AUDIT_TARGET_PROJECT, project.Name, project.Name, nil, projectInput)
 
 	return makeProjectOutput(project, false)
 }
@@ -193,7 +201,7 @@ func GetProject(name string) (*models.ApiOutputProject, errors.Error) {
 }
 
 // PatchProject FIXME ...
-func PatchProject(name string, body map[string]interface{}) (*models.ApiOutputProject, errors.Error) {
+func PatchProject(user *common.User, name string, body map[string]interface{}) (*models.ApiOutputProject, errors.Error) {
 	projectInput := &models.ApiInputProject{}
 
 	// load input
@@ -217,6 +225,7 @@ func PatchProject(name string, body map[string]interface{}) (*models.ApiOutputPr
 	if err != nil {
 		return nil, err
 	}
+	before := audithelper.Snapshot(project)
 
 	// allowed to changed the name
 	if projectInput.Name == "" {
@@ -275,6 +284,16 @@ func PatchProject(name string, body map[string]interface{}) (*models.ApiOutputPr
 		if err != nil {
 			return nil, err
 		}
+
+		// RoleBinding
+		err = tx.UpdateColumn(
+			&models.RoleBinding{},
+			"project_name", project.Name,
+			dal.Where("project_name = ?", name),
+		)
+		if err != nil {
+			return nil, err
+		}
 		if projectService != nil {
 			if err := projectService.RenameProject(tx, name, project.Name); err != nil {
 				return nil, err
@@ -317,6 +336,7 @@ func PatchProject(name string, body map[string]interface{}) (*models.ApiOutputPr
 	if err != nil {
 		return nil, err
 	}
+	recordAuditEvent(user, models.AUDIT_ACTION_UPDATE, models.AUDIT_TARGET_PROJECT, name, project.Name, before, project)
 
 	// all good, render output
 	return makeProjectOutput(project, false)
@@ -348,13 +368,13 @@ func thereAreUnfinishedPipelinesUnderBlueprint(blueprintID uint64) (bool, errors
 }
 
 // DeleteProject FIXME ...
-func DeleteProject(name string) errors.Error {
+func DeleteProject(user *common.User, name string) errors.Error {
 	// verify input
 	if name == "" {
 		return errors.BadInput.New("project name is missing")
 	}
 	// verify exists
-	_, err := getProjectByName(db, name)
+	project, err := getProjectByName(db, name)
 	if err != nil {
 		return err
 	}
@@ -399,7 +419,16 @@ func DeleteProject(name string) errors.Error {
 	if err != nil {
 		return errors.Default.Wrap(err, "error deleting project Issue metric")
 	}
-	return tx.Commit()
+	err = tx.Delete(&models.RoleBinding{}, dal.Where("project_name = ?", name))
+	if err != nil {
+		return errors.Default.Wrap(err, "error deleting project role bindings")
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	recordAuditEvent(user, models.AUDIT_ACTION_DELETE, models.AUDIT_TARGET_PROJECT, name, name, project, nil)
+	return nil
 }
 
 func deleteProjectBlueprint(projectName string) errors.Error {
@@ -0,0 +1,322 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
+	"github.com/apache/incubator-devlake/core/plugin"
+)
+
+// RoleBindingQuery used to query role bindings
+type RoleBindingQuery struct {
+	Pagination
+	Subject     string `form:"subject"`
+	ProjectName string `form:"projectName"`
+}
+
+// UserRoles holds the roles granted to a user, directly or through the groups
+type UserRoles struct {
+	Admin bool
+	// Projects maps project names to the highest role on them
+	Projects map[string]string
+}
+
+var projectRoleRanks = map[string]int{
+	models.ROLE_VIEWER:             1,
+	models.ROLE_PROJECT_MAINTAINER: 2,
+}
+
+// IsRbacEnabled tells if requests should be authorized by role bindings
+func IsRbacEnabled() bool {
+	return cfg.GetBool("RBAC_ENABLED")
+}
+
+// GetUserRoles collects the roles granted to the user by role bindings and RBAC_ADMINS
+func GetUserRoles(user *common.User) (*UserRoles, errors.Error) {
+	roles := &UserRoles{Projects: make(map[string]string)}
+	if user == nil {
+		return roles, nil
+	}
+	var subjects []string
+	for _, s := range []string{user.Name, user.Email} {
+		if s != "" {
+			subjects = append(subjects, s)
+		}
+	}
+	if len(subjects) == 0 {
+		return roles, nil
+	}
+	// admins configured by env, they work even before the role bindings table gets migrated
+	for _, admin := range strings.Split(cfg.GetString("RBAC_ADMINS"), ",") {
+		admin = strings.TrimSpace(admin)
+		for _, s := range subjects {
+			if admin != "" && strings.EqualFold(admin, s) {
+				roles.Admin = true
+				return roles, nil
+			}
+		}
+	}
+	// groups of the OIDC provider or the auth proxy could be granted admin by env as well
+	for _, adminGroup := range strings.Split(cfg.GetString("RBAC_ADMIN_GROUPS"), ",") {
+		adminGroup = strings.TrimSpace(adminGroup)
+		for _, group := range user.Groups {
+			if adminGroup != "" && adminGroup == group {
+				roles.Admin = true
+				return roles, nil
+			}
+		}
+	}
+	groups := user.Groups
+	if len(groups) == 0 {
+		groups = []string{""}
+	}
+	var bindings []*models.RoleBinding
+	err := db.All(&bindings, dal.Where(
+		"(subject_type = ? AND subject IN ?) OR (subject_type = ? AND subject IN ?)",
+		models.ROLE_SUBJECT_USER, subjects, models.ROLE_SUBJECT_GROUP, groups,
+	))
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error loading role bindings")
+	}
+	for _, binding := range bindings {
+		if binding.Role == models.ROLE_ADMIN {
+			roles.Admin = true
+			continue
+		}
+		if projectRoleRanks[binding.Role] > projectRoleRanks[roles.Projects[binding.ProjectName]] {
+			roles.Projects[binding.ProjectName] = binding.Role
+		}
+	}
+	return roles, nil
+}
+
+// HasRole tells if the user has the role, or a higher one, on the project
+func (r *UserRoles) HasRole(projectName string, role string) bool {
+	if r.Admin {
+		return true
+	}
+	if role == models.ROLE_ADMIN {
+		return false
+	}
+	granted, ok := r.Projects[projectName]
+	return ok && projectRoleRanks[granted] >= projectRoleRanks[role]
+}
+
+// HasRoleInAnyProject tells if the user has the role, or a higher one, on at least one project
+func (r *UserRoles) HasRoleInAnyProject(role string) bool {
+	if r.Admin {
+		return true
+	}
+	for projectName := range r.Projects {
+		if r.HasRole(projectName, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessibleProjects returns names of the projects the user can read, nil means no restriction
+func (r *UserRoles) AccessibleProjects() []string {
+	if r.Admin {
+		return nil
+	}
+	projectNames := make([]string, 0, len(r.Projects))
+	for projectName := range r.Projects {
+		projectNames = append(projectNames, projectName)
+	}
+	sort.Strings(projectNames)
+	return projectNames
+}
+
+// GetBlueprintProjectName returns the project the blueprint belongs to, empty for blueprints without project
+func GetBlueprintProjectName(blueprintId uint64) (string, errors.Error) {
+	blueprint := &models.Blueprint{}
+	err := db.First(blueprint, dal.Select("id, project_name"), dal.Where("id = ?", blueprintId))
+	if err != nil {
+		if db.IsErrorNotFound(err) {
+			return "", errors.NotFound.New(fmt.Sprintf("blueprint %d not found", blueprintId))
+		}
+		return "", err
+	}
+	return blueprint.ProjectName, nil
+}
+
+// GetPipelineProjectName returns the project the pipeline belongs to, empty for pipelines without project
+func GetPipelineProjectName(pipelineId uint64) (string, errors.Error) {
+	pipeline := &models.Pipeline{}
+	err := db.First(pipeline, dal.Select("id, blueprint_id"), dal.Where("id = ?", pipelineId))
+	if err != nil {
+		if db.IsErrorNotFound(err) {
+			return "", errors.NotFound.New(fmt.Sprintf("pipeline %d not found", pipelineId))
+		}
+		return "", err
+	}
+	if pipeline.BlueprintId == 0 {
+		return "", nil
+	}
+	return GetBlueprintProjectName(pipeline.BlueprintId)
+}
+
+// GetTaskProjectName returns the project the task belongs to, empty for tasks without project
+func GetTaskProjectName(taskId uint64) (string, errors.Error) {
+	task := &models.Task{}
+	err := db.First(task, dal.Select("id, pipeline_id"), dal.Where("id = ?", taskId))
+	if err != nil {
+		if db.IsErrorNotFound(err) {
+			return "", errors.NotFound.New(fmt.Sprintf("task %d not found", taskId))
+		}
+		return "", err
+	}
+	return GetPipelineProjectName(task.PipelineId)
+}
+
+// GetConnectionProjectNames returns the projects whose blueprints use the connection
+func GetConnectionProjectNames(pluginName string, connectionId uint64) ([]string, errors.Error) {
+	var projectNames []string
+	err := db.Pluck(
+		"DISTINCT bp.project_name",
+		&projectNames,
+		dal.From("_devlake_blueprint_connections bc"),
+		dal.Join("JOIN _devlake_blueprints bp ON bp.id = bc.blueprint_id"),
+		dal.Where("bc.plugin_name = ? AND bc.connection_id = ? AND bp.project_name <> ''", pluginName, connectionId),
+	)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error loading projects of the connection")
+	}
+	return projectNames, nil
+}
+
+// GetConnectionIdByName returns the id of the plugin connection with the given name
+func GetConnectionIdByName(pluginName string, connectionName string) (uint64, errors.Error) {
+	pluginMeta, err := plugin.GetPlugin(pluginName)
+	if err != nil {
+		return 0, err
+	}
+	pluginSource, ok := pluginMeta.(plugin.PluginSource)
+	if !ok {
+		return 0, errors.BadInput.New(fmt.Sprintf("plugin %s has no connections", pluginName))
+	}
+	var connectionIds []uint64
+	err = db.Pluck(
+		"id",
+		&connectionIds,
+		dal.From(pluginSource.Connection().TableName()),
+		dal.Where("name = ?", connectionName),
+	)
+	if err != nil {
+		return 0, errors.Default.Wrap(err, "error loading connection by name")
+	}
+	if len(connectionIds) == 0 {
+		return 0, errors.NotFound.New(fmt.Sprintf("connection %s not found", connectionName))
+	}
+	return connectionIds[0], nil
+}
+
+// GetProjectsConnectionIds returns ids of the plugin connections used by the projects
+func GetProjectsConnectionIds(pluginName string, projectNames []string) ([]uint64, errors.Error) {
+	var connectionIds []uint64
+	if len(projectNames) == 0 {
+		return connectionIds, nil
+	}
+	err := db.Pluck(
+		"DISTINCT bc.connection_id",
+		&connectionIds,
+		dal.From("_devlake_blueprint_connections bc"),
+		dal.Join("JOIN _devlake_blueprints bp ON bp.id = bc.blueprint_id"),
+		dal.Where("bc.plugin_name = ? AND bp.project_name IN ?", pluginName, projectNames),
+	)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error loading connections of the projects")
+	}
+	return connectionIds, nil
+}
+
+// GetRoleBindings returns a paginated list of role bindings based on `query`
+func GetRoleBindings(query *RoleBindingQuery) ([]*models.RoleBinding, int64, errors.Error) {
+	clauses := []dal.Clause{dal.From(&models.RoleBinding{})}
+	if query.Subject != "" {
+		clauses = append(clauses, dal.Where("subject = ?", query.Subject))
+	}
+	if query.ProjectName != "" {
+		clauses = append(clauses, dal.Where("project_name = ?", query.ProjectName))
+	}
+	count, err := db.Count(clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error getting DB count of role bindings")
+	}
+	clauses = append(clauses,
+		dal.Orderby("id DESC"),
+		dal.Offset(query.GetSkip()),
+		dal.Limit(query.GetPageSize()),
+	)
+	bindings := make([]*models.RoleBinding, 0)
+	err = db.All(&bindings, clauses...)
+	if err != nil {
+		return nil, 0, errors.Default.Wrap(err, "error finding DB role bindings")
+	}
+	return bindings, count, nil
+}
+
+// CreateRoleBinding grants a role to a user or group
+func CreateRoleBinding(user *common.User, binding *models.RoleBinding) errors.Error {
+	binding.ID = 0
+	if err := VerifyStruct(binding); err != nil {
+		return err
+	}
+	if binding.Role == models.ROLE_ADMIN {
+		binding.ProjectName = ""
+	} else {
+		if binding.ProjectName == "" {
+			return errors.BadInput.New(fmt.Sprintf("projectName is required by role %s", binding.Role))
+		}
+		if _, err := getProjectByName(db, binding.ProjectName); err != nil {
+			return err
+		}
+	}
+	err := db.Create(binding)
+	if err != nil {
+		return err
+	}
+	recordAuditEvent(user, models.AUDIT_ACTION_CREATE, models.AUDIT_TARGET_ROLE_BINDING, binding.ID, binding.Subject, nil, binding)
+	return nil
+}
+
+// DeleteRoleBinding revokes the role binding
+func DeleteRoleBinding(user *common.User, id uint64) errors.Error {
+	binding := &models.RoleBinding{}
+	err := db.First(binding, dal.Where("id = ?", id))
+	if err != nil {
+		if db.IsErrorNotFound(err) {
+			return errors.NotFound.New(fmt.Sprintf("role binding %d not found", id))
+		}
+		return err
+	}
+	err = db.Delete(binding)
+	if err != nil {
+		return err
+	}
+	recordAuditEvent(user, models.AUDIT_ACTION_DELETE, models.AUDIT_TARGET_ROLE_BINDING, binding.ID, binding.Subject, binding, nil)
+	return nil
+}
@@ -0,0 +1,51 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserRoles(t *testing.T) {
+	roles := &UserRoles{Projects: map[string]string{
+		"p1": models.ROLE_VIEWER,
+		"p2": models.ROLE_PROJECT_MAINTAINER,
+	}}
+	assert.True(t, roles.HasRole("p1", models.ROLE_VIEWER))
+	assert.False(t, roles.HasRole("p1", models.ROLE_PROJECT_MAINTAINER))
+	assert.True(t, roles.HasRole("p2", models.ROLE_VIEWER))
+	assert.True(t, roles.HasRole("p2", models.ROLE_PROJECT_MAINTAINER))
+	assert.False(t, roles.HasRole("p2", models.ROLE_ADMIN))
+	assert.False(t, roles.HasRole("p3", models.ROLE_VIEWER))
+	assert.True(t, roles.HasRoleInAnyProject(models.ROLE_PROJECT_MAINTAINER))
+	assert.False(t, roles.HasRoleInAnyProject(models.ROLE_ADMIN))
+	assert.Equal(t, []string{"p1", "p2"}, roles.AccessibleProjects())
+
+	none := &UserRoles{Projects: map[string]string{}}
+	assert.False(t, none.HasRoleInAnyProject(models.ROLE_VIEWER))
+	assert.NotNil(t, none.AccessibleProjects())
+	assert.Empty(t, none.AccessibleProjects())
+
+	admin := &UserRoles{Admin: true, Projects: map[string]string{}}
+	assert.True(t, admin.HasRole("p3", models.ROLE_ADMIN))
+	assert.True(t, admin.HasRoleInAnyProject(models.ROLE_PROJECT_MAINTAINER))
+	assert.Nil(t, admin.AccessibleProjects())
+}
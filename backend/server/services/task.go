@@ -27,6 +27,7 @@ import (
 	"github.com/apache/incubator-devlake/core/errors"
 	"github.com/apache/incubator-devlake/core/log"
 	"github.com/apache/incubator-devlake/core/models"
+	"github.com/apache/incubator-devlake/core/models/common"
 	"github.com/apache/incubator-devlake/impls/logruslog"
 )
 
@@ -220,12 +221,12 @@ func RunTasksStandalone(parentLogger log.Logger, taskIds []uint64) errors.Error
 }
 
 // RerunTask reruns specified task
-func RerunTask(taskId uint64) (*models.Task, errors.Error) {
+func RerunTask(user *common.User, taskId uint64) (*models.Task, errors.Error) {
 	task, err := GetTask(taskId)
 	if err != nil {
 		return nil, err
 	}
-	rerunTasks, err := RerunPipeline(task.PipelineId, task)
+	rerunTasks, err := RerunPipeline(user, task.PipelineId, task)
 	if err != nil {
 		return nil, err
 	}
@@ -365,14 +366,17 @@ func getTaskStatus(statuses []string) string {
 
 	failedCount := 0
 	completedCount := 0
+	partialCount := 0
 	for _, s := range statuses {
 		if s == models.TASK_FAILED {
 			failedCount++
 		} else if s == models.TASK_COMPLETED {
 			completedCount++
+		} else if s == models.TASK_PARTIAL {
+			partialCount++
 		}
 	}
-	if failedCount > 0 && completedCount > 0 {
+	if (failedCount > 0 && completedCount+partialCount > 0) || (partialCount > 0 && failedCount+completedCount+partialCount == len(statuses)) {
 		status = "TASK_PARTIAL"
 	} else if failedCount == len(statuses) {
 		status = models.TASK_FAILED
@@ -0,0 +1,36 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"testing"
+
+	"github.com/apache/incubator-devlake/core/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTaskStatus(t *testing.T) {
+	assert.Equal(t, "", getTaskStatus(nil))
+	assert.Equal(t, models.TASK_COMPLETED, getTaskStatus([]string{models.TASK_COMPLETED, models.TASK_COMPLETED}))
+	assert.Equal(t, models.TASK_FAILED, getTaskStatus([]string{models.TASK_FAILED}))
+	assert.Equal(t, models.TASK_PARTIAL, getTaskStatus([]string{models.TASK_FAILED, models.TASK_COMPLETED}))
+	// tasks which skipped part of their work
+	assert.Equal(t, models.TASK_PARTIAL, getTaskStatus([]string{models.TASK_PARTIAL, models.TASK_COMPLETED}))
+	assert.Equal(t, models.TASK_PARTIAL, getTaskStatus([]string{models.TASK_PARTIAL, models.TASK_FAILED}))
+	assert.Equal(t, models.TASK_RUNNING, getTaskStatus([]string{models.TASK_PARTIAL, models.TASK_RUNNING}))
+}
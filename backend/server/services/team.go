@@ -0,0 +1,45 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one or more
+contributor license agreements.  See the NOTICE file distributed with
+this work for additional information regarding copyright ownership.
+The ASF licenses this file to You under the Apache License, Version 2.0
+(the "License"); you may not use this file except in compliance with
+the License.  You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"fmt"
+
+	"github.com/apache/incubator-devlake/core/dal"
+	"github.com/apache/incubator-devlake/core/errors"
+	"github.com/apache/incubator-devlake/core/models/domainlayer/crossdomain"
+)
+
+// GetTeamSubtreeIds returns the ids of the team and all its sub-teams from team_closures, which is built by the
+// buildTeamClosure subtask of the org plugin
+func GetTeamSubtreeIds(teamId string) ([]string, errors.Error) {
+	var teamIds []string
+	err := db.Pluck(
+		"descendant_id",
+		&teamIds,
+		dal.From(&crossdomain.TeamClosure{}),
+		dal.Where("ancestor_id = ?", teamId),
+	)
+	if err != nil {
+		return nil, errors.Default.Wrap(err, "error loading sub-teams")
+	}
+	if len(teamIds) == 0 {
+		return nil, errors.NotFound.New(fmt.Sprintf("team %s not found", teamId))
+	}
+	return teamIds, nil
+}